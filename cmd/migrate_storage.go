@@ -0,0 +1,148 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/urfave/cli"
+)
+
+// CmdMigrateStorage represents the available migrate-storage sub-command.
+var CmdMigrateStorage = cli.Command{
+	Name:        "migrate-storage",
+	Usage:       "Migrate the storage",
+	Description: "This is a command for copying the attachments, LFS objects or avatars from the configured storage to another one.",
+	Action:      runMigrateStorage,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "config, c",
+			Value: "custom/conf/app.ini",
+			Usage: "Custom configuration file path",
+		},
+		cli.StringFlag{
+			Name:  "type, t",
+			Value: "",
+			Usage: "Kind of data to migrate: 'attachments', 'lfs' or 'avatars'",
+		},
+		cli.StringFlag{
+			Name:  "storage, s",
+			Value: setting.LocalStorageType,
+			Usage: "Type of the destination storage: 'local' or 's3'",
+		},
+		cli.StringFlag{
+			Name:  "path, p",
+			Value: "",
+			Usage: "Directory of the destination local storage",
+		},
+		cli.StringFlag{
+			Name:  "s3-endpoint",
+			Value: "localhost:9000",
+			Usage: "S3 endpoint of the destination storage",
+		},
+		cli.StringFlag{
+			Name:  "s3-access-key-id",
+			Value: "",
+			Usage: "S3 access key ID of the destination storage",
+		},
+		cli.StringFlag{
+			Name:  "s3-secret-access-key",
+			Value: "",
+			Usage: "S3 secret access key of the destination storage",
+		},
+		cli.StringFlag{
+			Name:  "s3-bucket",
+			Value: "gitea",
+			Usage: "S3 bucket of the destination storage",
+		},
+		cli.StringFlag{
+			Name:  "s3-location",
+			Value: "us-east-1",
+			Usage: "S3 location of the destination storage",
+		},
+		cli.StringFlag{
+			Name:  "s3-base-path",
+			Value: "",
+			Usage: "S3 base path of the destination storage, defaults to the type of data",
+		},
+		cli.BoolFlag{
+			Name:  "s3-use-ssl",
+			Usage: "Use SSL to connect to the S3 endpoint of the destination storage",
+		},
+	},
+}
+
+func runMigrateStorage(ctx *cli.Context) error {
+	if err := argsSet(ctx, "type"); err != nil {
+		return err
+	}
+	if ctx.IsSet("config") {
+		setting.CustomConf = ctx.String("config")
+	}
+	setting.NewContext()
+
+	var src setting.Storage
+	kind := ctx.String("type")
+	switch kind {
+	case "attachments":
+		src = setting.AttachmentStorage
+	case "lfs":
+		src = setting.LFSStorage
+	case "avatars":
+		src = setting.AvatarStorage
+	default:
+		return fmt.Errorf("unsupported type of data: %s", kind)
+	}
+
+	dst := setting.Storage{
+		Type:              ctx.String("storage"),
+		Path:              ctx.String("path"),
+		S3Endpoint:        ctx.String("s3-endpoint"),
+		S3AccessKeyID:     ctx.String("s3-access-key-id"),
+		S3SecretAccessKey: ctx.String("s3-secret-access-key"),
+		S3Bucket:          ctx.String("s3-bucket"),
+		S3Location:        ctx.String("s3-location"),
+		S3BasePath:        ctx.String("s3-base-path"),
+		S3UseSSL:          ctx.Bool("s3-use-ssl"),
+	}
+	if dst.S3BasePath == "" {
+		dst.S3BasePath = kind + "/"
+	}
+	if dst.Type == setting.LocalStorageType {
+		if dst.Path == "" {
+			return fmt.Errorf("the path of the local storage is not set")
+		}
+		if !filepath.IsAbs(dst.Path) {
+			dst.Path = filepath.Join(setting.AppWorkPath, dst.Path)
+		}
+	}
+
+	srcStorage, err := storage.NewStorage(src)
+	if err != nil {
+		return fmt.Errorf("source storage: %v", err)
+	}
+	dstStorage, err := storage.NewStorage(dst)
+	if err != nil {
+		return fmt.Errorf("destination storage: %v", err)
+	}
+
+	var count int
+	if err = srcStorage.IterateObjects(func(path string, obj storage.Object) error {
+		if _, err := storage.Copy(dstStorage, path, srcStorage, path); err != nil {
+			return fmt.Errorf("copy %s: %v", path, err)
+		}
+		count++
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Copied %d objects of %s\n", count, kind)
+	return nil
+}
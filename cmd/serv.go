@@ -188,6 +188,11 @@ func runServ(c *cli.Context) error {
 		fail("mirror repository is read-only", "")
 	}
 
+	// Prohibit push to archived repositories.
+	if requestedMode > models.AccessModeRead && repo.IsArchived {
+		fail("archived repository is read-only", "")
+	}
+
 	// Allow anonymous clone for public repositories.
 	var (
 		keyID int64
@@ -0,0 +1,64 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminHooks(t *testing.T) {
+	prepareTestEnv(t)
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+
+	req := NewRequest(t, "GET", "/admin/hooks")
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/admin/hooks/system", resp.Header().Get("Location"))
+
+	for _, kind := range []string{"system", "default"} {
+		req = NewRequestf(t, "GET", "/admin/hooks/%s", kind)
+		session.MakeRequest(t, req, http.StatusOK)
+
+		link := fmt.Sprintf("/admin/hooks/%s/gitea/new", kind)
+		req = NewRequest(t, "GET", link)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(fmt.Sprintf(`form[action="%s"]`, link)).Length())
+
+		req = NewRequestWithValues(t, "POST", link, map[string]string{
+			"_csrf":        GetCSRF(t, session, link),
+			"payload_url":  "http://example.com/" + kind,
+			"content_type": "1",
+			"events":       "push_only",
+			"active":       "on",
+		})
+		resp = session.MakeRequest(t, req, http.StatusFound)
+		hook := models.AssertExistsAndLoadBean(t, &models.Webhook{URL: "http://example.com/" + kind}).(*models.Webhook)
+		assert.EqualValues(t, 0, hook.RepoID)
+		assert.EqualValues(t, 0, hook.OrgID)
+		assert.Equal(t, kind == "system", hook.IsSystemWebhook)
+		assert.EqualValues(t, fmt.Sprintf("/admin/hooks/%s", kind), resp.Header().Get("Location"))
+
+		req = NewRequestf(t, "GET", "/admin/hooks/%s/%d", kind, hook.ID)
+		session.MakeRequest(t, req, http.StatusOK)
+	}
+
+	// a system hook is not shown as a default one and the hooks of the
+	// repositories are not shown at all
+	req = NewRequest(t, "GET", "/admin/hooks/default/4")
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/admin/hooks/system/1")
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	session = loginUser(t, "user2")
+	req = NewRequest(t, "GET", "/admin/hooks/system")
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -106,3 +106,81 @@ func TestAPISudoUserForbidden(t *testing.T) {
 	req := NewRequest(t, "GET", urlStr)
 	session.MakeRequest(t, req, http.StatusForbidden)
 }
+
+func TestAPIAdminHooks(t *testing.T) {
+	prepareTestEnv(t)
+	// user1 is an admin user
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/admin/hooks?token="+token, &api.CreateHookOption{
+		Type: "gitea",
+		Config: map[string]string{
+			"url":          "http://example.com/system",
+			"content_type": "json",
+		},
+		Events:          []string{"star"},
+		Active:          true,
+		IsSystemWebhook: true,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var systemHook api.Hook
+	DecodeJSON(t, resp, &systemHook)
+	assert.True(t, systemHook.IsSystemWebhook)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/hooks?token="+token, &api.CreateHookOption{
+		Type: "gitea",
+		Config: map[string]string{
+			"url":          "http://example.com/default",
+			"content_type": "json",
+		},
+		Active: true,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var defaultHook api.Hook
+	DecodeJSON(t, resp, &defaultHook)
+	assert.False(t, defaultHook.IsSystemWebhook)
+
+	req = NewRequestf(t, "GET", "/api/v1/admin/hooks?type=system&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var hooks []*api.Hook
+	DecodeJSON(t, resp, &hooks)
+	for _, hook := range hooks {
+		assert.True(t, hook.IsSystemWebhook)
+	}
+	req = NewRequestf(t, "GET", "/api/v1/admin/hooks?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var allHooks []*api.Hook
+	DecodeJSON(t, resp, &allHooks)
+	assert.True(t, len(allHooks) > len(hooks))
+
+	// the hooks of the repositories are not managed by the site administration
+	req = NewRequestf(t, "GET", "/api/v1/admin/hooks/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// a system hook receives the events of every repository
+	user2 := loginUser(t, "user2")
+	user2Token := getTokenForLoggedInUser(t, user2)
+	req = NewRequestf(t, "PUT", "/api/v1/user/starred/user2/repo1?token=%s", user2Token)
+	user2.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.HookTask{RepoID: 1, HookID: systemHook.ID, EventType: models.HookEventStar})
+
+	// a default hook is copied to every new repository
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/repos?token="+user2Token, &api.CreateRepoOption{
+		Name: "default-hooks",
+	})
+	resp = user2.MakeRequest(t, req, http.StatusCreated)
+	var repo api.Repository
+	DecodeJSON(t, resp, &repo)
+	models.AssertExistsAndLoadBean(t, &models.Webhook{RepoID: repo.ID, URL: "http://example.com/default"})
+	models.AssertNotExistsBean(t, &models.Webhook{RepoID: repo.ID, URL: "http://example.com/system"})
+
+	req = NewRequestf(t, "GET", "/api/v1/admin/hooks?token=%s", user2Token)
+	user2.MakeRequest(t, req, http.StatusForbidden)
+
+	for _, id := range []int64{systemHook.ID, defaultHook.ID} {
+		req = NewRequestf(t, "DELETE", "/api/v1/admin/hooks/%d?token=%s", id, token)
+		session.MakeRequest(t, req, http.StatusNoContent)
+		models.AssertNotExistsBean(t, &models.Webhook{ID: id})
+	}
+}
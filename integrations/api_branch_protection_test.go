@@ -0,0 +1,130 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIBranchProtection(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+		BranchName:              "master",
+		EnablePushWhitelist:     true,
+		PushWhitelistUsernames:  []string{"user2"},
+		EnableMergeWhitelist:    true,
+		MergeWhitelistUsernames: []string{"user2"},
+		StatusCheckContexts:     []string{"ci/build", " "},
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var bp api.BranchProtection
+	DecodeJSON(t, resp, &bp)
+	assert.EqualValues(t, "master", bp.BranchName)
+	assert.True(t, bp.EnablePushWhitelist)
+	assert.EqualValues(t, []string{"user2"}, bp.PushWhitelistUsernames)
+	assert.EqualValues(t, []string{"user2"}, bp.MergeWhitelistUsernames)
+	assert.EqualValues(t, []string{"ci/build"}, bp.StatusCheckContexts)
+	models.AssertExistsAndLoadBean(t, &models.ProtectedBranch{RepoID: 1, BranchName: "master"})
+
+	// the branch is already protected
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+		BranchName: "does-not-exist",
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// teams cannot be whitelisted for the repositories of a user
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+		PushWhitelistTeams: []string{"team1"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	approvals := int64(1)
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+		MergeWhitelistUsernames: []string{},
+		RequiredApprovals:       &approvals,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &bp)
+	assert.EqualValues(t, []string{"user2"}, bp.PushWhitelistUsernames)
+	assert.Empty(t, bp.MergeWhitelistUsernames)
+	assert.EqualValues(t, 1, bp.RequiredApprovals)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branch_protections?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var bps []*api.BranchProtection
+	DecodeJSON(t, resp, &bps)
+	if assert.Len(t, bps, 1) {
+		assert.EqualValues(t, "master", bps[0].BranchName)
+		assert.EqualValues(t, 1, bps[0].RequiredApprovals)
+	}
+
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/branch_protections/master?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.ProtectedBranch{RepoID: 1, BranchName: "master"})
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branch_protections/master?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIBranchProtectionTeams(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+		BranchName:          "master",
+		EnablePushWhitelist: true,
+		PushWhitelistTeams:  []string{"team1"},
+		MergeWhitelistTeams: []string{"team1"},
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var bp api.BranchProtection
+	DecodeJSON(t, resp, &bp)
+	assert.EqualValues(t, []string{"team1"}, bp.PushWhitelistTeams)
+	assert.EqualValues(t, []string{"team1"}, bp.MergeWhitelistTeams)
+
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user3/repo3/branch_protections/master?token="+token, &api.EditBranchProtectionOption{
+		PushWhitelistTeams: []string{"does-not-exist"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+func TestAPIBranchProtectionNotAdmin(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branch_protections?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIBranchProtectionArchived(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, repo.SetArchiveRepoState(true))
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+		BranchName: "master",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	// the protections of an archived repository can still be read
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/branch_protections?token=%s", token)
+	session.MakeRequest(t, req, http.StatusOK)
+}
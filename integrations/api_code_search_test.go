@@ -0,0 +1,85 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPISearchCode(t *testing.T) {
+	prepareTestEnv(t)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	// the index is updated in the background
+	models.UpdateRepoIndexer(repo)
+	time.Sleep(time.Second)
+
+	req := NewRequest(t, "GET", "/api/v1/code/search?q=Description")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var results api.CodeSearchResults
+	DecodeJSON(t, resp, &results)
+	assert.True(t, results.OK)
+	if assert.Len(t, results.Data, 1) {
+		assert.EqualValues(t, repo.ID, results.Data[0].Repository.ID)
+		assert.EqualValues(t, "README.md", results.Data[0].Path)
+		assert.EqualValues(t, "Markdown", results.Data[0].Language)
+		assert.NotEmpty(t, results.Data[0].Lines)
+	}
+	assert.EqualValues(t, []*api.CodeSearchLanguage{{Language: "Markdown", Count: 1}}, results.Languages)
+
+	for query, count := range map[string]int{
+		"q=Description&language=markdown&path=READ&owner=user2": 1,
+		"q=Description&language=Go":                             0,
+		"q=Description&path=docs/":                              0,
+		"q=Description&owner=user3":                             0,
+	} {
+		req = NewRequest(t, "GET", "/api/v1/code/search?"+query)
+		resp = MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &results)
+		assert.Len(t, results.Data, count, query)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=Description&owner=not-a-user")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequest(t, "GET", "/api/v1/code/search")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+func TestAPISearchCodeTokenScope(t *testing.T) {
+	prepareTestEnv(t)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	models.UpdateRepoIndexer(repo)
+	time.Sleep(time.Second)
+
+	// a token without any repository scope cannot search
+	token := &models.AccessToken{UID: 2, Name: "notifications", Scope: models.AccessTokenScopeNotification}
+	assert.NoError(t, models.NewAccessToken(token))
+	req := NewRequest(t, "GET", "/api/v1/code/search?q=Description&token="+token.Sha1)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	// a token restricted to other repositories does not find the file
+	token = &models.AccessToken{UID: 2, Name: "repo2", Scope: models.AccessTokenScopeRepoRead, RepoIDs: []int64{2}}
+	assert.NoError(t, models.NewAccessToken(token))
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=Description&token="+token.Sha1)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var results api.CodeSearchResults
+	DecodeJSON(t, resp, &results)
+	assert.Empty(t, results.Data)
+
+	token = &models.AccessToken{UID: 2, Name: "repo1", Scope: models.AccessTokenScopeRepoRead, RepoIDs: []int64{repo.ID}}
+	assert.NoError(t, models.NewAccessToken(token))
+	req = NewRequest(t, "GET", "/api/v1/code/search?q=Description&token="+token.Sha1)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &results)
+	assert.Len(t, results.Data, 1)
+}
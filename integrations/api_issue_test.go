@@ -62,3 +62,48 @@ func TestAPICreateIssue(t *testing.T) {
 		Title:      title,
 	})
 }
+
+func TestAPILockIssue(t *testing.T) {
+	prepareTestEnv(t)
+
+	// user4 can read the issues of repo1 but not lock them
+	session := loginUser(t, "user4")
+	readerToken := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/issues/1/lock?token="+readerToken, &api.LockIssueOption{})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	session = loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/issues/1/lock?token="+token, &api.LockIssueOption{
+		Reason: "Not a lock reason",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/issues/1/lock?token="+token, &api.LockIssueOption{
+		Reason: "Off-topic",
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, IsLocked: true})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: 1, Type: models.CommentTypeLock, Content: "Off-topic"})
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues/1")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var apiIssue api.Issue
+	DecodeJSON(t, resp, &apiIssue)
+	assert.True(t, apiIssue.IsLocked)
+
+	// only collaborators can comment on a locked issue
+	commentsURL := "/api/v1/repos/user2/repo1/issues/1/comments?token="
+	req = NewRequestWithJSON(t, "POST", commentsURL+readerToken, &api.CreateIssueCommentOption{
+		Body: "reader comment",
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/issues/1/lock?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: 1, Type: models.CommentTypeUnlock})
+	req = NewRequestWithJSON(t, "POST", commentsURL+readerToken, &api.CreateIssueCommentOption{
+		Body: "reader comment",
+	})
+	MakeRequest(t, req, http.StatusCreated)
+}
@@ -0,0 +1,147 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPINotification(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// the notifications read are only listed on demand
+	req := NewRequestf(t, "GET", "/api/v1/notifications?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var threads []*api.NotificationThread
+	DecodeJSON(t, resp, &threads)
+	if assert.Len(t, threads, 2) {
+		assert.EqualValues(t, 4, threads[0].ID)
+		assert.True(t, threads[0].Unread)
+		assert.EqualValues(t, "user2/repo1", threads[0].Repository.FullName)
+		assert.EqualValues(t, "issue2", threads[0].Subject.Title)
+		assert.EqualValues(t, 3, threads[1].ID)
+		assert.True(t, threads[1].Pinned)
+	}
+	req = NewRequestf(t, "GET", "/api/v1/notifications?all=true&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &threads)
+	assert.Len(t, threads, 3)
+	req = NewRequestf(t, "GET", "/api/v1/notifications?since=2000-01-01T00:00:01Z&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &threads)
+	assert.Len(t, threads, 0)
+	req = NewRequestf(t, "GET", "/api/v1/notifications?since=yesterday&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/notifications?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &threads)
+	assert.Len(t, threads, 2)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo2/notifications?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &threads)
+	assert.Len(t, threads, 0)
+
+	req = NewRequestf(t, "GET", "/api/v1/notifications/threads/4?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var thread api.NotificationThread
+	DecodeJSON(t, resp, &thread)
+	assert.EqualValues(t, 4, thread.ID)
+	assert.EqualValues(t, "Pull", thread.Subject.Type)
+
+	// the notifications of the other users are not found
+	req = NewRequestf(t, "GET", "/api/v1/notifications/threads/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "PATCH", "/api/v1/notifications/threads/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "GET", "/api/v1/notifications/new?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var count api.NotificationCount
+	DecodeJSON(t, resp, &count)
+	assert.EqualValues(t, 1, count.New)
+
+	req = NewRequestf(t, "PATCH", "/api/v1/notifications/threads/3?to-status=unread&token=%s", token)
+	session.MakeRequest(t, req, http.StatusResetContent)
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 3, Status: models.NotificationStatusUnread})
+
+	req = NewRequestf(t, "PUT", "/api/v1/notifications?token=%s", token)
+	session.MakeRequest(t, req, http.StatusResetContent)
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 3, Status: models.NotificationStatusRead})
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 4, Status: models.NotificationStatusRead})
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 1, Status: models.NotificationStatusUnread})
+
+	req = NewRequestf(t, "GET", "/api/v1/notifications/new?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &count)
+	assert.EqualValues(t, 0, count.New)
+}
+
+func TestAPINotificationRestrictedToken(t *testing.T) {
+	prepareTestEnv(t)
+
+	// the notifications of user2 are all about repo1
+	token := &models.AccessToken{
+		UID:     2,
+		Name:    "repo2",
+		Scope:   models.AccessTokenScope("notification,repo:read"),
+		RepoIDs: []int64{2},
+	}
+	assert.NoError(t, models.NewAccessToken(token))
+
+	req := NewRequestf(t, "GET", "/api/v1/notifications?all=true&token=%s", token.Sha1)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var threads []*api.NotificationThread
+	DecodeJSON(t, resp, &threads)
+	assert.Len(t, threads, 0)
+
+	req = NewRequestf(t, "GET", "/api/v1/notifications/new?token=%s", token.Sha1)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var count api.NotificationCount
+	DecodeJSON(t, resp, &count)
+	assert.EqualValues(t, 0, count.New)
+
+	req = NewRequestf(t, "GET", "/api/v1/notifications/threads/4?token=%s", token.Sha1)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "PATCH", "/api/v1/notifications/threads/4?token=%s", token.Sha1)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/notifications?token=%s", token.Sha1)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "PUT", "/api/v1/repos/user2/repo1/notifications?token=%s", token.Sha1)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "PUT", "/api/v1/notifications?token=%s", token.Sha1)
+	MakeRequest(t, req, http.StatusResetContent)
+	models.AssertExistsAndLoadBean(t, &models.Notification{ID: 4, Status: models.NotificationStatusUnread})
+}
+
+func TestAPINotificationEvents(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// the stream lasts until the client goes away
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req := NewRequestf(t, "GET", "/api/v1/notifications/events?token=%s", token).WithContext(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, "text/event-stream", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), "event: notification-count\nretry: 10000\ndata: {\"new\":1}\n\n")
+
+	// a signed in user gives the CSRF token instead of an access token
+	req = NewRequest(t, "GET", "/api/v1/notifications/events")
+	session.MakeRequest(t, req, http.StatusUnauthorized)
+}
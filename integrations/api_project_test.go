@@ -0,0 +1,229 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoProjects(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/projects?state=all&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	assert.Len(t, apiProjects, 2)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/projects?token="+token, &api.CreateProjectOption{
+		Title:     "API project",
+		BoardType: "basic_kanban",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, "API project", apiProject.Title)
+	assert.EqualValues(t, 1, apiProject.RepoID)
+	assert.Equal(t, api.StateOpen, apiProject.State)
+	models.AssertExistsAndLoadBean(t, &models.Project{ID: apiProject.ID, RepoID: 1, Type: models.ProjectTypeRepository})
+	models.AssertCount(t, &models.ProjectBoard{ProjectID: apiProject.ID}, 3)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/projects?token="+token, &api.CreateProjectOption{
+		Title:     "Invalid project",
+		BoardType: "unknown",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// user4 can read but not write the projects of repo1
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/projects/%d?token=%s", apiProject.ID, token)
+	session.MakeRequest(t, req, http.StatusOK)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/projects?token="+token, &api.CreateProjectOption{
+		Title: "Forbidden project",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/%d?token=%s", apiProject.ID, token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIEditProject(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	title := "Renamed project"
+	state := "closed"
+	closeBoardID := int64(2)
+	req := NewRequestWithJSON(t, "PATCH", "/api/v1/projects/1?token="+token, &api.EditProjectOption{
+		Title:        &title,
+		State:        &state,
+		CloseBoardID: &closeBoardID,
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.Equal(t, title, apiProject.Title)
+	assert.Equal(t, api.StateClosed, apiProject.State)
+	assert.EqualValues(t, 2, apiProject.CloseBoardID)
+	assert.Equal(t, 2, apiProject.OpenIssues)
+	models.AssertExistsAndLoadBean(t, &models.Project{ID: 1, Title: title, IsClosed: true, CloseBoardID: 2})
+
+	// the board of another project cannot be the close board
+	closeBoardID = 4
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/projects/1?token="+token, &api.EditProjectOption{
+		CloseBoardID: &closeBoardID,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.Project{ID: 1})
+	models.AssertNotExistsBean(t, &models.ProjectIssue{ProjectID: 1})
+}
+
+func TestAPIProjectBoards(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/projects/1/boards?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiBoards []*api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoards)
+	assert.Len(t, apiBoards, 3)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/projects/1/boards?token="+token, &api.CreateProjectBoardOption{
+		Title:   "Review",
+		Sorting: 3,
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiBoard api.ProjectBoard
+	DecodeJSON(t, resp, &apiBoard)
+	assert.Equal(t, "Review", apiBoard.Title)
+	models.AssertExistsAndLoadBean(t, &models.ProjectBoard{ID: apiBoard.ID, ProjectID: 1, Sorting: 3})
+
+	title := "In Review"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/projects/1/boards/%d?token=%s", apiBoard.ID, token), &api.EditProjectBoardOption{
+		Title: &title,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &apiBoard)
+	assert.Equal(t, title, apiBoard.Title)
+	assert.Equal(t, 3, apiBoard.Sorting)
+
+	// boards of other projects cannot be changed through this project
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/projects/1/boards/4?token="+token, &api.EditProjectBoardOption{
+		Title: &title,
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/1/boards/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.ProjectBoard{ID: 1})
+
+	// the issues of a deleted board are uncategorized
+	req = NewRequestf(t, "GET", "/api/v1/projects/1/boards/0/issues?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	assert.Len(t, apiIssues, 2)
+}
+
+func TestAPIMoveProjectIssue(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// issue 3 is added to the project
+	req := NewRequestWithJSON(t, "POST", "/api/v1/projects/1/boards/2/issues?token="+token, &api.MoveProjectIssueOption{
+		IssueID: 3,
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.ProjectIssue{IssueID: 3, ProjectID: 1, ProjectBoardID: 2})
+
+	// issue 2 is moved to the top of the board
+	position := 0
+	req = NewRequestWithJSON(t, "POST", "/api/v1/projects/1/boards/2/issues?token="+token, &api.MoveProjectIssueOption{
+		IssueID:  2,
+		Position: &position,
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequestf(t, "GET", "/api/v1/projects/1/boards/2/issues?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiIssues []*api.Issue
+	DecodeJSON(t, resp, &apiIssues)
+	if assert.Len(t, apiIssues, 2) {
+		assert.EqualValues(t, 2, apiIssues[0].ID)
+		assert.EqualValues(t, 3, apiIssues[1].ID)
+	}
+
+	// issues of other repositories cannot be put on a repository project
+	req = NewRequestWithJSON(t, "POST", "/api/v1/projects/1/boards/2/issues?token="+token, &api.MoveProjectIssueOption{
+		IssueID: 6,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/projects/1/issues/3?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.ProjectIssue{IssueID: 3})
+	models.AssertExistsAndLoadBean(t, &models.Comment{IssueID: 3, Type: models.CommentTypeProject, OldProjectID: 1})
+}
+
+func TestAPIOrgProjects(t *testing.T) {
+	prepareTestEnv(t)
+
+	// user4 is a member of org3 but not an owner
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "GET", "/api/v1/orgs/user3/projects?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var apiProjects []*api.Project
+	DecodeJSON(t, resp, &apiProjects)
+	if assert.Len(t, apiProjects, 1) {
+		assert.EqualValues(t, 3, apiProjects[0].OwnerID)
+	}
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/projects?token="+token, &api.CreateProjectOption{
+		Title: "Forbidden project",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/projects/3/boards?token="+token, &api.CreateProjectBoardOption{
+		Title: "Forbidden board",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	// user5 is not a member of org3
+	session = loginUser(t, "user5")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/projects/3?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/projects?token="+token, &api.CreateProjectOption{
+		Title:     "Org project",
+		BoardType: "bug_triage",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var apiProject api.Project
+	DecodeJSON(t, resp, &apiProject)
+	assert.EqualValues(t, 0, apiProject.RepoID)
+	assert.EqualValues(t, 3, apiProject.OwnerID)
+	models.AssertExistsAndLoadBean(t, &models.Project{ID: apiProject.ID, OwnerID: 3, Type: models.ProjectTypeOrganization})
+}
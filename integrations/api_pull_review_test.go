@@ -0,0 +1,179 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/test"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/Unknwon/com"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPullReview(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	otherSession := loginUser(t, "user1")
+	otherToken := getTokenForLoggedInUser(t, otherSession)
+
+	var lines []string
+	for i := 1; i <= 20; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/lines.txt?token="+token, &api.CreateFileOptions{
+		Content: base64.StdEncoding.EncodeToString([]byte(strings.Join(lines, "\n") + "\n")),
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	testRepoFork(t, otherSession, "user2", "repo1", "user1", "repo1")
+	testEditFile(t, otherSession, "user1", "repo1", "master", "README.md", "Hello, World (Edited)\n")
+	lines[19] = "last line"
+	testEditFile(t, otherSession, "user1", "repo1", "master", "lines.txt", strings.Join(lines, "\n")+"\n")
+	resp := testPullCreate(t, otherSession, "user1", "repo1", "master", "This is a pull title")
+	index := strings.Split(test.RedirectURL(resp), "/")[4]
+	reviewsURL := fmt.Sprintf("/api/v1/repos/user2/repo1/pulls/%s/reviews", index)
+
+	// the line does not exist
+	req = NewRequestWithJSON(t, "POST", reviewsURL+"?token="+token, &api.CreatePullReviewOptions{
+		Comments: []api.CreatePullReviewComment{{Path: "README.md", Body: "typo", NewLineNum: 2}},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the line is not part of the diff, no comment is created
+	req = NewRequestWithJSON(t, "POST", reviewsURL+"?token="+token, &api.CreatePullReviewOptions{
+		Comments: []api.CreatePullReviewComment{
+			{Path: "lines.txt", Body: "in the diff", NewLineNum: 20},
+			{Path: "lines.txt", Body: "not in the diff", NewLineNum: 1},
+		},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	models.AssertNotExistsBean(t, &models.Comment{Type: models.CommentTypeCode, TreePath: "lines.txt"})
+
+	// the merge base is computed when it is not known yet
+	pr, err := models.GetPullRequestByIndex(1, com.StrTo(index).MustInt64())
+	assert.NoError(t, err)
+	pr.MergeBase = ""
+	assert.NoError(t, pr.UpdateCols("merge_base"))
+
+	req = NewRequestWithJSON(t, "POST", reviewsURL+"?token="+token, &api.CreatePullReviewOptions{
+		Body: "first pass",
+		Comments: []api.CreatePullReviewComment{
+			{Path: "README.md", Body: "new line", NewLineNum: 1},
+			{Path: "README.md", Body: "old line", OldLineNum: 3},
+		},
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var pending api.PullReview
+	DecodeJSON(t, resp, &pending)
+	assert.EqualValues(t, api.ReviewStatePending, pending.State)
+	assert.EqualValues(t, 2, pending.CodeCommentsCount)
+
+	req = NewRequestf(t, "GET", "%s/%d/comments?token=%s", reviewsURL, pending.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var comments []*api.PullReviewComment
+	DecodeJSON(t, resp, &comments)
+	if assert.Len(t, comments, 2) {
+		assert.EqualValues(t, "README.md", comments[0].Path)
+		assert.EqualValues(t, 1, comments[0].LineNum)
+		assert.EqualValues(t, 3, comments[1].OldLineNum)
+	}
+
+	// pending reviews are only visible to their reviewer
+	req = NewRequestf(t, "GET", "%s/%d?token=%s", reviewsURL, pending.ID, otherToken)
+	otherSession.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("%s/%d?token=%s", reviewsURL, pending.ID, token), &api.SubmitPullReviewOptions{
+		Event: api.ReviewStateApproved,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var approved api.PullReview
+	DecodeJSON(t, resp, &approved)
+	assert.EqualValues(t, pending.ID, approved.ID)
+	assert.EqualValues(t, api.ReviewStateApproved, approved.State)
+	assert.EqualValues(t, "first pass", approved.Body)
+
+	// the review is not pending anymore
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "POST", "%s/%d/dismissals?token=%s", reviewsURL, approved.ID, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var dismissed api.PullReview
+	DecodeJSON(t, resp, &dismissed)
+	assert.True(t, dismissed.Dismissed)
+
+	// the poster cannot approve their own pull request
+	req = NewRequestWithJSON(t, "POST", reviewsURL+"?token="+otherToken, &api.CreatePullReviewOptions{
+		Event: api.ReviewStateApproved,
+		Body:  "LGTM",
+	})
+	otherSession.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", reviewsURL+"?token="+otherToken, &api.CreatePullReviewOptions{
+		Event: api.ReviewStateComment,
+		Body:  "Ready for review",
+	})
+	otherSession.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestf(t, "GET", "%s?token=%s", reviewsURL, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var reviews []*api.PullReview
+	DecodeJSON(t, resp, &reviews)
+	if assert.Len(t, reviews, 2) {
+		assert.EqualValues(t, api.ReviewStateApproved, reviews[0].State)
+		assert.EqualValues(t, api.ReviewStateComment, reviews[1].State)
+	}
+}
+
+func TestAPIPullReviewRequests(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	requestsURL := "/api/v1/repos/user2/repo1/pulls/3/requested_reviewers?token=" + token
+
+	req := NewRequestWithJSON(t, "POST", requestsURL, &api.PullReviewRequestOptions{
+		Reviewers: []string{"user4"},
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var requests []*api.PullReview
+	DecodeJSON(t, resp, &requests)
+	if assert.Len(t, requests, 1) {
+		assert.EqualValues(t, api.ReviewStateRequestReview, requests[0].State)
+		assert.EqualValues(t, "user4", requests[0].Reviewer.UserName)
+	}
+	models.AssertExistsAndLoadBean(t, &models.Review{IssueID: 3, ReviewerID: 4, Type: models.ReviewTypeRequest})
+
+	// the poster of the pull request
+	req = NewRequestWithJSON(t, "POST", requestsURL, &api.PullReviewRequestOptions{
+		Reviewers: []string{"user1"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", requestsURL, &api.PullReviewRequestOptions{
+		Reviewers: []string{"does-not-exist"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "DELETE", requestsURL, &api.PullReviewRequestOptions{
+		Reviewers: []string{"user4"},
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertNotExistsBean(t, &models.Review{IssueID: 3, ReviewerID: 4, Type: models.ReviewTypeRequest})
+
+	// only writers can request reviews
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/pulls/3/requested_reviewers?token="+token, &api.PullReviewRequestOptions{
+		Reviewers: []string{"user4"},
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
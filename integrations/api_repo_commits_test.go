@@ -0,0 +1,112 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIReposGetAllCommits(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits?limit=2&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var commits []*api.Commit
+	DecodeJSON(t, resp, &commits)
+	assert.EqualValues(t, "3", resp.Header().Get("X-Total-Count"))
+	if assert.Len(t, commits, 2) {
+		assert.EqualValues(t, "69554a64c1e6030f051e5c3f94bfbd773cd6a324", commits[0].SHA)
+		assert.EqualValues(t, "not signed commit\n", commits[0].RepoCommit.Message)
+		if assert.NotNil(t, commits[0].Author) {
+			assert.EqualValues(t, "user2", commits[0].Author.UserName)
+		}
+		if assert.Len(t, commits[0].Parents, 1) {
+			assert.EqualValues(t, "27566bd5738fc8b4e3fef3c5e72cce608537bd95", commits[0].Parents[0].SHA)
+		}
+		assert.Nil(t, commits[0].Files)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits?sha=good-sign&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &commits)
+	if assert.Len(t, commits, 1) {
+		assert.EqualValues(t, "f27c2b2b03dcab38beaf89b0ab4ff61f6de63441", commits[0].SHA)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits?since=2017-08-06T17:57:00Z&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, "2", resp.Header().Get("X-Total-Count"))
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits?since=yesterday&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits?sha=does-not-exist&token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIReposGetSingleCommit(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits/69554a64c1e6030f051e5c3f94bfbd773cd6a324?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var commit api.Commit
+	DecodeJSON(t, resp, &commit)
+	assert.EqualValues(t, "69554a64c1e6030f051e5c3f94bfbd773cd6a324", commit.SHA)
+	if assert.Len(t, commit.Files, 1) {
+		assert.EqualValues(t, "readme.md", commit.Files[0].Filename)
+		assert.EqualValues(t, "modified", commit.Files[0].Status)
+		assert.EqualValues(t, 1, commit.Files[0].Additions)
+		assert.EqualValues(t, 1, commit.Files[0].Deletions)
+	}
+	if assert.NotNil(t, commit.Stats) {
+		assert.EqualValues(t, 2, commit.Stats.Total)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits/master?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &commit)
+	assert.EqualValues(t, "69554a64c1e6030f051e5c3f94bfbd773cd6a324", commit.SHA)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/commits/does-not-exist?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIReposCompareCommits(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/compare/5099b81332712fe655e34e8dd63574f503f61811...master?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var compare api.Compare
+	DecodeJSON(t, resp, &compare)
+	assert.EqualValues(t, "ahead", compare.Status)
+	assert.EqualValues(t, 2, compare.AheadBy)
+	assert.EqualValues(t, 0, compare.BehindBy)
+	assert.EqualValues(t, 2, compare.TotalCommits)
+	assert.Len(t, compare.Commits, 2)
+	assert.EqualValues(t, "5099b81332712fe655e34e8dd63574f503f61811", compare.MergeBaseCommit.SHA)
+	if assert.Len(t, compare.Files, 1) {
+		assert.EqualValues(t, "readme.md", compare.Files[0].Filename)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/compare/master...5099b81332712fe655e34e8dd63574f503f61811?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &compare)
+	assert.EqualValues(t, "behind", compare.Status)
+	assert.Len(t, compare.Commits, 0)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo16/compare/master?token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIGetContents(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/README.md?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var contents api.ContentsResponse
+	DecodeJSON(t, resp, &contents)
+	assert.EqualValues(t, "README.md", contents.Name)
+	assert.EqualValues(t, "file", contents.Type)
+	if assert.NotNil(t, contents.Encoding) && assert.NotNil(t, contents.Content) {
+		assert.EqualValues(t, "base64", *contents.Encoding)
+		content, err := base64.StdEncoding.DecodeString(*contents.Content)
+		assert.NoError(t, err)
+		assert.EqualValues(t, "# repo1\n\nDescription for repo1", string(content))
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/README.md?ref=v1.1&token=%s", token)
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var entries []*api.ContentsResponse
+	DecodeJSON(t, resp, &entries)
+	if assert.Len(t, entries, 1) {
+		assert.EqualValues(t, "README.md", entries[0].Name)
+		assert.Nil(t, entries[0].Content)
+	}
+
+	// the content of files too large to be displayed is left out
+	defer func(size int64) {
+		setting.UI.MaxDisplayFileSize = size
+	}(setting.UI.MaxDisplayFileSize)
+	setting.UI.MaxDisplayFileSize = 10
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/README.md?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	contents = api.ContentsResponse{}
+	DecodeJSON(t, resp, &contents)
+	assert.EqualValues(t, 30, contents.Size)
+	assert.NotEmpty(t, contents.DownloadURL)
+	assert.Nil(t, contents.Content)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/README.md?ref=does-not-exist&token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/does-not-exist.md?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPICreateUpdateDeleteFile(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/docs/VERSION?token="+token, &api.CreateFileOptions{
+		FileOptions: api.FileOptions{
+			Message: "Add VERSION",
+			Author:  api.Identity{Name: "Release Bot", Email: "bot@example.com"},
+		},
+		Content: base64.StdEncoding.EncodeToString([]byte("1.0.0\n")),
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var created api.FileResponse
+	DecodeJSON(t, resp, &created)
+	if assert.NotNil(t, created.Content) && assert.NotNil(t, created.Commit) {
+		assert.EqualValues(t, "docs/VERSION", created.Content.Path)
+		assert.EqualValues(t, "Add VERSION\n", created.Commit.Message)
+		assert.EqualValues(t, "Release Bot", created.Commit.Author.Name)
+		assert.EqualValues(t, "Release Bot", created.Commit.Committer.Name)
+		assert.Len(t, created.Commit.Parents, 1)
+	}
+
+	// the file already exists
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the expected SHA does not match
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/contents/docs/VERSION?token="+token, &api.UpdateFileOptions{
+		SHA:     "0000000000000000000000000000000000000000",
+		Content: base64.StdEncoding.EncodeToString([]byte("1.0.1\n")),
+	})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/contents/docs/VERSION?token="+token, &api.UpdateFileOptions{
+		FileOptions: api.FileOptions{
+			NewBranchName: "bump-version",
+		},
+		SHA:     created.Content.SHA,
+		Content: base64.StdEncoding.EncodeToString([]byte("1.0.1\n")),
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var updated api.FileResponse
+	DecodeJSON(t, resp, &updated)
+	assert.NotEqual(t, created.Content.SHA, updated.Content.SHA)
+	assert.EqualValues(t, "Update 'docs/VERSION'\n", updated.Commit.Message)
+	assert.EqualValues(t, "User Two", updated.Commit.Author.Name)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/docs/VERSION?ref=bump-version&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var contents api.ContentsResponse
+	DecodeJSON(t, resp, &contents)
+	assert.EqualValues(t, base64.StdEncoding.EncodeToString([]byte("1.0.1\n")), *contents.Content)
+
+	// the new branch already exists
+	session.MakeRequest(t, NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/contents/docs/VERSION?token="+token, &api.UpdateFileOptions{
+		FileOptions: api.FileOptions{
+			NewBranchName: "bump-version",
+		},
+		SHA:     created.Content.SHA,
+		Content: base64.StdEncoding.EncodeToString([]byte("1.0.2\n")),
+	}), http.StatusUnprocessableEntity)
+
+	// the new branch name is not valid
+	for _, name := range []string{"bump..version", "bump version", "-bump", "bump.lock"} {
+		session.MakeRequest(t, NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/contents/docs/VERSION?token="+token, &api.UpdateFileOptions{
+			FileOptions: api.FileOptions{
+				NewBranchName: name,
+			},
+			SHA:     created.Content.SHA,
+			Content: base64.StdEncoding.EncodeToString([]byte("1.0.2\n")),
+		}), http.StatusUnprocessableEntity)
+	}
+
+	req = NewRequestWithJSON(t, "DELETE", "/api/v1/repos/user2/repo1/contents/docs/VERSION?token="+token, &api.DeleteFileOptions{
+		SHA: created.Content.SHA,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var deleted api.FileResponse
+	DecodeJSON(t, resp, &deleted)
+	assert.Nil(t, deleted.Content)
+	assert.EqualValues(t, "Delete 'docs/VERSION'\n", deleted.Commit.Message)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/contents/docs/VERSION?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPICreateFileProtectedBranch(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, models.UpdateProtectBranch(repo, &models.ProtectedBranch{
+		RepoID:     repo.ID,
+		BranchName: "master",
+	}, nil, nil, nil, nil))
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/VERSION?token="+token, &api.CreateFileOptions{
+		Content: base64.StdEncoding.EncodeToString([]byte("1.0.0\n")),
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/VERSION?token="+token, &api.CreateFileOptions{
+		FileOptions: api.FileOptions{
+			NewBranchName: "release",
+		},
+		Content: base64.StdEncoding.EncodeToString([]byte("1.0.0\n")),
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+}
+
+func TestAPICreateFileNoWriteAccess(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/VERSION?token="+token, &api.CreateFileOptions{
+		Content: base64.StdEncoding.EncodeToString([]byte("1.0.0\n")),
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
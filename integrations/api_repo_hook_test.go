@@ -0,0 +1,211 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoHookDeliveries(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/hooks/1/deliveries?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var deliveries []*api.HookDelivery
+	DecodeJSON(t, resp, &deliveries)
+	if assert.Len(t, deliveries, 1) {
+		assert.EqualValues(t, 1, deliveries[0].ID)
+		assert.EqualValues(t, "uuid1", deliveries[0].UUID)
+		assert.EqualValues(t, api.HookDeliveryStatusFailed, deliveries[0].Status)
+		assert.EqualValues(t, 1, deliveries[0].Attempts)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/hooks/1/deliveries/1?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var delivery api.HookDelivery
+	DecodeJSON(t, resp, &delivery)
+	assert.EqualValues(t, "uuid1", delivery.UUID)
+
+	// the delivery belongs to another hook
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/hooks/2/deliveries/1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/hooks/1/deliveries/1/redeliver?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var redelivery api.HookDelivery
+	DecodeJSON(t, resp, &redelivery)
+	assert.NotEqual(t, delivery.ID, redelivery.ID)
+	assert.NotEqual(t, delivery.UUID, redelivery.UUID)
+	models.AssertExistsAndLoadBean(t, &models.HookTask{ID: redelivery.ID, HookID: 1})
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/hooks/1/deliveries?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, "2", resp.Header().Get("X-Total-Count"))
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/settings/hooks/1/deliveries/1/redeliver", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/settings/hooks/1"),
+	})
+	resp := session.MakeRequest(t, req, http.StatusFound)
+	assert.EqualValues(t, "/user2/repo1/settings/hooks/1", resp.Header().Get("Location"))
+	assert.EqualValues(t, 2, models.GetCount(t, &models.HookTask{HookID: 1}))
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/hooks/2/deliveries/1/redeliver", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/settings/hooks/1"),
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIRepoHookTypes(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/hooks?token="+token, &api.CreateHookOption{
+		Type: "telegram",
+		Config: map[string]string{
+			"bot_token": "123:token",
+			"chat_id":   "-100",
+		},
+		Active: true,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var hook api.Hook
+	DecodeJSON(t, resp, &hook)
+	assert.EqualValues(t, "https://api.telegram.org/bot123:token/sendMessage?chat_id=-100", hook.Config["url"])
+	assert.EqualValues(t, "-100", hook.Config["chat_id"])
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/hooks?token="+token, &api.CreateHookOption{
+		Type: "matrix",
+		Config: map[string]string{
+			"homeserver_url": "https://matrix.example.com",
+			"room_id":        "!room:example.com",
+		},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/hooks?token="+token, &api.CreateHookOption{
+		Type: "custom",
+		Config: map[string]string{
+			"url":           "http://example.com/hook",
+			"content_type":  "json",
+			"template":      `{"text": {{json .ref}}}`,
+			"template_push": "{{.ref",
+		},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/hooks?token="+token, &api.CreateHookOption{
+		Type: "custom",
+		Config: map[string]string{
+			"url":           "http://example.com/hook",
+			"content_type":  "json",
+			"template":      `{"text": {{json .ref}}}`,
+			"template_push": `{"text": {{json .pusher.login}}}`,
+		},
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	DecodeJSON(t, resp, &hook)
+	assert.EqualValues(t, `{"text": {{json .pusher.login}}}`, hook.Config["template_push"])
+}
+
+func TestCustomWebhookForm(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequest(t, "GET", "/user2/repo1/settings/hooks/custom/new")
+	session.MakeRequest(t, req, http.StatusOK)
+
+	csrf := GetCSRF(t, session, "/user2/repo1/settings/hooks/custom/new")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/hooks/custom/new", map[string]string{
+		"_csrf":         csrf,
+		"payload_url":   "http://example.com/hook",
+		"content_type":  "1",
+		"template":      `{"text": {{json .ref}}}`,
+		"push_template": "{{.ref",
+		"events":        "push_only",
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, "{{.ref", htmlDoc.doc.Find("#push_template").Text())
+	models.AssertNotExistsBean(t, &models.Webhook{RepoID: 1, HookTaskType: models.CUSTOM})
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/settings/hooks/custom/new", map[string]string{
+		"_csrf":         csrf,
+		"payload_url":   "http://example.com/hook",
+		"content_type":  "1",
+		"template":      `{"text": {{json .ref}}}`,
+		"push_template": `{"text": {{json .pusher.login}}}`,
+		"events":        "push_only",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	hook := models.AssertExistsAndLoadBean(t, &models.Webhook{RepoID: 1, HookTaskType: models.CUSTOM}).(*models.Webhook)
+	assert.EqualValues(t, `{"text": {{json .pusher.login}}}`, hook.GetCustomHook().EventTemplate("push"))
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/user2/repo1/settings/hooks/%d", hook.ID))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, `{"text": {{json .ref}}}`, htmlDoc.doc.Find("#template").Text())
+}
+
+func TestNewWebhookForms(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	for _, hookType := range []string{"msteams", "telegram", "matrix", "custom"} {
+		req := NewRequestf(t, "GET", "/user2/repo1/settings/hooks/%s/new", hookType)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.EqualValues(t, 1, htmlDoc.doc.Find(fmt.Sprintf(`form[action="/user2/repo1/settings/hooks/%s/new"]`, hookType)).Length())
+
+		req = NewRequestf(t, "GET", "/org/user3/settings/hooks/%s/new", hookType)
+		session.MakeRequest(t, req, http.StatusOK)
+	}
+}
+
+func TestWebhookGranularEvents(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/hooks?token="+token, &api.CreateHookOption{
+		Type: "gitea",
+		Config: map[string]string{
+			"url":          "http://example.com/hook",
+			"content_type": "json",
+		},
+		Events: []string{"star", "issue_label"},
+		Active: true,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var hook api.Hook
+	DecodeJSON(t, resp, &hook)
+	assert.EqualValues(t, []string{"issue_label", "star"}, hook.Events)
+
+	req = NewRequestf(t, "PUT", "/api/v1/user/starred/user2/repo1?token=%s", token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	models.AssertExistsAndLoadBean(t, &models.HookTask{HookID: hook.ID, EventType: models.HookEventStar})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues/1/labels?token="+token, &api.IssueLabelsOption{
+		Labels: []int64{2},
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.HookTask{HookID: hook.ID, EventType: models.HookEventIssueLabel})
+	models.AssertNotExistsBean(t, &models.HookTask{HookID: hook.ID, EventType: models.HookEventIssues})
+	models.AssertNotExistsBean(t, &models.HookTask{HookID: 1, EventType: models.HookEventStar})
+}
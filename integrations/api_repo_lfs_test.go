@@ -0,0 +1,52 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPILFSArchived(t *testing.T) {
+	prepareTestEnv(t)
+	setting.LFS.StartServer = true
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.NoError(t, repo.SetArchiveRepoState(true))
+
+	oid := strings.Repeat("a", 64)
+	newLFSRequest := func(method, urlStr string, v interface{}) *http.Request {
+		req := NewRequestWithJSON(t, method, urlStr, v)
+		req.Header.Set("Accept", "application/vnd.git-lfs+json")
+		return AddBasicAuthHeader(req, "user2")
+	}
+
+	// nothing can be uploaded to an archived repository
+	req := newLFSRequest("POST", "/user2/repo1.git/info/lfs/objects/batch", &lfs.BatchVars{
+		Operation: "upload",
+		Objects:   []*lfs.RequestVars{{Oid: oid, Size: 6}},
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = newLFSRequest("POST", "/user2/repo1.git/info/lfs/objects", &lfs.RequestVars{Oid: oid, Size: 6})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestWithBody(t, "PUT", "/user2/repo1.git/info/lfs/objects/"+oid, strings.NewReader("foobar"))
+	req.Header.Set("Accept", "application/vnd.git-lfs")
+	MakeRequest(t, AddBasicAuthHeader(req, "user2"), http.StatusForbidden)
+	models.AssertNotExistsBean(t, &models.LFSMetaObject{Oid: oid})
+
+	// but its objects can still be downloaded
+	req = newLFSRequest("POST", "/user2/repo1.git/info/lfs/objects/batch", &lfs.BatchVars{
+		Operation: "download",
+		Objects:   []*lfs.RequestVars{{Oid: oid, Size: 6}},
+	})
+	MakeRequest(t, req, http.StatusOK)
+}
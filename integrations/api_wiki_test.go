@@ -0,0 +1,120 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIWikiPages(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var pages []*api.WikiPageMetaData
+	DecodeJSON(t, resp, &pages)
+	if assert.Len(t, pages, 1) {
+		assert.EqualValues(t, "Home", pages[0].Title)
+		assert.EqualValues(t, "Home", pages[0].SubURL)
+		assert.EqualValues(t, "Add Home.md\n", pages[0].LastCommit.Message)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages/Home")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var home api.WikiPage
+	DecodeJSON(t, resp, &home)
+	assert.EqualValues(t, "# Home page\n\nThis is the home page!\n", home.Content)
+	assert.Contains(t, home.ContentHTML, "<p>This is the home page!</p>")
+	assert.EqualValues(t, 1, home.CommitCount)
+	firstRevision := home.LastCommit.SHA
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/wiki/pages?token="+token, &api.CreateWikiPageOptions{
+		Title:   "API docs",
+		Content: "Generated",
+		Message: "Publish the API docs",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var page api.WikiPage
+	DecodeJSON(t, resp, &page)
+	assert.EqualValues(t, "API docs", page.Title)
+	assert.EqualValues(t, "API-docs", page.SubURL)
+	assert.EqualValues(t, "Publish the API docs\n", page.LastCommit.Message)
+
+	// the page already exists
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	content := "Updated"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/wiki/pages/Home?token="+token, &api.EditWikiPageOptions{
+		Content: &content,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &home)
+	assert.EqualValues(t, "Updated", home.Content)
+	assert.EqualValues(t, 2, home.CommitCount)
+
+	// the content at the first revision
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages/Home?ref=%s", firstRevision)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &home)
+	assert.EqualValues(t, "# Home page\n\nThis is the home page!\n", home.Content)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/revisions/Home")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var commits []*api.WikiCommit
+	DecodeJSON(t, resp, &commits)
+	if assert.Len(t, commits, 2) {
+		assert.EqualValues(t, "Update page 'Home'\n", commits[0].Message)
+		assert.EqualValues(t, firstRevision, commits[1].SHA)
+	}
+	assert.EqualValues(t, "2", resp.Header().Get("X-Total-Count"))
+
+	// renaming onto another page
+	title := "API docs"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/wiki/pages/Home?token="+token, &api.EditWikiPageOptions{
+		Title: &title,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	title = "API reference"
+	req = NewRequestWithJSON(t, "PATCH", "/api/v1/repos/user2/repo1/wiki/pages/API-docs?token="+token, &api.EditWikiPageOptions{
+		Title: &title,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &page)
+	assert.EqualValues(t, "API-reference", page.SubURL)
+	assert.EqualValues(t, "Generated", page.Content)
+
+	req = NewRequestWithJSON(t, "DELETE", "/api/v1/repos/user2/repo1/wiki/pages/API-reference?token="+token, &api.DeleteWikiPageOptions{
+		Message: "Remove the API docs",
+	})
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages/API-reference")
+	session.MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/wiki/pages/API-docs")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIWikiPagesNotWriter(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/wiki/pages?token="+token, &api.CreateWikiPageOptions{
+		Title:   "Page",
+		Content: "Content",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/wiki/pages/Home?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/setting"
@@ -85,8 +86,22 @@ func TestViewIssuesKeyword(t *testing.T) {
 	prepareTestEnv(t)
 
 	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	issue := models.AssertExistsAndLoadBean(t, &models.Issue{RepoID: repo.ID, Index: 1}).(*models.Issue)
+	// the index is updated in the background
+	models.UpdateIssueIndexer(issue.ID)
 
 	const keyword = "first"
+	for deadline := time.Now().Add(10 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		issueIDs, err := models.SearchIssuesByKeyword(repo.ID, keyword)
+		assert.NoError(t, err)
+		if len(issueIDs) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			assert.FailNow(t, "Timeout: the issue was not indexed")
+		}
+	}
+
 	req := NewRequestf(t, "GET", "%s/issues?q=%s", repo.RelLink(), keyword)
 	resp := MakeRequest(t, req, http.StatusOK)
 
@@ -184,3 +199,42 @@ func TestIssueCommentClose(t *testing.T) {
 	val := htmlDoc.doc.Find(".comment-list .comments .comment .render-content p").First().Text()
 	assert.Equal(t, "Description", val)
 }
+
+func TestIssueLock(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+	issueURL := "/user2/repo1/issues/1"
+
+	req := NewRequestWithValues(t, "POST", issueURL+"/lock", map[string]string{
+		"_csrf":  GetCSRF(t, session, issueURL),
+		"reason": "Too heated",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Issue{ID: 1, IsLocked: true})
+
+	req = NewRequest(t, "GET", issueURL)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".comment-list .event .octicon-lock").Length())
+	assert.EqualValues(t, 1, htmlDoc.doc.Find("#comment-form").Length())
+
+	// user4 cannot comment on the locked issue
+	readerSession := loginUser(t, "user4")
+	req = NewRequest(t, "GET", issueURL)
+	resp = readerSession.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, 0, NewHTMLParser(t, resp.Body).doc.Find("#comment-form").Length())
+
+	req = NewRequestWithValues(t, "POST", issueURL+"/comments", map[string]string{
+		"_csrf":   GetCSRF(t, readerSession, "/user2/repo1"),
+		"content": "Reader comment",
+	})
+	readerSession.MakeRequest(t, req, http.StatusFound)
+	models.AssertNotExistsBean(t, &models.Comment{Content: "Reader comment"})
+
+	req = NewRequestWithValues(t, "POST", issueURL+"/comments", map[string]string{
+		"_csrf":   htmlDoc.GetCSRF(),
+		"content": "Collaborator comment",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+	models.AssertExistsAndLoadBean(t, &models.Comment{Content: "Collaborator comment"})
+}
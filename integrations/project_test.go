@@ -0,0 +1,82 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewRepoProjects(t *testing.T) {
+	prepareTestEnv(t)
+
+	req := NewRequest(t, "GET", "/user2/repo1/projects")
+	resp := MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(".project.list > li").Length())
+
+	req = NewRequest(t, "GET", "/user2/repo1/projects/1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	// the uncategorized board and the three boards of the project
+	assert.EqualValues(t, 4, htmlDoc.doc.Find(".project-board").Length())
+}
+
+func TestCreateRepoProject(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/projects/new", map[string]string{
+		"_csrf":      GetCSRF(t, session, "/user2/repo1/projects/new"),
+		"title":      "Web project",
+		"content":    "Project description",
+		"board_type": "2",
+	})
+	session.MakeRequest(t, req, http.StatusFound)
+
+	p := models.AssertExistsAndLoadBean(t, &models.Project{RepoID: 1, Title: "Web project"}).(*models.Project)
+	assert.Equal(t, models.ProjectBoardTypeBugTriage, p.BoardType)
+	models.AssertCount(t, &models.ProjectBoard{ProjectID: p.ID}, len(setting.Project.ProjectBoardBugTriageType))
+}
+
+func TestMoveProjectIssues(t *testing.T) {
+	prepareTestEnv(t)
+	session := loginUser(t, "user2")
+
+	req := NewRequestWithValues(t, "POST", "/user2/repo1/projects/1/boards/2/move", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user2/repo1/projects/1"),
+		"issue_ids": "2,1",
+	})
+	session.MakeRequest(t, req, http.StatusOK)
+	models.AssertExistsAndLoadBean(t, &models.ProjectIssue{IssueID: 2, ProjectBoardID: 2, Sorting: 0})
+	models.AssertExistsAndLoadBean(t, &models.ProjectIssue{IssueID: 1, ProjectBoardID: 2, Sorting: 1})
+
+	// user4 cannot change the projects of repo1
+	session = loginUser(t, "user4")
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/projects/1/boards/1/move", map[string]string{
+		"_csrf":     GetCSRF(t, session, "/user2/repo1"),
+		"issue_ids": "1",
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestViewOrgProjects(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user4")
+	req := NewRequest(t, "GET", "/org/user3/projects/3")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find(".project-board").Length())
+
+	session = loginUser(t, "user5")
+	req = NewRequest(t, "GET", "/org/user3/projects/3")
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -9,6 +9,7 @@ import (
 	"path"
 	"testing"
 
+	"code.gitea.io/gitea/models"
 	api "code.gitea.io/sdk/gitea"
 
 	"github.com/stretchr/testify/assert"
@@ -88,3 +89,29 @@ func TestRepoCommitsWithStatusFailure(t *testing.T) {
 func TestRepoCommitsWithStatusWarning(t *testing.T) {
 	doTestRepoCommitWithStatus(t, "warning", "warning", "sign", "yellow")
 }
+
+func TestRepoCommitDiffStyle(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/user2/repo1/commits/branch/master")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	commitURL, exists := NewHTMLParser(t, resp.Body).doc.Find("#commits-table tbody tr td.sha a").Attr("href")
+	assert.True(t, exists)
+
+	req = NewRequest(t, "GET", commitURL+"?style=split")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.NotZero(t, htmlDoc.doc.Find(".code-diff-split .lines-code-new").Length())
+	models.AssertExistsAndLoadBean(t, &models.User{Name: "user2", DiffViewStyle: "split"})
+
+	// the chosen style is remembered for the user
+	req = NewRequest(t, "GET", commitURL)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.NotZero(t, NewHTMLParser(t, resp.Body).doc.Find(".code-diff-split").Length())
+
+	req = NewRequest(t, "GET", commitURL+"?style=unified")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.NotZero(t, NewHTMLParser(t, resp.Body).doc.Find(".code-diff-unified").Length())
+	models.AssertExistsAndLoadBean(t, &models.User{Name: "user2", DiffViewStyle: "unified"})
+}
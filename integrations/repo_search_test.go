@@ -7,6 +7,9 @@ package integrations
 import (
 	"net/http"
 	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/stretchr/testify/assert"
@@ -33,3 +36,68 @@ func TestSearchRepo(t *testing.T) {
 	filenames := resultFilenames(t, NewHTMLParser(t, resp.Body))
 	assert.EqualValues(t, []string{"README.md"}, filenames)
 }
+
+func TestExploreCode(t *testing.T) {
+	prepareTestEnv(t)
+
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	// the index is updated in the background
+	models.UpdateRepoIndexer(repo)
+	time.Sleep(time.Second)
+
+	req := NewRequest(t, "GET", "/explore/code?q=Description&l=markdown&path=README&owner=user2")
+	resp := MakeRequest(t, req, http.StatusOK)
+	filenames := resultFilenames(t, NewHTMLParser(t, resp.Body))
+	assert.EqualValues(t, []string{"user2/repo1 - README.md"}, filenames)
+
+	req = NewRequest(t, "GET", "/explore/code?q=Description&l=go")
+	resp = MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 0, htmlDoc.doc.Find(".repo-search-result").Length())
+}
+
+func TestSearchRepoRefs(t *testing.T) {
+	prepareTestEnv(t)
+
+	session := loginUser(t, "user2")
+	setIndexerRefs := func(refs string) {
+		req := NewRequestWithValues(t, "POST", "/user2/repo1/settings", map[string]string{
+			"_csrf":        GetCSRF(t, session, "/user2/repo1/settings"),
+			"action":       "indexer",
+			"indexer_refs": refs,
+		})
+		session.MakeRequest(t, req, http.StatusFound)
+		// the index is updated in the background
+		time.Sleep(time.Second)
+	}
+	setIndexerRefs("feature/*, refs/tags/v*")
+	repo := models.AssertExistsAndLoadBean(t, &models.Repository{ID: 1}).(*models.Repository)
+	assert.Equal(t, []string{"feature/*", "refs/tags/v*"}, repo.IndexerRefs)
+
+	refs, err := models.GetIndexedRefs(repo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"refs/heads/feature/1", "refs/heads/master", "refs/tags/v1.1"}, refs)
+
+	req := NewRequest(t, "GET", "/user2/repo1/search?q=Description")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, []string{"README.md", "README.md", "README.md"}, resultFilenames(t, htmlDoc))
+	assert.EqualValues(t, 4, htmlDoc.doc.Find("select[name=ref] option").Length())
+
+	req = NewRequest(t, "GET", "/user2/repo1/search?q=Description&ref=refs/tags/v1.1")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc = NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, []string{"README.md"}, resultFilenames(t, htmlDoc))
+	link, _ := htmlDoc.doc.Find(".repo-search-result .header a.button").Attr("href")
+	assert.EqualValues(t, "/user2/repo1/src/tag/v1.1/README.md", link)
+
+	// the branches and tags which are no longer indexed are removed
+	setIndexerRefs("")
+	refs, err = models.GetIndexedRefs(repo.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"refs/heads/master"}, refs)
+
+	req = NewRequest(t, "GET", "/user2/repo1/search?q=Description")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, []string{"README.md"}, resultFilenames(t, NewHTMLParser(t, resp.Body)))
+}
@@ -49,6 +49,7 @@ arguments - which can alternatively be run by running the subcommand web.`
 		cmd.CmdAdmin,
 		cmd.CmdGenerate,
 		cmd.CmdMigrate,
+		cmd.CmdMigrateStorage,
 		cmd.CmdKeys,
 	}
 	app.Flags = append(app.Flags, cmd.CmdWeb.Flags...)
@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/Unknwon/com"
@@ -69,6 +70,18 @@ func removeAllWithNotice(e Engine, title, path string) {
 	}
 }
 
+// removeStorageWithNotice removes a file from the storage and creates a
+// notice if any error occurs
+func removeStorageWithNotice(e Engine, bucket storage.ObjectStorage, title, path string) {
+	if err := bucket.Delete(path); err != nil {
+		desc := fmt.Sprintf("%s [%s]: %v", title, path, err)
+		log.Warn(desc)
+		if err = createNotice(e, NoticeRepository, desc); err != nil {
+			log.Error(4, "CreateRepositoryNotice: %v", err)
+		}
+	}
+}
+
 // CountNotices returns number of notices.
 func CountNotices() int64 {
 	count, _ := x.Count(new(Notice))
@@ -33,6 +33,9 @@ type ProtectedBranch struct {
 	EnableMergeWhitelist  bool           `xorm:"NOT NULL DEFAULT false"`
 	MergeWhitelistUserIDs []int64        `xorm:"JSON TEXT"`
 	MergeWhitelistTeamIDs []int64        `xorm:"JSON TEXT"`
+	RequiredApprovals     int64          `xorm:"NOT NULL DEFAULT 0"`
+	EnableStatusCheck     bool           `xorm:"NOT NULL DEFAULT false"`
+	StatusCheckContexts   []string       `xorm:"JSON TEXT"`
 	CreatedUnix           util.TimeStamp `xorm:"created"`
 	UpdatedUnix           util.TimeStamp `xorm:"updated"`
 }
@@ -86,6 +89,96 @@ func (protectBranch *ProtectedBranch) CanUserMerge(userID int64) bool {
 	return in
 }
 
+// GetGrantedApprovalsCount returns the number of granted approvals for pr. An approval is only
+// granted if it is the latest review of a user who has write access to the code of the repository.
+func (protectBranch *ProtectedBranch) GetGrantedApprovalsCount(pr *PullRequest) int64 {
+	if err := pr.GetBaseRepo(); err != nil {
+		log.Error(1, "GetBaseRepo: %v", err)
+		return 0
+	}
+
+	reviewers, err := GetReviewersByPullID(pr.IssueID)
+	if err != nil {
+		log.Error(1, "GetReviewersByPullID: %v", err)
+		return 0
+	}
+
+	var approvals int64
+	for _, reviewer := range reviewers {
+		if reviewer.Type != ReviewTypeApprove {
+			continue
+		}
+		perm, err := GetUserRepoPermission(pr.BaseRepo, &reviewer.User)
+		if err != nil {
+			log.Error(1, "GetUserRepoPermission: %v", err)
+			continue
+		}
+		if perm.CanWrite(UnitTypeCode) {
+			approvals++
+		}
+	}
+	return approvals
+}
+
+// GetApproverIDs returns the IDs of the users whose approvals are granted for the pull
+// requests of the protected branch, if approvals are required.
+func (protectBranch *ProtectedBranch) GetApproverIDs(repo *Repository) ([]int64, error) {
+	if protectBranch.RequiredApprovals == 0 {
+		return nil, nil
+	}
+
+	users, err := repo.GetAssignees()
+	if err != nil {
+		return nil, fmt.Errorf("GetAssignees: %v", err)
+	}
+
+	ids := make([]int64, 0, len(users))
+	for _, user := range users {
+		perm, err := GetUserRepoPermission(repo, user)
+		if err != nil {
+			return nil, fmt.Errorf("GetUserRepoPermission: %v", err)
+		}
+		if perm.CanWrite(UnitTypeCode) {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+// HasEnoughApprovals returns true if pr has enough granted approvals.
+func (protectBranch *ProtectedBranch) HasEnoughApprovals(pr *PullRequest) bool {
+	if protectBranch.RequiredApprovals == 0 {
+		return true
+	}
+	return protectBranch.GetGrantedApprovalsCount(pr) >= protectBranch.RequiredApprovals
+}
+
+// GetUnsuccessfulStatusChecks returns the required status check contexts whose latest
+// commit status on the head commit of pr is not success.
+func (protectBranch *ProtectedBranch) GetUnsuccessfulStatusChecks(pr *PullRequest) ([]string, error) {
+	if !protectBranch.EnableStatusCheck || len(protectBranch.StatusCheckContexts) == 0 {
+		return nil, nil
+	}
+
+	sha, err := pr.GetHeadCommitID()
+	if err != nil {
+		return nil, fmt.Errorf("GetHeadCommitID: %v", err)
+	}
+
+	statuses, err := GetLatestCommitStatusByContext(pr.BaseRepo, sha)
+	if err != nil {
+		return nil, fmt.Errorf("GetLatestCommitStatusByContext: %v", err)
+	}
+
+	unsuccessful := make([]string, 0, len(protectBranch.StatusCheckContexts))
+	for _, statusContext := range protectBranch.StatusCheckContexts {
+		if status, ok := statuses[statusContext]; !ok || status.State != CommitStatusSuccess {
+			unsuccessful = append(unsuccessful, statusContext)
+		}
+	}
+	return unsuccessful, nil
+}
+
 // GetProtectedBranchByRepoID getting protected branch by repo ID
 func GetProtectedBranchByRepoID(RepoID int64) ([]*ProtectedBranch, error) {
 	protectedBranches := make([]*ProtectedBranch, 0)
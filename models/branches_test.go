@@ -78,3 +78,16 @@ func getDeletedBranch(t *testing.T, branch *DeletedBranch) *DeletedBranch {
 
 	return deletedBranch
 }
+
+func TestProtectedBranchHasEnoughApprovals(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 2}).(*PullRequest)
+
+	protectBranch := &ProtectedBranch{RepoID: pr.BaseRepoID, BranchName: pr.BaseBranch}
+	assert.True(t, protectBranch.HasEnoughApprovals(pr))
+
+	// the only approval comes from a user without write access
+	protectBranch.RequiredApprovals = 1
+	assert.EqualValues(t, 0, protectBranch.GetGrantedApprovalsCount(pr))
+	assert.False(t, protectBranch.HasEnoughApprovals(pr))
+}
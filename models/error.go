@@ -483,6 +483,21 @@ func (err ErrAccessTokenEmpty) Error() string {
 	return fmt.Sprintf("access token is empty")
 }
 
+// ErrAccessTokenScopeInvalid represents a "AccessTokenScopeInvalid" kind of error.
+type ErrAccessTokenScopeInvalid struct {
+	Scope string
+}
+
+// IsErrAccessTokenScopeInvalid checks if an error is a ErrAccessTokenScopeInvalid.
+func IsErrAccessTokenScopeInvalid(err error) bool {
+	_, ok := err.(ErrAccessTokenScopeInvalid)
+	return ok
+}
+
+func (err ErrAccessTokenScopeInvalid) Error() string {
+	return fmt.Sprintf("access token scope is invalid [scope: %s]", err.Scope)
+}
+
 // ________                            .__                __  .__
 // \_____  \_______  _________    ____ |__|____________ _/  |_|__| ____   ____
 //  /   |   \_  __ \/ ___\__  \  /    \|  \___   /\__  \\   __\  |/  _ \ /    \
@@ -718,6 +733,21 @@ func (err ErrInvalidTagName) Error() string {
 	return fmt.Sprintf("release tag name is not valid [tag_name: %s]", err.TagName)
 }
 
+// ErrInvalidIndexerRefPattern represents a "InvalidIndexerRefPattern" kind of error.
+type ErrInvalidIndexerRefPattern struct {
+	Pattern string
+}
+
+// IsErrInvalidIndexerRefPattern checks if an error is a ErrInvalidIndexerRefPattern.
+func IsErrInvalidIndexerRefPattern(err error) bool {
+	_, ok := err.(ErrInvalidIndexerRefPattern)
+	return ok
+}
+
+func (err ErrInvalidIndexerRefPattern) Error() string {
+	return fmt.Sprintf("indexer ref pattern is not valid [pattern: %s]", err.Pattern)
+}
+
 // ErrRepoFileAlreadyExist represents a "RepoFileAlreadyExist" kind of error.
 type ErrRepoFileAlreadyExist struct {
 	FileName string
@@ -733,6 +763,39 @@ func (err ErrRepoFileAlreadyExist) Error() string {
 	return fmt.Sprintf("repository file already exists [file_name: %s]", err.FileName)
 }
 
+// ErrRepoFileDoesNotExist represents a "RepoFileDoesNotExist" kind of error.
+type ErrRepoFileDoesNotExist struct {
+	FileName string
+}
+
+// IsErrRepoFileDoesNotExist checks if an error is a ErrRepoFileDoesNotExist.
+func IsErrRepoFileDoesNotExist(err error) bool {
+	_, ok := err.(ErrRepoFileDoesNotExist)
+	return ok
+}
+
+func (err ErrRepoFileDoesNotExist) Error() string {
+	return fmt.Sprintf("repository file does not exist [file_name: %s]", err.FileName)
+}
+
+// ErrRepoFileSHAMismatch represents a "RepoFileSHAMismatch" kind of error: the file
+// was changed since the given blob SHA.
+type ErrRepoFileSHAMismatch struct {
+	FileName   string
+	GivenSHA   string
+	CurrentSHA string
+}
+
+// IsErrRepoFileSHAMismatch checks if an error is a ErrRepoFileSHAMismatch.
+func IsErrRepoFileSHAMismatch(err error) bool {
+	_, ok := err.(ErrRepoFileSHAMismatch)
+	return ok
+}
+
+func (err ErrRepoFileSHAMismatch) Error() string {
+	return fmt.Sprintf("repository file SHA does not match [file_name: %s, given_sha: %s, current_sha: %s]", err.FileName, err.GivenSHA, err.CurrentSHA)
+}
+
 // ErrUserDoesNotHaveAccessToRepo represets an error where the user doesn't has access to a given repo
 type ErrUserDoesNotHaveAccessToRepo struct {
 	UserID   int64
@@ -749,6 +812,22 @@ func (err ErrUserDoesNotHaveAccessToRepo) Error() string {
 	return fmt.Sprintf("user doesn't have acces to repo [user_id: %d, repo_name: %s]", err.UserID, err.RepoName)
 }
 
+// ErrPushMirrorNotExist represents a "PushMirrorNotExist" kind of error.
+type ErrPushMirrorNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrPushMirrorNotExist checks if an error is a ErrPushMirrorNotExist.
+func IsErrPushMirrorNotExist(err error) bool {
+	_, ok := err.(ErrPushMirrorNotExist)
+	return ok
+}
+
+func (err ErrPushMirrorNotExist) Error() string {
+	return fmt.Sprintf("push mirror does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
 // __________                             .__
 // \______   \____________    ____   ____ |  |__
 //  |    |  _/\_  __ \__  \  /    \_/ ___\|  |  \
@@ -853,6 +932,38 @@ func (err ErrWebhookNotExist) Error() string {
 	return fmt.Sprintf("webhook does not exist [id: %d]", err.ID)
 }
 
+// ErrHookTaskNotExist represents a "HookTaskNotExist" kind of error.
+type ErrHookTaskNotExist struct {
+	ID     int64
+	HookID int64
+}
+
+// IsErrHookTaskNotExist checks if an error is a ErrHookTaskNotExist.
+func IsErrHookTaskNotExist(err error) bool {
+	_, ok := err.(ErrHookTaskNotExist)
+	return ok
+}
+
+func (err ErrHookTaskNotExist) Error() string {
+	return fmt.Sprintf("hook task does not exist [id: %d, hook_id: %d]", err.ID, err.HookID)
+}
+
+// ErrInvalidWebhookTemplate represents a "InvalidWebhookTemplate" kind of error.
+type ErrInvalidWebhookTemplate struct {
+	Event  HookEventType
+	Reason string
+}
+
+// IsErrInvalidWebhookTemplate checks if an error is a ErrInvalidWebhookTemplate.
+func IsErrInvalidWebhookTemplate(err error) bool {
+	_, ok := err.(ErrInvalidWebhookTemplate)
+	return ok
+}
+
+func (err ErrInvalidWebhookTemplate) Error() string {
+	return fmt.Sprintf("invalid webhook template [event: %s, reason: %s]", err.Event, err.Reason)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -1057,6 +1168,38 @@ func (err ErrMilestoneNotExist) Error() string {
 	return fmt.Sprintf("milestone does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
 }
 
+// ErrProjectNotExist represents a "ProjectNotExist" kind of error.
+type ErrProjectNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrProjectNotExist checks if an error is a ErrProjectNotExist.
+func IsErrProjectNotExist(err error) bool {
+	_, ok := err.(ErrProjectNotExist)
+	return ok
+}
+
+func (err ErrProjectNotExist) Error() string {
+	return fmt.Sprintf("project does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrProjectBoardNotExist represents a "ProjectBoardNotExist" kind of error.
+type ErrProjectBoardNotExist struct {
+	ID        int64
+	ProjectID int64
+}
+
+// IsErrProjectBoardNotExist checks if an error is a ErrProjectBoardNotExist.
+func IsErrProjectBoardNotExist(err error) bool {
+	_, ok := err.(ErrProjectBoardNotExist)
+	return ok
+}
+
+func (err ErrProjectBoardNotExist) Error() string {
+	return fmt.Sprintf("project board does not exist [id: %d, project_id: %d]", err.ID, err.ProjectID)
+}
+
 //    _____   __    __                .__                           __
 //   /  _  \_/  |__/  |______    ____ |  |__   _____   ____   _____/  |_
 //  /  /_\  \   __\   __\__  \ _/ ___\|  |  \ /     \_/ __ \ /    \   __\
@@ -1366,3 +1509,41 @@ func IsErrReviewNotExist(err error) bool {
 func (err ErrReviewNotExist) Error() string {
 	return fmt.Sprintf("review does not exist [id: %d]", err.ID)
 }
+
+// ErrReviewNotDismissable represents a "ReviewNotDismissable" kind of error.
+type ErrReviewNotDismissable struct {
+	ID   int64
+	Type ReviewType
+}
+
+// IsErrReviewNotDismissable checks if an error is a ErrReviewNotDismissable.
+func IsErrReviewNotDismissable(err error) bool {
+	_, ok := err.(ErrReviewNotDismissable)
+	return ok
+}
+
+func (err ErrReviewNotDismissable) Error() string {
+	return fmt.Sprintf("only approvals and rejections can be dismissed [id: %d, type: %d]", err.ID, err.Type)
+}
+
+//  _______          __  .__  _____.__               __  .__
+//  \      \   _____/  |_|__|/ ____\__| ____ _____ _/  |_|__| ____   ____
+//  /   |   \ /  _ \   __\  \   __\|  |/ ___\\__  \\   __\  |/  _ \ /    \
+// /    |    (  <_> )  | |  ||  |  |  \  \___ / __ \|  | |  (  <_> )   |  \
+// \____|__  /\____/|__| |__||__|  |__|\___  >____  /__| |__|\____/|___|  /
+//         \/                              \/     \/                    \/
+
+// ErrNotificationNotExist represents a "NotificationNotExist" kind of error.
+type ErrNotificationNotExist struct {
+	ID int64
+}
+
+// IsErrNotificationNotExist checks if an error is a ErrNotificationNotExist.
+func IsErrNotificationNotExist(err error) bool {
+	_, ok := err.(ErrNotificationNotExist)
+	return ok
+}
+
+func (err ErrNotificationNotExist) Error() string {
+	return fmt.Sprintf("notification does not exist [id: %d]", err.ID)
+}
@@ -22,3 +22,33 @@ func IsErrOpenIDConnectInitialize(err error) bool {
 func (err ErrOpenIDConnectInitialize) Error() string {
 	return fmt.Sprintf("Failed to initialize OpenID Connect Provider with name '%s' with url '%s': %v", err.ProviderName, err.OpenIDConnectAutoDiscoveryURL, err.Cause)
 }
+
+// ErrOAuthApplicationNotFound represents a "OAuthApplicationNotFound" kind of error.
+type ErrOAuthApplicationNotFound struct {
+	ID int64
+}
+
+// IsErrOAuthApplicationNotFound checks if an error is a ErrOAuthApplicationNotFound.
+func IsErrOAuthApplicationNotFound(err error) bool {
+	_, ok := err.(ErrOAuthApplicationNotFound)
+	return ok
+}
+
+func (err ErrOAuthApplicationNotFound) Error() string {
+	return fmt.Sprintf("OAuth application not found [id: %d]", err.ID)
+}
+
+// ErrOAuthClientIDInvalid represents a "OAuthClientIDInvalid" kind of error.
+type ErrOAuthClientIDInvalid struct {
+	ClientID string
+}
+
+// IsErrOAuthClientIDInvalid checks if an error is a ErrOAuthClientIDInvalid.
+func IsErrOAuthClientIDInvalid(err error) bool {
+	_, ok := err.(ErrOAuthClientIDInvalid)
+	return ok
+}
+
+func (err ErrOAuthClientIDInvalid) Error() string {
+	return fmt.Sprintf("OAuth client ID is invalid [client_id: %s]", err.ClientID)
+}
@@ -84,6 +84,10 @@ func (d *DiffLine) GetCommentSide() string {
 type DiffSection struct {
 	Name  string
 	Lines []*DiffLine
+
+	// splitMatches pairs the deleted and added lines of the section for the
+	// split view, it is computed once by GetSplitMatch
+	splitMatches map[*DiffLine]*DiffLine
 }
 
 var (
@@ -164,6 +168,42 @@ LOOP:
 	return nil
 }
 
+// GetSplitMatch returns the line displayed next to the given one in the split
+// view. Within a block of changes the n-th deleted line is paired with the
+// n-th added line, lines without a counterpart have no match.
+func (diffSection *DiffSection) GetSplitMatch(diffLine *DiffLine) *DiffLine {
+	if diffSection.splitMatches == nil {
+		diffSection.pairSplitLines()
+	}
+	return diffSection.splitMatches[diffLine]
+}
+
+// pairSplitLines pairs the deleted and added lines of each block of changes
+// of the section.
+func (diffSection *DiffSection) pairSplitLines() {
+	diffSection.splitMatches = make(map[*DiffLine]*DiffLine)
+
+	var adds, dels []*DiffLine
+	pair := func() {
+		for i := 0; i < len(adds) && i < len(dels); i++ {
+			diffSection.splitMatches[adds[i]] = dels[i]
+			diffSection.splitMatches[dels[i]] = adds[i]
+		}
+		adds, dels = adds[:0], dels[:0]
+	}
+	for _, line := range diffSection.Lines {
+		switch line.Type {
+		case DiffLineAdd:
+			adds = append(adds, line)
+		case DiffLineDel:
+			dels = append(dels, line)
+		default:
+			pair()
+		}
+	}
+	pair()
+}
+
 var diffMatchPatch = diffmatchpatch.New()
 
 func init() {
@@ -139,3 +139,23 @@ func TestDiffLine_GetCommentSide(t *testing.T) {
 	assert.Equal(t, "previous", (&DiffLine{Comments: []*Comment{{Line: -3}}}).GetCommentSide())
 	assert.Equal(t, "proposed", (&DiffLine{Comments: []*Comment{{Line: 3}}}).GetCommentSide())
 }
+
+func TestDiffSection_GetSplitMatch(t *testing.T) {
+	section := &DiffSection{
+		Lines: []*DiffLine{
+			{LeftIdx: 1, RightIdx: 1, Type: DiffLinePlain, Content: " # gitea-github-migrator"},
+			{LeftIdx: 2, Type: DiffLineDel, Content: "- Latest Release"},
+			{LeftIdx: 3, Type: DiffLineDel, Content: "- Build Status"},
+			{RightIdx: 2, Type: DiffLineAdd, Content: "+ Latest"},
+			{LeftIdx: 4, RightIdx: 3, Type: DiffLinePlain, Content: " Docker Pulls"},
+			{RightIdx: 4, Type: DiffLineAdd, Content: "+ cut off"},
+		},
+	}
+	lines := section.Lines
+
+	assertLineEqual(t, lines[3], section.GetSplitMatch(lines[1]))
+	assertLineEqual(t, lines[1], section.GetSplitMatch(lines[3]))
+	assert.Nil(t, section.GetSplitMatch(lines[2]))
+	assert.Nil(t, section.GetSplitMatch(lines[0]))
+	assert.Nil(t, section.GetSplitMatch(lines[5]))
+}
@@ -36,6 +36,7 @@ type Issue struct {
 	Labels          []*Label    `xorm:"-"`
 	MilestoneID     int64       `xorm:"INDEX"`
 	Milestone       *Milestone  `xorm:"-"`
+	Project         *Project    `xorm:"-"`
 	Priority        int
 	AssigneeID      int64        `xorm:"-"`
 	Assignee        *User        `xorm:"-"`
@@ -45,6 +46,7 @@ type Issue struct {
 	PullRequest     *PullRequest `xorm:"-"`
 	NumComments     int
 	Ref             string
+	IsLocked        bool `xorm:"NOT NULL DEFAULT false"`
 
 	DeadlineUnix util.TimeStamp `xorm:"INDEX"`
 
@@ -96,6 +98,11 @@ func (issue *Issue) loadRepo(e Engine) (err error) {
 	return nil
 }
 
+// LoadRepo loads the repository of the issue
+func (issue *Issue) LoadRepo() error {
+	return issue.loadRepo(x)
+}
+
 // IsTimetrackerEnabled returns true if the repo enables timetracking
 func (issue *Issue) IsTimetrackerEnabled() bool {
 	if err := issue.loadRepo(x); err != nil {
@@ -324,6 +331,7 @@ func (issue *Issue) APIFormat() *api.Issue {
 		Body:     issue.Content,
 		Labels:   apiLabels,
 		State:    issue.State(),
+		IsLocked: issue.IsLocked,
 		Comments: issue.NumComments,
 		Created:  issue.CreatedUnix.AsTime(),
 		Updated:  issue.UpdatedUnix.AsTime(),
@@ -372,64 +380,13 @@ func (issue *Issue) HasLabel(labelID int64) bool {
 	return issue.hasLabel(x, labelID)
 }
 
-func (issue *Issue) sendLabelUpdatedWebhook(doer *User) {
-	var err error
-
-	if err = issue.loadRepo(x); err != nil {
-		log.Error(4, "loadRepo: %v", err)
-		return
-	}
-
-	if err = issue.loadPoster(x); err != nil {
-		log.Error(4, "loadPoster: %v", err)
-		return
-	}
-
-	mode, _ := AccessLevel(issue.Poster, issue.Repo)
-	if issue.IsPull {
-		if err = issue.loadPullRequest(x); err != nil {
-			log.Error(4, "loadPullRequest: %v", err)
-			return
-		}
-		if err = issue.PullRequest.LoadIssue(); err != nil {
-			log.Error(4, "LoadIssue: %v", err)
-			return
-		}
-		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
-			Action:      api.HookIssueLabelUpdated,
-			Index:       issue.Index,
-			PullRequest: issue.PullRequest.APIFormat(),
-			Repository:  issue.Repo.APIFormat(AccessModeNone),
-			Sender:      doer.APIFormat(),
-		})
-	} else {
-		err = PrepareWebhooks(issue.Repo, HookEventIssues, &api.IssuePayload{
-			Action:     api.HookIssueLabelUpdated,
-			Index:      issue.Index,
-			Issue:      issue.APIFormat(),
-			Repository: issue.Repo.APIFormat(mode),
-			Sender:     doer.APIFormat(),
-		})
-	}
-	if err != nil {
-		log.Error(4, "PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
-	} else {
-		go HookQueue.Add(issue.RepoID)
-	}
-}
-
 func (issue *Issue) addLabel(e *xorm.Session, label *Label, doer *User) error {
 	return newIssueLabel(e, issue, label, doer)
 }
 
 // AddLabel adds a new label to the issue.
 func (issue *Issue) AddLabel(doer *User, label *Label) error {
-	if err := NewIssueLabel(issue, label, doer); err != nil {
-		return err
-	}
-
-	issue.sendLabelUpdatedWebhook(doer)
-	return nil
+	return NewIssueLabel(issue, label, doer)
 }
 
 func (issue *Issue) addLabels(e *xorm.Session, labels []*Label, doer *User) error {
@@ -438,12 +395,7 @@ func (issue *Issue) addLabels(e *xorm.Session, labels []*Label, doer *User) erro
 
 // AddLabels adds a list of new labels to the issue.
 func (issue *Issue) AddLabels(doer *User, labels []*Label) error {
-	if err := NewIssueLabels(issue, labels, doer); err != nil {
-		return err
-	}
-
-	issue.sendLabelUpdatedWebhook(doer)
-	return nil
+	return NewIssueLabels(issue, labels, doer)
 }
 
 func (issue *Issue) getLabels(e Engine) (err error) {
@@ -476,12 +428,7 @@ func (issue *Issue) RemoveLabel(doer *User, label *Label) error {
 		return ErrLabelNotExist{}
 	}
 
-	if err := DeleteIssueLabel(issue, label, doer); err != nil {
-		return err
-	}
-
-	issue.sendLabelUpdatedWebhook(doer)
-	return nil
+	return DeleteIssueLabel(issue, label, doer)
 }
 
 func (issue *Issue) clearLabels(e *xorm.Session, doer *User) (err error) {
@@ -499,7 +446,6 @@ func (issue *Issue) clearLabels(e *xorm.Session, doer *User) (err error) {
 }
 
 // ClearLabels removes all issue labels as the given user.
-// Triggers appropriate WebHooks, if any.
 func (issue *Issue) ClearLabels(doer *User) (err error) {
 	sess := x.NewSession()
 	defer sess.Close()
@@ -528,40 +474,6 @@ func (issue *Issue) ClearLabels(doer *User) (err error) {
 	if err = sess.Commit(); err != nil {
 		return fmt.Errorf("Commit: %v", err)
 	}
-
-	if err = issue.loadPoster(x); err != nil {
-		return fmt.Errorf("loadPoster: %v", err)
-	}
-
-	mode, _ := AccessLevel(issue.Poster, issue.Repo)
-	if issue.IsPull {
-		err = issue.PullRequest.LoadIssue()
-		if err != nil {
-			log.Error(4, "LoadIssue: %v", err)
-			return
-		}
-		err = PrepareWebhooks(issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
-			Action:      api.HookIssueLabelCleared,
-			Index:       issue.Index,
-			PullRequest: issue.PullRequest.APIFormat(),
-			Repository:  issue.Repo.APIFormat(mode),
-			Sender:      doer.APIFormat(),
-		})
-	} else {
-		err = PrepareWebhooks(issue.Repo, HookEventIssues, &api.IssuePayload{
-			Action:     api.HookIssueLabelCleared,
-			Index:      issue.Index,
-			Issue:      issue.APIFormat(),
-			Repository: issue.Repo.APIFormat(mode),
-			Sender:     doer.APIFormat(),
-		})
-	}
-	if err != nil {
-		log.Error(4, "PrepareWebhooks [is_pull: %v]: %v", issue.IsPull, err)
-	} else {
-		go HookQueue.Add(issue.RepoID)
-	}
-
 	return nil
 }
 
@@ -702,6 +614,13 @@ func (issue *Issue) changeStatus(e *xorm.Session, doer *User, repo *Repository,
 		return err
 	}
 
+	// Move the card of the issue on its project
+	if isClosed {
+		if err = moveClosedIssueCard(e, issue); err != nil {
+			return err
+		}
+	}
+
 	// New action comment
 	if _, err = createStatusComment(e, doer, repo, issue); err != nil {
 		return err
@@ -977,8 +896,7 @@ func newIssue(e *xorm.Session, doer *User, opts NewIssueOptions) (err error) {
 
 	// Insert the assignees
 	for _, assigneeID := range opts.AssigneeIDs {
-		err = opts.Issue.changeAssignee(e, doer, assigneeID, true)
-		if err != nil {
+		if _, err = opts.Issue.changeAssignee(e, doer, assigneeID); err != nil {
 			return err
 		}
 	}
@@ -80,6 +80,12 @@ const (
 	CommentTypeCode
 	// Reviews a pull request by giving general feedback
 	CommentTypeReview
+	// Project changed
+	CommentTypeProject
+	// Lock an issue, giving only collaborators access
+	CommentTypeLock
+	// Unlocks a previously locked issue
+	CommentTypeUnlock
 )
 
 // CommentTag defines comment tag type
@@ -107,6 +113,10 @@ type Comment struct {
 	MilestoneID      int64
 	OldMilestone     *Milestone `xorm:"-"`
 	Milestone        *Milestone `xorm:"-"`
+	OldProjectID     int64
+	ProjectID        int64
+	OldProject       *Project `xorm:"-"`
+	Project          *Project `xorm:"-"`
 	AssigneeID       int64
 	RemovedAssignee  bool
 	Assignee         *User `xorm:"-"`
@@ -303,6 +313,30 @@ func (c *Comment) LoadMilestone() error {
 	return nil
 }
 
+// LoadProject if comment.Type is CommentTypeProject, then load project
+func (c *Comment) LoadProject() error {
+	if c.OldProjectID > 0 {
+		var oldProject Project
+		has, err := x.ID(c.OldProjectID).Get(&oldProject)
+		if err != nil {
+			return err
+		} else if has {
+			c.OldProject = &oldProject
+		}
+	}
+
+	if c.ProjectID > 0 {
+		var project Project
+		has, err := x.ID(c.ProjectID).Get(&project)
+		if err != nil {
+			return err
+		} else if has {
+			c.Project = &project
+		}
+	}
+	return nil
+}
+
 // LoadAssigneeUser if comment.Type is CommentTypeAssignees, then load assignees
 func (c *Comment) LoadAssigneeUser() error {
 	var err error
@@ -471,6 +505,8 @@ func createComment(e *xorm.Session, opts *CreateCommentOptions) (_ *Comment, err
 		LabelID:          LabelID,
 		OldMilestoneID:   opts.OldMilestoneID,
 		MilestoneID:      opts.MilestoneID,
+		OldProjectID:     opts.OldProjectID,
+		ProjectID:        opts.ProjectID,
 		RemovedAssignee:  opts.RemovedAssignee,
 		AssigneeID:       opts.AssigneeID,
 		CommitID:         opts.CommitID,
@@ -640,6 +676,17 @@ func createMilestoneComment(e *xorm.Session, doer *User, repo *Repository, issue
 	})
 }
 
+func createProjectComment(e *xorm.Session, doer *User, repo *Repository, issue *Issue, oldProjectID, projectID int64) (*Comment, error) {
+	return createComment(e, &CreateCommentOptions{
+		Type:         CommentTypeProject,
+		Doer:         doer,
+		Repo:         repo,
+		Issue:        issue,
+		OldProjectID: oldProjectID,
+		ProjectID:    projectID,
+	})
+}
+
 func createAssigneeComment(e *xorm.Session, doer *User, repo *Repository, issue *Issue, assigneeID int64, removedAssignee bool) (*Comment, error) {
 	return createComment(e, &CreateCommentOptions{
 		Type:            CommentTypeAssignees,
@@ -744,6 +791,8 @@ type CreateCommentOptions struct {
 	DependentIssueID int64
 	OldMilestoneID   int64
 	MilestoneID      int64
+	OldProjectID     int64
+	ProjectID        int64
 	AssigneeID       int64
 	RemovedAssignee  bool
 	OldTitle         string
@@ -812,7 +861,28 @@ func CreateIssueComment(doer *User, repo *Repository, issue *Issue, content stri
 
 // CreateCodeComment creates a plain code comment at the specified line / path
 func CreateCodeComment(doer *User, repo *Repository, issue *Issue, content, treePath string, line, reviewID int64) (*Comment, error) {
-	var commitID, patch string
+	comments, err := CreateCodeComments(doer, repo, issue, reviewID, []CodeCommentOptions{{
+		TreePath: treePath,
+		Line:     line,
+		Content:  content,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	return comments[0], nil
+}
+
+// CodeCommentOptions defines the options of a code comment: the line is
+// negative for the lines of the previous version of the file
+type CodeCommentOptions struct {
+	TreePath string
+	Line     int64
+	Content  string
+}
+
+// CreateCodeComments creates the code comments of a review at once, either
+// all of them are created or none
+func CreateCodeComments(doer *User, repo *Repository, issue *Issue, reviewID int64, comments []CodeCommentOptions) ([]*Comment, error) {
 	pr, err := GetPullRequestByIssueID(issue.ID)
 	if err != nil {
 		return nil, fmt.Errorf("GetPullRequestByIssueID: %v", err)
@@ -825,41 +895,64 @@ func CreateCodeComment(doer *User, repo *Repository, issue *Issue, content, tree
 		return nil, fmt.Errorf("OpenRepository: %v", err)
 	}
 
-	// FIXME validate treePath
-	// Get latest commit referencing the commented line
-	// No need for get commit for base branch changes
-	if line > 0 {
-		commit, err := gitRepo.LineBlame(pr.GetGitRefName(), gitRepo.Path, treePath, uint(line))
-		if err != nil {
-			return nil, fmt.Errorf("LineBlame[%s, %s, %s, %d]: %v", pr.GetGitRefName(), gitRepo.Path, treePath, line, err)
-		}
-		commitID = commit.ID.String()
-	}
-
 	// Only fetch diff if comment is review comment
+	var headCommitID string
 	if reviewID != 0 {
-		headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
-		if err != nil {
+		if headCommitID, err = gitRepo.GetRefCommitID(pr.GetGitRefName()); err != nil {
 			return nil, fmt.Errorf("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
 		}
-		patchBuf := new(bytes.Buffer)
-		if err := GetRawDiffForFile(gitRepo.Path, pr.MergeBase, headCommitID, RawDiffNormal, treePath, patchBuf); err != nil {
-			return nil, fmt.Errorf("GetRawDiffForLine[%s, %s, %s, %s]: %v", err, gitRepo.Path, pr.MergeBase, headCommitID, treePath)
+	}
+
+	// Git is run before the transaction is started, so that it is kept short
+	opts := make([]*CreateCommentOptions, 0, len(comments))
+	for _, c := range comments {
+		var commitID, patch string
+		// FIXME validate treePath
+		// Get latest commit referencing the commented line
+		// No need for get commit for base branch changes
+		if c.Line > 0 {
+			commit, err := gitRepo.LineBlame(pr.GetGitRefName(), gitRepo.Path, c.TreePath, uint(c.Line))
+			if err != nil {
+				return nil, fmt.Errorf("LineBlame[%s, %s, %s, %d]: %v", pr.GetGitRefName(), gitRepo.Path, c.TreePath, c.Line, err)
+			}
+			commitID = commit.ID.String()
+		}
+
+		if reviewID != 0 {
+			patchBuf := new(bytes.Buffer)
+			if err := GetRawDiffForFile(gitRepo.Path, pr.MergeBase, headCommitID, RawDiffNormal, c.TreePath, patchBuf); err != nil {
+				return nil, fmt.Errorf("GetRawDiffForLine[%s, %s, %s, %s]: %v", err, gitRepo.Path, pr.MergeBase, headCommitID, c.TreePath)
+			}
+			patch = CutDiffAroundLine(strings.NewReader(patchBuf.String()), int64((&Comment{Line: c.Line}).UnsignedLine()), c.Line < 0, setting.UI.CodeCommentLines)
 		}
-		patch = CutDiffAroundLine(strings.NewReader(patchBuf.String()), int64((&Comment{Line: line}).UnsignedLine()), line < 0, setting.UI.CodeCommentLines)
+		opts = append(opts, &CreateCommentOptions{
+			Type:      CommentTypeCode,
+			Doer:      doer,
+			Repo:      repo,
+			Issue:     issue,
+			Content:   c.Content,
+			LineNum:   c.Line,
+			TreePath:  c.TreePath,
+			CommitSHA: commitID,
+			ReviewID:  reviewID,
+			Patch:     patch,
+		})
 	}
-	return CreateComment(&CreateCommentOptions{
-		Type:      CommentTypeCode,
-		Doer:      doer,
-		Repo:      repo,
-		Issue:     issue,
-		Content:   content,
-		LineNum:   line,
-		TreePath:  treePath,
-		CommitSHA: commitID,
-		ReviewID:  reviewID,
-		Patch:     patch,
-	})
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+	created := make([]*Comment, 0, len(opts))
+	for _, opt := range opts {
+		comment, err := createComment(sess, opt)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, comment)
+	}
+	return created, sess.Commit()
 }
 
 // CreateRefComment creates a commit reference comment to issue.
@@ -7,25 +7,61 @@ package models
 import (
 	"fmt"
 
-	"code.gitea.io/gitea/modules/indexer"
+	"code.gitea.io/gitea/modules/indexer/issues"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
 	"code.gitea.io/gitea/modules/util"
+
+	"github.com/Unknwon/com"
+	"github.com/go-xorm/builder"
 )
 
-// issueIndexerUpdateQueue queue of issue ids to be updated
-var issueIndexerUpdateQueue chan int64
+// maxSearchIssuesResults the maximum number of issues returned by a keyword search
+const maxSearchIssuesResults = 1000
+
+// issueIndexerMaxBatchSize the maximum number of issues sent to the indexer at once
+const issueIndexerMaxBatchSize = 16
+
+var (
+	// issueIndexer the configured issue indexer
+	issueIndexer issues.Indexer
+	// issueIndexerQueue queue of issue ids to be updated
+	issueIndexerQueue = sync.NewWorkQueue("issue_indexer")
+)
 
 // InitIssueIndexer initialize issue indexer
 func InitIssueIndexer() {
-	indexer.InitIssueIndexer(populateIssueIndexer)
-	issueIndexerUpdateQueue = make(chan int64, setting.Indexer.UpdateQueueLength)
-	go processIssueIndexerUpdateQueue()
+	var err error
+	var exist bool
+	switch setting.Indexer.IssueType {
+	case "bleve":
+		issueIndexer = issues.NewBleveIndexer(setting.Indexer.IssuePath)
+	case "elasticsearch":
+		issueIndexer, err = issues.NewElasticSearchIndexer(setting.Indexer.IssueConnStr, setting.Indexer.IssueIndexerName)
+		if err != nil {
+			log.Fatal(4, "InitIssueIndexer: %v", err)
+		}
+	case "db":
+		issueIndexer = &DBIndexer{}
+	default:
+		log.Fatal(4, "InitIssueIndexer: unknown issue indexer type %s", setting.Indexer.IssueType)
+	}
+
+	if exist, err = issueIndexer.Init(); err != nil {
+		log.Fatal(4, "InitIssueIndexer: %v", err)
+	}
+	if !exist {
+		if err = populateIssueIndexer(); err != nil {
+			log.Fatal(4, "InitIssueIndexer: populate index, %v", err)
+		}
+	}
+
+	go processIssueIndexerQueue()
 }
 
 // populateIssueIndexer populate the issue indexer with issue data
 func populateIssueIndexer() error {
-	batch := indexer.IssueIndexerBatch()
 	for page := 1; ; page++ {
 		repos, _, err := SearchRepositoryByName(&SearchRepoOptions{
 			Page:        page,
@@ -38,10 +74,10 @@ func populateIssueIndexer() error {
 			return fmt.Errorf("Repositories: %v", err)
 		}
 		if len(repos) == 0 {
-			return batch.Flush()
+			return nil
 		}
 		for _, repo := range repos {
-			issues, err := Issues(&IssuesOptions{
+			issueList, err := Issues(&IssuesOptions{
 				RepoIDs:  []int64{repo.ID},
 				IsClosed: util.OptionalBoolNone,
 				IsPull:   util.OptionalBoolNone,
@@ -49,56 +85,64 @@ func populateIssueIndexer() error {
 			if err != nil {
 				return err
 			}
-			if err = IssueList(issues).LoadComments(); err != nil {
+			if err = IssueList(issueList).LoadComments(); err != nil {
 				return err
 			}
-			for _, issue := range issues {
-				if err := issue.update().AddToFlushingBatch(batch); err != nil {
-					return err
-				}
+			data := make([]*issues.IndexerData, 0, len(issueList))
+			for _, issue := range issueList {
+				data = append(data, issue.indexerData())
+			}
+			if err = issueIndexer.Index(data); err != nil {
+				return err
 			}
 		}
 	}
 }
 
-func processIssueIndexerUpdateQueue() {
-	batch := indexer.IssueIndexerBatch()
-	for {
-		var issueID int64
-		select {
-		case issueID = <-issueIndexerUpdateQueue:
-		default:
-			// flush whatever updates we currently have, since we
-			// might have to wait a while
-			if err := batch.Flush(); err != nil {
+func processIssueIndexerQueue() {
+	issueIndexerQueue.RunBatch(issueIndexerMaxBatchSize, func(ids []string) {
+		updates := make([]*issues.IndexerData, 0, len(ids))
+		deletes := make([]int64, 0, len(ids))
+		for _, id := range ids {
+			issueID := com.StrTo(id).MustInt64()
+			issue, err := GetIssueByID(issueID)
+			if IsErrIssueNotExist(err) {
+				deletes = append(deletes, issueID)
+			} else if err != nil {
+				log.Error(4, "GetIssueByID: %v", err)
+			} else if err = issue.loadComments(x); err != nil {
+				log.Error(4, "loadComments: %v", err)
+			} else {
+				updates = append(updates, issue.indexerData())
+			}
+		}
+
+		if len(updates) > 0 {
+			if err := issueIndexer.Index(updates); err != nil {
 				log.Error(4, "IssueIndexer: %v", err)
 			}
-			issueID = <-issueIndexerUpdateQueue
 		}
-		issue, err := GetIssueByID(issueID)
-		if err != nil {
-			log.Error(4, "GetIssueByID: %v", err)
-		} else if err = issue.update().AddToFlushingBatch(batch); err != nil {
-			log.Error(4, "IssueIndexer: %v", err)
+		if len(deletes) > 0 {
+			if err := issueIndexer.Delete(deletes...); err != nil {
+				log.Error(4, "IssueIndexer: %v", err)
+			}
 		}
-	}
+	})
 }
 
-func (issue *Issue) update() indexer.IssueIndexerUpdate {
+func (issue *Issue) indexerData() *issues.IndexerData {
 	comments := make([]string, 0, 5)
 	for _, comment := range issue.Comments {
 		if comment.Type == CommentTypeComment {
 			comments = append(comments, comment.Content)
 		}
 	}
-	return indexer.IssueIndexerUpdate{
-		IssueID: issue.ID,
-		Data: &indexer.IssueIndexerData{
-			RepoID:   issue.RepoID,
-			Title:    issue.Title,
-			Content:  issue.Content,
-			Comments: comments,
-		},
+	return &issues.IndexerData{
+		ID:       issue.ID,
+		RepoID:   issue.RepoID,
+		Title:    issue.Title,
+		Content:  issue.Content,
+		Comments: comments,
 	}
 }
 
@@ -122,13 +166,77 @@ func UpdateIssueIndexerCols(issueID int64, cols ...string) {
 	}
 }
 
-// UpdateIssueIndexer add/update an issue to the issue indexer
+// UpdateIssueIndexer add/update an issue to the issue indexer. An issue which
+// does not exist anymore is removed from the indexer.
 func UpdateIssueIndexer(issueID int64) {
-	select {
-	case issueIndexerUpdateQueue <- issueID:
-	default:
-		go func() {
-			issueIndexerUpdateQueue <- issueID
-		}()
+	if issueIndexer == nil {
+		// the indexer is not initialized
+		return
+	}
+	go issueIndexerQueue.Add(issueID)
+}
+
+// SearchIssuesByKeyword searches for issues by given conditions.
+// Returns the matching issue IDs
+func SearchIssuesByKeyword(repoID int64, keyword string) ([]int64, error) {
+	res, err := issueIndexer.Search(keyword, repoID, maxSearchIssuesResults, 0)
+	if err != nil {
+		return nil, err
+	}
+	issueIDs := make([]int64, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		issueIDs = append(issueIDs, hit.ID)
+	}
+	return issueIDs, nil
+}
+
+var _ issues.Indexer = &DBIndexer{}
+
+// DBIndexer implements issues.Indexer interface by searching the issues
+// directly in the database with LIKE conditions, so no index has to be kept.
+type DBIndexer struct {
+}
+
+// Init does nothing as there is no index
+func (db *DBIndexer) Init() (bool, error) {
+	return true, nil
+}
+
+// Index does nothing as there is no index
+func (db *DBIndexer) Index(data []*issues.IndexerData) error {
+	return nil
+}
+
+// Delete does nothing as there is no index
+func (db *DBIndexer) Delete(ids ...int64) error {
+	return nil
+}
+
+// Search searches the issues of a repository whose title, content or
+// comments contain the keyword
+func (db *DBIndexer) Search(kw string, repoID int64, limit, start int) (*issues.SearchResult, error) {
+	cond := builder.NewCond().
+		And(builder.Eq{"repo_id": repoID}).
+		And(builder.Like{"name", kw}.
+			Or(builder.Like{"content", kw}).
+			Or(builder.In("id", builder.Select("issue_id").
+				From("comment").
+				Where(builder.Eq{"type": CommentTypeComment}.And(builder.Like{"content", kw})))))
+
+	issueIDs := make([]int64, 0, limit)
+	if err := x.Table("issue").Cols("id").Where(cond).
+		Desc("id").Limit(limit, start).Find(&issueIDs); err != nil {
+		return nil, err
+	}
+
+	result := &issues.SearchResult{
+		Hits: make([]issues.Match, 0, len(issueIDs)),
+	}
+	for _, id := range issueIDs {
+		result.Hits = append(result.Hits, issues.Match{
+			ID:     id,
+			RepoID: repoID,
+		})
 	}
+	return result, nil
 }
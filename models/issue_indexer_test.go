@@ -0,0 +1,40 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDBIndexer_Search(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	indexer := &DBIndexer{}
+	test := func(keyword string, repoID int64, expectedIDs ...int64) {
+		if expectedIDs == nil {
+			expectedIDs = []int64{}
+		}
+		res, err := indexer.Search(keyword, repoID, 10, 0)
+		assert.NoError(t, err)
+		ids := make([]int64, 0, len(res.Hits))
+		for _, hit := range res.Hits {
+			assert.EqualValues(t, repoID, hit.RepoID)
+			ids = append(ids, hit.ID)
+		}
+		assert.Equal(t, expectedIDs, ids)
+	}
+
+	// title
+	test("issue2", 1, 2)
+	// content
+	test("first issue", 1, 1)
+	test("issue", 1, 5, 3, 2, 1)
+	// comments
+	test("good work", 1, 1)
+	test("good work", 2)
+	test("nothing", 1)
+}
@@ -0,0 +1,30 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockIssue(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	assert.NoError(t, LockIssue(&IssueLockOptions{Doer: doer, Issue: issue, Reason: "Spam"}))
+	AssertExistsAndLoadBean(t, &Issue{ID: 1, IsLocked: true})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 1, Type: CommentTypeLock, PosterID: 2, Content: "Spam"})
+
+	// locking twice does not add another comment
+	assert.NoError(t, LockIssue(&IssueLockOptions{Doer: doer, Issue: issue}))
+	AssertCount(t, &Comment{IssueID: 1, Type: CommentTypeLock}, 1)
+
+	assert.NoError(t, UnlockIssue(&IssueLockOptions{Doer: doer, Issue: issue}))
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.False(t, issue.IsLocked)
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 1, Type: CommentTypeUnlock, PosterID: 2})
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/Unknwon/com"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/mailer"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/setting"
 )
@@ -59,6 +60,19 @@ func mailIssueCommentToParticipants(e Engine, issue *Issue, doer *User, content
 		}
 	}
 
+	// reasons of the participants who are not notified as watchers
+	reasons := make(map[int64]NotificationReason, len(participants))
+	reasons[issue.PosterID] = NotificationReasonAuthor
+	for _, assignee := range assignees {
+		reasons[assignee.ID] = NotificationReasonAssigned
+	}
+	participantReason := func(userID int64) NotificationReason {
+		if reason, ok := reasons[userID]; ok {
+			return reason
+		}
+		return NotificationReasonSubscribed
+	}
+
 	tos := make([]string, 0, len(watchers)) // List of email addresses.
 	names := make([]string, 0, len(watchers))
 	for i := range watchers {
@@ -74,8 +88,15 @@ func mailIssueCommentToParticipants(e Engine, issue *Issue, doer *User, content
 			continue
 		}
 
-		tos = append(tos, to.Email)
+		// watchers who do not want the mail are still excluded from the
+		// mentions below
 		names = append(names, to.Name)
+		if enabled, err := isEmailNotificationEnabled(e, to.ID, participantReason(to.ID)); err != nil {
+			return err
+		} else if !enabled {
+			continue
+		}
+		tos = append(tos, to.Email)
 	}
 	for i := range participants {
 		if participants[i].ID == doer.ID {
@@ -84,8 +105,13 @@ func mailIssueCommentToParticipants(e Engine, issue *Issue, doer *User, content
 			continue
 		}
 
-		tos = append(tos, participants[i].Email)
 		names = append(names, participants[i].Name)
+		if enabled, err := isEmailNotificationEnabled(e, participants[i].ID, participantReason(participants[i].ID)); err != nil {
+			return err
+		} else if !enabled {
+			continue
+		}
+		tos = append(tos, participants[i].Email)
 	}
 
 	for _, to := range tos {
@@ -103,15 +129,68 @@ func mailIssueCommentToParticipants(e Engine, issue *Issue, doer *User, content
 		tos = append(tos, mentions[i])
 	}
 
-	emails := getUserEmailsByNames(e, tos)
+	for _, name := range tos {
+		to, err := getUserByName(e, name)
+		if err != nil || !to.IsMailable() {
+			continue
+		}
 
-	for _, to := range emails {
-		SendIssueMentionMail(issue, doer, content, comment, []string{to})
+		if enabled, err := isEmailNotificationEnabled(e, to.ID, NotificationReasonMention); err != nil {
+			return err
+		} else if !enabled {
+			continue
+		}
+		SendIssueMentionMail(issue, doer, content, comment, []string{to.Email})
 	}
 
 	return nil
 }
 
+// mailIssueNotification returns the mail to a user notified of an activity on
+// an issue for a reason which is not already covered by the mails of the
+// issue comments, or nil if the user does not want these notifications by
+// email. The mail is to be sent once the notifications are committed.
+func mailIssueNotification(e Engine, issue *Issue, doerID, userID int64, reason NotificationReason) (*mailer.Message, error) {
+	if !setting.Service.EnableNotifyMail {
+		return nil, nil
+	}
+
+	var message string
+	switch reason {
+	case NotificationReasonAssigned:
+		message = "%s assigned you to %s."
+	case NotificationReasonReviewRequested:
+		message = "%s requested your review on %s."
+	case NotificationReasonCIFailed:
+		message = "A status check failed on %[2]s."
+	default:
+		return nil, nil
+	}
+
+	if enabled, err := isEmailNotificationEnabled(e, userID, reason); err != nil {
+		return nil, err
+	} else if !enabled {
+		return nil, nil
+	}
+
+	to, err := getUserByID(e, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getUserByID [%d]: %v", userID, err)
+	} else if !to.IsMailable() {
+		return nil, nil
+	}
+	doer, err := getUserByID(e, doerID)
+	if err != nil {
+		return nil, fmt.Errorf("getUserByID [%d]: %v", doerID, err)
+	}
+	if err = issue.loadRepo(e); err != nil {
+		return nil, err
+	}
+
+	ref := fmt.Sprintf("%s#%d", issue.Repo.FullName(), issue.Index)
+	return newNotificationMail(to, doer, issue.mailSubject(), fmt.Sprintf(message, doer.DisplayName(), ref), issue.HTMLURL(), reason), nil
+}
+
 // MailParticipants sends new issue thread created emails to repository watchers
 // and mentioned people.
 func (issue *Issue) MailParticipants() (err error) {
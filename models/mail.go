@@ -29,7 +29,9 @@ const (
 	mailIssueComment base.TplName = "issue/comment"
 	mailIssueMention base.TplName = "issue/mention"
 
-	mailNotifyCollaborator base.TplName = "notify/collaborator"
+	mailNotifyCollaborator    base.TplName = "notify/collaborator"
+	mailNotifyWebhookDisabled base.TplName = "notify/webhook_disabled"
+	mailNotifyNotification    base.TplName = "notify/notification"
 )
 
 var templates *template.Template
@@ -141,6 +143,149 @@ func SendCollaboratorMail(u, doer *User, repo *Repository) {
 	mailer.SendAsync(msg)
 }
 
+// newNotificationMail returns a mail about a notification for the given
+// reason to a user, or nil if it cannot be rendered.
+func newNotificationMail(u, doer *User, subject, message, link string, reason NotificationReason) *mailer.Message {
+	data := map[string]interface{}{
+		"Subject": subject,
+		"Message": message,
+		"Reason":  reason.String(),
+		"Link":    link,
+	}
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyNotification), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return nil
+	}
+
+	msg := mailer.NewMessageFrom([]string{u.Email}, doer.DisplayName(), setting.MailService.FromEmail, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, %s notification", u.ID, reason)
+	return msg
+}
+
+// mailReleaseNotification returns the mail about a published release to a
+// watcher of its repository, or nil if the watcher does not want these
+// notifications by email. The mail is to be sent once the notifications are
+// committed.
+func mailReleaseNotification(e Engine, rel *Release, userID int64) (*mailer.Message, error) {
+	if !setting.Service.EnableNotifyMail {
+		return nil, nil
+	}
+
+	if enabled, err := isEmailNotificationEnabled(e, userID, NotificationReasonRelease); err != nil {
+		return nil, err
+	} else if !enabled {
+		return nil, nil
+	}
+
+	to, err := getUserByID(e, userID)
+	if err != nil {
+		return nil, fmt.Errorf("getUserByID [%d]: %v", userID, err)
+	} else if !to.IsMailable() {
+		return nil, nil
+	}
+	publisher, err := getUserByID(e, rel.PublisherID)
+	if err != nil {
+		return nil, fmt.Errorf("getUserByID [%d]: %v", rel.PublisherID, err)
+	}
+
+	subject := fmt.Sprintf("[%s] Release %s", rel.Repo.Name, rel.Title)
+	message := fmt.Sprintf("%s published the release %s of %s.", publisher.DisplayName(), rel.TagName, rel.Repo.FullName())
+	return newNotificationMail(to, publisher, subject, message, rel.HTMLURL(), NotificationReasonRelease), nil
+}
+
+// webhookOwnerEmails returns the emails of the users who own a webhook: the
+// owner of its repository, the owners of its organization or the site
+// administrators for system and default webhooks
+func webhookOwnerEmails(w *Webhook) (name string, link string, emails []string, err error) {
+	var owner *User
+	if w.RepoID == 0 && w.OrgID == 0 {
+		admins := make([]*User, 0, 5)
+		if err = x.Where("is_admin = ? AND is_active = ?", true, true).Find(&admins); err != nil {
+			return "", "", nil, err
+		}
+		for _, admin := range admins {
+			emails = append(emails, admin.Email)
+		}
+		kind := "default"
+		if w.IsSystemWebhook {
+			kind = "system"
+		}
+		link = fmt.Sprintf("%sadmin/hooks/%s/%d", setting.AppURL, kind, w.ID)
+		return setting.AppName, link, emails, nil
+	} else if w.RepoID > 0 {
+		repo, err := GetRepositoryByID(w.RepoID)
+		if err != nil {
+			return "", "", nil, err
+		}
+		if err = repo.GetOwner(); err != nil {
+			return "", "", nil, err
+		}
+		owner = repo.Owner
+		name = repo.FullName()
+		link = fmt.Sprintf("%s/settings/hooks/%d", repo.HTMLURL(), w.ID)
+	} else {
+		if owner, err = GetUserByID(w.OrgID); err != nil {
+			return "", "", nil, err
+		}
+		name = owner.Name
+		link = fmt.Sprintf("%sorg/%s/settings/hooks/%d", setting.AppURL, owner.Name, w.ID)
+	}
+
+	if !owner.IsOrganization() {
+		return name, link, []string{owner.Email}, nil
+	}
+	team, err := owner.GetOwnerTeam()
+	if err != nil {
+		return "", "", nil, err
+	}
+	if err = team.GetMembers(); err != nil {
+		return "", "", nil, err
+	}
+	for _, member := range team.Members {
+		emails = append(emails, member.Email)
+	}
+	return name, link, emails, nil
+}
+
+// SendWebhookDisabledMail notifies the owners of a webhook that it has been
+// disabled after too many failed deliveries.
+func SendWebhookDisabledMail(w *Webhook) {
+	if setting.MailService == nil {
+		return
+	}
+	name, link, emails, err := webhookOwnerEmails(w)
+	if err != nil {
+		log.Error(3, "webhookOwnerEmails [%d]: %v", w.ID, err)
+		return
+	} else if len(emails) == 0 {
+		return
+	}
+	subject := fmt.Sprintf("A webhook of %s has been disabled", name)
+
+	data := map[string]interface{}{
+		"Subject":      subject,
+		"Name":         name,
+		"URL":          w.URL,
+		"FailureCount": w.FailureCount,
+		"Link":         link,
+	}
+
+	var content bytes.Buffer
+
+	if err := templates.ExecuteTemplate(&content, string(mailNotifyWebhookDisabled), data); err != nil {
+		log.Error(3, "Template: %v", err)
+		return
+	}
+
+	msg := mailer.NewMessage(emails, subject, content.String())
+	msg.Info = fmt.Sprintf("HookID: %d, webhook disabled", w.ID)
+
+	mailer.SendAsync(msg)
+}
+
 func composeTplData(subject, body, link string) map[string]interface{} {
 	data := make(map[string]interface{}, 10)
 	data["Subject"] = subject
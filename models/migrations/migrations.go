@@ -200,6 +200,34 @@ var migrations = []Migration{
 	NewMigration("add review", addReview),
 	// v73 -> v74
 	NewMigration("add must_change_password column for users table", addMustChangePassword),
+	// v74 -> v75
+	NewMigration("add approval and status check requirements to protected_branch table", addMergeRequirementsToProtectedBranch),
+	// v75 -> v76
+	NewMigration("add push_mirror table", addPushMirrorTable),
+	// v76 -> v77
+	NewMigration("add is_archived column for repository table", addIsArchivedToRepository),
+	// v77 -> v78
+	NewMigration("add is_template and template_id columns for repository table", addTemplateToRepository),
+	// v78 -> v79
+	NewMigration("add scope, repo_ids and expires_unix columns for access_token table", addScopeAndExpiryToAccessToken),
+	// v79 -> v80
+	NewMigration("add oauth2_application, oauth2_authorization_code and oauth2_grant tables", addOAuth2ApplicationTables),
+	// v80 -> v81
+	NewMigration("add project, project_board and project_issue tables", addProjectTables),
+	// v81 -> v82
+	NewMigration("add is_locked column for issue table", addIsLockedToIssues),
+	// v82 -> v83
+	NewMigration("add indexer_refs column for repository table and ref column for repo_indexer_status table", addIndexerRefsToRepository),
+	// v83 -> v84
+	NewMigration("add attempts and next_retry_unix columns for hook_task table and failure_count column for webhook table", addWebhookRetries),
+	// v84 -> v85
+	NewMigration("split the label, assignee, milestone and synchronization events of webhooks", splitWebhookIssueAndPullRequestEvents),
+	// v85 -> v86
+	NewMigration("add is_system_webhook column for webhook table", addIsSystemWebhookToWebhook),
+	// v86 -> v87
+	NewMigration("add reason and release_id columns for notification table and create user_notification_setting table", addNotificationReasons),
+	// v87 -> v88
+	NewMigration("add dismissed column for review table", addDismissedToReview),
 }
 
 // Migrate database to current version
@@ -0,0 +1,26 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addMergeRequirementsToProtectedBranch(x *xorm.Engine) error {
+	// ProtectedBranch see models/branches.go
+	type ProtectedBranch struct {
+		ID                  int64    `xorm:"pk autoincr"`
+		RequiredApprovals   int64    `xorm:"NOT NULL DEFAULT 0"`
+		EnableStatusCheck   bool     `xorm:"NOT NULL DEFAULT false"`
+		StatusCheckContexts []string `xorm:"JSON TEXT"`
+	}
+
+	if err := x.Sync2(new(ProtectedBranch)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
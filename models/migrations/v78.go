@@ -0,0 +1,27 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addScopeAndExpiryToAccessToken(x *xorm.Engine) error {
+	// AccessToken see models/token.go
+	type AccessToken struct {
+		ID          int64   `xorm:"pk autoincr"`
+		Scope       string  `xorm:"VARCHAR(255) NOT NULL DEFAULT 'all'"`
+		RepoIDs     []int64 `xorm:"TEXT JSON"`
+		ExpiresUnix int64
+	}
+
+	// Existing tokens keep full access.
+	if err := x.Sync2(new(AccessToken)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
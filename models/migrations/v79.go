@@ -0,0 +1,70 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+// OAuth2Application see models/oauth2_application.go
+type OAuth2Application struct {
+	ID           int64 `xorm:"pk autoincr"`
+	UID          int64 `xorm:"INDEX"`
+	Name         string
+	ClientID     string         `xorm:"UNIQUE"`
+	ClientSecret string         `xorm:"VARCHAR(64)"`
+	RedirectURIs []string       `xorm:"redirect_uris JSON TEXT"`
+	CreatedUnix  util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix  util.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName sets the table name to `oauth2_application`
+func (app *OAuth2Application) TableName() string {
+	return "oauth2_application"
+}
+
+// OAuth2AuthorizationCode see models/oauth2_application.go
+type OAuth2AuthorizationCode struct {
+	ID                  int64 `xorm:"pk autoincr"`
+	GrantID             int64
+	Code                string `xorm:"INDEX UNIQUE"`
+	CodeChallenge       string
+	CodeChallengeMethod string
+	RedirectURI         string
+	ValidUntil          util.TimeStamp `xorm:"INDEX"`
+}
+
+// TableName sets the table name to `oauth2_authorization_code`
+func (code *OAuth2AuthorizationCode) TableName() string {
+	return "oauth2_authorization_code"
+}
+
+// OAuth2Grant see models/oauth2_application.go
+type OAuth2Grant struct {
+	ID            int64          `xorm:"pk autoincr"`
+	UserID        int64          `xorm:"INDEX unique(user_application)"`
+	ApplicationID int64          `xorm:"INDEX unique(user_application)"`
+	Counter       int64          `xorm:"NOT NULL DEFAULT 1"`
+	Scope         string         `xorm:"TEXT"`
+	Nonce         string         `xorm:"TEXT"`
+	CreatedUnix   util.TimeStamp `xorm:"created"`
+	UpdatedUnix   util.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the table name to `oauth2_grant`
+func (grant *OAuth2Grant) TableName() string {
+	return "oauth2_grant"
+}
+
+func addOAuth2ApplicationTables(x *xorm.Engine) error {
+	if err := x.Sync2(new(OAuth2Application), new(OAuth2AuthorizationCode), new(OAuth2Grant)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addProjectTables(x *xorm.Engine) error {
+	// Project see models/project.go
+	type Project struct {
+		ID             int64  `xorm:"pk autoincr"`
+		Title          string `xorm:"INDEX NOT NULL"`
+		Description    string `xorm:"TEXT"`
+		RepoID         int64  `xorm:"INDEX"`
+		OwnerID        int64  `xorm:"INDEX"`
+		CreatorID      int64  `xorm:"NOT NULL"`
+		IsClosed       bool   `xorm:"INDEX"`
+		Type           uint8
+		BoardType      uint8
+		CloseBoardID   int64
+		CreatedUnix    util.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix    util.TimeStamp `xorm:"INDEX updated"`
+		ClosedDateUnix util.TimeStamp
+	}
+
+	// ProjectBoard see models/project_board.go
+	type ProjectBoard struct {
+		ID          int64          `xorm:"pk autoincr"`
+		Title       string         `xorm:"NOT NULL"`
+		Sorting     int            `xorm:"NOT NULL DEFAULT 0"`
+		ProjectID   int64          `xorm:"INDEX NOT NULL"`
+		CreatorID   int64          `xorm:"NOT NULL"`
+		CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	// ProjectIssue see models/project_issue.go
+	type ProjectIssue struct {
+		ID             int64 `xorm:"pk autoincr"`
+		IssueID        int64 `xorm:"UNIQUE NOT NULL"`
+		ProjectID      int64 `xorm:"INDEX NOT NULL"`
+		ProjectBoardID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Sorting        int   `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	// Comment see models/issue_comment.go
+	type Comment struct {
+		OldProjectID int64
+		ProjectID    int64
+	}
+
+	// RepoUnit see models/repo_unit.go
+	type RepoUnit struct {
+		ID          int64
+		RepoID      int64                  `xorm:"INDEX(s)"`
+		Type        int                    `xorm:"INDEX(s)"`
+		Config      map[string]interface{} `xorm:"TEXT"`
+		CreatedUnix util.TimeStamp         `xorm:"INDEX CREATED"`
+	}
+
+	// TeamUnit see models/org_team.go
+	type TeamUnit struct {
+		ID     int64 `xorm:"pk autoincr"`
+		OrgID  int64 `xorm:"INDEX"`
+		TeamID int64 `xorm:"UNIQUE(s)"`
+		Type   int   `xorm:"UNIQUE(s)"`
+	}
+
+	const (
+		unitTypeIssues   = 2
+		unitTypeProjects = 8
+	)
+
+	if err := x.Sync2(new(Project), new(ProjectBoard), new(ProjectIssue), new(Comment)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	// Enable projects on the repositories and for the teams that have issues
+	const batchSize = 100
+	for start := 0; ; start += batchSize {
+		units := make([]*RepoUnit, 0, batchSize)
+		if err := sess.Where("`type` = ?", unitTypeIssues).Asc("id").Limit(batchSize, start).Find(&units); err != nil {
+			return err
+		}
+		if len(units) == 0 {
+			break
+		}
+
+		for _, unit := range units {
+			has, err := sess.Where("repo_id = ? AND `type` = ?", unit.RepoID, unitTypeProjects).Exist(new(RepoUnit))
+			if err != nil {
+				return err
+			} else if has {
+				continue
+			}
+			if _, err = sess.Insert(&RepoUnit{
+				RepoID: unit.RepoID,
+				Type:   unitTypeProjects,
+				Config: map[string]interface{}{},
+			}); err != nil {
+				return fmt.Errorf("Insert repo unit: %v", err)
+			}
+		}
+	}
+
+	for start := 0; ; start += batchSize {
+		units := make([]*TeamUnit, 0, batchSize)
+		if err := sess.Where("`type` = ?", unitTypeIssues).Asc("id").Limit(batchSize, start).Find(&units); err != nil {
+			return err
+		}
+		if len(units) == 0 {
+			break
+		}
+
+		for _, unit := range units {
+			has, err := sess.Where("team_id = ? AND `type` = ?", unit.TeamID, unitTypeProjects).Exist(new(TeamUnit))
+			if err != nil {
+				return err
+			} else if has {
+				continue
+			}
+			if _, err = sess.Insert(&TeamUnit{
+				OrgID:  unit.OrgID,
+				TeamID: unit.TeamID,
+				Type:   unitTypeProjects,
+			}); err != nil {
+				return fmt.Errorf("Insert team unit: %v", err)
+			}
+		}
+	}
+
+	return sess.Commit()
+}
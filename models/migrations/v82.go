@@ -0,0 +1,33 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addIndexerRefsToRepository(x *xorm.Engine) error {
+	// Repository see models/repo.go
+	type Repository struct {
+		ID          int64    `xorm:"pk autoincr"`
+		IndexerRefs []string `xorm:"TEXT JSON"`
+	}
+
+	// RepoIndexerStatus see models/repo_indexer.go
+	type RepoIndexerStatus struct {
+		ID  int64  `xorm:"pk autoincr"`
+		Ref string `xorm:"VARCHAR(255)"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(RepoIndexerStatus)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
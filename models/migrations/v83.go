@@ -0,0 +1,41 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addWebhookRetries(x *xorm.Engine) error {
+	// HookTask see models/webhook.go
+	type HookTask struct {
+		ID            int64          `xorm:"pk autoincr"`
+		Attempts      int            `xorm:"NOT NULL DEFAULT 0"`
+		NextRetryUnix util.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	// Webhook see models/webhook.go
+	type Webhook struct {
+		ID           int64 `xorm:"pk autoincr"`
+		FailureCount int   `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(HookTask)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	if err := x.Sync2(new(Webhook)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+
+	// the past deliveries were attempted once
+	if _, err := x.Exec("UPDATE hook_task SET attempts = 1 WHERE is_delivered = ?", true); err != nil {
+		return fmt.Errorf("UPDATE hook_task: %v", err)
+	}
+	return nil
+}
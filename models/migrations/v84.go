@@ -0,0 +1,79 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func splitWebhookIssueAndPullRequestEvents(x *xorm.Engine) error {
+	// Webhook see models/webhook.go
+	type Webhook struct {
+		ID     int64  `xorm:"pk autoincr"`
+		Events string `xorm:"TEXT"`
+	}
+
+	// HookEvent see models/webhook.go
+	type HookEvent struct {
+		PushOnly       bool            `json:"push_only"`
+		SendEverything bool            `json:"send_everything"`
+		ChooseEvents   bool            `json:"choose_events"`
+		HookEvents     map[string]bool `json:"events"`
+	}
+
+	// the label, assignee and milestone changes of issues and pull requests,
+	// as well as the synchronizations of pull requests, used to be sent with
+	// the issues and pull_request events
+	splitEvents := map[string][]string{
+		"issues":       {"issue_assign", "issue_label", "issue_milestone"},
+		"pull_request": {"pull_request_assign", "pull_request_label", "pull_request_milestone", "pull_request_sync"},
+	}
+
+	var hooks []*Webhook
+	if err := x.Find(&hooks); err != nil {
+		return fmt.Errorf("Find: %v", err)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		event := &HookEvent{}
+		if err := json.Unmarshal([]byte(hook.Events), event); err != nil {
+			// the hooks with invalid events are skipped by Gitea as well
+			continue
+		}
+		if !event.ChooseEvents {
+			continue
+		}
+		if event.HookEvents == nil {
+			event.HookEvents = make(map[string]bool)
+		}
+		for name, split := range splitEvents {
+			if !event.HookEvents[name] {
+				continue
+			}
+			for _, splitName := range split {
+				event.HookEvents[splitName] = true
+			}
+		}
+
+		data, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("encode JSON events: %v", err)
+		}
+		hook.Events = string(data)
+		if _, err = sess.ID(hook.ID).Cols("events").Update(hook); err != nil {
+			return fmt.Errorf("update events column: %v", err)
+		}
+	}
+	return sess.Commit()
+}
@@ -0,0 +1,34 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/xorm"
+)
+
+func addNotificationReasons(x *xorm.Engine) error {
+	// Notification see models/notification.go
+	type Notification struct {
+		ID        int64 `xorm:"pk autoincr"`
+		Reason    uint8 `xorm:"SMALLINT INDEX NOT NULL DEFAULT 1"`
+		ReleaseID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	// UserNotificationSetting see models/user_notification_setting.go
+	type UserNotificationSetting struct {
+		ID     int64 `xorm:"pk autoincr"`
+		UserID int64 `xorm:"UNIQUE(s) NOT NULL"`
+		Reason uint8 `xorm:"SMALLINT UNIQUE(s) NOT NULL"`
+		Web    bool  `xorm:"NOT NULL DEFAULT true"`
+		Email  bool  `xorm:"NOT NULL DEFAULT true"`
+	}
+
+	if err := x.Sync2(new(Notification), new(UserNotificationSetting)); err != nil {
+		return fmt.Errorf("Sync2: %v", err)
+	}
+	return nil
+}
@@ -7,7 +7,12 @@ package models
 import (
 	"fmt"
 
+	"code.gitea.io/gitea/modules/mailer"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/go-xorm/builder"
 )
 
 type (
@@ -15,6 +20,8 @@ type (
 	NotificationStatus uint8
 	// NotificationSource is the source of the notification (issue, PR, commit, etc)
 	NotificationSource uint8
+	// NotificationReason is the reason why a user is notified (watching, mentioned, etc)
+	NotificationReason uint8
 )
 
 const (
@@ -33,8 +40,53 @@ const (
 	NotificationSourcePullRequest
 	// NotificationSourceCommit is a notification of a commit
 	NotificationSourceCommit
+	// NotificationSourceRelease is a notification of a release
+	NotificationSourceRelease
 )
 
+const (
+	// NotificationReasonSubscribed is the activity of a watched repository or issue
+	NotificationReasonSubscribed NotificationReason = iota + 1
+	// NotificationReasonAuthor is the activity of an issue or a pull request created by the user
+	NotificationReasonAuthor
+	// NotificationReasonMention is a mention of the user
+	NotificationReasonMention
+	// NotificationReasonAssigned is the assignment of an issue or a pull request to the user
+	NotificationReasonAssigned
+	// NotificationReasonReviewRequested is a pull request waiting for the approval of the user
+	NotificationReasonReviewRequested
+	// NotificationReasonRelease is a release published in a watched repository
+	NotificationReasonRelease
+	// NotificationReasonCIFailed is a failed commit status of a pull request created by the user
+	NotificationReasonCIFailed
+)
+
+// NotificationReasons contains all the notification reasons
+var NotificationReasons = []NotificationReason{
+	NotificationReasonSubscribed,
+	NotificationReasonAuthor,
+	NotificationReasonMention,
+	NotificationReasonAssigned,
+	NotificationReasonReviewRequested,
+	NotificationReasonRelease,
+	NotificationReasonCIFailed,
+}
+
+var notificationReasonNames = map[NotificationReason]string{
+	NotificationReasonSubscribed:      "subscribed",
+	NotificationReasonAuthor:          "author",
+	NotificationReasonMention:         "mention",
+	NotificationReasonAssigned:        "assign",
+	NotificationReasonReviewRequested: "review_requested",
+	NotificationReasonRelease:         "release",
+	NotificationReasonCIFailed:        "ci_failed",
+}
+
+// String returns the name of the reason
+func (reason NotificationReason) String() string {
+	return notificationReasonNames[reason]
+}
+
 // Notification represents a notification
 type Notification struct {
 	ID     int64 `xorm:"pk autoincr"`
@@ -43,56 +95,78 @@ type Notification struct {
 
 	Status NotificationStatus `xorm:"SMALLINT INDEX NOT NULL"`
 	Source NotificationSource `xorm:"SMALLINT INDEX NOT NULL"`
+	Reason NotificationReason `xorm:"SMALLINT INDEX NOT NULL DEFAULT 1"`
 
-	IssueID  int64  `xorm:"INDEX NOT NULL"`
-	CommitID string `xorm:"INDEX"`
+	IssueID   int64  `xorm:"INDEX NOT NULL"`
+	CommitID  string `xorm:"INDEX"`
+	ReleaseID int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
 
 	UpdatedBy int64 `xorm:"INDEX NOT NULL"`
 
 	Issue      *Issue      `xorm:"-"`
 	Repository *Repository `xorm:"-"`
+	Release    *Release    `xorm:"-"`
 
 	CreatedUnix util.TimeStamp `xorm:"created INDEX NOT NULL"`
 	UpdatedUnix util.TimeStamp `xorm:"updated INDEX NOT NULL"`
 }
 
+// IssueNotificationOptions represents the options of the notifications
+// created for an activity on an issue
+type IssueNotificationOptions struct {
+	Issue                *Issue
+	NotificationAuthorID int64
+	// Receivers are notified for the given reason even if they do not watch
+	// the issue, unless they cannot read it
+	Receivers map[int64]NotificationReason
+	// OnlyReceivers does not notify the watchers of the issue and the
+	// repository
+	OnlyReceivers bool
+}
+
 // CreateOrUpdateIssueNotifications creates an issue notification
 // for each watcher, or updates it if already exists
-func CreateOrUpdateIssueNotifications(issue *Issue, notificationAuthorID int64) error {
+func CreateOrUpdateIssueNotifications(opts IssueNotificationOptions) error {
 	sess := x.NewSession()
 	defer sess.Close()
 	if err := sess.Begin(); err != nil {
 		return err
 	}
 
-	if err := createOrUpdateIssueNotifications(sess, issue, notificationAuthorID); err != nil {
+	mails, err := createOrUpdateIssueNotifications(sess, opts)
+	if err != nil {
 		return err
 	}
 
-	return sess.Commit()
-}
-
-func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthorID int64) error {
-	issueWatches, err := getIssueWatchers(e, issue.ID)
-	if err != nil {
+	if err = sess.Commit(); err != nil {
 		return err
 	}
+	for _, msg := range mails {
+		mailer.SendAsync(msg)
+	}
+	return nil
+}
 
-	watches, err := getWatchers(e, issue.RepoID)
-	if err != nil {
-		return err
+// createOrUpdateIssueNotifications creates or updates the notifications and
+// returns the mails to send once they are committed
+func createOrUpdateIssueNotifications(e Engine, opts IssueNotificationOptions) ([]*mailer.Message, error) {
+	issue := opts.Issue
+	if err := issue.loadRepo(e); err != nil {
+		return nil, err
 	}
 
 	notifications, err := getNotificationsByIssueID(e, issue.ID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	alreadyNotified := make(map[int64]struct{}, len(issueWatches)+len(watches))
+	var mails []*mailer.Message
+
+	alreadyNotified := make(map[int64]struct{}, len(opts.Receivers))
 
-	notifyUser := func(userID int64) error {
+	notifyUser := func(userID int64, reason NotificationReason) error {
 		// do not send notification for the own issuer/commenter
-		if userID == notificationAuthorID {
+		if userID == opts.NotificationAuthorID {
 			return nil
 		}
 
@@ -101,10 +175,61 @@ func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthor
 		}
 		alreadyNotified[userID] = struct{}{}
 
+		if msg, err := mailIssueNotification(e, issue, opts.NotificationAuthorID, userID, reason); err != nil {
+			return err
+		} else if msg != nil {
+			mails = append(mails, msg)
+		}
+
+		if enabled, err := isWebNotificationEnabled(e, userID, reason); err != nil {
+			return err
+		} else if !enabled {
+			return nil
+		}
+
 		if notificationExists(notifications, issue.ID, userID) {
-			return updateIssueNotification(e, userID, issue.ID, notificationAuthorID)
+			return updateIssueNotification(e, userID, issue.ID, opts.NotificationAuthorID, reason)
+		}
+		return createIssueNotification(e, userID, issue, opts.NotificationAuthorID, reason)
+	}
+
+	canRead := func(userID int64) bool {
+		issue.Repo.Units = nil
+		if issue.IsPull {
+			return issue.Repo.checkUnitUser(e, userID, false, UnitTypePullRequests)
 		}
-		return createIssueNotification(e, userID, issue, notificationAuthorID)
+		return issue.Repo.checkUnitUser(e, userID, false, UnitTypeIssues)
+	}
+
+	for userID, reason := range opts.Receivers {
+		if !canRead(userID) {
+			continue
+		}
+
+		if err := notifyUser(userID, reason); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.OnlyReceivers {
+		return mails, nil
+	}
+
+	issueWatches, err := getIssueWatchers(e, issue.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	watches, err := getWatchers(e, issue.RepoID)
+	if err != nil {
+		return nil, err
+	}
+
+	watchReason := func(userID int64) NotificationReason {
+		if userID == issue.PosterID {
+			return NotificationReasonAuthor
+		}
+		return NotificationReasonSubscribed
 	}
 
 	for _, issueWatch := range issueWatches {
@@ -114,27 +239,94 @@ func createOrUpdateIssueNotifications(e Engine, issue *Issue, notificationAuthor
 			continue
 		}
 
-		if err := notifyUser(issueWatch.UserID); err != nil {
-			return err
+		if err := notifyUser(issueWatch.UserID, watchReason(issueWatch.UserID)); err != nil {
+			return nil, err
 		}
 	}
 
-	issue.loadRepo(e)
+	for _, watch := range watches {
+		if !canRead(watch.UserID) {
+			continue
+		}
+
+		if err := notifyUser(watch.UserID, watchReason(watch.UserID)); err != nil {
+			return nil, err
+		}
+	}
+	return mails, nil
+}
+
+// CreateReleaseNotifications creates a notification of a published release
+// for each watcher of its repository. No transaction is needed as the
+// notifications are inserted at once, the mails are sent afterwards.
+func CreateReleaseNotifications(rel *Release) error {
+	mails, err := createReleaseNotifications(x, rel)
+	if err != nil {
+		return err
+	}
+	for _, msg := range mails {
+		mailer.SendAsync(msg)
+	}
+	return nil
+}
+
+// createReleaseNotifications creates the notifications of a release and
+// returns the mails to send once they are committed
+func createReleaseNotifications(e Engine, rel *Release) (mails []*mailer.Message, err error) {
+	if rel.Repo == nil {
+		if rel.Repo, err = getRepositoryByID(e, rel.RepoID); err != nil {
+			return nil, err
+		}
+	}
+
+	watches, err := getWatchers(e, rel.RepoID)
+	if err != nil {
+		return nil, err
+	}
 
+	notifications := make([]*Notification, 0, len(watches))
 	for _, watch := range watches {
-		issue.Repo.Units = nil
-		if issue.IsPull && !issue.Repo.checkUnitUser(e, watch.UserID, false, UnitTypePullRequests) {
+		// do not send notification for the own publisher
+		if watch.UserID == rel.PublisherID {
 			continue
 		}
-		if !issue.IsPull && !issue.Repo.checkUnitUser(e, watch.UserID, false, UnitTypeIssues) {
+
+		rel.Repo.Units = nil
+		if !rel.Repo.checkUnitUser(e, watch.UserID, false, UnitTypeReleases) {
 			continue
 		}
 
-		if err := notifyUser(watch.UserID); err != nil {
-			return err
+		if msg, err := mailReleaseNotification(e, rel, watch.UserID); err != nil {
+			return nil, err
+		} else if msg != nil {
+			mails = append(mails, msg)
 		}
+
+		if enabled, err := isWebNotificationEnabled(e, watch.UserID, NotificationReasonRelease); err != nil {
+			return nil, err
+		} else if !enabled {
+			continue
+		}
+
+		notifications = append(notifications, &Notification{
+			UserID:    watch.UserID,
+			RepoID:    rel.RepoID,
+			Status:    NotificationStatusUnread,
+			Source:    NotificationSourceRelease,
+			Reason:    NotificationReasonRelease,
+			ReleaseID: rel.ID,
+			UpdatedBy: rel.PublisherID,
+		})
 	}
-	return nil
+
+	// the notifications are inserted at once, so that the table is not
+	// locked while the watchers are checked on SQLite
+	if len(notifications) > 0 {
+		if _, err = e.Insert(&notifications); err != nil {
+			return nil, err
+		}
+	}
+	return mails, nil
 }
 
 func getNotificationsByIssueID(e Engine, issueID int64) (notifications []*Notification, err error) {
@@ -154,11 +346,12 @@ func notificationExists(notifications []*Notification, issueID, userID int64) bo
 	return false
 }
 
-func createIssueNotification(e Engine, userID int64, issue *Issue, updatedByID int64) error {
+func createIssueNotification(e Engine, userID int64, issue *Issue, updatedByID int64, reason NotificationReason) error {
 	notification := &Notification{
 		UserID:    userID,
 		RepoID:    issue.RepoID,
 		Status:    NotificationStatusUnread,
+		Reason:    reason,
 		IssueID:   issue.ID,
 		UpdatedBy: updatedByID,
 	}
@@ -173,13 +366,14 @@ func createIssueNotification(e Engine, userID int64, issue *Issue, updatedByID i
 	return err
 }
 
-func updateIssueNotification(e Engine, userID, issueID, updatedByID int64) error {
+func updateIssueNotification(e Engine, userID, issueID, updatedByID int64, reason NotificationReason) error {
 	notification, err := getIssueNotification(e, userID, issueID)
 	if err != nil {
 		return err
 	}
 
 	notification.Status = NotificationStatusUnread
+	notification.Reason = reason
 	notification.UpdatedBy = updatedByID
 
 	_, err = e.ID(notification.ID).Update(notification)
@@ -218,6 +412,91 @@ func notificationsForUser(e Engine, user *User, statuses []NotificationStatus, p
 	return
 }
 
+// FindNotificationOptions represents the filters of the notifications, an
+// empty field does not filter anything
+type FindNotificationOptions struct {
+	UserID int64
+	RepoID int64
+	// RepoIDs restricts the notifications to these repositories, if not empty
+	RepoIDs           []int64
+	IssueID           int64
+	Status            []NotificationStatus
+	UpdatedAfterUnix  int64
+	UpdatedBeforeUnix int64
+}
+
+func (opts *FindNotificationOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.UserID != 0 {
+		cond = cond.And(builder.Eq{"notification.user_id": opts.UserID})
+	}
+	if opts.RepoID != 0 {
+		cond = cond.And(builder.Eq{"notification.repo_id": opts.RepoID})
+	}
+	if len(opts.RepoIDs) > 0 {
+		cond = cond.And(builder.In("notification.repo_id", opts.RepoIDs))
+	}
+	if opts.IssueID != 0 {
+		cond = cond.And(builder.Eq{"notification.issue_id": opts.IssueID})
+	}
+	if len(opts.Status) > 0 {
+		cond = cond.And(builder.In("notification.status", opts.Status))
+	}
+	if opts.UpdatedAfterUnix != 0 {
+		cond = cond.And(builder.Gte{"notification.updated_unix": opts.UpdatedAfterUnix})
+	}
+	if opts.UpdatedBeforeUnix != 0 {
+		cond = cond.And(builder.Lte{"notification.updated_unix": opts.UpdatedBeforeUnix})
+	}
+	return cond
+}
+
+// GetNotifications returns a page of the notifications matching the options,
+// the latest updated first
+func GetNotifications(opts FindNotificationOptions, page, pageSize int) (NotificationList, error) {
+	sess := x.
+		Where(opts.toConds()).
+		Desc("notification.updated_unix", "notification.id")
+	if page > 0 && pageSize > 0 {
+		sess.Limit(pageSize, (page-1)*pageSize)
+	}
+	notifications := make(NotificationList, 0, pageSize)
+	return notifications, sess.Find(&notifications)
+}
+
+// CountNotifications returns the number of the notifications matching the
+// options
+func CountNotifications(opts FindNotificationOptions) (int64, error) {
+	return x.Where(opts.toConds()).Count(&Notification{})
+}
+
+// SetNotificationsStatus changes the status of all the notifications matching
+// the options
+func SetNotificationsStatus(opts FindNotificationOptions, status NotificationStatus) error {
+	_, err := x.
+		Where(opts.toConds()).
+		Cols("status", "updated_unix").
+		Update(&Notification{Status: status})
+	return err
+}
+
+// UserIDCount is the number of something of a user
+type UserIDCount struct {
+	UserID int64
+	Count  int64
+}
+
+// GetUIDsAndNotificationCounts returns the number of unread notifications of
+// every user having a notification updated since the given time, inclusive,
+// until the given time, exclusive
+func GetUIDsAndNotificationCounts(since, until util.TimeStamp) ([]UserIDCount, error) {
+	counts := make([]UserIDCount, 0, 10)
+	return counts, x.SQL(`SELECT u.user_id, COUNT(n.id) AS count FROM `+
+		`(SELECT DISTINCT user_id FROM notification WHERE updated_unix >= ? AND updated_unix < ?) u `+
+		`LEFT JOIN notification n ON n.user_id = u.user_id AND n.status = ? `+
+		`GROUP BY u.user_id`, since, until, NotificationStatusUnread).Find(&counts)
+}
+
 // GetRepo returns the repo of the notification
 func (n *Notification) GetRepo() (*Repository, error) {
 	n.Repository = new(Repository)
@@ -227,6 +506,15 @@ func (n *Notification) GetRepo() (*Repository, error) {
 	return n.Repository, err
 }
 
+// GetRelease returns the release of the notification
+func (n *Notification) GetRelease() (*Release, error) {
+	n.Release = new(Release)
+	_, err := x.
+		Where("id = ?", n.ReleaseID).
+		Get(n.Release)
+	return n.Release, err
+}
+
 // GetIssue returns the issue of the notification
 func (n *Notification) GetIssue() (*Issue, error) {
 	n.Issue = new(Issue)
@@ -236,6 +524,122 @@ func (n *Notification) GetIssue() (*Issue, error) {
 	return n.Issue, err
 }
 
+// APIURL returns the absolute URL of the notification in the API
+func (n *Notification) APIURL() string {
+	return fmt.Sprintf("%sapi/v1/notifications/threads/%d", setting.AppURL, n.ID)
+}
+
+// APIFormat converts a Notification to api.NotificationThread. The
+// repository and the issue of the notification must be loaded.
+func (n *Notification) APIFormat() *api.NotificationThread {
+	thread := &api.NotificationThread{
+		ID:        n.ID,
+		Unread:    n.Status == NotificationStatusUnread,
+		Pinned:    n.Status == NotificationStatusPinned,
+		Reason:    n.Reason.String(),
+		UpdatedAt: n.UpdatedUnix.AsTime(),
+		URL:       n.APIURL(),
+	}
+	if n.Repository != nil {
+		thread.Repository = n.Repository.APIFormat(AccessModeRead)
+	}
+	if n.Issue != nil && n.Repository != nil {
+		n.Issue.Repo = n.Repository
+		thread.Subject = &api.NotificationSubject{
+			Title:   n.Issue.Title,
+			URL:     n.Issue.APIURL(),
+			HTMLURL: n.Issue.HTMLURL(),
+			Type:    "Issue",
+			State:   api.StateOpen,
+		}
+		if n.Issue.IsPull {
+			thread.Subject.URL = fmt.Sprintf("%s/pulls/%d", n.Repository.APIURL(), n.Issue.Index)
+			thread.Subject.Type = "Pull"
+		}
+		if n.Issue.IsClosed {
+			thread.Subject.State = api.StateClosed
+		}
+	}
+	if n.Release != nil && n.Repository != nil {
+		n.Release.Repo = n.Repository
+		thread.Subject = &api.NotificationSubject{
+			Title:   n.Release.Title,
+			URL:     n.Release.APIURL(),
+			HTMLURL: n.Release.HTMLURL(),
+			Type:    "Release",
+			State:   api.StateOpen,
+		}
+	}
+	return thread
+}
+
+// NotificationList contains a list of notifications
+type NotificationList []*Notification
+
+func (nl NotificationList) getRepoIDs() []int64 {
+	ids := make(map[int64]struct{}, len(nl))
+	for _, notification := range nl {
+		ids[notification.RepoID] = struct{}{}
+	}
+	return keysInt64(ids)
+}
+
+func (nl NotificationList) getIssueIDs() []int64 {
+	ids := make(map[int64]struct{}, len(nl))
+	for _, notification := range nl {
+		if notification.IssueID != 0 {
+			ids[notification.IssueID] = struct{}{}
+		}
+	}
+	return keysInt64(ids)
+}
+
+func (nl NotificationList) getReleaseIDs() []int64 {
+	ids := make(map[int64]struct{}, len(nl))
+	for _, notification := range nl {
+		if notification.ReleaseID != 0 {
+			ids[notification.ReleaseID] = struct{}{}
+		}
+	}
+	return keysInt64(ids)
+}
+
+// LoadAttributes loads the repositories, the issues and the releases of the
+// notifications
+func (nl NotificationList) LoadAttributes() error {
+	return nl.loadAttributes(x)
+}
+
+func (nl NotificationList) loadAttributes(e Engine) error {
+	if len(nl) == 0 {
+		return nil
+	}
+
+	repos := make(map[int64]*Repository, len(nl))
+	if err := e.In("id", nl.getRepoIDs()).Find(&repos); err != nil {
+		return fmt.Errorf("find repositories: %v", err)
+	}
+	issues := make(map[int64]*Issue, len(nl))
+	if issueIDs := nl.getIssueIDs(); len(issueIDs) > 0 {
+		if err := e.In("id", issueIDs).Find(&issues); err != nil {
+			return fmt.Errorf("find issues: %v", err)
+		}
+	}
+	releases := make(map[int64]*Release, len(nl))
+	if releaseIDs := nl.getReleaseIDs(); len(releaseIDs) > 0 {
+		if err := e.In("id", releaseIDs).Find(&releases); err != nil {
+			return fmt.Errorf("find releases: %v", err)
+		}
+	}
+
+	for _, notification := range nl {
+		notification.Repository = repos[notification.RepoID]
+		notification.Issue = issues[notification.IssueID]
+		notification.Release = releases[notification.ReleaseID]
+	}
+	return nil
+}
+
 // GetNotificationCount returns the notification count for user
 func GetNotificationCount(user *User, status NotificationStatus) (int64, error) {
 	return getNotificationCount(x, user, status)
@@ -268,7 +672,7 @@ func setNotificationStatusReadIfUnread(e Engine, userID, issueID int64) error {
 
 // SetNotificationStatus change the notification status
 func SetNotificationStatus(notificationID int64, user *User, status NotificationStatus) error {
-	notification, err := getNotificationByID(notificationID)
+	notification, err := GetNotificationByID(notificationID)
 	if err != nil {
 		return err
 	}
@@ -283,7 +687,8 @@ func SetNotificationStatus(notificationID int64, user *User, status Notification
 	return err
 }
 
-func getNotificationByID(notificationID int64) (*Notification, error) {
+// GetNotificationByID returns the notification by given ID
+func GetNotificationByID(notificationID int64) (*Notification, error) {
 	notification := new(Notification)
 	ok, err := x.
 		Where("id = ?", notificationID).
@@ -294,7 +699,7 @@ func getNotificationByID(notificationID int64) (*Notification, error) {
 	}
 
 	if !ok {
-		return nil, fmt.Errorf("Notification %d does not exists", notificationID)
+		return nil, ErrNotificationNotExist{notificationID}
 	}
 
 	return notification, nil
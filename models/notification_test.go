@@ -5,8 +5,12 @@
 package models
 
 import (
+	"html/template"
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -14,15 +18,109 @@ func TestCreateOrUpdateIssueNotifications(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
 
-	assert.NoError(t, CreateOrUpdateIssueNotifications(issue, 2))
+	assert.NoError(t, CreateOrUpdateIssueNotifications(IssueNotificationOptions{
+		Issue:                issue,
+		NotificationAuthorID: 2,
+	}))
 
 	// User 9 is inactive, thus notifications for user 1 and 4 are created
 	notf := AssertExistsAndLoadBean(t, &Notification{UserID: 1, IssueID: issue.ID}).(*Notification)
 	assert.Equal(t, NotificationStatusUnread, notf.Status)
+	assert.Equal(t, NotificationReasonAuthor, notf.Reason)
 	CheckConsistencyFor(t, &Issue{ID: issue.ID})
 
 	notf = AssertExistsAndLoadBean(t, &Notification{UserID: 4, IssueID: issue.ID}).(*Notification)
 	assert.Equal(t, NotificationStatusUnread, notf.Status)
+	assert.Equal(t, NotificationReasonSubscribed, notf.Reason)
+}
+
+func TestCreateOrUpdateIssueNotifications_Receivers(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	assert.NoError(t, CreateOrUpdateIssueNotifications(IssueNotificationOptions{
+		Issue:                issue,
+		NotificationAuthorID: 1,
+		Receivers:            map[int64]NotificationReason{2: NotificationReasonAssigned},
+		OnlyReceivers:        true,
+	}))
+
+	notf := AssertExistsAndLoadBean(t, &Notification{UserID: 2, IssueID: issue.ID}).(*Notification)
+	assert.Equal(t, NotificationStatusUnread, notf.Status)
+	assert.Equal(t, NotificationReasonAssigned, notf.Reason)
+
+	// the watchers are not notified
+	AssertNotExistsBean(t, &Notification{UserID: 4, IssueID: issue.ID})
+}
+
+func TestCreateOrUpdateIssueNotifications_WebDisabled(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	assert.NoError(t, UpdateUserNotificationSettings(4, []*UserNotificationSetting{
+		{Reason: NotificationReasonSubscribed, Web: false, Email: true},
+	}))
+	assert.NoError(t, CreateOrUpdateIssueNotifications(IssueNotificationOptions{
+		Issue:                issue,
+		NotificationAuthorID: 2,
+	}))
+
+	AssertExistsAndLoadBean(t, &Notification{UserID: 1, IssueID: issue.ID})
+	AssertNotExistsBean(t, &Notification{UserID: 4, IssueID: issue.ID})
+}
+
+func TestCreateReleaseNotifications(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	rel := &Release{
+		RepoID:      1,
+		PublisherID: 1,
+		TagName:     "v1.0",
+		Title:       "v1.0",
+	}
+	_, err := x.Insert(rel)
+	assert.NoError(t, err)
+
+	assert.NoError(t, CreateReleaseNotifications(rel))
+
+	// User 1 is the publisher and user 9 is inactive
+	notf := AssertExistsAndLoadBean(t, &Notification{UserID: 4, ReleaseID: rel.ID}).(*Notification)
+	assert.Equal(t, NotificationStatusUnread, notf.Status)
+	assert.Equal(t, NotificationSourceRelease, notf.Source)
+	assert.Equal(t, NotificationReasonRelease, notf.Reason)
+	AssertNotExistsBean(t, &Notification{UserID: 1, ReleaseID: rel.ID})
+
+	notifications := NotificationList{notf}
+	assert.NoError(t, notifications.LoadAttributes())
+	thread := notf.APIFormat()
+	assert.EqualValues(t, "release", thread.Reason)
+	assert.EqualValues(t, "Release", thread.Subject.Type)
+	assert.EqualValues(t, "v1.0", thread.Subject.Title)
+	assert.EqualValues(t, setting.AppURL+"user2/repo1/releases/tag/v1.0", thread.Subject.HTMLURL)
+}
+
+func TestMailIssueNotification(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+
+	defer func(enabled bool, mailService *setting.Mailer, tmpls *template.Template) {
+		setting.Service.EnableNotifyMail = enabled
+		setting.MailService = mailService
+		templates = tmpls
+	}(setting.Service.EnableNotifyMail, setting.MailService, templates)
+	setting.Service.EnableNotifyMail = true
+	setting.MailService = &setting.Mailer{}
+	InitMailRender(template.Must(template.New(string(mailNotifyNotification)).Parse("{{.Message}}")))
+
+	msg, err := mailIssueNotification(x, issue, 1, 2, NotificationReasonAssigned)
+	assert.NoError(t, err)
+	if assert.NotNil(t, msg) {
+		assert.EqualValues(t, []string{"user2@example.com"}, msg.GetHeader("To"))
+	}
+
+	// user 9 is inactive
+	msg, err = mailIssueNotification(x, issue, 1, 9, NotificationReasonAssigned)
+	assert.NoError(t, err)
+	assert.Nil(t, msg)
 }
 
 func TestNotificationsForUser(t *testing.T) {
@@ -99,3 +197,91 @@ func TestUpdateNotificationStatuses(t *testing.T) {
 	AssertExistsAndLoadBean(t,
 		&Notification{ID: notfPinned.ID, Status: NotificationStatusPinned})
 }
+
+func TestGetNotifications(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	notifications, err := GetNotifications(FindNotificationOptions{
+		UserID: 2,
+		Status: []NotificationStatus{NotificationStatusUnread, NotificationStatusPinned},
+	}, 1, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, notifications, 2) {
+		// the notifications updated at the same time are sorted by ID
+		assert.EqualValues(t, 4, notifications[0].ID)
+		assert.EqualValues(t, 3, notifications[1].ID)
+	}
+
+	notifications, err = GetNotifications(FindNotificationOptions{UserID: 2}, 2, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, notifications, 1) {
+		assert.EqualValues(t, 2, notifications[0].ID)
+	}
+
+	notifications, err = GetNotifications(FindNotificationOptions{
+		UserID:           2,
+		UpdatedAfterUnix: 946684801,
+	}, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, notifications, 0)
+
+	cnt, err := CountNotifications(FindNotificationOptions{UserID: 2, RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+}
+
+func TestSetNotificationsStatus(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, SetNotificationsStatus(FindNotificationOptions{
+		UserID: 2,
+		Status: []NotificationStatus{NotificationStatusUnread},
+	}, NotificationStatusRead))
+	AssertExistsAndLoadBean(t, &Notification{ID: 4, Status: NotificationStatusRead})
+	AssertExistsAndLoadBean(t, &Notification{ID: 3, Status: NotificationStatusPinned})
+	// the notifications of the other users are left alone
+	AssertExistsAndLoadBean(t, &Notification{ID: 1, Status: NotificationStatusUnread})
+}
+
+func TestGetNotificationByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	notf, err := GetNotificationByID(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, notf.UserID)
+
+	_, err = GetNotificationByID(NonexistentID)
+	assert.True(t, IsErrNotificationNotExist(err))
+}
+
+func TestNotificationList_LoadAttributes(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	notifications, err := GetNotifications(FindNotificationOptions{UserID: 2}, 1, 10)
+	assert.NoError(t, err)
+	assert.NoError(t, notifications.LoadAttributes())
+	for _, notf := range notifications {
+		assert.EqualValues(t, notf.RepoID, notf.Repository.ID)
+		assert.EqualValues(t, notf.IssueID, notf.Issue.ID)
+	}
+
+	thread := notifications[0].APIFormat()
+	assert.EqualValues(t, 4, thread.ID)
+	assert.True(t, thread.Unread)
+	assert.False(t, thread.Pinned)
+	assert.EqualValues(t, "user2/repo1", thread.Repository.FullName)
+	assert.EqualValues(t, "Pull", thread.Subject.Type)
+	assert.EqualValues(t, "issue2", thread.Subject.Title)
+	assert.EqualValues(t, setting.AppURL+"api/v1/repos/user2/repo1/pulls/2", thread.Subject.URL)
+	assert.EqualValues(t, setting.AppURL+"api/v1/notifications/threads/4", thread.URL)
+}
+
+func TestGetUIDsAndNotificationCounts(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	counts, err := GetUIDsAndNotificationCounts(946684800, 946684801)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []UserIDCount{{UserID: 1, Count: 1}, {UserID: 2, Count: 1}}, counts)
+
+	// the users without unread notifications are given too
+	since := util.TimeStampNow()
+	assert.NoError(t, SetNotificationStatus(4, &User{ID: 2}, NotificationStatusRead))
+	counts, err = GetUIDsAndNotificationCounts(since, util.TimeStampNow()+1)
+	assert.NoError(t, err)
+	assert.Equal(t, []UserIDCount{{UserID: 2, Count: 0}}, counts)
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 
 	"code.gitea.io/gitea/modules/auth/oauth2"
+	"code.gitea.io/gitea/modules/setting"
 )
 
 // OAuth2Provider describes the display values of a single OAuth2 provider
@@ -101,6 +102,11 @@ func InitOAuth2() error {
 	if err := oauth2.Init(x); err != nil {
 		return err
 	}
+	if setting.OAuth2.Enable {
+		if err := oauth2.InitSigningKey(setting.OAuth2.JWTSigningPrivateKeyFile); err != nil {
+			return err
+		}
+	}
 	loginSources, _ := GetActiveOAuth2ProviderLoginSources()
 
 	for _, source := range loginSources {
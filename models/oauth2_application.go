@@ -0,0 +1,517 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/auth/oauth2"
+	"code.gitea.io/gitea/modules/generate"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/Unknwon/com"
+	"github.com/dgrijalva/jwt-go"
+	gouuid "github.com/satori/go.uuid"
+)
+
+// OAuth2Application represents an OAuth2 client registered by a user or an organization
+type OAuth2Application struct {
+	ID    int64 `xorm:"pk autoincr"`
+	UID   int64 `xorm:"INDEX"`
+	Owner *User `xorm:"-"`
+
+	Name string
+
+	ClientID     string   `xorm:"UNIQUE"`
+	ClientSecret string   `xorm:"VARCHAR(64)"`
+	RedirectURIs []string `xorm:"redirect_uris JSON TEXT"`
+
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+}
+
+// TableName sets the table name to `oauth2_application`
+func (app *OAuth2Application) TableName() string {
+	return "oauth2_application"
+}
+
+// PrimaryRedirectURI returns the first redirect uri or an empty string if empty
+func (app *OAuth2Application) PrimaryRedirectURI() string {
+	if len(app.RedirectURIs) == 0 {
+		return ""
+	}
+	return app.RedirectURIs[0]
+}
+
+// LoadOwner loads the user or organization the application belongs to
+func (app *OAuth2Application) LoadOwner() (err error) {
+	if app.Owner == nil {
+		app.Owner, err = GetUserByID(app.UID)
+	}
+	return err
+}
+
+// ContainsRedirectURI checks if the redirect uri is registered for the application
+func (app *OAuth2Application) ContainsRedirectURI(redirectURI string) bool {
+	return com.IsSliceContainsStr(app.RedirectURIs, redirectURI)
+}
+
+func hashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateClientSecret generates a new client secret, stores its hash and returns the plain secret
+func (app *OAuth2Application) GenerateClientSecret() (string, error) {
+	secret, err := generate.GetRandomString(44)
+	if err != nil {
+		return "", err
+	}
+	app.ClientSecret = hashClientSecret(secret)
+	if _, err = x.ID(app.ID).Cols("client_secret").Update(app); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// ValidateClientSecret validates the given secret against the stored hash
+func (app *OAuth2Application) ValidateClientSecret(secret string) bool {
+	if len(app.ClientSecret) == 0 || len(secret) == 0 {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(app.ClientSecret), []byte(hashClientSecret(secret))) == 1
+}
+
+// GetGrantByUserID returns an OAuth2Grant by its user and application ID
+func (app *OAuth2Application) GetGrantByUserID(userID int64) (*OAuth2Grant, error) {
+	return app.getGrantByUserID(x, userID)
+}
+
+func (app *OAuth2Application) getGrantByUserID(e Engine, userID int64) (*OAuth2Grant, error) {
+	grant := new(OAuth2Grant)
+	has, err := e.Where("user_id = ? AND application_id = ?", userID, app.ID).Get(grant)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return grant, nil
+}
+
+// CreateGrant generates a grant for a user
+func (app *OAuth2Application) CreateGrant(userID int64, scope string) (*OAuth2Grant, error) {
+	grant := &OAuth2Grant{
+		ApplicationID: app.ID,
+		UserID:        userID,
+		Scope:         scope,
+		Counter:       1,
+	}
+	if _, err := x.Insert(grant); err != nil {
+		return nil, err
+	}
+	return grant, nil
+}
+
+// GetOAuth2ApplicationByClientID returns the oauth2 application with the given client_id.
+func GetOAuth2ApplicationByClientID(clientID string) (*OAuth2Application, error) {
+	app := new(OAuth2Application)
+	has, err := x.Where("client_id = ?", clientID).Get(app)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOAuthClientIDInvalid{ClientID: clientID}
+	}
+	return app, nil
+}
+
+// GetOAuth2ApplicationByID returns the oauth2 application with the given id.
+func GetOAuth2ApplicationByID(id int64) (*OAuth2Application, error) {
+	app := new(OAuth2Application)
+	has, err := x.ID(id).Get(app)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOAuthApplicationNotFound{ID: id}
+	}
+	return app, nil
+}
+
+// GetOAuth2ApplicationsByUserID returns all oauth2 applications owned by the user or organization
+func GetOAuth2ApplicationsByUserID(uid int64) ([]*OAuth2Application, error) {
+	apps := make([]*OAuth2Application, 0, 5)
+	return apps, x.Where("uid = ?", uid).Asc("id").Find(&apps)
+}
+
+// CreateOAuth2ApplicationOptions holds options to create an oauth2 application
+type CreateOAuth2ApplicationOptions struct {
+	Name         string
+	UID          int64
+	RedirectURIs []string
+}
+
+// CreateOAuth2Application inserts a new oauth application. The client secret
+// has to be generated afterwards.
+func CreateOAuth2Application(opts CreateOAuth2ApplicationOptions) (*OAuth2Application, error) {
+	app := &OAuth2Application{
+		UID:          opts.UID,
+		Name:         opts.Name,
+		ClientID:     gouuid.NewV4().String(),
+		RedirectURIs: opts.RedirectURIs,
+	}
+	if _, err := x.Insert(app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+// UpdateOAuth2ApplicationOptions holds options to update an oauth2 application
+type UpdateOAuth2ApplicationOptions struct {
+	ID           int64
+	Name         string
+	UID          int64
+	RedirectURIs []string
+}
+
+// UpdateOAuth2Application updates the name and the redirect uris of an oauth2 application
+func UpdateOAuth2Application(opts UpdateOAuth2ApplicationOptions) (*OAuth2Application, error) {
+	app, err := GetOAuth2ApplicationByID(opts.ID)
+	if err != nil {
+		return nil, err
+	} else if app.UID != opts.UID {
+		return nil, ErrOAuthApplicationNotFound{ID: opts.ID}
+	}
+
+	app.Name = opts.Name
+	app.RedirectURIs = opts.RedirectURIs
+	if _, err = x.ID(app.ID).Cols("name", "redirect_uris").Update(app); err != nil {
+		return nil, err
+	}
+	return app, nil
+}
+
+func deleteOAuth2Application(sess Engine, id, userID int64) error {
+	if deleted, err := sess.Delete(&OAuth2Application{ID: id, UID: userID}); err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrOAuthApplicationNotFound{ID: id}
+	}
+
+	grantIDs := make([]int64, 0, 10)
+	if err := sess.Table("oauth2_grant").Cols("id").Where("application_id = ?", id).Find(&grantIDs); err != nil {
+		return err
+	}
+	if len(grantIDs) > 0 {
+		if _, err := sess.In("grant_id", grantIDs).Delete(new(OAuth2AuthorizationCode)); err != nil {
+			return err
+		}
+	}
+	_, err := sess.Delete(&OAuth2Grant{ApplicationID: id})
+	return err
+}
+
+// DeleteOAuth2Application deletes the application with the given id and the grants and auth codes related to it.
+// It checks if the application belongs to the user or organization.
+func DeleteOAuth2Application(id, userID int64) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+	if err := deleteOAuth2Application(sess, id, userID); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func deleteOAuth2ApplicationsByUserID(e Engine, userID int64) error {
+	apps := make([]*OAuth2Application, 0, 5)
+	if err := e.Where("uid = ?", userID).Find(&apps); err != nil {
+		return err
+	}
+	for _, app := range apps {
+		if err := deleteOAuth2Application(e, app.ID, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//////////////////////////////////////////////////////
+
+// OAuth2AuthorizationCode is a code to obtain an access token in combination with the client secret once. It has a limited lifetime.
+type OAuth2AuthorizationCode struct {
+	ID                  int64        `xorm:"pk autoincr"`
+	Grant               *OAuth2Grant `xorm:"-"`
+	GrantID             int64
+	Code                string `xorm:"INDEX UNIQUE"`
+	CodeChallenge       string
+	CodeChallengeMethod string
+	RedirectURI         string
+	ValidUntil          util.TimeStamp `xorm:"INDEX"`
+}
+
+// TableName sets the table name to `oauth2_authorization_code`
+func (code *OAuth2AuthorizationCode) TableName() string {
+	return "oauth2_authorization_code"
+}
+
+// GenerateRedirectURI generates a redirect URI for a successful authorization request. State will be used if not empty.
+func (code *OAuth2AuthorizationCode) GenerateRedirectURI(state string) (*url.URL, error) {
+	redirect, err := url.Parse(code.RedirectURI)
+	if err != nil {
+		return nil, err
+	}
+	q := redirect.Query()
+	if state != "" {
+		q.Set("state", state)
+	}
+	q.Set("code", code.Code)
+	redirect.RawQuery = q.Encode()
+	return redirect, err
+}
+
+// Invalidate deletes the auth code from the database to invalidate this code
+func (code *OAuth2AuthorizationCode) Invalidate() error {
+	_, err := x.Delete(code)
+	return err
+}
+
+// ValidateCodeChallenge validates the given verifier against the saved code challenge. This is part of the PKCE implementation.
+func (code *OAuth2AuthorizationCode) ValidateCodeChallenge(verifier string) bool {
+	switch code.CodeChallengeMethod {
+	case "S256":
+		// base64url(SHA256(verifier)) see https://tools.ietf.org/html/rfc7636#section-4.6
+		h := sha256.Sum256([]byte(verifier))
+		hashedVerifier := base64.RawURLEncoding.EncodeToString(h[:])
+		return hashedVerifier == code.CodeChallenge
+	case "plain":
+		return verifier == code.CodeChallenge
+	case "":
+		return true
+	default:
+		// unsupported method -> return false
+		return false
+	}
+}
+
+// GetOAuth2AuthorizationByCode returns an authorization by its code, with its grant loaded
+func GetOAuth2AuthorizationByCode(code string) (*OAuth2AuthorizationCode, error) {
+	auth := new(OAuth2AuthorizationCode)
+	if has, err := x.Where("code = ?", code).Get(auth); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+
+	auth.Grant = new(OAuth2Grant)
+	if has, err := x.ID(auth.GrantID).Get(auth.Grant); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return auth, nil
+}
+
+//////////////////////////////////////////////////////
+
+// OAuth2Grant represents the permission of a user for a specific application to access resources
+type OAuth2Grant struct {
+	ID            int64              `xorm:"pk autoincr"`
+	UserID        int64              `xorm:"INDEX unique(user_application)"`
+	Application   *OAuth2Application `xorm:"-"`
+	ApplicationID int64              `xorm:"INDEX unique(user_application)"`
+	Counter       int64              `xorm:"NOT NULL DEFAULT 1"`
+	Scope         string             `xorm:"TEXT"`
+	Nonce         string             `xorm:"TEXT"`
+	CreatedUnix   util.TimeStamp     `xorm:"created"`
+	UpdatedUnix   util.TimeStamp     `xorm:"updated"`
+}
+
+// TableName sets the table name to `oauth2_grant`
+func (grant *OAuth2Grant) TableName() string {
+	return "oauth2_grant"
+}
+
+// GenerateNewAuthorizationCode generates a new authorization code for a grant and saves it to the database
+func (grant *OAuth2Grant) GenerateNewAuthorizationCode(redirectURI, codeChallenge, codeChallengeMethod string) (*OAuth2AuthorizationCode, error) {
+	secret, err := generate.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	code := &OAuth2AuthorizationCode{
+		Grant:               grant,
+		GrantID:             grant.ID,
+		RedirectURI:         redirectURI,
+		Code:                secret,
+		CodeChallenge:       codeChallenge,
+		CodeChallengeMethod: codeChallengeMethod,
+		ValidUntil:          util.TimeStampNow().AddDuration(10 * time.Minute),
+	}
+	if _, err = x.Insert(code); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// IncreaseCounter increases the counter of the grant, which invalidates all refresh tokens issued before
+func (grant *OAuth2Grant) IncreaseCounter() error {
+	if _, err := x.ID(grant.ID).Incr("counter").Update(new(OAuth2Grant)); err != nil {
+		return err
+	}
+	updatedGrant, err := GetOAuth2GrantByID(grant.ID)
+	if err != nil {
+		return err
+	}
+	grant.Counter = updatedGrant.Counter
+	return nil
+}
+
+// ScopeContains returns true if the grant scope contains the specified scope
+func (grant *OAuth2Grant) ScopeContains(scope string) bool {
+	return com.IsSliceContainsStr(strings.Fields(grant.Scope), scope)
+}
+
+// AccessTokenScope returns the scope of the API access granted to the application.
+// Unlike for access tokens, requesting no API scope grants no API access.
+func (grant *OAuth2Grant) AccessTokenScope() AccessTokenScope {
+	names := make([]string, 0, 5)
+	for _, name := range strings.Fields(grant.Scope) {
+		if _, err := ParseAccessTokenScope([]string{name}); err == nil {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return AccessTokenScopeNone
+	}
+	scope, _ := ParseAccessTokenScope(names)
+	return scope
+}
+
+// UpdateScope updates the scope of the grant
+func (grant *OAuth2Grant) UpdateScope(scope string) error {
+	grant.Scope = scope
+	_, err := x.ID(grant.ID).Cols("scope").Update(grant)
+	return err
+}
+
+// SetNonce updates the nonce of the grant, which is returned in the next ID token
+func (grant *OAuth2Grant) SetNonce(nonce string) error {
+	grant.Nonce = nonce
+	_, err := x.ID(grant.ID).Cols("nonce").Update(grant)
+	return err
+}
+
+// GetOAuth2GrantByID returns the grant with the given ID
+func GetOAuth2GrantByID(id int64) (*OAuth2Grant, error) {
+	grant := new(OAuth2Grant)
+	if has, err := x.ID(id).Get(grant); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return grant, nil
+}
+
+//////////////////////////////////////////////////////
+
+// OAuth2TokenType represents the type of token for an oauth application
+type OAuth2TokenType int
+
+const (
+	// TypeAccessToken is a token with short lifetime to access the api
+	TypeAccessToken OAuth2TokenType = iota
+	// TypeRefreshToken is token with long lifetime to refresh access tokens obtained by the client
+	TypeRefreshToken
+)
+
+// OAuth2Token represents a JWT token used to authenticate a client
+type OAuth2Token struct {
+	GrantID int64           `json:"gnt"`
+	Type    OAuth2TokenType `json:"tt"`
+	Counter int64           `json:"cnt,omitempty"`
+	jwt.StandardClaims
+}
+
+// ParseOAuth2Token parses a signed jwt string
+func ParseOAuth2Token(jwtToken string) (*OAuth2Token, error) {
+	token := new(OAuth2Token)
+	if err := oauth2.ParseToken(jwtToken, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// SignToken signs the token with the JWT signing key and returns it
+func (token *OAuth2Token) SignToken() (string, error) {
+	token.IssuedAt = time.Now().Unix()
+	return oauth2.SignToken(token)
+}
+
+// OIDCToken represents an OpenID Connect id_token
+type OIDCToken struct {
+	jwt.StandardClaims
+	Nonce string `json:"nonce,omitempty"`
+
+	// Scope profile
+	Name              string         `json:"name,omitempty"`
+	PreferredUsername string         `json:"preferred_username,omitempty"`
+	Profile           string         `json:"profile,omitempty"`
+	Picture           string         `json:"picture,omitempty"`
+	Website           string         `json:"website,omitempty"`
+	UpdatedAt         util.TimeStamp `json:"updated_at,omitempty"`
+
+	// Scope email
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified,omitempty"`
+}
+
+// SignToken signs an id_token with the JWT signing key
+func (token *OIDCToken) SignToken() (string, error) {
+	return oauth2.SignToken(token)
+}
+
+// GetOAuth2GrantByAccessToken validates an access token issued by the OAuth2
+// provider and returns the grant it has been issued for.
+func GetOAuth2GrantByAccessToken(jwtToken string) (*OAuth2Grant, error) {
+	token, err := ParseOAuth2Token(jwtToken)
+	if err != nil || token.Type != TypeAccessToken {
+		return nil, ErrAccessTokenNotExist{}
+	}
+
+	grant, err := GetOAuth2GrantByID(token.GrantID)
+	if err != nil {
+		return nil, err
+	} else if grant == nil {
+		return nil, ErrAccessTokenNotExist{}
+	}
+	return grant, nil
+}
+
+// GetOAuth2AccessToken validates an access token issued by the OAuth2 provider
+// and returns an access token holding the user and the scope of its grant.
+func GetOAuth2AccessToken(jwtToken string) (*AccessToken, error) {
+	grant, err := GetOAuth2GrantByAccessToken(jwtToken)
+	if err != nil {
+		return nil, err
+	}
+	return &AccessToken{
+		UID:   grant.UserID,
+		Name:  fmt.Sprintf("oauth2_grant_%d", grant.ID),
+		Scope: grant.AccessTokenScope(),
+	}, nil
+}
+
+// IsOAuth2Token returns true if the token looks like a JWT issued by the OAuth2 provider
+func IsOAuth2Token(token string) bool {
+	return setting.OAuth2.Enable && strings.Count(token, ".") == 2
+}
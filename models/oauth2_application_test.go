@@ -0,0 +1,268 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/auth/oauth2"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuth2Application_GenerateClientSecret(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
+	secret, err := app.GenerateClientSecret()
+	assert.NoError(t, err)
+	assert.True(t, len(secret) > 0)
+	assert.True(t, app.ValidateClientSecret(secret))
+	AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1, ClientSecret: app.ClientSecret})
+}
+
+func TestOAuth2Application_ValidateClientSecret(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
+	assert.True(t, app.ValidateClientSecret("4MK8Na6R55smdCY0WuCCumZ6hjRPnGY5saWVRHHjJiA="))
+	assert.False(t, app.ValidateClientSecret("fewijfowejgfiowjeoifew"))
+	assert.False(t, app.ValidateClientSecret(""))
+}
+
+func TestOAuth2Application_ContainsRedirectURI(t *testing.T) {
+	app := &OAuth2Application{
+		RedirectURIs: []string{"a", "b", "c"},
+	}
+	assert.True(t, app.ContainsRedirectURI("a"))
+	assert.True(t, app.ContainsRedirectURI("b"))
+	assert.True(t, app.ContainsRedirectURI("c"))
+	assert.False(t, app.ContainsRedirectURI("d"))
+}
+
+func TestGetOAuth2ApplicationByClientID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app, err := GetOAuth2ApplicationByClientID("da7da3ba-9a13-4167-856f-3899de0b0138")
+	assert.NoError(t, err)
+	assert.Equal(t, "da7da3ba-9a13-4167-856f-3899de0b0138", app.ClientID)
+
+	_, err = GetOAuth2ApplicationByClientID("invalid client id")
+	assert.True(t, IsErrOAuthClientIDInvalid(err))
+}
+
+func TestCreateOAuth2Application(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app, err := CreateOAuth2Application(CreateOAuth2ApplicationOptions{
+		Name:         "newapp",
+		UID:          3,
+		RedirectURIs: []string{"https://example.com/callback"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "newapp", app.Name)
+	assert.Len(t, app.ClientID, 36)
+	AssertExistsAndLoadBean(t, &OAuth2Application{Name: "newapp", UID: 3})
+
+	apps, err := GetOAuth2ApplicationsByUserID(3)
+	assert.NoError(t, err)
+	if assert.Len(t, apps, 1) {
+		assert.Equal(t, []string{"https://example.com/callback"}, apps[0].RedirectURIs)
+	}
+}
+
+func TestUpdateOAuth2Application(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app, err := UpdateOAuth2Application(UpdateOAuth2ApplicationOptions{
+		ID:           1,
+		Name:         "renamed",
+		UID:          1,
+		RedirectURIs: []string{"b"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "renamed", app.Name)
+	AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1, Name: "renamed"})
+
+	_, err = UpdateOAuth2Application(UpdateOAuth2ApplicationOptions{ID: 1, Name: "other", UID: 2})
+	assert.True(t, IsErrOAuthApplicationNotFound(err))
+}
+
+func TestDeleteOAuth2Application(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.True(t, IsErrOAuthApplicationNotFound(DeleteOAuth2Application(1, 2)))
+
+	assert.NoError(t, DeleteOAuth2Application(1, 1))
+	AssertNotExistsBean(t, &OAuth2Application{ID: 1})
+	AssertNotExistsBean(t, &OAuth2Grant{ApplicationID: 1})
+	AssertNotExistsBean(t, &OAuth2AuthorizationCode{GrantID: 1})
+}
+
+func TestOAuth2Application_GetGrantByUserID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
+	grant, err := app.GetGrantByUserID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), grant.UserID)
+
+	grant, err = app.GetGrantByUserID(34923458)
+	assert.NoError(t, err)
+	assert.Nil(t, grant)
+}
+
+func TestOAuth2Application_CreateGrant(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	app := AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
+	grant, err := app.CreateGrant(2, "repo:read")
+	assert.NoError(t, err)
+	assert.NotNil(t, grant)
+	assert.Equal(t, int64(2), grant.UserID)
+	assert.Equal(t, int64(1), grant.ApplicationID)
+	assert.Equal(t, int64(1), grant.Counter)
+	assert.Equal(t, "repo:read", grant.Scope)
+}
+
+func TestOAuth2Grant_GenerateNewAuthorizationCode(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	grant := AssertExistsAndLoadBean(t, &OAuth2Grant{ID: 1}).(*OAuth2Grant)
+	code, err := grant.GenerateNewAuthorizationCode("https://example.com/callback", "CjvyTLSdR47G5zYenDA-eDWW4lRrO8yvjcWwbD_deOg", "S256")
+	assert.NoError(t, err)
+	assert.NotNil(t, code)
+	assert.Len(t, code.Code, 32)
+
+	auth, err := GetOAuth2AuthorizationByCode(code.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, grant.ID, auth.Grant.ID)
+}
+
+func TestOAuth2Grant_IncreaseCounter(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	grant := AssertExistsAndLoadBean(t, &OAuth2Grant{ID: 1, Counter: 1}).(*OAuth2Grant)
+	assert.NoError(t, grant.IncreaseCounter())
+	assert.Equal(t, int64(2), grant.Counter)
+	AssertExistsAndLoadBean(t, &OAuth2Grant{ID: 1, Counter: 2})
+}
+
+func TestOAuth2Grant_ScopeContains(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	grant := AssertExistsAndLoadBean(t, &OAuth2Grant{ID: 1, Scope: "openid profile"}).(*OAuth2Grant)
+	assert.True(t, grant.ScopeContains("openid"))
+	assert.True(t, grant.ScopeContains("profile"))
+	assert.False(t, grant.ScopeContains("profil"))
+	assert.False(t, grant.ScopeContains("profile2"))
+}
+
+func TestOAuth2Grant_AccessTokenScope(t *testing.T) {
+	// an application which only asks for the identity of the user gets no
+	// API access
+	grant := &OAuth2Grant{Scope: "openid profile email"}
+	assert.Equal(t, AccessTokenScopeNone, grant.AccessTokenScope())
+	for _, scope := range append(AccessTokenScopes, AccessTokenScopeAll) {
+		assert.False(t, grant.AccessTokenScope().Has(scope), scope)
+	}
+
+	grant.Scope = "openid repo:read issue"
+	assert.Equal(t, AccessTokenScope("repo:read,issue"), grant.AccessTokenScope())
+	grant.Scope = "all"
+	assert.Equal(t, AccessTokenScopeAll, grant.AccessTokenScope())
+}
+
+func TestOAuth2AuthorizationCode_ValidateCodeChallenge(t *testing.T) {
+	// test plain
+	code := &OAuth2AuthorizationCode{
+		CodeChallengeMethod: "plain",
+		CodeChallenge:       "test123",
+	}
+	assert.True(t, code.ValidateCodeChallenge("test123"))
+	assert.False(t, code.ValidateCodeChallenge("ierwgjoergjio"))
+
+	// test S256
+	code = &OAuth2AuthorizationCode{
+		CodeChallengeMethod: "S256",
+		CodeChallenge:       "CjvyTLSdR47G5zYenDA-eDWW4lRrO8yvjcWwbD_deOg",
+	}
+	assert.True(t, code.ValidateCodeChallenge("N1Zo9-8Rfwhkt68r1r29ty8YwIraXR8eh_1Qwxg7yQXsonBt"))
+	assert.False(t, code.ValidateCodeChallenge("wiogjerogorewngoenrgoiuenorg"))
+
+	// test unknown
+	code = &OAuth2AuthorizationCode{
+		CodeChallengeMethod: "monkey",
+		CodeChallenge:       "foiwgjioriogeiogjerger",
+	}
+	assert.False(t, code.ValidateCodeChallenge("foiwgjioriogeiogjerger"))
+
+	// test no code challenge
+	code = &OAuth2AuthorizationCode{
+		CodeChallengeMethod: "",
+		CodeChallenge:       "foierjiogerogerg",
+	}
+	assert.True(t, code.ValidateCodeChallenge(""))
+}
+
+func TestOAuth2AuthorizationCode_GenerateRedirectURI(t *testing.T) {
+	code := &OAuth2AuthorizationCode{
+		RedirectURI: "https://example.com/callback",
+		Code:        "thecode",
+	}
+
+	redirect, err := code.GenerateRedirectURI("thestate")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/callback?code=thecode&state=thestate", redirect.String())
+
+	redirect, err = code.GenerateRedirectURI("")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/callback?code=thecode", redirect.String())
+}
+
+func TestOAuth2AuthorizationCode_Invalidate(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	code := AssertExistsAndLoadBean(t, &OAuth2AuthorizationCode{Code: "authcode"}).(*OAuth2AuthorizationCode)
+	assert.NoError(t, code.Invalidate())
+	AssertNotExistsBean(t, &OAuth2AuthorizationCode{Code: "authcode"})
+}
+
+func TestOAuth2Token(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	tmpDir, err := ioutil.TempDir("", "jwt")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, oauth2.InitSigningKey(filepath.Join(tmpDir, "jwt", "private.pem")))
+
+	token := &OAuth2Token{
+		GrantID: 1,
+		Type:    TypeAccessToken,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		},
+	}
+	signed, err := token.SignToken()
+	assert.NoError(t, err)
+
+	parsed, err := ParseOAuth2Token(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), parsed.GrantID)
+	assert.Equal(t, TypeAccessToken, parsed.Type)
+
+	accessToken, err := GetOAuth2AccessToken(signed)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), accessToken.UID)
+	assert.Equal(t, AccessTokenScopeNone, accessToken.Scope)
+
+	// refresh tokens cannot be used to access the API
+	token.Type = TypeRefreshToken
+	signed, err = token.SignToken()
+	assert.NoError(t, err)
+	_, err = GetOAuth2AccessToken(signed)
+	assert.True(t, IsErrAccessTokenNotExist(err))
+
+	// expired tokens are rejected
+	token.Type = TypeAccessToken
+	token.ExpiresAt = time.Now().Add(-time.Hour).Unix()
+	signed, err = token.SignToken()
+	assert.NoError(t, err)
+	_, err = ParseOAuth2Token(signed)
+	assert.Error(t, err)
+}
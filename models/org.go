@@ -11,8 +11,8 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/storage"
 
-	"github.com/Unknwon/com"
 	"github.com/go-xorm/builder"
 	"github.com/go-xorm/xorm"
 )
@@ -253,6 +253,14 @@ func deleteOrg(e *xorm.Session, u *User) error {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deleteOAuth2ApplicationsByUserID(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOAuth2ApplicationsByUserID: %v", err)
+	}
+
+	if err = deleteProjectsByCond(e, builder.Eq{"owner_id": u.ID, "type": ProjectTypeOrganization}); err != nil {
+		return fmt.Errorf("deleteProjectsByCond: %v", err)
+	}
+
 	if _, err = e.ID(u.ID).Delete(new(User)); err != nil {
 		return fmt.Errorf("Delete: %v", err)
 	}
@@ -267,11 +275,9 @@ func deleteOrg(e *xorm.Session, u *User) error {
 	}
 
 	if len(u.Avatar) > 0 {
-		avatarPath := u.CustomAvatarPath()
-		if com.IsExist(avatarPath) {
-			if err := os.Remove(avatarPath); err != nil {
-				return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
-			}
+		avatarPath := u.CustomAvatarRelativePath()
+		if err := storage.Avatars.Delete(avatarPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
 		}
 	}
 
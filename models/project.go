@@ -0,0 +1,454 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/go-xorm/builder"
+)
+
+// ProjectType is the kind of owner of a project
+type ProjectType uint8
+
+const (
+	// ProjectTypeRepository is a project that belongs to a repository
+	ProjectTypeRepository ProjectType = iota + 1
+	// ProjectTypeOrganization is a project that belongs to an organization
+	// and can hold issues of all its repositories
+	ProjectTypeOrganization
+)
+
+// ProjectBoardType is the set of boards a project is created with
+type ProjectBoardType uint8
+
+const (
+	// ProjectBoardTypeNone creates a project without any board
+	ProjectBoardTypeNone ProjectBoardType = iota
+	// ProjectBoardTypeBasicKanban creates a project with the basic kanban boards
+	ProjectBoardTypeBasicKanban
+	// ProjectBoardTypeBugTriage creates a project with the bug triage boards
+	ProjectBoardTypeBugTriage
+)
+
+// ProjectBoardTypes contains all the board types a project can be created with
+var ProjectBoardTypes = []ProjectBoardType{
+	ProjectBoardTypeNone,
+	ProjectBoardTypeBasicKanban,
+	ProjectBoardTypeBugTriage,
+}
+
+var projectBoardTypeNames = map[ProjectBoardType]string{
+	ProjectBoardTypeNone:        "none",
+	ProjectBoardTypeBasicKanban: "basic_kanban",
+	ProjectBoardTypeBugTriage:   "bug_triage",
+}
+
+// Name returns the name of the board type as used by the API
+func (t ProjectBoardType) Name() string {
+	return projectBoardTypeNames[t]
+}
+
+// NameKey returns the locale key of the board type
+func (t ProjectBoardType) NameKey() string {
+	return "repo.projects.type." + t.Name()
+}
+
+// BoardTitles returns the titles of the boards a project of this type is
+// created with
+func (t ProjectBoardType) BoardTitles() []string {
+	switch t {
+	case ProjectBoardTypeBasicKanban:
+		return setting.Project.ProjectBoardBasicKanbanType
+	case ProjectBoardTypeBugTriage:
+		return setting.Project.ProjectBoardBugTriageType
+	}
+	return nil
+}
+
+// IsValid returns true if the board type is known
+func (t ProjectBoardType) IsValid() bool {
+	_, ok := projectBoardTypeNames[t]
+	return ok
+}
+
+// ParseProjectBoardType returns the board type with the given name
+func ParseProjectBoardType(name string) (ProjectBoardType, bool) {
+	if len(name) == 0 {
+		return ProjectBoardTypeNone, true
+	}
+	for t, n := range projectBoardTypeNames {
+		if n == name {
+			return t, true
+		}
+	}
+	return ProjectBoardTypeNone, false
+}
+
+// Project represents a project board of a repository or an organization.
+type Project struct {
+	ID              int64       `xorm:"pk autoincr"`
+	Title           string      `xorm:"INDEX NOT NULL"`
+	Description     string      `xorm:"TEXT"`
+	RenderedContent string      `xorm:"-"`
+	RepoID          int64       `xorm:"INDEX"`
+	Repo            *Repository `xorm:"-"`
+	OwnerID         int64       `xorm:"INDEX"`
+	Owner           *User       `xorm:"-"`
+	CreatorID       int64       `xorm:"NOT NULL"`
+	Creator         *User       `xorm:"-"`
+	IsClosed        bool        `xorm:"INDEX"`
+	Type            ProjectType
+	BoardType       ProjectBoardType
+
+	// CloseBoardID is the board the cards of closed issues are moved to,
+	// they stay in place when it is 0.
+	CloseBoardID int64
+
+	NumIssues       int `xorm:"-"`
+	NumClosedIssues int `xorm:"-"`
+	NumOpenIssues   int `xorm:"-"`
+
+	CreatedUnix    util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix    util.TimeStamp `xorm:"INDEX updated"`
+	ClosedDateUnix util.TimeStamp
+}
+
+// IsRepositoryProject returns true if the project belongs to a repository
+func (p *Project) IsRepositoryProject() bool {
+	return p.Type == ProjectTypeRepository
+}
+
+// State returns string representation of project status.
+func (p *Project) State() api.StateType {
+	if p.IsClosed {
+		return api.StateClosed
+	}
+	return api.StateOpen
+}
+
+func (p *Project) loadAttributes(e Engine) (err error) {
+	if p.IsRepositoryProject() && p.Repo == nil {
+		if p.Repo, err = getRepositoryByID(e, p.RepoID); err != nil {
+			return fmt.Errorf("getRepositoryByID [%d]: %v", p.RepoID, err)
+		}
+	} else if !p.IsRepositoryProject() && p.Owner == nil {
+		if p.Owner, err = getUserByID(e, p.OwnerID); err != nil {
+			return fmt.Errorf("getUserByID [%d]: %v", p.OwnerID, err)
+		}
+	}
+	if p.Creator == nil {
+		if p.Creator, err = getUserByID(e, p.CreatorID); err != nil {
+			if !IsErrUserNotExist(err) {
+				return fmt.Errorf("getUserByID [%d]: %v", p.CreatorID, err)
+			}
+			p.Creator = NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// LoadAttributes loads the repository or organization and the creator of the project
+func (p *Project) LoadAttributes() error {
+	return p.loadAttributes(x)
+}
+
+// Link returns the relative URL of the project, the attributes of the project
+// must be loaded.
+func (p *Project) Link() string {
+	if p.IsRepositoryProject() {
+		return fmt.Sprintf("%s/projects/%d", p.Repo.Link(), p.ID)
+	}
+	return fmt.Sprintf("%s/org/%s/projects/%d", setting.AppSubURL, p.Owner.Name, p.ID)
+}
+
+// HTMLURL returns the absolute URL of the project, the attributes of the
+// project must be loaded.
+func (p *Project) HTMLURL() string {
+	if p.IsRepositoryProject() {
+		return fmt.Sprintf("%s/projects/%d", p.Repo.HTMLURL(), p.ID)
+	}
+	return fmt.Sprintf("%sorg/%s/projects/%d", setting.AppURL, p.Owner.Name, p.ID)
+}
+
+// APIFormat returns this Project in API format, the attributes of the project
+// must be loaded.
+func (p *Project) APIFormat() *api.Project {
+	apiProject := &api.Project{
+		ID:           p.ID,
+		Title:        p.Title,
+		Description:  p.Description,
+		State:        p.State(),
+		RepoID:       p.RepoID,
+		OwnerID:      p.OwnerID,
+		CloseBoardID: p.CloseBoardID,
+		OpenIssues:   p.NumOpenIssues,
+		ClosedIssues: p.NumClosedIssues,
+		Creator:      p.Creator.APIFormat(),
+		HTMLURL:      p.HTMLURL(),
+		Created:      p.CreatedUnix.AsTime(),
+		Updated:      p.UpdatedUnix.AsTime(),
+	}
+	if p.IsClosed {
+		apiProject.Closed = p.ClosedDateUnix.AsTimePtr()
+	}
+	return apiProject
+}
+
+// NewProject creates a new project and the boards of its board type.
+func NewProject(p *Project) (err error) {
+	if !p.BoardType.IsValid() {
+		return fmt.Errorf("invalid project board type: %d", p.BoardType)
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.Insert(p); err != nil {
+		return err
+	}
+
+	titles := p.BoardType.BoardTitles()
+	if len(titles) > 0 {
+		boards := make([]*ProjectBoard, 0, len(titles))
+		for i, title := range titles {
+			boards = append(boards, &ProjectBoard{
+				Title:     title,
+				Sorting:   i,
+				ProjectID: p.ID,
+				CreatorID: p.CreatorID,
+			})
+		}
+		if _, err = sess.Insert(&boards); err != nil {
+			return err
+		}
+	}
+	return sess.Commit()
+}
+
+func getProjectByID(e Engine, id int64) (*Project, error) {
+	p := new(Project)
+	has, err := e.ID(id).Get(p)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectNotExist{ID: id}
+	}
+	return p, nil
+}
+
+// GetProjectByID returns the project with the given id.
+func GetProjectByID(id int64) (*Project, error) {
+	return getProjectByID(x, id)
+}
+
+// ProjectSearchOptions are the options to search projects
+type ProjectSearchOptions struct {
+	RepoID   int64
+	OwnerID  int64
+	IsClosed util.OptionalBool
+	SortType string
+	Page     int
+	PageSize int
+}
+
+func (opts *ProjectSearchOptions) toCond() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID, "type": ProjectTypeRepository})
+	} else {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID, "type": ProjectTypeOrganization})
+	}
+	if !opts.IsClosed.IsNone() {
+		cond = cond.And(builder.Eq{"is_closed": opts.IsClosed.IsTrue()})
+	}
+	return cond
+}
+
+// ProjectList is a list of projects offering additional functionality
+type ProjectList []*Project
+
+func (projects ProjectList) getProjectIDs() []int64 {
+	ids := make([]int64, 0, len(projects))
+	for _, p := range projects {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func (projects ProjectList) loadNumIssues(e Engine) error {
+	type numIssuesByProject struct {
+		ProjectID int64
+		IsClosed  bool
+		Num       int
+	}
+	if len(projects) == 0 {
+		return nil
+	}
+
+	rows, err := e.Table("project_issue").
+		Join("INNER", "issue", "issue.id = project_issue.issue_id").
+		Select("project_issue.project_id, issue.is_closed, count(*) as num").
+		In("project_issue.project_id", projects.getProjectIDs()).
+		GroupBy("project_issue.project_id, issue.is_closed").
+		Rows(new(numIssuesByProject))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	open := make(map[int64]int, len(projects))
+	closed := make(map[int64]int, len(projects))
+	for rows.Next() {
+		var n numIssuesByProject
+		if err = rows.Scan(&n); err != nil {
+			return err
+		}
+		if n.IsClosed {
+			closed[n.ProjectID] = n.Num
+		} else {
+			open[n.ProjectID] = n.Num
+		}
+	}
+
+	for _, p := range projects {
+		p.NumOpenIssues = open[p.ID]
+		p.NumClosedIssues = closed[p.ID]
+		p.NumIssues = p.NumOpenIssues + p.NumClosedIssues
+	}
+	return nil
+}
+
+// LoadNumIssues loads the number of open and closed issues of every project
+// in the list by a batch request
+func (projects ProjectList) LoadNumIssues() error {
+	return projects.loadNumIssues(x)
+}
+
+// LoadAttributes loads the attributes of every project in the list
+func (projects ProjectList) LoadAttributes() error {
+	for _, p := range projects {
+		if err := p.loadAttributes(x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetProjects returns a list of projects of a repository or an organization
+// and the total number of projects matching the options.
+func GetProjects(opts ProjectSearchOptions) (ProjectList, int64, error) {
+	cond := opts.toCond()
+	count, err := x.Where(cond).Count(new(Project))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := x.Where(cond)
+	if opts.Page > 0 {
+		pageSize := opts.PageSize
+		if pageSize <= 0 {
+			pageSize = setting.UI.IssuePagingNum
+		}
+		sess = sess.Limit(pageSize, (opts.Page-1)*pageSize)
+	}
+
+	switch opts.SortType {
+	case "oldest":
+		sess.Asc("created_unix")
+	case "recentupdate":
+		sess.Desc("updated_unix")
+	case "leastupdate":
+		sess.Asc("updated_unix")
+	default:
+		sess.Desc("created_unix")
+	}
+
+	projects := make(ProjectList, 0, setting.UI.IssuePagingNum)
+	if err = sess.Find(&projects); err != nil {
+		return nil, 0, err
+	}
+	return projects, count, projects.loadNumIssues(x)
+}
+
+// ProjectStats returns the number of open and closed projects matching the
+// options.
+func ProjectStats(opts ProjectSearchOptions) (open int64, closed int64, err error) {
+	opts.IsClosed = util.OptionalBoolFalse
+	if open, err = x.Where(opts.toCond()).Count(new(Project)); err != nil {
+		return 0, 0, err
+	}
+	opts.IsClosed = util.OptionalBoolTrue
+	closed, err = x.Where(opts.toCond()).Count(new(Project))
+	return open, closed, err
+}
+
+// UpdateProject updates the title, description and close board of a project.
+func UpdateProject(p *Project) error {
+	if p.CloseBoardID > 0 {
+		if _, err := GetProjectBoard(p.ID, p.CloseBoardID); err != nil {
+			return err
+		}
+	}
+	_, err := x.ID(p.ID).Cols("title", "description", "close_board_id").Update(p)
+	return err
+}
+
+// ChangeProjectStatus changes the project open/closed status.
+func ChangeProjectStatus(p *Project, isClosed bool) error {
+	p.IsClosed = isClosed
+	if isClosed {
+		p.ClosedDateUnix = util.TimeStampNow()
+	} else {
+		p.ClosedDateUnix = 0
+	}
+	_, err := x.ID(p.ID).Cols("is_closed", "closed_date_unix").Update(p)
+	return err
+}
+
+func deleteProjectByID(e Engine, id int64) error {
+	if _, err := e.ID(id).Delete(new(Project)); err != nil {
+		return err
+	}
+	return deleteBeans(e,
+		&ProjectBoard{ProjectID: id},
+		&ProjectIssue{ProjectID: id},
+	)
+}
+
+// DeleteProjectByID deletes a project with its boards, the issues on the
+// project are kept.
+func DeleteProjectByID(id int64) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = deleteProjectByID(sess, id); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+func deleteProjectsByCond(e Engine, cond builder.Cond) error {
+	projectIDs := make([]int64, 0, 10)
+	if err := e.Table("project").Where(cond).Cols("id").Find(&projectIDs); err != nil {
+		return err
+	}
+	for _, id := range projectIDs {
+		if err := deleteProjectByID(e, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
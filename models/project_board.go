@@ -0,0 +1,136 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"code.gitea.io/gitea/modules/util"
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/go-xorm/builder"
+)
+
+// ProjectBoard is a column of a project that holds the cards of its issues.
+// The board with ID 0 is the pseudo board of the issues that have not been
+// sorted into any column yet.
+type ProjectBoard struct {
+	ID        int64  `xorm:"pk autoincr"`
+	Title     string `xorm:"NOT NULL"`
+	Sorting   int    `xorm:"NOT NULL DEFAULT 0"`
+	ProjectID int64  `xorm:"INDEX NOT NULL"`
+	CreatorID int64  `xorm:"NOT NULL"`
+
+	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
+
+	Issues []*Issue `xorm:"-"`
+}
+
+// APIFormat returns this board in API format
+func (b *ProjectBoard) APIFormat() *api.ProjectBoard {
+	return &api.ProjectBoard{
+		ID:        b.ID,
+		Title:     b.Title,
+		Sorting:   b.Sorting,
+		ProjectID: b.ProjectID,
+		Created:   b.CreatedUnix.AsTime(),
+		Updated:   b.UpdatedUnix.AsTime(),
+	}
+}
+
+func (b *ProjectBoard) loadIssues(e Engine) error {
+	issueIDs := make([]int64, 0, 10)
+	if err := e.Table("project_issue").
+		Where(builder.Eq{"project_id": b.ProjectID, "project_board_id": b.ID}).
+		Asc("sorting", "id").
+		Cols("issue_id").
+		Find(&issueIDs); err != nil {
+		return err
+	}
+
+	issues, err := getIssuesByIDs(e, issueIDs)
+	if err != nil {
+		return err
+	}
+	issuesMap := make(map[int64]*Issue, len(issues))
+	for _, issue := range issues {
+		issuesMap[issue.ID] = issue
+	}
+
+	b.Issues = make([]*Issue, 0, len(issues))
+	for _, id := range issueIDs {
+		if issue, ok := issuesMap[id]; ok {
+			b.Issues = append(b.Issues, issue)
+		}
+	}
+	return IssueList(b.Issues).loadAttributes(e)
+}
+
+// LoadIssues loads the issues on the board in the order of their cards
+func (b *ProjectBoard) LoadIssues() error {
+	return b.loadIssues(x)
+}
+
+// NewProjectBoard adds a board to a project
+func NewProjectBoard(b *ProjectBoard) error {
+	_, err := x.Insert(b)
+	return err
+}
+
+// GetProjectBoard returns the board of a project with the given id
+func GetProjectBoard(projectID, id int64) (*ProjectBoard, error) {
+	b := &ProjectBoard{ID: id, ProjectID: projectID}
+	has, err := x.Get(b)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrProjectBoardNotExist{ID: id, ProjectID: projectID}
+	}
+	return b, nil
+}
+
+// GetProjectBoards returns the boards of a project in their display order
+func GetProjectBoards(projectID int64) ([]*ProjectBoard, error) {
+	boards := make([]*ProjectBoard, 0, 5)
+	return boards, x.Where("project_id = ?", projectID).Asc("sorting", "id").Find(&boards)
+}
+
+// UpdateProjectBoard updates the title and the position of a board
+func UpdateProjectBoard(b *ProjectBoard) error {
+	_, err := x.ID(b.ID).Cols("title", "sorting").Update(b)
+	return err
+}
+
+// DeleteProjectBoardByID deletes a board of a project, its cards are moved
+// to the uncategorized board.
+func DeleteProjectBoardByID(projectID, id int64) (err error) {
+	b, err := GetProjectBoard(projectID, id)
+	if err != nil {
+		if IsErrProjectBoardNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if _, err = sess.ID(b.ID).Delete(new(ProjectBoard)); err != nil {
+		return err
+	}
+	if _, err = sess.Where("project_board_id = ?", b.ID).
+		Cols("project_board_id").
+		Update(&ProjectIssue{ProjectBoardID: 0}); err != nil {
+		return err
+	}
+	if _, err = sess.Where("id = ? AND close_board_id = ?", projectID, b.ID).
+		Cols("close_board_id").
+		Update(&Project{CloseBoardID: 0}); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
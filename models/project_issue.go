@@ -0,0 +1,241 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+
+	"github.com/go-xorm/builder"
+	"github.com/go-xorm/xorm"
+)
+
+// ProjectIssue is the card of an issue or a pull request on a project board,
+// an issue can be on a single project at a time.
+type ProjectIssue struct {
+	ID             int64 `xorm:"pk autoincr"`
+	IssueID        int64 `xorm:"UNIQUE NOT NULL"`
+	ProjectID      int64 `xorm:"INDEX NOT NULL"`
+	ProjectBoardID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Sorting        int   `xorm:"NOT NULL DEFAULT 0"`
+}
+
+func getProjectIssue(e Engine, issueID int64) (*ProjectIssue, error) {
+	pi := &ProjectIssue{IssueID: issueID}
+	has, err := e.Get(pi)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return pi, nil
+}
+
+func (issue *Issue) loadProject(e Engine) error {
+	if issue.Project != nil {
+		return nil
+	}
+	pi, err := getProjectIssue(e, issue.ID)
+	if err != nil || pi == nil {
+		return err
+	}
+	issue.Project, err = getProjectByID(e, pi.ProjectID)
+	if err != nil {
+		if IsErrProjectNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return issue.Project.loadAttributes(e)
+}
+
+// LoadProject loads the project the issue is on, it is nil if the issue is
+// not on any project.
+func (issue *Issue) LoadProject() error {
+	return issue.loadProject(x)
+}
+
+// canBeOnProject returns true if the issue can be put on the given project,
+// that is the project belongs to the repository of the issue or to its owner.
+func (issue *Issue) canBeOnProject(p *Project) bool {
+	if p.IsRepositoryProject() {
+		return p.RepoID == issue.RepoID
+	}
+	return p.OwnerID == issue.Repo.OwnerID
+}
+
+func nextProjectIssueSorting(e Engine, projectID, boardID int64) (int, error) {
+	last := new(ProjectIssue)
+	has, err := e.Where(builder.Eq{"project_id": projectID, "project_board_id": boardID}).
+		Desc("sorting").
+		Get(last)
+	if err != nil || !has {
+		return 0, err
+	}
+	return last.Sorting + 1, nil
+}
+
+func changeProjectAssign(e *xorm.Session, doer *User, issue *Issue, projectID int64) error {
+	if err := issue.loadRepo(e); err != nil {
+		return err
+	}
+
+	pi, err := getProjectIssue(e, issue.ID)
+	if err != nil {
+		return err
+	}
+	var oldProjectID int64
+	if pi != nil {
+		oldProjectID = pi.ProjectID
+	}
+	if oldProjectID == projectID {
+		return nil
+	}
+
+	if projectID > 0 {
+		p, err := getProjectByID(e, projectID)
+		if err != nil {
+			return err
+		}
+		if !issue.canBeOnProject(p) {
+			return ErrProjectNotExist{ID: projectID, RepoID: issue.RepoID}
+		}
+	}
+
+	if pi != nil {
+		if _, err = e.ID(pi.ID).Delete(new(ProjectIssue)); err != nil {
+			return err
+		}
+	}
+
+	if projectID > 0 {
+		sorting, err := nextProjectIssueSorting(e, projectID, 0)
+		if err != nil {
+			return err
+		}
+		if _, err = e.Insert(&ProjectIssue{
+			IssueID:   issue.ID,
+			ProjectID: projectID,
+			Sorting:   sorting,
+		}); err != nil {
+			return err
+		}
+	}
+
+	issue.Project = nil
+	_, err = createProjectComment(e, doer, issue.Repo, issue, oldProjectID, projectID)
+	return err
+}
+
+// ChangeProjectAssign puts the issue on the given project, it is removed from
+// its current project if projectID is 0.
+func ChangeProjectAssign(issue *Issue, doer *User, projectID int64) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = changeProjectAssign(sess, doer, issue, projectID); err != nil {
+		return err
+	}
+
+	if err = sess.Commit(); err != nil {
+		return fmt.Errorf("Commit: %v", err)
+	}
+	return nil
+}
+
+func moveIssuesOnProjectBoard(e Engine, board *ProjectBoard, issueIDs []int64) error {
+	for sorting, issueID := range issueIDs {
+		if _, err := e.Where("project_id = ? AND issue_id = ?", board.ProjectID, issueID).
+			Cols("project_board_id", "sorting").
+			Update(&ProjectIssue{ProjectBoardID: board.ID, Sorting: sorting}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MoveIssuesOnProjectBoard moves the cards of the given issues to the board
+// in the order of issueIDs. Issues that are not on the project of the board
+// are ignored.
+func MoveIssuesOnProjectBoard(board *ProjectBoard, issueIDs []int64) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	if err = moveIssuesOnProjectBoard(sess, board, issueIDs); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// MoveIssueOnProjectBoard moves the card of an issue on the project to the
+// given position of the board, it is appended to the board if position is
+// negative or past the last card.
+func MoveIssueOnProjectBoard(board *ProjectBoard, issueID int64, position int) (err error) {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return err
+	}
+
+	issueIDs := make([]int64, 0, 10)
+	if err = sess.Table("project_issue").
+		Where(builder.Eq{"project_id": board.ProjectID, "project_board_id": board.ID}).
+		And(builder.Neq{"issue_id": issueID}).
+		Asc("sorting", "id").
+		Cols("issue_id").
+		Find(&issueIDs); err != nil {
+		return err
+	}
+
+	if position < 0 || position > len(issueIDs) {
+		position = len(issueIDs)
+	}
+	issueIDs = append(issueIDs, 0)
+	copy(issueIDs[position+1:], issueIDs[position:])
+	issueIDs[position] = issueID
+
+	if err = moveIssuesOnProjectBoard(sess, board, issueIDs); err != nil {
+		return err
+	}
+	return sess.Commit()
+}
+
+// moveClosedIssueCard moves the card of a closed issue to the close board of
+// its project if the project has one.
+func moveClosedIssueCard(e Engine, issue *Issue) error {
+	pi, err := getProjectIssue(e, issue.ID)
+	if err != nil || pi == nil {
+		return err
+	}
+
+	p, err := getProjectByID(e, pi.ProjectID)
+	if err != nil {
+		return err
+	}
+	if p.CloseBoardID == 0 || p.CloseBoardID == pi.ProjectBoardID {
+		return nil
+	}
+
+	pi.ProjectBoardID = p.CloseBoardID
+	if pi.Sorting, err = nextProjectIssueSorting(e, p.ID, p.CloseBoardID); err != nil {
+		return err
+	}
+	_, err = e.ID(pi.ID).Cols("project_board_id", "sorting").Update(pi)
+	return err
+}
+
+// removeRepoIssuesFromOrgProjects removes the cards of the issues of a
+// repository from the projects of its organization
+func removeRepoIssuesFromOrgProjects(e Engine, repoID int64) error {
+	_, err := e.In("issue_id", builder.Select("id").From("issue").Where(builder.Eq{"repo_id": repoID})).
+		And(builder.In("project_id", builder.Select("id").From("project").Where(builder.Eq{"type": ProjectTypeOrganization}))).
+		Delete(new(ProjectIssue))
+	return err
+}
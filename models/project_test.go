@@ -0,0 +1,190 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProject(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	p := &Project{
+		Title:     "New project",
+		RepoID:    1,
+		CreatorID: 2,
+		Type:      ProjectTypeRepository,
+		BoardType: ProjectBoardTypeBugTriage,
+	}
+	assert.NoError(t, NewProject(p))
+	AssertExistsAndLoadBean(t, &Project{ID: p.ID, Title: "New project"})
+
+	boards, err := GetProjectBoards(p.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, boards, len(setting.Project.ProjectBoardBugTriageType)) {
+		for i, b := range boards {
+			assert.Equal(t, setting.Project.ProjectBoardBugTriageType[i], b.Title)
+			assert.Equal(t, i, b.Sorting)
+		}
+	}
+
+	assert.Error(t, NewProject(&Project{Title: "invalid", RepoID: 1, Type: ProjectTypeRepository, BoardType: 42}))
+}
+
+func TestGetProjectByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	p, err := GetProjectByID(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "First project", p.Title)
+	assert.NoError(t, p.LoadAttributes())
+	assert.Equal(t, p.Repo.Link()+"/projects/1", p.Link())
+
+	p, err = GetProjectByID(3)
+	assert.NoError(t, err)
+	assert.NoError(t, p.LoadAttributes())
+	assert.Equal(t, setting.AppSubURL+"/org/user3/projects/3", p.Link())
+
+	_, err = GetProjectByID(NonexistentID)
+	assert.True(t, IsErrProjectNotExist(err))
+}
+
+func TestGetProjects(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	projects, count, err := GetProjects(ProjectSearchOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Len(t, projects, 2)
+
+	projects, count, err = GetProjects(ProjectSearchOptions{RepoID: 1, IsClosed: util.OptionalBoolFalse})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, projects, 1) {
+		assert.EqualValues(t, 1, projects[0].ID)
+		assert.Equal(t, 2, projects[0].NumIssues)
+		assert.Equal(t, 2, projects[0].NumOpenIssues)
+	}
+
+	projects, _, err = GetProjects(ProjectSearchOptions{OwnerID: 3})
+	assert.NoError(t, err)
+	if assert.Len(t, projects, 1) {
+		assert.EqualValues(t, 3, projects[0].ID)
+	}
+
+	open, closed, err := ProjectStats(ProjectSearchOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, open)
+	assert.EqualValues(t, 1, closed)
+}
+
+func TestDeleteProjectByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, DeleteProjectByID(1))
+	AssertNotExistsBean(t, &Project{ID: 1})
+	AssertNotExistsBean(t, &ProjectBoard{ProjectID: 1})
+	AssertNotExistsBean(t, &ProjectIssue{ProjectID: 1})
+	AssertExistsAndLoadBean(t, &Issue{ID: 1})
+}
+
+func TestDeleteProjectBoardByID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	assert.NoError(t, DeleteProjectBoardByID(1, 3))
+	AssertNotExistsBean(t, &ProjectBoard{ID: 3})
+	p := AssertExistsAndLoadBean(t, &Project{ID: 1}).(*Project)
+	assert.EqualValues(t, 0, p.CloseBoardID)
+
+	assert.NoError(t, DeleteProjectBoardByID(1, 1))
+	pi := AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1}).(*ProjectIssue)
+	assert.EqualValues(t, 0, pi.ProjectBoardID)
+
+	// boards of other projects are not deleted
+	assert.NoError(t, DeleteProjectBoardByID(1, 4))
+	AssertExistsAndLoadBean(t, &ProjectBoard{ID: 4})
+}
+
+func TestProjectBoard_LoadIssues(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	b := &ProjectBoard{ID: 0, ProjectID: 1}
+	assert.NoError(t, b.LoadIssues())
+	if assert.Len(t, b.Issues, 1) {
+		assert.EqualValues(t, 2, b.Issues[0].ID)
+		assert.NotNil(t, b.Issues[0].Repo)
+	}
+
+	b = AssertExistsAndLoadBean(t, &ProjectBoard{ID: 1}).(*ProjectBoard)
+	assert.NoError(t, b.LoadIssues())
+	if assert.Len(t, b.Issues, 1) {
+		assert.EqualValues(t, 1, b.Issues[0].ID)
+	}
+}
+
+func TestChangeProjectAssign(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	assert.NoError(t, ChangeProjectAssign(issue, doer, 1))
+	pi := AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 3, ProjectID: 1}).(*ProjectIssue)
+	assert.EqualValues(t, 0, pi.ProjectBoardID)
+	assert.EqualValues(t, 1, pi.Sorting)
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 3, Type: CommentTypeProject, ProjectID: 1})
+
+	assert.NoError(t, issue.LoadProject())
+	if assert.NotNil(t, issue.Project) {
+		assert.EqualValues(t, 1, issue.Project.ID)
+	}
+
+	// issues of repo1 cannot be put on the projects of another owner
+	assert.True(t, IsErrProjectNotExist(ChangeProjectAssign(issue, doer, 3)))
+
+	assert.NoError(t, ChangeProjectAssign(issue, doer, 0))
+	AssertNotExistsBean(t, &ProjectIssue{IssueID: 3})
+	AssertExistsAndLoadBean(t, &Comment{IssueID: 3, Type: CommentTypeProject, OldProjectID: 1, ProjectID: 0})
+
+	// issues of a repository of an organization can be put on its projects
+	issue = AssertExistsAndLoadBean(t, &Issue{ID: 6}).(*Issue)
+	assert.NoError(t, ChangeProjectAssign(issue, doer, 0))
+	assert.NoError(t, ChangeProjectAssign(issue, doer, 3))
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 6, ProjectID: 3})
+}
+
+func TestMoveIssueOnProjectBoard(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	board := AssertExistsAndLoadBean(t, &ProjectBoard{ID: 1}).(*ProjectBoard)
+	assert.NoError(t, MoveIssueOnProjectBoard(board, 2, 0))
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 2, ProjectBoardID: 1, Sorting: 0})
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 1, Sorting: 1})
+
+	board = AssertExistsAndLoadBean(t, &ProjectBoard{ID: 2}).(*ProjectBoard)
+	assert.NoError(t, MoveIssuesOnProjectBoard(board, []int64{1, 2, 6}))
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 2, Sorting: 0})
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 2, ProjectBoardID: 2, Sorting: 1})
+	// issue 6 is on another project
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 6, ProjectID: 3, ProjectBoardID: 4})
+}
+
+func TestCloseIssueMovesProjectCard(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 1}).(*Issue)
+	assert.NoError(t, issue.LoadAttributes())
+	assert.NoError(t, issue.ChangeStatus(doer, issue.Repo, true))
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 3})
+
+	// the card stays on the close board when the issue is reopened
+	assert.NoError(t, issue.ChangeStatus(doer, issue.Repo, false))
+	AssertExistsAndLoadBean(t, &ProjectIssue{IssueID: 1, ProjectBoardID: 3})
+}
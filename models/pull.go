@@ -28,7 +28,7 @@ import (
 	"github.com/go-xorm/xorm"
 )
 
-var pullRequestQueue = sync.NewUniqueQueue(setting.Repository.PullRequestQueueLength)
+var pullRequestQueue = sync.NewWorkQueue("pr_patch_checker")
 
 // PullRequestType defines pull request type
 type PullRequestType int
@@ -74,6 +74,8 @@ type PullRequest struct {
 	MergerID       int64          `xorm:"INDEX"`
 	Merger         *User          `xorm:"-"`
 	MergedUnix     util.TimeStamp `xorm:"updated INDEX"`
+
+	ProtectedBranch *ProtectedBranch `xorm:"-"`
 }
 
 // Note: don't try to get Issue because will end up recursive querying.
@@ -252,6 +254,33 @@ func (pr *PullRequest) GetBaseRepo() (err error) {
 	return nil
 }
 
+// LoadProtectedBranch loads the protected branch of the base branch
+func (pr *PullRequest) LoadProtectedBranch() (err error) {
+	if pr.ProtectedBranch != nil {
+		return nil
+	}
+
+	if err = pr.GetBaseRepo(); err != nil {
+		return err
+	}
+
+	pr.ProtectedBranch, err = GetProtectedBranchBy(pr.BaseRepo.ID, pr.BaseBranch)
+	return err
+}
+
+// GetHeadCommitID returns the SHA of the head commit of the pull request, as stored in the base repository
+func (pr *PullRequest) GetHeadCommitID() (string, error) {
+	if err := pr.GetBaseRepo(); err != nil {
+		return "", err
+	}
+
+	gitRepo, err := git.OpenRepository(pr.BaseRepo.RepoPath())
+	if err != nil {
+		return "", fmt.Errorf("OpenRepository: %v", err)
+	}
+	return gitRepo.GetRefCommitID(pr.GetGitRefName())
+}
+
 // IsChecking returns true if this pull request is still checking conflict.
 func (pr *PullRequest) IsChecking() bool {
 	return pr.Status == PullRequestStatusChecking
@@ -296,6 +325,28 @@ func (pr *PullRequest) CheckUserAllowedToMerge(doer *User) (err error) {
 		}
 	}
 
+	if err = pr.LoadProtectedBranch(); err != nil {
+		return fmt.Errorf("LoadProtectedBranch: %v", err)
+	}
+	if pr.ProtectedBranch == nil {
+		return nil
+	}
+
+	if !pr.ProtectedBranch.HasEnoughApprovals(pr) {
+		return ErrNotAllowedToMerge{
+			"Not enough approvals",
+		}
+	}
+
+	unsuccessful, err := pr.ProtectedBranch.GetUnsuccessfulStatusChecks(pr)
+	if err != nil {
+		return fmt.Errorf("GetUnsuccessfulStatusChecks: %v", err)
+	} else if len(unsuccessful) > 0 {
+		return ErrNotAllowedToMerge{
+			"Required status checks are not successful: " + strings.Join(unsuccessful, ", "),
+		}
+	}
+
 	return nil
 }
 
@@ -903,6 +954,40 @@ func GetUnmergedPullRequestsByBaseInfo(repoID int64, branch string) ([]*PullRequ
 		Find(&prs)
 }
 
+// GetUnmergedPullRequestsByHeadCommit returns all pull requests of a base repository
+// that are open, have not been merged and whose head commit is the given one.
+func GetUnmergedPullRequestsByHeadCommit(repo *Repository, sha string) ([]*PullRequest, error) {
+	prs := make([]*PullRequest, 0, 2)
+	if err := x.
+		Where("base_repo_id=? AND has_merged=? AND issue.is_closed=?",
+			repo.ID, false, false).
+		Join("INNER", "issue", "issue.id=pull_request.issue_id").
+		Find(&prs); err != nil {
+		return nil, err
+	} else if len(prs) == 0 {
+		return prs, nil
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return nil, fmt.Errorf("OpenRepository: %v", err)
+	}
+
+	matched := prs[:0]
+	for _, pr := range prs {
+		commitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+		if err != nil {
+			// the head of the pull request may not be pushed yet
+			continue
+		}
+		if commitID == sha {
+			pr.BaseRepo = repo
+			matched = append(matched, pr)
+		}
+	}
+	return matched, nil
+}
+
 // GetPullRequestByIndex returns a pull request by the given index
 func GetPullRequestByIndex(repoID int64, index int64) (*PullRequest, error) {
 	pr := &PullRequest{
@@ -1058,12 +1143,11 @@ func (pr *PullRequest) PushToBaseRepo() (err error) {
 
 // AddToTaskQueue adds itself to pull request test task queue.
 func (pr *PullRequest) AddToTaskQueue() {
-	go pullRequestQueue.AddFunc(pr.ID, func() {
-		pr.Status = PullRequestStatusChecking
-		if err := pr.UpdateCols("status"); err != nil {
-			log.Error(5, "AddToTaskQueue.UpdateCols[%d].(add to queue): %v", pr.ID, err)
-		}
-	})
+	pr.Status = PullRequestStatusChecking
+	if err := pr.UpdateCols("status"); err != nil {
+		log.Error(5, "AddToTaskQueue.UpdateCols[%d].(add to queue): %v", pr.ID, err)
+	}
+	go pullRequestQueue.Add(pr.ID)
 }
 
 // PullRequestList defines a list of pull requests
@@ -1172,7 +1256,7 @@ func AddTestPullRequestTask(doer *User, repoID int64, branch string, isSync bool
 					log.Error(4, "LoadAttributes: %v", err)
 					continue
 				}
-				if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequest, &api.PullRequestPayload{
+				if err = PrepareWebhooks(pr.Issue.Repo, HookEventPullRequestSync, &api.PullRequestPayload{
 					Action:      api.HookIssueSynchronized,
 					Index:       pr.Issue.Index,
 					PullRequest: pr.Issue.PullRequest.APIFormat(),
@@ -1279,7 +1363,6 @@ func (pr *PullRequest) GetWorkInProgressPrefix() string {
 }
 
 // TestPullRequests checks and tests untested patches of pull requests.
-// TODO: test more pull requests at same time.
 func TestPullRequests() {
 	prs := make([]*PullRequest, 0, 10)
 
@@ -1310,28 +1393,27 @@ func TestPullRequests() {
 	}
 
 	// Start listening on new test requests.
-	for prID := range pullRequestQueue.Queue() {
+	pullRequestQueue.Run(func(prID string) {
 		log.Trace("TestPullRequests[%v]: processing test task", prID)
-		pullRequestQueue.Remove(prID)
 
 		id := com.StrTo(prID).MustInt64()
 		if _, ok := checkedPRs[id]; ok {
-			continue
+			return
 		}
 
 		pr, err := GetPullRequestByID(id)
 		if err != nil {
 			log.Error(4, "GetPullRequestByID[%s]: %v", prID, err)
-			continue
+			return
 		} else if pr.manuallyMerged() {
-			continue
+			return
 		} else if err = pr.testPatch(); err != nil {
 			log.Error(4, "testPatch[%d]: %v", pr.ID, err)
-			continue
+			return
 		}
 
 		pr.checkAndUpdateStatus()
-	}
+	})
 }
 
 // InitTestPullRequests runs the task to test all the checking status pull requests
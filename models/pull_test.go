@@ -5,7 +5,6 @@
 package models
 
 import (
-	"strconv"
 	"testing"
 	"time"
 
@@ -194,14 +193,14 @@ func TestPullRequest_AddToTaskQueue(t *testing.T) {
 	pr := AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
 	pr.AddToTaskQueue()
 
-	select {
-	case id := <-pullRequestQueue.Queue():
-		assert.EqualValues(t, strconv.FormatInt(pr.ID, 10), id)
-	case <-time.After(time.Second):
-		assert.Fail(t, "Timeout: nothing was added to pullRequestQueue")
+	timeout := time.After(time.Second)
+	for !pullRequestQueue.Exist(pr.ID) {
+		select {
+		case <-timeout:
+			assert.FailNow(t, "Timeout: nothing was added to pullRequestQueue")
+		case <-time.After(10 * time.Millisecond):
+		}
 	}
-
-	assert.True(t, pullRequestQueue.Exist(pr.ID))
 	pr = AssertExistsAndLoadBean(t, &PullRequest{ID: 1}).(*PullRequest)
 	assert.Equal(t, PullRequestStatusChecking, pr.Status)
 }
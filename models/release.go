@@ -68,6 +68,11 @@ func (r *Release) APIURL() string {
 		setting.AppURL, r.Repo.FullName(), r.ID)
 }
 
+// HTMLURL the url of the page of a release. release must have attributes loaded
+func (r *Release) HTMLURL() string {
+	return fmt.Sprintf("%s/releases/tag/%s", r.Repo.HTMLURL(), r.TagName)
+}
+
 // ZipURL the zip url for a release. release must have attributes loaded
 func (r *Release) ZipURL() string {
 	return fmt.Sprintf("%s/archive/%s.zip", r.Repo.HTMLURL(), r.TagName)
@@ -129,6 +134,9 @@ func createTag(gitRepo *git.Repository, rel *Release) error {
 				return err
 			}
 			rel.LowerTagName = strings.ToLower(rel.TagName)
+			if rel.Repo != nil && rel.Repo.IsIndexedRef(git.TagPrefix+rel.TagName) {
+				UpdateRepoIndexer(rel.Repo)
+			}
 		}
 		commit, err := gitRepo.GetTagCommit(rel.TagName)
 		if err != nil {
@@ -426,6 +434,9 @@ func DeleteReleaseByID(id int64, u *User, delTag bool) error {
 		if err != nil && !strings.Contains(stderr, "not found") {
 			return fmt.Errorf("git tag -d: %v - %s", err, stderr)
 		}
+		if repo.IsIndexedRef(git.TagPrefix + rel.TagName) {
+			UpdateRepoIndexer(repo)
+		}
 
 		if _, err = x.ID(rel.ID).Delete(new(Release)); err != nil {
 			return fmt.Errorf("Delete: %v", err)
@@ -26,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/sync"
 	"code.gitea.io/gitea/modules/util"
 	api "code.gitea.io/sdk/gitea"
@@ -192,16 +193,24 @@ type Repository struct {
 	IsMirror bool `xorm:"INDEX"`
 	*Mirror  `xorm:"-"`
 
+	IsArchived bool `xorm:"INDEX NOT NULL DEFAULT false"`
+
+	IsTemplate   bool        `xorm:"INDEX NOT NULL DEFAULT false"`
+	TemplateID   int64       `xorm:"INDEX"`
+	TemplateRepo *Repository `xorm:"-"`
+
 	ExternalMetas map[string]string `xorm:"-"`
 	Units         []*RepoUnit       `xorm:"-"`
 
-	IsFork        bool               `xorm:"INDEX NOT NULL DEFAULT false"`
-	ForkID        int64              `xorm:"INDEX"`
-	BaseRepo      *Repository        `xorm:"-"`
-	Size          int64              `xorm:"NOT NULL DEFAULT 0"`
-	IndexerStatus *RepoIndexerStatus `xorm:"-"`
-	IsFsckEnabled bool               `xorm:"NOT NULL DEFAULT true"`
-	Topics        []string           `xorm:"TEXT JSON"`
+	IsFork        bool        `xorm:"INDEX NOT NULL DEFAULT false"`
+	ForkID        int64       `xorm:"INDEX"`
+	BaseRepo      *Repository `xorm:"-"`
+	Size          int64       `xorm:"NOT NULL DEFAULT 0"`
+	IsFsckEnabled bool        `xorm:"NOT NULL DEFAULT true"`
+	Topics        []string    `xorm:"TEXT JSON"`
+	// IndexerRefs patterns of the names of the branches and tags indexed
+	// by the code indexer in addition to the default branch
+	IndexerRefs []string `xorm:"TEXT JSON"`
 
 	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
@@ -296,6 +305,8 @@ func (repo *Repository) innerAPIFormat(e Engine, mode AccessMode, isParent bool)
 		Fork:          repo.IsFork,
 		Parent:        parent,
 		Mirror:        repo.IsMirror,
+		Archived:      repo.IsArchived,
+		Template:      repo.IsTemplate,
 		HTMLURL:       repo.HTMLURL(),
 		SSHURL:        cloneLink.SSH,
 		CloneURL:      cloneLink.HTTPS,
@@ -574,6 +585,27 @@ func (repo *Repository) getBaseRepo(e Engine) (err error) {
 	return err
 }
 
+// IsGenerated returns true if the repository was generated from a template repository.
+func (repo *Repository) IsGenerated() bool {
+	return repo.TemplateID > 0
+}
+
+// GetTemplateRepo populates repo.TemplateRepo for a generated repository and
+// returns an error on failure (NOTE: no error is returned for
+// non-generated repositories, and TemplateRepo will be left untouched)
+func (repo *Repository) GetTemplateRepo() (err error) {
+	return repo.getTemplateRepo(x)
+}
+
+func (repo *Repository) getTemplateRepo(e Engine) (err error) {
+	if !repo.IsGenerated() {
+		return nil
+	}
+
+	repo.TemplateRepo, err = getRepositoryByID(e, repo.TemplateID)
+	return err
+}
+
 func (repo *Repository) repoPath(e Engine) string {
 	return RepoPath(repo.mustOwnerName(e), repo.Name)
 }
@@ -610,8 +642,12 @@ func (repo *Repository) ComposeCompareURL(oldCommitID, newCommitID string) strin
 
 // UpdateDefaultBranch updates the default branch
 func (repo *Repository) UpdateDefaultBranch() error {
-	_, err := x.ID(repo.ID).Cols("default_branch").Update(repo)
-	return err
+	if _, err := x.ID(repo.ID).Cols("default_branch").Update(repo); err != nil {
+		return err
+	}
+	// the default branch is always indexed
+	UpdateRepoIndexer(repo)
+	return nil
 }
 
 // IsOwnedBy returns true when user owns this repository
@@ -666,7 +702,7 @@ func (repo *Repository) AllowsPulls() bool {
 
 // CanEnableEditor returns true if repository meets the requirements of web editor.
 func (repo *Repository) CanEnableEditor() bool {
-	return !repo.IsMirror
+	return !repo.IsMirror && !repo.IsArchived
 }
 
 // GetWriters returns all users that have write access to the repository.
@@ -866,8 +902,8 @@ type MigrateRepoOptions struct {
 }
 
 /*
-	GitHub, GitLab, Gogs: *.wiki.git
-	BitBucket: *.git/wiki
+GitHub, GitLab, Gogs: *.wiki.git
+BitBucket: *.git/wiki
 */
 var commonWikiURLSuffixes = []string{".wiki.git", ".git/wiki"}
 
@@ -1313,6 +1349,10 @@ func createRepository(e *xorm.Session, doer, u *User, repo *Repository) (err err
 		return err
 	}
 
+	if err = copyDefaultWebhooksToRepo(e, repo.ID); err != nil {
+		return fmt.Errorf("copyDefaultWebhooksToRepo: %v", err)
+	}
+
 	u.NumRepos++
 	// Remember visibility preference.
 	u.LastRepoVisibility = repo.IsPrivate
@@ -1497,6 +1537,9 @@ func TransferOwnership(doer *User, newOwnerName string, repo *Repository) error
 		if err = owner.removeOrgRepo(sess, repo.ID); err != nil {
 			return fmt.Errorf("removeOrgRepo: %v", err)
 		}
+		if err = removeRepoIssuesFromOrgProjects(sess, repo.ID); err != nil {
+			return fmt.Errorf("removeRepoIssuesFromOrgProjects: %v", err)
+		}
 	}
 
 	if newOwner.IsOrganization() {
@@ -1714,6 +1757,13 @@ func UpdateRepositoryUnits(repo *Repository, units []RepoUnit) (err error) {
 	return sess.Commit()
 }
 
+// SetArchiveRepoState sets whether the repository is archived, i.e. read-only.
+func (repo *Repository) SetArchiveRepoState(isArchived bool) (err error) {
+	repo.IsArchived = isArchived
+	_, err = x.ID(repo.ID).Cols("is_archived").Update(repo)
+	return err
+}
+
 // DeleteRepository deletes a repository for a user or organization.
 func DeleteRepository(doer *User, uid, repoID int64) error {
 	// In case is a organization.
@@ -1763,6 +1813,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		&Watch{RepoID: repoID},
 		&Star{RepoID: repoID},
 		&Mirror{RepoID: repoID},
+		&PushMirror{RepoID: repoID},
 		&Milestone{RepoID: repoID},
 		&Release{RepoID: repoID},
 		&Collaboration{RepoID: repoID},
@@ -1775,7 +1826,15 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deleteProjectsByCond(sess, builder.Eq{"repo_id": repoID, "type": ProjectTypeRepository}); err != nil {
+		return fmt.Errorf("deleteProjectsByCond: %v", err)
+	}
+
 	deleteCond := builder.Select("id").From("issue").Where(builder.Eq{"repo_id": repoID})
+	deletedIssueIDs := make([]int64, 0, 10)
+	if err = sess.Table("issue").Cols("id").Where("repo_id = ?", repoID).Find(&deletedIssueIDs); err != nil {
+		return err
+	}
 	// Delete comments and attachments
 	if _, err = sess.In("issue_id", deleteCond).
 		Delete(&Comment{}); err != nil {
@@ -1802,6 +1861,11 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 
+	if _, err = sess.In("issue_id", deleteCond).
+		Delete(&ProjectIssue{}); err != nil {
+		return err
+	}
+
 	attachmentPaths := make([]string, 0, 20)
 	attachments := make([]*Attachment, 0, len(attachmentPaths))
 	if err = sess.Join("INNER", "issue", "issue.id = attachment.issue_id").
@@ -1810,7 +1874,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		return err
 	}
 	for j := range attachments {
-		attachmentPaths = append(attachmentPaths, attachments[j].LocalPath())
+		attachmentPaths = append(attachmentPaths, attachments[j].RelativePath())
 	}
 
 	if _, err = sess.In("issue_id", deleteCond).
@@ -1844,7 +1908,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 
 	// Remove attachment files.
 	for i := range attachmentPaths {
-		removeAllWithNotice(sess, "Delete attachment", attachmentPaths[i])
+		removeStorageWithNotice(sess, storage.Attachments, "Delete attachment", attachmentPaths[i])
 	}
 
 	// Remove LFS objects
@@ -1863,11 +1927,7 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 			continue
 		}
 
-		oidPath := filepath.Join(v.Oid[0:2], v.Oid[2:4], v.Oid[4:len(v.Oid)])
-		err = os.Remove(filepath.Join(setting.LFS.ContentPath, oidPath))
-		if err != nil {
-			return err
-		}
+		removeStorageWithNotice(sess, storage.LFS, "Delete orphaned LFS file", v.RelativePath())
 	}
 
 	if _, err := sess.Delete(&LFSMetaObject{RepositoryID: repoID}); err != nil {
@@ -1880,6 +1940,11 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 		}
 	}
 
+	// A template may have been used before it was unmarked as such.
+	if _, err = sess.Exec("UPDATE `repository` SET template_id=0 WHERE template_id=?", repo.ID); err != nil {
+		log.Error(4, "reset 'template_id': %v", err)
+	}
+
 	if err = sess.Commit(); err != nil {
 		return fmt.Errorf("Commit: %v", err)
 	}
@@ -1897,6 +1962,9 @@ func DeleteRepository(doer *User, uid, repoID int64) error {
 	}
 
 	DeleteRepoFromIndexer(repo)
+	for _, issueID := range deletedIssueIDs {
+		UpdateIssueIndexer(issueID)
+	}
 	return nil
 }
 
@@ -44,7 +44,7 @@ func GetBranchesByPath(path string) ([]*Branch, error) {
 
 // CanCreateBranch returns true if repository meets the requirements for creating new branches.
 func (repo *Repository) CanCreateBranch() bool {
-	return !repo.IsMirror
+	return !repo.IsMirror && !repo.IsArchived
 }
 
 // GetBranch returns a branch by it's name
@@ -0,0 +1,107 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+)
+
+// SearchCommitsOptions represents the options to search the commits of a
+// repository
+type SearchCommitsOptions struct {
+	// Revision the commits are reachable from
+	Revision string
+	// Path restricts the commits to the ones changing it, if not empty
+	Path string
+	// Author restricts the commits to the ones with an author name or email
+	// matching it, if not empty
+	Author string
+	Since  time.Time
+	Until  time.Time
+	Page   int
+	// PageSize is the maximum number of commits returned, all commits are
+	// returned if not positive
+	PageSize int
+}
+
+func (opts *SearchCommitsOptions) toRevListArgs() []string {
+	args := make([]string, 0, 6)
+	if len(opts.Author) > 0 {
+		args = append(args, "--regexp-ignore-case", "--fixed-strings", "--author="+opts.Author)
+	}
+	if !opts.Since.IsZero() {
+		args = append(args, "--since="+opts.Since.Format(time.RFC3339))
+	}
+	if !opts.Until.IsZero() {
+		args = append(args, "--until="+opts.Until.Format(time.RFC3339))
+	}
+	args = append(args, opts.Revision, "--")
+	if len(opts.Path) > 0 {
+		args = append(args, opts.Path)
+	}
+	return args
+}
+
+// SearchRepoCommits returns the commits matching the options, the most recent
+// first, and the total count of matching commits
+func SearchRepoCommits(gitRepo *git.Repository, opts SearchCommitsOptions) ([]*git.Commit, int64, error) {
+	stdout, err := git.NewCommand("rev-list", "--count").AddArguments(opts.toRevListArgs()...).RunInDir(gitRepo.Path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("rev-list --count: %v", err)
+	}
+	count, err := strconv.ParseInt(strings.TrimSpace(stdout), 10, 64)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	cmd := git.NewCommand("rev-list")
+	if opts.PageSize > 0 {
+		if opts.Page <= 0 {
+			opts.Page = 1
+		}
+		cmd.AddArguments("--skip="+strconv.Itoa((opts.Page-1)*opts.PageSize), "--max-count="+strconv.Itoa(opts.PageSize))
+	}
+	stdout, err = cmd.AddArguments(opts.toRevListArgs()...).RunInDir(gitRepo.Path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("rev-list: %v", err)
+	}
+
+	ids := strings.Fields(stdout)
+	commits := make([]*git.Commit, 0, len(ids))
+	for _, id := range ids {
+		commit, err := gitRepo.GetCommit(id)
+		if err != nil {
+			return nil, 0, fmt.Errorf("GetCommit [%s]: %v", id, err)
+		}
+		commits = append(commits, commit)
+	}
+	return commits, count, nil
+}
+
+// CommitsAheadBehind returns the number of commits reachable from head but
+// not from base, and the number of commits reachable from base but not from
+// head
+func CommitsAheadBehind(gitRepo *git.Repository, base, head string) (ahead, behind int64, err error) {
+	stdout, err := git.NewCommand("rev-list", "--left-right", "--count", base+"..."+head).RunInDir(gitRepo.Path)
+	if err != nil {
+		return 0, 0, fmt.Errorf("rev-list --left-right: %v", err)
+	}
+	counts := strings.Fields(stdout)
+	if len(counts) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %s", stdout)
+	}
+	if behind, err = strconv.ParseInt(counts[0], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	if ahead, err = strconv.ParseInt(counts[1], 10, 64); err != nil {
+		return 0, 0, err
+	}
+	return ahead, behind, nil
+}
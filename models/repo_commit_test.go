@@ -0,0 +1,77 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchRepoCommits(t *testing.T) {
+	PrepareTestEnv(t)
+
+	gitRepo, err := git.OpenRepository(RepoPath("user2", "repo16"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	commits, count, err := SearchRepoCommits(gitRepo, SearchCommitsOptions{
+		Revision: "master",
+		PageSize: 2,
+		Page:     2,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	if assert.Len(t, commits, 1) {
+		assert.Equal(t, "5099b81332712fe655e34e8dd63574f503f61811", commits[0].ID.String())
+	}
+
+	commits, count, err = SearchRepoCommits(gitRepo, SearchCommitsOptions{
+		Revision: "master",
+		Author:   "USER21@",
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, commits, 1) {
+		assert.Equal(t, "27566bd5738fc8b4e3fef3c5e72cce608537bd95", commits[0].ID.String())
+	}
+
+	_, count, err = SearchRepoCommits(gitRepo, SearchCommitsOptions{
+		Revision: "master",
+		Since:    time.Date(2017, 8, 6, 17, 57, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	_, count, err = SearchRepoCommits(gitRepo, SearchCommitsOptions{
+		Revision: "master",
+		Path:     "does-not-exist.md",
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
+
+func TestCommitsAheadBehind(t *testing.T) {
+	PrepareTestEnv(t)
+
+	gitRepo, err := git.OpenRepository(RepoPath("user2", "repo16"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	ahead, behind, err := CommitsAheadBehind(gitRepo, "5099b81332712fe655e34e8dd63574f503f61811", "master")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, ahead)
+	assert.EqualValues(t, 0, behind)
+
+	ahead, behind, err = CommitsAheadBehind(gitRepo, "master", "good-sign")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, ahead)
+	assert.EqualValues(t, 3, behind)
+}
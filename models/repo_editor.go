@@ -72,6 +72,36 @@ func (repo *Repository) CheckoutNewBranch(oldBranch, newBranch string) error {
 	return checkoutNewBranch(repo.RepoPath(), repo.LocalCopyPath(), oldBranch, newBranch)
 }
 
+// checkRepoFileSHA checks that the blob of the file at treePath in the branch has the
+// given SHA, if any.
+func (repo *Repository) checkRepoFileSHA(branch, treePath, sha string) error {
+	if len(sha) == 0 {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository: %v", err)
+	}
+	commit, err := gitRepo.GetBranchCommit(branch)
+	if err != nil {
+		return fmt.Errorf("GetBranchCommit [branch: %s]: %v", branch, err)
+	}
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return ErrRepoFileDoesNotExist{treePath}
+		}
+		return fmt.Errorf("GetTreeEntryByPath [path: %s]: %v", treePath, err)
+	} else if entry.IsDir() {
+		return ErrRepoFileDoesNotExist{treePath}
+	}
+	if entry.ID.String() != sha {
+		return ErrRepoFileSHAMismatch{treePath, sha, entry.ID.String()}
+	}
+	return nil
+}
+
 // UpdateRepoFileOptions holds the repository file update options
 type UpdateRepoFileOptions struct {
 	LastCommitID string
@@ -82,6 +112,12 @@ type UpdateRepoFileOptions struct {
 	Message      string
 	Content      string
 	IsNewFile    bool
+	// SHA is the blob SHA the file to update is expected to have, it is not
+	// checked if empty
+	SHA string
+	// Author and Committer of the commit, the doer if nil
+	Author    *git.Signature
+	Committer *git.Signature
 }
 
 // UpdateRepoFile adds or updates a file in repository.
@@ -89,6 +125,10 @@ func (repo *Repository) UpdateRepoFile(doer *User, opts UpdateRepoFileOptions) (
 	repoWorkingPool.CheckIn(com.ToStr(repo.ID))
 	defer repoWorkingPool.CheckOut(com.ToStr(repo.ID))
 
+	if err = repo.checkRepoFileSHA(opts.OldBranch, opts.OldTreeName, opts.SHA); err != nil {
+		return err
+	}
+
 	if err = repo.DiscardLocalRepoBranchChanges(opts.OldBranch); err != nil {
 		return fmt.Errorf("DiscardLocalRepoBranchChanges [branch: %s]: %v", opts.OldBranch, err)
 	} else if err = repo.UpdateLocalCopyBranch(opts.OldBranch); err != nil {
@@ -101,6 +141,10 @@ func (repo *Repository) UpdateRepoFile(doer *User, opts UpdateRepoFileOptions) (
 		}
 	}
 
+	if opts.Committer == nil {
+		opts.Committer = doer.NewGitSig()
+	}
+
 	localPath := repo.LocalCopyPath()
 	oldFilePath := path.Join(localPath, opts.OldTreeName)
 	filePath := path.Join(localPath, opts.NewTreeName)
@@ -132,7 +176,8 @@ func (repo *Repository) UpdateRepoFile(doer *User, opts UpdateRepoFileOptions) (
 	if err = git.AddChanges(localPath, true); err != nil {
 		return fmt.Errorf("git add --all: %v", err)
 	} else if err = git.CommitChanges(localPath, git.CommitChangesOptions{
-		Committer: doer.NewGitSig(),
+		Committer: opts.Committer,
+		Author:    opts.Author,
 		Message:   opts.Message,
 	}); err != nil {
 		return fmt.Errorf("CommitChanges: %v", err)
@@ -249,6 +294,12 @@ type DeleteRepoFileOptions struct {
 	NewBranch    string
 	TreePath     string
 	Message      string
+	// SHA is the blob SHA the file to delete is expected to have, it is not
+	// checked if empty
+	SHA string
+	// Author and Committer of the commit, the doer if nil
+	Author    *git.Signature
+	Committer *git.Signature
 }
 
 // DeleteRepoFile deletes a repository file
@@ -256,6 +307,10 @@ func (repo *Repository) DeleteRepoFile(doer *User, opts DeleteRepoFileOptions) (
 	repoWorkingPool.CheckIn(com.ToStr(repo.ID))
 	defer repoWorkingPool.CheckOut(com.ToStr(repo.ID))
 
+	if err = repo.checkRepoFileSHA(opts.OldBranch, opts.TreePath, opts.SHA); err != nil {
+		return err
+	}
+
 	if err = repo.DiscardLocalRepoBranchChanges(opts.OldBranch); err != nil {
 		return fmt.Errorf("DiscardLocalRepoBranchChanges [branch: %s]: %v", opts.OldBranch, err)
 	} else if err = repo.UpdateLocalCopyBranch(opts.OldBranch); err != nil {
@@ -268,6 +323,10 @@ func (repo *Repository) DeleteRepoFile(doer *User, opts DeleteRepoFileOptions) (
 		}
 	}
 
+	if opts.Committer == nil {
+		opts.Committer = doer.NewGitSig()
+	}
+
 	localPath := repo.LocalCopyPath()
 	if err = os.Remove(path.Join(localPath, opts.TreePath)); err != nil {
 		return fmt.Errorf("Remove: %v", err)
@@ -276,7 +335,8 @@ func (repo *Repository) DeleteRepoFile(doer *User, opts DeleteRepoFileOptions) (
 	if err = git.AddChanges(localPath, true); err != nil {
 		return fmt.Errorf("git add --all: %v", err)
 	} else if err = git.CommitChanges(localPath, git.CommitChangesOptions{
-		Committer: doer.NewGitSig(),
+		Committer: opts.Committer,
+		Author:    opts.Author,
 		Message:   opts.Message,
 	}); err != nil {
 		return fmt.Errorf("CommitChanges: %v", err)
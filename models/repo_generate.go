@@ -0,0 +1,380 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+
+	"github.com/Unknwon/com"
+	"github.com/go-xorm/xorm"
+)
+
+// templateFilePath is the path of the file inside a template repository
+// listing the globs of the files in which variables are expanded.
+const templateFilePath = ".gitea/template"
+
+// GenerateRepoOptions contains the template units to generate
+type GenerateRepoOptions struct {
+	Name        string
+	Description string
+	Private     bool
+	GitContent  bool
+	Topics      bool
+	// Webhooks are only copied if the doer is an admin of the template
+	// repository, since they carry secrets and credentials
+	Webhooks    bool
+	IssueLabels bool
+}
+
+// IsValid checks whether at least one option is chosen for generation
+func (gro GenerateRepoOptions) IsValid() bool {
+	return gro.GitContent || gro.Topics || gro.Webhooks || gro.IssueLabels
+}
+
+var templateVarPattern = regexp.MustCompile(`\$(?:([A-Z_]+)|\{([A-Z_]+)\})`)
+
+// expandTemplateVars replaces all known $VAR and ${VAR} occurrences in s
+// by their value, unknown variables are left untouched.
+func expandTemplateVars(s string, vars map[string]string) string {
+	return templateVarPattern.ReplaceAllStringFunc(s, func(match string) string {
+		name := strings.Trim(match, "${}")
+		if value, ok := vars[name]; ok {
+			return value
+		}
+		return match
+	})
+}
+
+// templateGlobToRegexp converts a glob of a template file into a regular
+// expression matching slash separated paths relative to the repository root.
+// "*" and "?" do not match "/", "**" matches any number of directories, and a
+// glob without "/" matches files of that name in every directory.
+func templateGlobToRegexp(glob string) (*regexp.Regexp, error) {
+	var buf bytes.Buffer
+	buf.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		buf.WriteString("(?:.*/)?")
+	}
+	glob = strings.TrimPrefix(glob, "/")
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					buf.WriteString("(?:.*/)?")
+				} else {
+					buf.WriteString(".*")
+				}
+			} else {
+				buf.WriteString("[^/]*")
+			}
+		case '?':
+			buf.WriteString("[^/]")
+		default:
+			buf.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	buf.WriteString("$")
+	return regexp.Compile(buf.String())
+}
+
+// parseTemplateFile returns the globs listed in the content of a template file,
+// ignoring empty lines and lines starting with "#".
+func parseTemplateFile(content []byte) ([]*regexp.Regexp, error) {
+	var globs []*regexp.Regexp
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		g, err := templateGlobToRegexp(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob '%s': %v", line, err)
+		}
+		globs = append(globs, g)
+	}
+	return globs, scanner.Err()
+}
+
+func templateVars(e Engine, templateRepo, generateRepo *Repository) map[string]string {
+	cloneLink := generateRepo.cloneLink(e, false)
+	return map[string]string{
+		"REPO_NAME":        generateRepo.Name,
+		"REPO_DESCRIPTION": generateRepo.Description,
+		"REPO_OWNER":       generateRepo.mustOwnerName(e),
+		"OWNER":            generateRepo.mustOwnerName(e),
+		"REPO_LINK":        generateRepo.HTMLURL(),
+		"REPO_SSH_URL":     cloneLink.SSH,
+		"REPO_HTTPS_URL":   cloneLink.HTTPS,
+		"TEMPLATE_NAME":    templateRepo.Name,
+		"TEMPLATE_OWNER":   templateRepo.mustOwnerName(e),
+	}
+}
+
+// expandTemplateFiles expands the template variables in all files of tmpDir
+// matched by the globs of the template file, which is removed afterwards.
+func expandTemplateFiles(tmpDir string, vars map[string]string) error {
+	templatePath := filepath.Join(tmpDir, filepath.FromSlash(templateFilePath))
+	if !com.IsFile(templatePath) {
+		return nil
+	}
+
+	content, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		return err
+	}
+	globs, err := parseTemplateFile(content)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(templatePath); err != nil {
+		return err
+	}
+
+	return filepath.Walk(tmpDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(tmpDir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		for _, g := range globs {
+			if !g.MatchString(relPath) {
+				continue
+			}
+
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(path, []byte(expandTemplateVars(string(content), vars)), info.Mode())
+		}
+		return nil
+	})
+}
+
+// generateRepoCommit copies the content of the default branch of the template
+// repository into tmpDir and commits it as the first commit of the new repository.
+func generateRepoCommit(e Engine, templateRepo, generateRepo *Repository, tmpDir string, sig *git.Signature) error {
+	templateRepoPath := templateRepo.repoPath(e)
+	repoPath := generateRepo.repoPath(e)
+
+	if _, stderr, err := process.GetManager().Exec(
+		fmt.Sprintf("generateRepoCommit(git clone): %s", templateRepoPath),
+		"git", "clone", "--branch", templateRepo.DefaultBranch, templateRepoPath, tmpDir,
+	); err != nil {
+		return fmt.Errorf("git clone: %v - %s", err, stderr)
+	}
+
+	// Drop the history of the template repository.
+	if err := os.RemoveAll(filepath.Join(tmpDir, ".git")); err != nil {
+		return fmt.Errorf("remove .git: %v", err)
+	}
+
+	if err := expandTemplateFiles(tmpDir, templateVars(e, templateRepo, generateRepo)); err != nil {
+		return fmt.Errorf("expandTemplateFiles: %v", err)
+	}
+
+	if err := git.InitRepository(tmpDir, false); err != nil {
+		return fmt.Errorf("git init: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"symbolic-ref", "HEAD", git.BranchPrefix + "master"},
+		{"remote", "add", "origin", repoPath},
+	} {
+		if _, stderr, err := process.GetManager().ExecDir(-1,
+			tmpDir, fmt.Sprintf("generateRepoCommit (git %s): %s", args[0], tmpDir),
+			"git", args...); err != nil {
+			return fmt.Errorf("git %s: %s", args[0], stderr)
+		}
+	}
+
+	return initRepoCommit(tmpDir, sig)
+}
+
+// generateGitContent initializes the bare repository of the generated
+// repository, filled with the content of the template repository if requested.
+func generateGitContent(e Engine, doer *User, templateRepo, generateRepo *Repository, opts GenerateRepoOptions) error {
+	repoPath := generateRepo.repoPath(e)
+	if com.IsExist(repoPath) {
+		return fmt.Errorf("generateGitContent: path already exists: %s", repoPath)
+	}
+
+	if err := git.InitRepository(repoPath, true); err != nil {
+		return fmt.Errorf("InitRepository: %v", err)
+	} else if err = createDelegateHooks(repoPath); err != nil {
+		return fmt.Errorf("createDelegateHooks: %v", err)
+	}
+
+	if !generateRepo.IsBare {
+		tmpDir := filepath.Join(os.TempDir(), "gitea-"+generateRepo.Name+"-"+com.ToStr(time.Now().Nanosecond()))
+		defer os.RemoveAll(tmpDir)
+
+		if err := generateRepoCommit(e, templateRepo, generateRepo, tmpDir, doer.NewGitSig()); err != nil {
+			return fmt.Errorf("generateRepoCommit: %v", err)
+		}
+	}
+
+	if _, stderr, err := process.GetManager().ExecDir(-1,
+		repoPath, fmt.Sprintf("generateGitContent(git update-server-info): %s", repoPath),
+		"git", "update-server-info"); err != nil {
+		return fmt.Errorf("git update-server-info: %s", stderr)
+	}
+	return nil
+}
+
+// generateIssueLabels copies all labels of the template repository.
+func generateIssueLabels(e Engine, templateRepo, generateRepo *Repository) error {
+	templateLabels := make([]*Label, 0, 10)
+	err := e.Where("repo_id = ?", templateRepo.ID).Asc("name").Find(&templateLabels)
+	if err != nil {
+		return err
+	}
+
+	for _, templateLabel := range templateLabels {
+		if err = newLabel(e, &Label{
+			RepoID:      generateRepo.ID,
+			Name:        templateLabel.Name,
+			Description: templateLabel.Description,
+			Color:       templateLabel.Color,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateWebhooks copies all webhooks of the template repository.
+func generateWebhooks(e Engine, templateRepo, generateRepo *Repository) error {
+	templateWebhooks := make([]*Webhook, 0, 5)
+	if err := e.Find(&templateWebhooks, &Webhook{RepoID: templateRepo.ID}); err != nil {
+		return err
+	}
+
+	for _, templateWebhook := range templateWebhooks {
+		if _, err := e.Insert(&Webhook{
+			RepoID:       generateRepo.ID,
+			URL:          templateWebhook.URL,
+			ContentType:  templateWebhook.ContentType,
+			Secret:       templateWebhook.Secret,
+			Events:       templateWebhook.Events,
+			IsSSL:        templateWebhook.IsSSL,
+			IsActive:     templateWebhook.IsActive,
+			HookTaskType: templateWebhook.HookTaskType,
+			Meta:         templateWebhook.Meta,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func generateRepository(e *xorm.Session, doer, owner *User, templateRepo, generateRepo *Repository, opts GenerateRepoOptions) (err error) {
+	if err = createRepository(e, doer, owner, generateRepo); err != nil {
+		return err
+	}
+
+	if opts.IssueLabels {
+		if err = generateIssueLabels(e, templateRepo, generateRepo); err != nil {
+			return fmt.Errorf("generateIssueLabels: %v", err)
+		}
+	}
+
+	if opts.Webhooks {
+		isAdmin, err := isUserRepoAdmin(e, templateRepo, doer)
+		if err != nil {
+			return fmt.Errorf("isUserRepoAdmin: %v", err)
+		}
+		if isAdmin {
+			if err = generateWebhooks(e, templateRepo, generateRepo); err != nil {
+				return fmt.Errorf("generateWebhooks: %v", err)
+			}
+		}
+	}
+
+	repoPath := generateRepo.repoPath(e)
+	if err = generateGitContent(e, doer, templateRepo, generateRepo, opts); err != nil {
+		if err2 := os.RemoveAll(repoPath); err2 != nil {
+			log.Error(4, "generateGitContent: %v", err)
+			return fmt.Errorf("delete repo directory %s failed(2): %v", repoPath, err2)
+		}
+		return fmt.Errorf("generateGitContent: %v", err)
+	}
+
+	return nil
+}
+
+// GenerateRepository creates a repository for the user/organization owner
+// from the template repository. The git content is copied with a fresh
+// history and variables are expanded in the files listed in .gitea/template.
+func GenerateRepository(doer, owner *User, templateRepo *Repository, opts GenerateRepoOptions) (_ *Repository, err error) {
+	if !doer.IsAdmin && !owner.CanCreateRepo() {
+		return nil, ErrReachLimitOfRepo{owner.MaxRepoCreation}
+	}
+
+	generateRepo := &Repository{
+		OwnerID:       owner.ID,
+		Owner:         owner,
+		Name:          opts.Name,
+		LowerName:     strings.ToLower(opts.Name),
+		Description:   opts.Description,
+		IsPrivate:     opts.Private,
+		IsBare:        !opts.GitContent || templateRepo.IsBare,
+		DefaultBranch: "master",
+		TemplateID:    templateRepo.ID,
+	}
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if err = generateRepository(sess, doer, owner, templateRepo, generateRepo, opts); err != nil {
+		return nil, err
+	}
+
+	if err = sess.Commit(); err != nil {
+		return nil, err
+	}
+
+	if opts.Topics && len(templateRepo.Topics) > 0 {
+		if err = SaveTopics(generateRepo.ID, templateRepo.Topics...); err != nil {
+			log.Error(4, "SaveTopics: %v", err)
+		} else {
+			generateRepo.Topics = templateRepo.Topics
+		}
+	}
+
+	return generateRepo, nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Unknwon/com"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateGlobToRegexp(t *testing.T) {
+	for _, c := range []struct {
+		Glob    string
+		Path    string
+		Matches bool
+	}{
+		{"README.md", "README.md", true},
+		{"README.md", "docs/README.md", true},
+		{"/README.md", "docs/README.md", false},
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/main.go", true},
+		{"cmd/*.go", "cmd/serv/main.go", false},
+		{"cmd/**/*.go", "cmd/main.go", true},
+		{"cmd/**/*.go", "cmd/serv/main.go", true},
+		{"cmd/**", "cmd/serv/main.go", true},
+		{"Makefile.?", "Makefile.1", true},
+		{"Makefile.?", "Makefile.10", false},
+		{"a.b", "axb", false},
+	} {
+		g, err := templateGlobToRegexp(c.Glob)
+		assert.NoError(t, err)
+		assert.Equal(t, c.Matches, g.MatchString(c.Path), "glob %s, path %s", c.Glob, c.Path)
+	}
+}
+
+func TestExpandTemplateVars(t *testing.T) {
+	vars := map[string]string{
+		"REPO_NAME": "service",
+		"OWNER":     "user2",
+	}
+	assert.Equal(t, "module user2/service", expandTemplateVars("module $OWNER/$REPO_NAME", vars))
+	assert.Equal(t, "service-cli", expandTemplateVars("${REPO_NAME}-cli", vars))
+	assert.Equal(t, "$HOME and ${UNKNOWN}", expandTemplateVars("$HOME and ${UNKNOWN}", vars))
+}
+
+func TestExpandTemplateFiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "gitea-template")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	files := map[string]string{
+		".gitea/template": "# expanded files\n*.go\n\ndocs/**\n",
+		"main.go":         "package $REPO_NAME",
+		"cmd/serv.go":     "// ${OWNER}/${REPO_NAME}",
+		"docs/a/index.md": "# $REPO_NAME",
+		"README.md":       "# $REPO_NAME",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+	}
+
+	assert.NoError(t, expandTemplateFiles(tmpDir, map[string]string{
+		"REPO_NAME": "service",
+		"OWNER":     "user2",
+	}))
+
+	assert.False(t, com.IsExist(filepath.Join(tmpDir, ".gitea", "template")))
+	for name, expected := range map[string]string{
+		"main.go":         "package service",
+		"cmd/serv.go":     "// user2/service",
+		"docs/a/index.md": "# service",
+		"README.md":       "# $REPO_NAME",
+	} {
+		content, err := ioutil.ReadFile(filepath.Join(tmpDir, filepath.FromSlash(name)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(content))
+	}
+}
+
+func TestGenerateRepository(t *testing.T) {
+	PrepareTestEnv(t)
+
+	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	templateRepo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+
+	repo, err := GenerateRepository(doer, doer, templateRepo, GenerateRepoOptions{
+		Name:        "generated",
+		Description: "generated from repo1",
+		Webhooks:    true,
+		IssueLabels: true,
+	})
+	assert.NoError(t, err)
+	assert.True(t, repo.IsBare)
+	assert.True(t, repo.IsGenerated())
+	AssertExistsAndLoadBean(t, &Repository{ID: repo.ID, TemplateID: templateRepo.ID})
+	assert.True(t, com.IsDir(repo.RepoPath()))
+
+	assert.Equal(t, GetCount(t, &Label{RepoID: templateRepo.ID}), GetCount(t, &Label{RepoID: repo.ID}))
+	// the generated repository also receives a copy of the default webhook
+	assert.Equal(t, GetCount(t, &Webhook{RepoID: templateRepo.ID})+1, GetCount(t, &Webhook{RepoID: repo.ID}))
+
+	assert.NoError(t, repo.GetTemplateRepo())
+	assert.Equal(t, templateRepo.ID, repo.TemplateRepo.ID)
+
+	_, err = GenerateRepository(doer, doer, templateRepo, GenerateRepoOptions{Name: "generated"})
+	assert.True(t, IsErrRepoAlreadyExist(err))
+
+	// the webhooks are not copied for the readers of the template repository
+	reader := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	repo, err = GenerateRepository(reader, reader, templateRepo, GenerateRepoOptions{
+		Name:     "generated",
+		Webhooks: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, GetCount(t, &Webhook{RepoID: repo.ID}))
+	AssertNotExistsBean(t, &Webhook{RepoID: repo.ID, URL: "www.example.com/url1"})
+}
@@ -6,6 +6,7 @@ package models
 
 import (
 	"fmt"
+	"path"
 	"strconv"
 	"strings"
 
@@ -14,63 +15,116 @@ import (
 	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
 
+	"github.com/Unknwon/com"
 	"github.com/ethantkoenig/rupture"
 )
 
-// RepoIndexerStatus status of a repo's entry in the repo indexer
-// For now, implicitly refers to default branch
+// RepoIndexerStatus status of a repo's entry in the repo indexer, for one of
+// its indexed branches or tags
 type RepoIndexerStatus struct {
-	ID        int64  `xorm:"pk autoincr"`
-	RepoID    int64  `xorm:"INDEX"`
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"INDEX"`
+	// Ref the full name of the branch or tag, e.g. refs/heads/master
+	Ref       string `xorm:"VARCHAR(255)"`
 	CommitSha string `xorm:"VARCHAR(40)"`
 }
 
-func (repo *Repository) getIndexerStatus() error {
-	if repo.IndexerStatus != nil {
-		return nil
+// getIndexerStatuses returns the statuses of the indexed refs of a repo,
+// indexed by ref
+func (repo *Repository) getIndexerStatuses() (map[string]*RepoIndexerStatus, error) {
+	statuses := make([]*RepoIndexerStatus, 0, 5)
+	if err := x.Where("repo_id = ?", repo.ID).Find(&statuses); err != nil {
+		return nil, err
 	}
-	status := &RepoIndexerStatus{RepoID: repo.ID}
-	has, err := x.Get(status)
-	if err != nil {
-		return err
-	} else if !has {
-		status.CommitSha = ""
+	statusMap := make(map[string]*RepoIndexerStatus, len(statuses))
+	for _, status := range statuses {
+		statusMap[status.Ref] = status
 	}
-	repo.IndexerStatus = status
-	return nil
+	return statusMap, nil
 }
 
-func (repo *Repository) updateIndexerStatus(sha string) error {
-	if err := repo.getIndexerStatus(); err != nil {
-		return err
-	}
-	if len(repo.IndexerStatus.CommitSha) == 0 {
-		repo.IndexerStatus.CommitSha = sha
-		_, err := x.Insert(repo.IndexerStatus)
+func updateIndexerStatus(status *RepoIndexerStatus, sha string) error {
+	status.CommitSha = sha
+	if status.ID == 0 {
+		_, err := x.Insert(status)
 		return err
 	}
-	repo.IndexerStatus.CommitSha = sha
-	_, err := x.ID(repo.IndexerStatus.ID).Cols("commit_sha").
-		Update(repo.IndexerStatus)
+	_, err := x.ID(status.ID).Cols("commit_sha").Update(status)
 	return err
 }
 
-type repoIndexerOperation struct {
-	repo    *Repository
-	deleted bool
+// GetIndexedRefs returns the full names of the branches and tags of a repo
+// which are in the repo indexer
+func GetIndexedRefs(repoID int64) ([]string, error) {
+	refs := make([]string, 0, 5)
+	return refs, x.Table("repo_indexer_status").
+		Where("repo_id = ?", repoID).
+		Asc("ref").
+		Cols("ref").
+		Find(&refs)
+}
+
+// IsIndexedRef returns true if the branch or tag of the given full name is
+// indexed by the repo indexer
+func (repo *Repository) IsIndexedRef(ref string) bool {
+	var name string
+	switch {
+	case strings.HasPrefix(ref, git.BranchPrefix):
+		name = ref[len(git.BranchPrefix):]
+		if name == repo.DefaultBranch {
+			return true
+		}
+	case strings.HasPrefix(ref, git.TagPrefix):
+		name = ref[len(git.TagPrefix):]
+	default:
+		return false
+	}
+	for _, pattern := range repo.IndexerRefs {
+		// patterns starting with refs/ match the full name, which allows to
+		// tell branches and tags apart
+		if strings.HasPrefix(pattern, "refs/") {
+			if matched, _ := path.Match(pattern, ref); matched {
+				return true
+			}
+		} else if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateIndexerRefs updates the patterns of the branches and tags indexed by
+// the repo indexer, and updates the indexer accordingly
+func (repo *Repository) UpdateIndexerRefs(patterns []string) error {
+	repo.IndexerRefs = make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if len(pattern) == 0 {
+			continue
+		} else if _, err := path.Match(pattern, ""); err != nil {
+			return ErrInvalidIndexerRefPattern{pattern}
+		}
+		repo.IndexerRefs = append(repo.IndexerRefs, pattern)
+	}
+	if _, err := x.ID(repo.ID).Cols("indexer_refs").Update(repo); err != nil {
+		return err
+	}
+	UpdateRepoIndexer(repo)
+	return nil
 }
 
-var repoIndexerOperationQueue chan repoIndexerOperation
+// repoIndexerQueue queue of the IDs of the repositories to be updated
+var repoIndexerQueue = sync.NewWorkQueue("repo_indexer")
 
 // InitRepoIndexer initialize the repo indexer
 func InitRepoIndexer() {
 	if !setting.Indexer.RepoIndexerEnabled {
 		return
 	}
-	repoIndexerOperationQueue = make(chan repoIndexerOperation, setting.Indexer.UpdateQueueLength)
 	indexer.InitRepoIndexer(populateRepoIndexerAsynchronously)
-	go processRepoIndexerOperationQueue()
+	go processRepoIndexerQueue()
 }
 
 // populateRepoIndexerAsynchronously asynchronously populates the repo indexer
@@ -119,10 +173,7 @@ func populateRepoIndexer(maxRepoID int64) {
 			break
 		}
 		for _, repo := range repos {
-			repoIndexerOperationQueue <- repoIndexerOperation{
-				repo:    repo,
-				deleted: false,
-			}
+			repoIndexerQueue.Add(repo.ID)
 			maxRepoID = repo.ID - 1
 		}
 	}
@@ -130,11 +181,45 @@ func populateRepoIndexer(maxRepoID int64) {
 }
 
 func updateRepoIndexer(repo *Repository) error {
-	sha, err := getDefaultBranchSha(repo)
+	refs, err := getIndexedRefShas(repo)
 	if err != nil {
 		return err
 	}
-	changes, err := getRepoChanges(repo, sha)
+	statuses, err := repo.getIndexerStatuses()
+	if err != nil {
+		return err
+	}
+
+	for ref, status := range statuses {
+		if _, ok := refs[ref]; ok {
+			continue
+		}
+		// the ref has been deleted or is no longer indexed
+		if err = indexer.DeleteRefFromIndexer(repo.ID, ref); err != nil {
+			return err
+		}
+		if _, err = x.ID(status.ID).Delete(new(RepoIndexerStatus)); err != nil {
+			return err
+		}
+	}
+
+	for ref, sha := range refs {
+		status, ok := statuses[ref]
+		if !ok {
+			status = &RepoIndexerStatus{RepoID: repo.ID, Ref: ref}
+		} else if status.CommitSha == sha {
+			continue
+		}
+		if err = updateRepoIndexerRef(repo, status, sha); err != nil {
+			return fmt.Errorf("%s: %v", ref, err)
+		}
+	}
+	return nil
+}
+
+// updateRepoIndexerRef updates the files of a branch or tag in the indexer
+func updateRepoIndexerRef(repo *Repository, status *RepoIndexerStatus, sha string) error {
+	changes, err := getRepoChanges(repo, status, sha)
 	if err != nil {
 		return err
 	} else if changes == nil {
@@ -143,19 +228,19 @@ func updateRepoIndexer(repo *Repository) error {
 
 	batch := indexer.RepoIndexerBatch()
 	for _, update := range changes.Updates {
-		if err := addUpdate(update, repo, batch); err != nil {
+		if err := addUpdate(update, repo, status.Ref, batch); err != nil {
 			return err
 		}
 	}
 	for _, filename := range changes.RemovedFilenames {
-		if err := addDelete(filename, repo, batch); err != nil {
+		if err := addDelete(filename, repo, status.Ref, batch); err != nil {
 			return err
 		}
 	}
 	if err = batch.Flush(); err != nil {
 		return err
 	}
-	return repo.updateIndexerStatus(sha)
+	return updateIndexerStatus(status, sha)
 }
 
 // repoChanges changes (file additions/updates/removals) to a repo
@@ -169,27 +254,34 @@ type fileUpdate struct {
 	BlobSha  string
 }
 
-func getDefaultBranchSha(repo *Repository) (string, error) {
-	stdout, err := git.NewCommand("show-ref", "-s", repo.DefaultBranch).RunInDir(repo.RepoPath())
+// getIndexedRefShas returns the SHAs of the branches and tags of a repo which
+// are to be indexed, indexed by their full name
+func getIndexedRefShas(repo *Repository) (map[string]string, error) {
+	stdout, err := git.NewCommand("show-ref", "--heads", "--tags").RunInDir(repo.RepoPath())
 	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(stdout), nil
-}
-
-// getRepoChanges returns changes to repo since last indexer update
-func getRepoChanges(repo *Repository, revision string) (*repoChanges, error) {
-	if err := repo.getIndexerStatus(); err != nil {
 		return nil, err
 	}
+	refs := make(map[string]string)
+	for _, line := range strings.Split(stdout, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		} else if repo.IsIndexedRef(fields[1]) {
+			refs[fields[1]] = fields[0]
+		}
+	}
+	return refs, nil
+}
 
-	if len(repo.IndexerStatus.CommitSha) == 0 {
+// getRepoChanges returns changes to a ref of a repo since last indexer update
+func getRepoChanges(repo *Repository, status *RepoIndexerStatus, revision string) (*repoChanges, error) {
+	if len(status.CommitSha) == 0 {
 		return genesisChanges(repo, revision)
 	}
-	return nonGenesisChanges(repo, revision)
+	return nonGenesisChanges(repo, status, revision)
 }
 
-func addUpdate(update fileUpdate, repo *Repository, batch rupture.FlushingBatch) error {
+func addUpdate(update fileUpdate, repo *Repository, ref string, batch rupture.FlushingBatch) error {
 	stdout, err := git.NewCommand("cat-file", "-s", update.BlobSha).
 		RunInDir(repo.RepoPath())
 	if err != nil {
@@ -212,19 +304,23 @@ func addUpdate(update fileUpdate, repo *Repository, batch rupture.FlushingBatch)
 		Filepath: update.Filename,
 		Op:       indexer.RepoIndexerOpUpdate,
 		Data: &indexer.RepoIndexerData{
-			RepoID:  repo.ID,
-			Content: string(fileContents),
+			RepoID:   repo.ID,
+			Ref:      ref,
+			Filename: update.Filename,
+			Language: indexer.FileLanguage(update.Filename),
+			Content:  string(fileContents),
 		},
 	}
 	return indexerUpdate.AddToFlushingBatch(batch)
 }
 
-func addDelete(filename string, repo *Repository, batch rupture.FlushingBatch) error {
+func addDelete(filename string, repo *Repository, ref string, batch rupture.FlushingBatch) error {
 	indexerUpdate := indexer.RepoIndexerUpdate{
 		Filepath: filename,
 		Op:       indexer.RepoIndexerOpDelete,
 		Data: &indexer.RepoIndexerData{
 			RepoID: repo.ID,
+			Ref:    ref,
 		},
 	}
 	return indexerUpdate.AddToFlushingBatch(batch)
@@ -259,15 +355,15 @@ func genesisChanges(repo *Repository, revision string) (*repoChanges, error) {
 }
 
 // nonGenesisChanges get changes since the previous indexer update
-func nonGenesisChanges(repo *Repository, revision string) (*repoChanges, error) {
+func nonGenesisChanges(repo *Repository, status *RepoIndexerStatus, revision string) (*repoChanges, error) {
 	diffCmd := git.NewCommand("diff", "--name-status",
-		repo.IndexerStatus.CommitSha, revision)
+		status.CommitSha, revision)
 	stdout, err := diffCmd.RunInDir(repo.RepoPath())
 	if err != nil {
 		// previous commit sha may have been removed by a force push, so
 		// try rebuilding from scratch
 		log.Warn("git diff: %v", err)
-		if err = indexer.DeleteRepoFromIndexer(repo.ID); err != nil {
+		if err = indexer.DeleteRefFromIndexer(repo.ID, status.Ref); err != nil {
 			return nil, err
 		}
 		return genesisChanges(repo, revision)
@@ -309,41 +405,37 @@ func nonGenesisChanges(repo *Repository, revision string) (*repoChanges, error)
 	return &changes, err
 }
 
-func processRepoIndexerOperationQueue() {
-	for {
-		op := <-repoIndexerOperationQueue
-		if op.deleted {
-			if err := indexer.DeleteRepoFromIndexer(op.repo.ID); err != nil {
+func processRepoIndexerQueue() {
+	repoIndexerQueue.Run(func(id string) {
+		repoID := com.StrTo(id).MustInt64()
+		repo, err := GetRepositoryByID(repoID)
+		if IsErrRepoNotExist(err) {
+			// the repository has been deleted
+			if err = indexer.DeleteRepoFromIndexer(repoID); err != nil {
 				log.Error(4, "DeleteRepoFromIndexer: %v", err)
 			}
-		} else {
-			if err := updateRepoIndexer(op.repo); err != nil {
-				log.Error(4, "updateRepoIndexer: %v", err)
-			}
+		} else if err != nil {
+			log.Error(4, "GetRepositoryByID: %v", err)
+		} else if err = updateRepoIndexer(repo); err != nil {
+			log.Error(4, "updateRepoIndexer: %v", err)
 		}
-	}
+	})
 }
 
-// DeleteRepoFromIndexer remove all of a repository's entries from the indexer
+// DeleteRepoFromIndexer remove all of a repository's entries from the
+// indexer, the repository must already be deleted
 func DeleteRepoFromIndexer(repo *Repository) {
-	addOperationToQueue(repoIndexerOperation{repo: repo, deleted: true})
+	addRepoToIndexerQueue(repo)
 }
 
 // UpdateRepoIndexer update a repository's entries in the indexer
 func UpdateRepoIndexer(repo *Repository) {
-	addOperationToQueue(repoIndexerOperation{repo: repo, deleted: false})
+	addRepoToIndexerQueue(repo)
 }
 
-func addOperationToQueue(op repoIndexerOperation) {
+func addRepoToIndexerQueue(repo *Repository) {
 	if !setting.Indexer.RepoIndexerEnabled {
 		return
 	}
-	select {
-	case repoIndexerOperationQueue <- op:
-		break
-	default:
-		go func() {
-			repoIndexerOperationQueue <- op
-		}()
-	}
+	go repoIndexerQueue.Add(repo.ID)
 }
@@ -0,0 +1,43 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_IsIndexedRef(t *testing.T) {
+	repo := &Repository{
+		DefaultBranch: "master",
+		IndexerRefs:   []string{"release/*", "refs/tags/v*"},
+	}
+	assert.True(t, repo.IsIndexedRef("refs/heads/master"))
+	assert.True(t, repo.IsIndexedRef("refs/heads/release/1.0"))
+	assert.True(t, repo.IsIndexedRef("refs/tags/release/1.0"))
+	assert.True(t, repo.IsIndexedRef("refs/tags/v1.0"))
+	assert.False(t, repo.IsIndexedRef("refs/heads/v1.0"))
+	assert.False(t, repo.IsIndexedRef("refs/heads/release/1.0/fix"))
+	assert.False(t, repo.IsIndexedRef("refs/heads/develop"))
+	assert.False(t, repo.IsIndexedRef("refs/pull/1/head"))
+
+	repo.IndexerRefs = nil
+	assert.True(t, repo.IsIndexedRef("refs/heads/master"))
+	assert.False(t, repo.IsIndexedRef("refs/heads/release/1.0"))
+}
+
+func TestRepository_UpdateIndexerRefs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, repo.UpdateIndexerRefs([]string{" release/* ", "", "v*"}))
+	assert.Equal(t, []string{"release/*", "v*"}, repo.IndexerRefs)
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.Equal(t, []string{"release/*", "v*"}, repo.IndexerRefs)
+
+	err := repo.UpdateIndexerRefs([]string{"release/["})
+	assert.True(t, IsErrInvalidIndexerRefPattern(err))
+}
@@ -131,6 +131,10 @@ type SearchRepoOptions struct {
 	// True -> include just mirrors
 	// False -> include just non-mirrors
 	Mirror util.OptionalBool
+	// None -> include archived AND non-archived
+	// True -> include just archived
+	// False -> include just non-archived
+	Archived util.OptionalBool
 	// only search topic name
 	TopicOnly bool
 }
@@ -231,6 +235,10 @@ func SearchRepositoryByName(opts *SearchRepoOptions) (RepositoryList, int64, err
 		cond = cond.And(builder.Eq{"is_mirror": opts.Mirror == util.OptionalBoolTrue})
 	}
 
+	if opts.Archived != util.OptionalBoolNone {
+		cond = cond.And(builder.Eq{"is_archived": opts.Archived == util.OptionalBoolTrue})
+	}
+
 	if len(opts.OrderBy) == 0 {
 		opts.OrderBy = SearchOrderByAlphabetically
 	}
@@ -288,3 +296,47 @@ func FindUserAccessibleRepoIDs(userID int64) ([]int64, error) {
 	}
 	return repoIDs, nil
 }
+
+// FindUserCodeAccessibleRepoIDs finds the IDs of the repositories whose code
+// the user can read, only those of the owner if ownerID is not zero. user is
+// nil for an anonymous visitor. As a site administrator can read every
+// repository, nil is returned for them if ownerID is zero.
+func FindUserCodeAccessibleRepoIDs(user *User, ownerID int64) ([]int64, error) {
+	if user != nil && user.IsAdmin {
+		if ownerID == 0 {
+			return nil, nil
+		}
+		repoIDs := make([]int64, 0, 10)
+		return repoIDs, x.Table("repository").Cols("id").Where("owner_id = ?", ownerID).Find(&repoIDs)
+	}
+
+	// The code of public repositories can be read by everyone, the code of
+	// private ones by their owner, their collaborators and the members of the
+	// teams the code unit is enabled for.
+	var accessCond builder.Cond = builder.Eq{"is_private": false}
+	if user != nil {
+		accessCond = accessCond.Or(
+			builder.Eq{"owner_id": user.ID},
+			builder.Expr("id IN (SELECT repo_id FROM `collaboration` WHERE collaboration.user_id = ?)", user.ID),
+			builder.Expr("id IN (SELECT team_repo.repo_id FROM `team_repo` "+
+				"INNER JOIN `team_user` ON team_user.team_id = team_repo.team_id "+
+				"INNER JOIN `team_unit` ON team_unit.team_id = team_repo.team_id "+
+				"WHERE team_user.uid = ? AND team_unit.`type` = ?)", user.ID, UnitTypeCode),
+		)
+	}
+	cond := builder.And(accessCond,
+		builder.Expr("id IN (SELECT repo_id FROM `repo_unit` WHERE repo_unit.`type` = ?)", UnitTypeCode))
+	if ownerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": ownerID})
+	}
+
+	repoIDs := make([]int64, 0, 10)
+	if err := x.
+		Table("repository").
+		Cols("id").
+		Where(cond).
+		Find(&repoIDs); err != nil {
+		return nil, fmt.Errorf("FindUserCodeAccessibleRepoIDs: %v", err)
+	}
+	return repoIDs, nil
+}
@@ -160,6 +160,12 @@ func TestSearchRepositoryByName(t *testing.T) {
 		{name: "AllPublic/PublicRepositoriesOfOrganization",
 			opts:  &SearchRepoOptions{Page: 1, PageSize: 10, OwnerID: 17, AllPublic: true, Collaborate: util.OptionalBoolFalse},
 			count: 19},
+		{name: "ArchivedRepositoriesOfUser",
+			opts:  &SearchRepoOptions{Page: 1, PageSize: 10, OwnerID: 15, Private: true, Collaborate: util.OptionalBoolFalse, Archived: util.OptionalBoolTrue},
+			count: 1},
+		{name: "NonArchivedRepositoriesOfUser",
+			opts:  &SearchRepoOptions{Page: 1, PageSize: 10, OwnerID: 15, Private: true, Collaborate: util.OptionalBoolFalse, Archived: util.OptionalBoolFalse},
+			count: 3},
 	}
 
 	for _, testCase := range testCases {
@@ -209,6 +215,13 @@ func TestSearchRepositoryByName(t *testing.T) {
 						}
 					}
 
+					switch testCase.opts.Archived {
+					case util.OptionalBoolFalse:
+						assert.False(t, repo.IsArchived)
+					case util.OptionalBoolTrue:
+						assert.True(t, repo.IsArchived)
+					}
+
 					if testCase.opts.OwnerID > 0 && !testCase.opts.AllPublic {
 						switch testCase.opts.Collaborate {
 						case util.OptionalBoolFalse:
@@ -250,3 +263,38 @@ func TestSearchRepositoryByTopicName(t *testing.T) {
 		})
 	}
 }
+
+func TestFindUserCodeAccessibleRepoIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	admin := AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	// an administrator can read every repository
+	repoIDs, err := FindUserCodeAccessibleRepoIDs(admin, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, repoIDs)
+
+	repoIDs, err = FindUserCodeAccessibleRepoIDs(admin, 2)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2, 15, 16, 31, 33}, repoIDs)
+
+	repoIDs, err = FindUserCodeAccessibleRepoIDs(user2, 0)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 2, 3, 4, 16, 23, 27, 31, 32, 33}, repoIDs)
+
+	repoIDs, err = FindUserCodeAccessibleRepoIDs(user2, 3)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{3, 32}, repoIDs)
+
+	// user20 reads the private repository 24 through team 9
+	user20 := AssertExistsAndLoadBean(t, &User{ID: 20}).(*User)
+	repoIDs, err = FindUserCodeAccessibleRepoIDs(user20, 17)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{23, 24}, repoIDs)
+
+	// anonymous visitors can only read public repositories
+	repoIDs, err = FindUserCodeAccessibleRepoIDs(nil, 0)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{1, 4, 23, 27, 32, 33}, repoIDs)
+}
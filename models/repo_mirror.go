@@ -23,8 +23,8 @@ import (
 	"gopkg.in/ini.v1"
 )
 
-// MirrorQueue holds an UniqueQueue object of the mirror
-var MirrorQueue = sync.NewUniqueQueue(setting.Repository.MirrorQueueLength)
+// MirrorQueue holds the queue of the mirrors to synchronize
+var MirrorQueue = sync.NewWorkQueue("mirror")
 
 // Mirror represents mirror information of a repository.
 type Mirror struct {
@@ -71,11 +71,15 @@ func (m *Mirror) ScheduleNextUpdate() {
 }
 
 func remoteAddress(repoPath string) (string, error) {
+	return remoteAddressByName(repoPath, "origin")
+}
+
+func remoteAddressByName(repoPath, remoteName string) (string, error) {
 	cfg, err := ini.Load(GitConfigPath(repoPath))
 	if err != nil {
 		return "", err
 	}
-	return cfg.Section("remote \"origin\"").Key("url").Value(), nil
+	return cfg.Section("remote \"" + remoteName + "\"").Key("url").Value(), nil
 }
 
 func (m *Mirror) readAddress() {
@@ -319,33 +323,47 @@ func MirrorUpdate() {
 		}); err != nil {
 		log.Error(4, "MirrorUpdate: %v", err)
 	}
+
+	if err := x.
+		Where("next_update_unix<=?", time.Now().Unix()).
+		And("next_update_unix!=0").
+		Iterate(new(PushMirror), func(idx int, bean interface{}) error {
+			m := bean.(*PushMirror)
+			if m.Repo == nil {
+				log.Error(4, "Disconnected push mirror repository found: %d", m.ID)
+				return nil
+			}
+
+			PushMirrorQueue.Add(m.ID)
+			return nil
+		}); err != nil {
+		log.Error(4, "MirrorUpdate: %v", err)
+	}
 }
 
 // SyncMirrors checks and syncs mirrors.
-// TODO: sync more mirrors at same time.
 func SyncMirrors() {
-	sess := x.NewSession()
-	defer sess.Close()
 	// Start listening on new sync requests.
-	for repoID := range MirrorQueue.Queue() {
+	MirrorQueue.Run(func(repoID string) {
 		log.Trace("SyncMirrors [repo_id: %v]", repoID)
-		MirrorQueue.Remove(repoID)
+		sess := x.NewSession()
+		defer sess.Close()
 
 		m, err := GetMirrorByRepoID(com.StrTo(repoID).MustInt64())
 		if err != nil {
 			log.Error(4, "GetMirrorByRepoID [%s]: %v", repoID, err)
-			continue
+			return
 		}
 
 		results, ok := m.runSync()
 		if !ok {
-			continue
+			return
 		}
 
 		m.ScheduleNextUpdate()
 		if err = updateMirror(sess, m); err != nil {
 			log.Error(4, "UpdateMirror [%s]: %v", repoID, err)
-			continue
+			return
 		}
 
 		var gitRepo *git.Repository
@@ -355,7 +373,7 @@ func SyncMirrors() {
 			gitRepo, err = git.OpenRepository(m.Repo.RepoPath())
 			if err != nil {
 				log.Error(2, "OpenRepository [%d]: %v", m.RepoID, err)
-				continue
+				return
 			}
 		}
 
@@ -412,17 +430,18 @@ func SyncMirrors() {
 		commitDate, err := git.GetLatestCommitTime(m.Repo.RepoPath())
 		if err != nil {
 			log.Error(2, "GetLatestCommitDate [%s]: %v", m.RepoID, err)
-			continue
+			return
 		}
 
 		if _, err = sess.Exec("UPDATE repository SET updated_unix = ? WHERE id = ?", commitDate.Unix(), m.RepoID); err != nil {
 			log.Error(2, "Update repository 'updated_unix' [%s]: %v", m.RepoID, err)
-			continue
+			return
 		}
-	}
+	})
 }
 
 // InitSyncMirrors initializes a go routine to sync the mirrors
 func InitSyncMirrors() {
 	go SyncMirrors()
+	go SyncPushMirrors()
 }
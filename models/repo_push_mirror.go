@@ -0,0 +1,232 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/Unknwon/com"
+	"github.com/go-xorm/xorm"
+)
+
+// PushMirrorQueue holds the queue of the push mirrors to synchronize
+var PushMirrorQueue = sync.NewWorkQueue("push_mirror")
+
+// PushMirror represents a remote Git repository the repository is pushed to.
+// The remote address, including any credentials, is stored in the Git config
+// of the repository under RemoteName.
+type PushMirror struct {
+	ID         int64       `xorm:"pk autoincr"`
+	RepoID     int64       `xorm:"INDEX"`
+	Repo       *Repository `xorm:"-"`
+	RemoteName string
+	Interval   time.Duration
+
+	CreatedUnix    util.TimeStamp `xorm:"created"`
+	LastUpdateUnix util.TimeStamp `xorm:"INDEX"`
+	NextUpdateUnix util.TimeStamp `xorm:"INDEX"`
+	LastError      string         `xorm:"TEXT"`
+
+	address string `xorm:"-"`
+}
+
+// AfterLoad is invoked from XORM after setting the values of all fields of this object.
+func (m *PushMirror) AfterLoad(session *xorm.Session) {
+	if m == nil {
+		return
+	}
+
+	var err error
+	m.Repo, err = getRepositoryByID(session, m.RepoID)
+	if err != nil {
+		log.Error(3, "getRepositoryByID[%d]: %v", m.ID, err)
+	}
+}
+
+// ScheduleNextUpdate calculates and sets next update time.
+func (m *PushMirror) ScheduleNextUpdate() {
+	if m.Interval != 0 {
+		m.NextUpdateUnix = util.TimeStampNow().AddDuration(m.Interval)
+	} else {
+		m.NextUpdateUnix = 0
+	}
+}
+
+func (m *PushMirror) readAddress() {
+	if len(m.address) > 0 {
+		return
+	}
+	var err error
+	m.address, err = remoteAddressByName(m.Repo.RepoPath(), m.RemoteName)
+	if err != nil {
+		log.Error(4, "remoteAddressByName: %v", err)
+	}
+}
+
+// Address returns the push mirror address from Git repository config without credentials.
+func (m *PushMirror) Address() string {
+	m.readAddress()
+	return util.SanitizeURLCredentials(m.address, false)
+}
+
+// IsSyncing returns true if the push mirror is waiting to be synchronized.
+func (m *PushMirror) IsSyncing() bool {
+	return PushMirrorQueue.Exist(m.ID)
+}
+
+// runSync pushes all references of the repository to the remote and returns
+// the sanitized error output if the push failed.
+func (m *PushMirror) runSync() error {
+	repoPath := m.Repo.RepoPath()
+	timeout := time.Duration(setting.Git.Timeout.Mirror) * time.Second
+
+	_, stderr, err := process.GetManager().ExecDir(
+		timeout, repoPath, fmt.Sprintf("PushMirror.runSync: %s", repoPath),
+		"git", "push", "--mirror", m.RemoteName)
+	if err != nil {
+		// sanitize the output, since it may contain the remote address, which may
+		// contain a password
+		m.readAddress()
+		return fmt.Errorf("%s", util.SanitizeMessage(stderr, m.address))
+	}
+	return nil
+}
+
+// CreatePushMirror adds a push mirror to the repository with the given remote address,
+// which may contain credentials.
+func CreatePushMirror(repo *Repository, address string, interval time.Duration) (_ *PushMirror, err error) {
+	m := &PushMirror{
+		RepoID:   repo.ID,
+		Repo:     repo,
+		Interval: interval,
+	}
+	m.ScheduleNextUpdate()
+
+	sess := x.NewSession()
+	defer sess.Close()
+	if err = sess.Begin(); err != nil {
+		return nil, err
+	}
+
+	if _, err = sess.Insert(m); err != nil {
+		return nil, err
+	}
+	m.RemoteName = fmt.Sprintf("push_mirror_%d", m.ID)
+	if _, err = sess.ID(m.ID).Cols("remote_name").Update(m); err != nil {
+		return nil, err
+	}
+	if err = sess.Commit(); err != nil {
+		return nil, err
+	}
+
+	// The remote is only added once the mirror is saved, so that no remote
+	// is left behind in the repository when the transaction fails.
+	repoPath := repo.RepoPath()
+	if _, stderr, err := process.GetManager().ExecDir(-1, repoPath,
+		fmt.Sprintf("CreatePushMirror (git remote add): %s", repoPath),
+		"git", "remote", "add", "--mirror=push", m.RemoteName, address); err != nil {
+		if _, err := x.ID(m.ID).Delete(new(PushMirror)); err != nil {
+			log.Error(4, "Delete push mirror [%d]: %v", m.ID, err)
+		}
+		return nil, fmt.Errorf("git remote add: %s", util.SanitizeMessage(stderr, address))
+	}
+
+	return m, nil
+}
+
+func getPushMirrorByID(e Engine, repoID, id int64) (*PushMirror, error) {
+	m := &PushMirror{ID: id, RepoID: repoID}
+	has, err := e.Get(m)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPushMirrorNotExist{id, repoID}
+	}
+	return m, nil
+}
+
+// GetPushMirrorByID returns the push mirror with given ID of the repository.
+func GetPushMirrorByID(repoID, id int64) (*PushMirror, error) {
+	return getPushMirrorByID(x, repoID, id)
+}
+
+// GetPushMirrorsByRepoID returns all push mirrors of a repository.
+func GetPushMirrorsByRepoID(repoID int64) ([]*PushMirror, error) {
+	mirrors := make([]*PushMirror, 0, 5)
+	return mirrors, x.Where("repo_id = ?", repoID).Asc("id").Find(&mirrors)
+}
+
+// UpdatePushMirror updates the push mirror
+func UpdatePushMirror(m *PushMirror) error {
+	_, err := x.ID(m.ID).AllCols().Update(m)
+	return err
+}
+
+// DeletePushMirror removes the push mirror and its remote from the repository.
+func DeletePushMirror(m *PushMirror) error {
+	// Read the address while the remote still exists, so that Address keeps
+	// returning it once the mirror is deleted.
+	m.readAddress()
+
+	repoPath := m.Repo.RepoPath()
+	if _, stderr, err := process.GetManager().ExecDir(-1, repoPath,
+		fmt.Sprintf("DeletePushMirror (git remote remove): %s", repoPath),
+		"git", "remote", "remove", m.RemoteName); err != nil {
+		log.Warn("git remote remove %s [%s]: %s", m.RemoteName, repoPath, stderr)
+	}
+
+	_, err := x.ID(m.ID).Delete(new(PushMirror))
+	return err
+}
+
+// AddPushMirrorSyncTasks queues all push mirrors of the repository for synchronization.
+func AddPushMirrorSyncTasks(repoID int64) {
+	mirrors, err := GetPushMirrorsByRepoID(repoID)
+	if err != nil {
+		log.Error(4, "GetPushMirrorsByRepoID [%d]: %v", repoID, err)
+		return
+	}
+	for _, m := range mirrors {
+		PushMirrorQueue.Add(m.ID)
+	}
+}
+
+// SyncPushMirrors pushes repositories to their push mirrors.
+func SyncPushMirrors() {
+	// Start listening on new sync requests.
+	PushMirrorQueue.Run(func(id string) {
+		log.Trace("SyncPushMirrors [push_mirror_id: %v]", id)
+
+		m := &PushMirror{ID: com.StrTo(id).MustInt64()}
+		has, err := x.Get(m)
+		if err != nil {
+			log.Error(4, "GetPushMirrorByID [%s]: %v", id, err)
+			return
+		} else if !has || m.Repo == nil {
+			return
+		}
+
+		if err = m.runSync(); err != nil {
+			desc := fmt.Sprintf("Failed to push repository '%s' to mirror '%s': %v", m.Repo.FullName(), m.Address(), err)
+			log.Error(4, desc)
+			m.LastError = err.Error()
+		} else {
+			m.LastError = ""
+		}
+
+		m.LastUpdateUnix = util.TimeStampNow()
+		m.ScheduleNextUpdate()
+		if _, err = x.ID(m.ID).Cols("last_update_unix", "next_update_unix", "last_error").Update(m); err != nil {
+			log.Error(4, "UpdatePushMirror [%s]: %v", id, err)
+		}
+	})
+}
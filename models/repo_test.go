@@ -96,6 +96,23 @@ func TestUpdateRepositoryVisibilityChanged(t *testing.T) {
 	assert.Equal(t, true, act.IsPrivate)
 }
 
+func TestSetArchiveRepoState(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.False(t, repo.IsArchived)
+	assert.True(t, repo.CanEnableEditor())
+
+	assert.NoError(t, repo.SetArchiveRepoState(true))
+	repo = AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.True(t, repo.IsArchived)
+	assert.False(t, repo.CanEnableEditor())
+	assert.False(t, repo.CanCreateBranch())
+
+	assert.NoError(t, repo.SetArchiveRepoState(false))
+	AssertExistsAndLoadBean(t, &Repository{ID: 1, IsArchived: false})
+}
+
 func TestGetUserFork(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -29,6 +29,8 @@ const (
 	ReviewTypeComment
 	// ReviewTypeReject gives feedback blocking merge
 	ReviewTypeReject
+	// ReviewTypeRequest asks the reviewer to review the pull request
+	ReviewTypeRequest
 )
 
 // Icon returns the corresponding icon for the review type
@@ -54,6 +56,8 @@ type Review struct {
 	Issue      *Issue `xorm:"-"`
 	IssueID    int64  `xorm:"index"`
 	Content    string
+	// Dismissed reviews no longer count as an approval or a rejection
+	Dismissed bool `xorm:"NOT NULL DEFAULT false"`
 
 	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
@@ -67,6 +71,16 @@ func (r *Review) loadCodeComments(e Engine) (err error) {
 	return
 }
 
+// GetCodeCommentsCount returns the number of code comments of the review
+func (r *Review) GetCodeCommentsCount() int {
+	count, err := x.Where("review_id = ? AND type = ?", r.ID, CommentTypeCode).Count(new(Comment))
+	if err != nil {
+		log.Error(4, "GetCodeCommentsCount: %v", err)
+		return 0
+	}
+	return int(count)
+}
+
 // LoadCodeComments loads CodeComments
 func (r *Review) LoadCodeComments() error {
 	return r.loadCodeComments(x)
@@ -256,6 +270,105 @@ func UpdateReview(r *Review) error {
 	return nil
 }
 
+// SubmitReview publishes the pending review of doer for issue, or creates a new
+// review if there is none, and withdraws the review requested from doer
+func SubmitReview(doer *User, issue *Issue, reviewType ReviewType, content string) (*Review, *Comment, error) {
+	if reviewType == ReviewTypePending || reviewType == ReviewTypeRequest || reviewType == ReviewTypeUnknown {
+		return nil, nil, fmt.Errorf("review cannot be submitted with type %d", reviewType)
+	}
+	if err := issue.loadRepo(x); err != nil {
+		return nil, nil, err
+	}
+
+	review, err := getCurrentReview(x, doer, issue)
+	if err != nil {
+		if !IsErrReviewNotExist(err) {
+			return nil, nil, err
+		}
+		if review, err = createReview(x, CreateReviewOptions{
+			Type:     reviewType,
+			Issue:    issue,
+			Reviewer: doer,
+			Content:  content,
+		}); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		review.Content = content
+		review.Type = reviewType
+		if err = UpdateReview(review); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	comm, err := CreateComment(&CreateCommentOptions{
+		Type:     CommentTypeReview,
+		Doer:     doer,
+		Content:  review.Content,
+		Issue:    issue,
+		Repo:     issue.Repo,
+		ReviewID: review.ID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = removeReviewRequest(x, issue, doer); err != nil {
+		return nil, nil, err
+	}
+	if err = review.Publish(); err != nil {
+		return nil, nil, err
+	}
+	return review, comm, nil
+}
+
+// AddReviewRequest requests reviewer to review the pull request issue, the
+// existing request is returned if the review was requested already
+func AddReviewRequest(issue *Issue, reviewer *User) (*Review, error) {
+	reviews, err := findReviews(x, FindReviewOptions{
+		Type:       ReviewTypeRequest,
+		IssueID:    issue.ID,
+		ReviewerID: reviewer.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(reviews) > 0 {
+		reviews[0].Issue = issue
+		reviews[0].Reviewer = reviewer
+		return reviews[0], nil
+	}
+	return createReview(x, CreateReviewOptions{
+		Type:     ReviewTypeRequest,
+		Issue:    issue,
+		Reviewer: reviewer,
+	})
+}
+
+func removeReviewRequest(e Engine, issue *Issue, reviewer *User) error {
+	_, err := e.Delete(&Review{
+		Type:       ReviewTypeRequest,
+		IssueID:    issue.ID,
+		ReviewerID: reviewer.ID,
+	})
+	return err
+}
+
+// RemoveReviewRequest withdraws the review requested from reviewer
+func RemoveReviewRequest(issue *Issue, reviewer *User) error {
+	return removeReviewRequest(x, issue, reviewer)
+}
+
+// DismissReview dismisses an approval or a rejection, so that it is no longer
+// taken into account for the pull request
+func DismissReview(r *Review) error {
+	if r.Type != ReviewTypeApprove && r.Type != ReviewTypeReject {
+		return ErrReviewNotDismissable{ID: r.ID, Type: r.Type}
+	}
+	r.Dismissed = true
+	_, err := x.ID(r.ID).Cols("dismissed").Update(r)
+	return err
+}
+
 // PullReviewersWithType represents the type used to display a review overview
 type PullReviewersWithType struct {
 	User              `xorm:"extends"`
@@ -269,7 +382,7 @@ func GetReviewersByPullID(pullID int64) (issueReviewers []*PullReviewersWithType
 	err = x.Select("`user`.*, review.type, max(review.updated_unix) as review_updated_unix").
 		Table("review").
 		Join("INNER", "`user`", "review.reviewer_id = `user`.id").
-		Where("review.issue_id = ? AND (review.type = ? OR review.type = ?)", pullID, ReviewTypeApprove, ReviewTypeReject).
+		Where("review.issue_id = ? AND (review.type = ? OR review.type = ?) AND review.dismissed = ?", pullID, ReviewTypeApprove, ReviewTypeReject, false).
 		GroupBy("`user`.id, review.type").
 		OrderBy("review_updated_unix DESC").
 		Find(&irs)
@@ -135,3 +135,58 @@ func TestGetReviewersByPullID(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedReviews, allReviews)
 }
+
+func TestSubmitReview(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 3}).(*Issue)
+	user2 := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	_, err := AddReviewRequest(issue, user2)
+	assert.NoError(t, err)
+
+	review, comment, err := SubmitReview(user2, issue, ReviewTypeComment, "Submitted review")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, review.ID)
+	assert.Equal(t, ReviewTypeComment, review.Type)
+	assert.Equal(t, CommentTypeReview, comment.Type)
+	AssertExistsAndLoadBean(t, &Review{ID: 6, Type: ReviewTypeComment, Content: "Submitted review"})
+	AssertNotExistsBean(t, &Review{IssueID: issue.ID, ReviewerID: user2.ID, Type: ReviewTypeRequest})
+
+	_, _, err = SubmitReview(user2, issue, ReviewTypePending, "")
+	assert.Error(t, err)
+}
+
+func TestAddRemoveReviewRequest(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	issue := AssertExistsAndLoadBean(t, &Issue{ID: 2}).(*Issue)
+	user4 := AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+
+	review, err := AddReviewRequest(issue, user4)
+	assert.NoError(t, err)
+	assert.Equal(t, ReviewTypeRequest, review.Type)
+
+	review2, err := AddReviewRequest(issue, user4)
+	assert.NoError(t, err)
+	assert.Equal(t, review.ID, review2.ID)
+
+	assert.NoError(t, RemoveReviewRequest(issue, user4))
+	AssertNotExistsBean(t, &Review{ID: review.ID})
+	assert.NoError(t, RemoveReviewRequest(issue, user4))
+}
+
+func TestDismissReview(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	review := AssertExistsAndLoadBean(t, &Review{ID: 8}).(*Review)
+	assert.NoError(t, DismissReview(review))
+	AssertExistsAndLoadBean(t, &Review{ID: 8, Dismissed: true})
+
+	reviewers, err := GetReviewersByPullID(3)
+	assert.NoError(t, err)
+	for _, reviewer := range reviewers {
+		assert.NotEqual(t, int64(4), reviewer.ID)
+	}
+
+	pending := AssertExistsAndLoadBean(t, &Review{ID: 6}).(*Review)
+	err = DismissReview(pending)
+	assert.True(t, IsErrReviewNotDismissable(err))
+}
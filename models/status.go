@@ -157,6 +157,22 @@ func GetLatestCommitStatus(repo *Repository, sha string, page int) ([]*CommitSta
 	return statuses, x.In("id", ids).Find(&statuses)
 }
 
+// GetLatestCommitStatusByContext returns the latest status of every context for a given commit.
+func GetLatestCommitStatusByContext(repo *Repository, sha string) (map[string]*CommitStatus, error) {
+	statuses := make([]*CommitStatus, 0, 10)
+	if err := x.Where("repo_id = ?", repo.ID).And("sha = ?", sha).Desc("id").Find(&statuses); err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]*CommitStatus, len(statuses))
+	for _, status := range statuses {
+		if _, ok := latest[status.Context]; !ok {
+			latest[status.Context] = status
+		}
+	}
+	return latest, nil
+}
+
 // GetCommitStatus populates a given status for a given commit.
 // NOTE: If ID or Index isn't given, and only Context, TargetURL and/or Description
 //       is given, the CommitStatus created _last_ will be returned.
@@ -5,14 +5,107 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	gouuid "github.com/satori/go.uuid"
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/util"
+
+	"github.com/Unknwon/com"
+)
+
+// AccessTokenScope is a comma separated list of the scopes granted to an access token
+type AccessTokenScope string
+
+// Access token scopes
+const (
+	AccessTokenScopeAll          AccessTokenScope = "all"
+	AccessTokenScopeRepoRead     AccessTokenScope = "repo:read"
+	AccessTokenScopeRepoWrite    AccessTokenScope = "repo:write"
+	AccessTokenScopeAdminOrg     AccessTokenScope = "admin:org"
+	AccessTokenScopeIssue        AccessTokenScope = "issue"
+	AccessTokenScopePackage      AccessTokenScope = "package"
+	AccessTokenScopeUserEmail    AccessTokenScope = "user:email"
+	AccessTokenScopeNotification AccessTokenScope = "notification"
+	AccessTokenScopeSudo         AccessTokenScope = "sudo"
+	// AccessTokenScopeNone grants no API access, it is given to the OAuth2
+	// applications which only request the identity of the user
+	AccessTokenScopeNone AccessTokenScope = "none"
 )
 
+// AccessTokenScopes contains all the scopes an access token can be restricted to
+var AccessTokenScopes = []AccessTokenScope{
+	AccessTokenScopeRepoRead,
+	AccessTokenScopeRepoWrite,
+	AccessTokenScopeAdminOrg,
+	AccessTokenScopeIssue,
+	AccessTokenScopePackage,
+	AccessTokenScopeUserEmail,
+	AccessTokenScopeNotification,
+	AccessTokenScopeSudo,
+}
+
+// ParseAccessTokenScope validates the given scope names and returns them as
+// an AccessTokenScope. No scope at all grants full access.
+func ParseAccessTokenScope(names []string) (AccessTokenScope, error) {
+	scopes := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+		if AccessTokenScope(name) == AccessTokenScopeAll {
+			return AccessTokenScopeAll, nil
+		}
+
+		var valid bool
+		for _, scope := range AccessTokenScopes {
+			if AccessTokenScope(name) == scope {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return "", ErrAccessTokenScopeInvalid{name}
+		}
+		if !com.IsSliceContainsStr(scopes, name) {
+			scopes = append(scopes, name)
+		}
+	}
+
+	if len(scopes) == 0 {
+		return AccessTokenScopeAll, nil
+	}
+	return AccessTokenScope(strings.Join(scopes, ",")), nil
+}
+
+// Scopes returns the list of scopes
+func (s AccessTokenScope) Scopes() []AccessTokenScope {
+	if len(s) == 0 {
+		return nil
+	}
+	names := strings.Split(string(s), ",")
+	scopes := make([]AccessTokenScope, len(names))
+	for i := range names {
+		scopes[i] = AccessTokenScope(names[i])
+	}
+	return scopes
+}
+
+// Has returns true if the scope is granted. The write scope on repositories
+// implies the read scope.
+func (s AccessTokenScope) Has(scope AccessTokenScope) bool {
+	for _, granted := range s.Scopes() {
+		if granted == AccessTokenScopeAll || granted == scope ||
+			(granted == AccessTokenScopeRepoWrite && scope == AccessTokenScopeRepoRead) {
+			return true
+		}
+	}
+	return false
+}
+
 // AccessToken represents a personal access token.
 type AccessToken struct {
 	ID   int64 `xorm:"pk autoincr"`
@@ -20,10 +113,15 @@ type AccessToken struct {
 	Name string
 	Sha1 string `xorm:"UNIQUE VARCHAR(40)"`
 
+	Scope   AccessTokenScope `xorm:"VARCHAR(255) NOT NULL DEFAULT 'all'"`
+	RepoIDs []int64          `xorm:"TEXT JSON"`
+	Repos   []*Repository    `xorm:"-"`
+
 	CreatedUnix       util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       util.TimeStamp `xorm:"INDEX updated"`
-	HasRecentActivity bool           `xorm:"-"`
-	HasUsed           bool           `xorm:"-"`
+	ExpiresUnix       util.TimeStamp
+	HasRecentActivity bool `xorm:"-"`
+	HasUsed           bool `xorm:"-"`
 }
 
 // AfterLoad is invoked from XORM after setting the values of all fields of this object.
@@ -32,9 +130,129 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > util.TimeStampNow()
 }
 
+// IsExpired returns true if the token has an expiry date which has passed.
+func (t *AccessToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= util.TimeStampNow()
+}
+
+// IsRepoRestricted returns true if the token only grants access to some repositories.
+func (t *AccessToken) IsRepoRestricted() bool {
+	return len(t.RepoIDs) > 0
+}
+
+// CanAccessRepo returns true if the token is not restricted to other repositories.
+func (t *AccessToken) CanAccessRepo(repoID int64) bool {
+	if !t.IsRepoRestricted() {
+		return true
+	}
+	for _, id := range t.RepoIDs {
+		if id == repoID {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadRepos loads the repositories the token is restricted to.
+func (t *AccessToken) LoadRepos() error {
+	if !t.IsRepoRestricted() || t.Repos != nil {
+		return nil
+	}
+
+	repos, err := GetRepositoriesMapByIDs(t.RepoIDs)
+	if err != nil {
+		return err
+	}
+	t.Repos = make([]*Repository, 0, len(repos))
+	for _, id := range t.RepoIDs {
+		if repo, ok := repos[id]; ok {
+			t.Repos = append(t.Repos, repo)
+		}
+	}
+	return nil
+}
+
+// LimitRepoPermission restricts the permission of the token owner on the
+// repository to what the token allows. The repository scopes cap the access to
+// all units, and the issue scope grants write access to the issues and the
+// projects units.
+// Administering a repository requires a token with full access.
+func (t *AccessToken) LimitRepoPermission(repo *Repository, perm Permission) Permission {
+	if !t.CanAccessRepo(repo.ID) {
+		return Permission{UnitsMode: make(map[UnitType]AccessMode)}
+	} else if t.Scope.Has(AccessTokenScopeAll) {
+		return perm
+	}
+
+	maxMode := AccessModeNone
+	if t.Scope.Has(AccessTokenScopeRepoWrite) {
+		maxMode = AccessModeWrite
+	} else if t.Scope.Has(AccessTokenScopeRepoRead) {
+		maxMode = AccessModeRead
+	}
+
+	limited := Permission{
+		AccessMode: perm.AccessMode,
+		UnitsMode:  make(map[UnitType]AccessMode),
+	}
+	if limited.AccessMode > maxMode {
+		limited.AccessMode = maxMode
+	}
+
+	for _, u := range perm.Units {
+		unitMaxMode := maxMode
+		if (u.Type == UnitTypeIssues || u.Type == UnitTypeProjects) && t.Scope.Has(AccessTokenScopeIssue) {
+			unitMaxMode = AccessModeWrite
+		}
+
+		mode := perm.UnitAccessMode(u.Type)
+		if mode > unitMaxMode {
+			mode = unitMaxMode
+		}
+		if mode > AccessModeNone {
+			limited.UnitsMode[u.Type] = mode
+			limited.Units = append(limited.Units, u)
+		}
+	}
+	return limited
+}
+
+// GetAccessTokenRepoIDs returns the IDs of the repositories with the given
+// full names, all of which the user must have access to.
+func GetAccessTokenRepoIDs(u *User, fullNames []string) ([]int64, error) {
+	ids := make([]int64, 0, len(fullNames))
+	for _, fullName := range fullNames {
+		fullName = strings.TrimSpace(fullName)
+		if len(fullName) == 0 {
+			continue
+		}
+
+		parts := strings.SplitN(fullName, "/", 2)
+		if len(parts) != 2 {
+			return nil, ErrRepoNotExist{0, 0, "", fullName}
+		}
+		repo, err := GetRepositoryByOwnerAndName(parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		perm, err := GetUserRepoPermission(repo, u)
+		if err != nil {
+			return nil, err
+		} else if !perm.HasAccess() {
+			return nil, ErrRepoNotExist{0, 0, parts[0], parts[1]}
+		}
+		ids = append(ids, repo.ID)
+	}
+	return ids, nil
+}
+
 // NewAccessToken creates new access token.
 func NewAccessToken(t *AccessToken) error {
 	t.Sha1 = base.EncodeSha1(gouuid.NewV4().String())
+	if len(t.Scope) == 0 {
+		t.Scope = AccessTokenScopeAll
+	}
 	_, err := x.Insert(t)
 	return err
 }
@@ -7,6 +7,8 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -91,3 +93,88 @@ func TestDeleteAccessTokenByID(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, IsErrAccessTokenNotExist(err))
 }
+
+func TestParseAccessTokenScope(t *testing.T) {
+	scope, err := ParseAccessTokenScope(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTokenScopeAll, scope)
+
+	scope, err = ParseAccessTokenScope([]string{"repo:read", " issue", "repo:read", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTokenScope("repo:read,issue"), scope)
+
+	scope, err = ParseAccessTokenScope([]string{"repo:read", "all"})
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTokenScopeAll, scope)
+
+	_, err = ParseAccessTokenScope([]string{"repo:delete"})
+	assert.True(t, IsErrAccessTokenScopeInvalid(err))
+}
+
+func TestAccessTokenScope_Has(t *testing.T) {
+	assert.True(t, AccessTokenScopeAll.Has(AccessTokenScopeSudo))
+	assert.True(t, AccessTokenScopeRepoWrite.Has(AccessTokenScopeRepoRead))
+	assert.False(t, AccessTokenScopeRepoRead.Has(AccessTokenScopeRepoWrite))
+	assert.True(t, AccessTokenScope("issue,user:email").Has(AccessTokenScopeUserEmail))
+	assert.False(t, AccessTokenScope("issue,user:email").Has(AccessTokenScopeAll))
+}
+
+func TestAccessToken_IsExpired(t *testing.T) {
+	token := &AccessToken{}
+	assert.False(t, token.IsExpired())
+	token.ExpiresUnix = util.TimeStampNow().Add(3600)
+	assert.False(t, token.IsExpired())
+	token.ExpiresUnix = util.TimeStampNow().Add(-3600)
+	assert.True(t, token.IsExpired())
+}
+
+func TestAccessToken_LimitRepoPermission(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	perm, err := GetUserRepoPermission(repo, user)
+	assert.NoError(t, err)
+	assert.True(t, perm.IsOwner())
+
+	token := &AccessToken{Scope: AccessTokenScopeAll}
+	limited := token.LimitRepoPermission(repo, perm)
+	assert.True(t, limited.IsOwner())
+
+	token = &AccessToken{Scope: AccessTokenScopeRepoRead}
+	limited = token.LimitRepoPermission(repo, perm)
+	assert.False(t, limited.IsAdmin())
+	assert.True(t, limited.CanRead(UnitTypeCode))
+	assert.False(t, limited.CanWrite(UnitTypeCode))
+	assert.False(t, limited.CanWrite(UnitTypeIssues))
+
+	token = &AccessToken{Scope: "repo:read,issue"}
+	limited = token.LimitRepoPermission(repo, perm)
+	assert.False(t, limited.CanWrite(UnitTypeCode))
+	assert.True(t, limited.CanWrite(UnitTypeIssues))
+	assert.True(t, limited.CanWrite(UnitTypeProjects))
+
+	token = &AccessToken{Scope: AccessTokenScopeRepoWrite}
+	limited = token.LimitRepoPermission(repo, perm)
+	assert.True(t, limited.CanWrite(UnitTypeCode))
+	assert.False(t, limited.IsAdmin())
+
+	token = &AccessToken{Scope: AccessTokenScopeAll, RepoIDs: []int64{2}}
+	limited = token.LimitRepoPermission(repo, perm)
+	assert.False(t, limited.HasAccess())
+}
+
+func TestGetAccessTokenRepoIDs(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	ids, err := GetAccessTokenRepoIDs(user, []string{"user2/repo1", " "})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, ids)
+
+	_, err = GetAccessTokenRepoIDs(user, []string{"user2/repo1", "user2/notexist"})
+	assert.True(t, IsErrRepoNotExist(err))
+
+	_, err = GetAccessTokenRepoIDs(user, []string{"repo1"})
+	assert.True(t, IsErrRepoNotExist(err))
+}
@@ -20,6 +20,7 @@ const (
 	UnitTypeWiki                                // 5 Wiki
 	UnitTypeExternalWiki                        // 6 ExternalWiki
 	UnitTypeExternalTracker                     // 7 ExternalTracker
+	UnitTypeProjects                            // 8 Projects
 )
 
 var (
@@ -32,6 +33,7 @@ var (
 		UnitTypeWiki,
 		UnitTypeExternalWiki,
 		UnitTypeExternalTracker,
+		UnitTypeProjects,
 	}
 
 	// defaultRepoUnits contains the default unit types
@@ -41,6 +43,7 @@ var (
 		UnitTypePullRequests,
 		UnitTypeReleases,
 		UnitTypeWiki,
+		UnitTypeProjects,
 	}
 
 	// MustRepoUnits contains the units could not be disabled currently
@@ -130,6 +133,14 @@ var (
 		4,
 	}
 
+	UnitProjects = Unit{
+		UnitTypeProjects,
+		"repo.projects",
+		"/projects",
+		"repo.projects.desc",
+		5,
+	}
+
 	// Units contains all the units
 	Units = map[UnitType]Unit{
 		UnitTypeCode:            UnitCode,
@@ -139,6 +150,7 @@ var (
 		UnitTypeReleases:        UnitReleases,
 		UnitTypeWiki:            UnitWiki,
 		UnitTypeExternalWiki:    UnitExternalWiki,
+		UnitTypeProjects:        UnitProjects,
 	}
 )
 
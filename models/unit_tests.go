@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
 	"github.com/Unknwon/com"
 	"github.com/go-xorm/core"
@@ -57,6 +58,12 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 	if err != nil {
 		fatalTestError("TempDir: %v\n", err)
 	}
+	// Keep the authorized_keys file written by the SSH key tests out of the
+	// source tree.
+	setting.SSH.RootPath, err = ioutil.TempDir(os.TempDir(), "ssh")
+	if err != nil {
+		fatalTestError("TempDir: %v\n", err)
+	}
 	setting.AppWorkPath = pathToGiteaRoot
 	setting.StaticRootPath = pathToGiteaRoot
 	setting.GravatarSourceURL, err = url.Parse("https://secure.gravatar.com/avatar/")
@@ -64,6 +71,13 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 		fatalTestError("url.Parse: %v\n", err)
 	}
 
+	setting.AttachmentStorage = setting.Storage{Type: setting.LocalStorageType, Path: filepath.Join(setting.AppDataPath, "attachments")}
+	setting.LFSStorage = setting.Storage{Type: setting.LocalStorageType, Path: filepath.Join(setting.AppDataPath, "lfs")}
+	setting.AvatarStorage = setting.Storage{Type: setting.LocalStorageType, Path: filepath.Join(setting.AppDataPath, "avatars")}
+	if err = storage.Init(); err != nil {
+		fatalTestError("storage.Init: %v\n", err)
+	}
+
 	exitStatus := m.Run()
 	if err = removeAllWithRetry(setting.RepoRootPath); err != nil {
 		fatalTestError("os.RemoveAll: %v\n", err)
@@ -71,6 +85,9 @@ func MainTest(m *testing.M, pathToGiteaRoot string) {
 	if err = removeAllWithRetry(setting.AppDataPath); err != nil {
 		fatalTestError("os.RemoveAll: %v\n", err)
 	}
+	if err = removeAllWithRetry(setting.SSH.RootPath); err != nil {
+		fatalTestError("os.RemoveAll: %v\n", err)
+	}
 	os.Exit(exitStatus)
 }
 
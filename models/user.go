@@ -39,6 +39,7 @@ import (
 	"code.gitea.io/gitea/modules/generate"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
 )
 
@@ -287,9 +288,9 @@ func (u *User) GenerateActivateCode() string {
 	return u.GenerateEmailActivateCode(u.Email)
 }
 
-// CustomAvatarPath returns user custom avatar file path.
-func (u *User) CustomAvatarPath() string {
-	return filepath.Join(setting.AvatarUploadPath, u.Avatar)
+// CustomAvatarRelativePath returns user custom avatar relative path.
+func (u *User) CustomAvatarRelativePath() string {
+	return u.Avatar
 }
 
 // GenerateRandomAvatar generates a random avatar for user.
@@ -312,23 +313,18 @@ func (u *User) generateRandomAvatar(e Engine) error {
 	if u.Avatar == "" {
 		u.Avatar = fmt.Sprintf("%d", u.ID)
 	}
-	if err = os.MkdirAll(filepath.Dir(u.CustomAvatarPath()), os.ModePerm); err != nil {
-		return fmt.Errorf("MkdirAll: %v", err)
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("Encode: %v", err)
 	}
-	fw, err := os.Create(u.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("Create: %v", err)
+	if _, err = storage.Avatars.Save(u.CustomAvatarRelativePath(), &buf, int64(buf.Len())); err != nil {
+		return fmt.Errorf("Save: %v", err)
 	}
-	defer fw.Close()
 
 	if _, err := e.ID(u.ID).Cols("avatar").Update(u); err != nil {
 		return err
 	}
 
-	if err = png.Encode(fw, img); err != nil {
-		return fmt.Errorf("Encode: %v", err)
-	}
-
 	log.Info("New random avatar created: %d", u.ID)
 	return nil
 }
@@ -342,12 +338,22 @@ func (u *User) SizedRelAvatarLink(size int) string {
 
 	switch {
 	case u.UseCustomAvatar:
-		if !com.IsFile(u.CustomAvatarPath()) {
+		if u.Avatar == "" {
 			return base.DefaultAvatarLink()
 		}
 		return setting.AppSubURL + "/avatars/" + u.Avatar
 	case setting.DisableGravatar, setting.OfflineMode:
-		if !com.IsFile(u.CustomAvatarPath()) {
+		// the random avatar is generated again when it is missing from the
+		// storage, e.g. after moving to another one
+		missing := u.Avatar == ""
+		if !missing {
+			if _, err := storage.Avatars.Stat(u.CustomAvatarRelativePath()); os.IsNotExist(err) {
+				missing = true
+			} else if err != nil {
+				log.Error(3, "Stat avatar [%d]: %v", u.ID, err)
+			}
+		}
+		if missing {
 			if err := u.GenerateRandomAvatar(); err != nil {
 				log.Error(3, "GenerateRandomAvatar: %v", err)
 			}
@@ -462,18 +468,13 @@ func (u *User) UploadAvatar(data []byte) error {
 		return fmt.Errorf("updateUser: %v", err)
 	}
 
-	if err := os.MkdirAll(setting.AvatarUploadPath, os.ModePerm); err != nil {
-		return fmt.Errorf("Failed to create dir %s: %v", setting.AvatarUploadPath, err)
-	}
-
-	fw, err := os.Create(u.CustomAvatarPath())
-	if err != nil {
-		return fmt.Errorf("Create: %v", err)
+	var buf bytes.Buffer
+	if err = png.Encode(&buf, m); err != nil {
+		return fmt.Errorf("Encode: %v", err)
 	}
-	defer fw.Close()
 
-	if err = png.Encode(fw, m); err != nil {
-		return fmt.Errorf("Encode: %v", err)
+	if _, err = storage.Avatars.Save(u.CustomAvatarRelativePath(), &buf, int64(buf.Len())); err != nil {
+		return fmt.Errorf("Failed to create avatar %s: %v", u.CustomAvatarRelativePath(), err)
 	}
 
 	return sess.Commit()
@@ -481,10 +482,10 @@ func (u *User) UploadAvatar(data []byte) error {
 
 // DeleteAvatar deletes the user's custom avatar.
 func (u *User) DeleteAvatar() error {
-	log.Trace("DeleteAvatar[%d]: %s", u.ID, u.CustomAvatarPath())
+	log.Trace("DeleteAvatar[%d]: %s", u.ID, u.CustomAvatarRelativePath())
 	if len(u.Avatar) > 0 {
-		if err := os.Remove(u.CustomAvatarPath()); err != nil {
-			return fmt.Errorf("Failed to remove %s: %v", u.CustomAvatarPath(), err)
+		if err := storage.Avatars.Delete(u.CustomAvatarRelativePath()); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to remove %s: %v", u.CustomAvatarRelativePath(), err)
 		}
 	}
 
@@ -1015,10 +1016,15 @@ func deleteUser(e *xorm.Session, u *User) error {
 		&EmailAddress{UID: u.ID},
 		&UserOpenID{UID: u.ID},
 		&Reaction{UserID: u.ID},
+		&OAuth2Grant{UserID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err = deleteOAuth2ApplicationsByUserID(e, u.ID); err != nil {
+		return fmt.Errorf("deleteOAuth2ApplicationsByUserID: %v", err)
+	}
+
 	// ***** START: PublicKey *****
 	keys := make([]*PublicKey, 0, 10)
 	if err = e.Find(&keys, &PublicKey{OwnerID: u.ID}); err != nil {
@@ -1059,11 +1065,9 @@ func deleteUser(e *xorm.Session, u *User) error {
 	}
 
 	if len(u.Avatar) > 0 {
-		avatarPath := u.CustomAvatarPath()
-		if com.IsExist(avatarPath) {
-			if err := os.Remove(avatarPath); err != nil {
-				return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
-			}
+		avatarPath := u.CustomAvatarRelativePath()
+		if err := storage.Avatars.Delete(avatarPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("Failed to remove %s: %v", avatarPath, err)
 		}
 	}
 
@@ -0,0 +1,104 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+// UserNotificationSetting represents how a user is notified for a reason,
+// a missing setting means that both deliveries are enabled
+type UserNotificationSetting struct {
+	ID     int64              `xorm:"pk autoincr"`
+	UserID int64              `xorm:"UNIQUE(s) NOT NULL"`
+	Reason NotificationReason `xorm:"SMALLINT UNIQUE(s) NOT NULL"`
+	Web    bool               `xorm:"NOT NULL DEFAULT true"`
+	Email  bool               `xorm:"NOT NULL DEFAULT true"`
+}
+
+func getUserNotificationSetting(e Engine, userID int64, reason NotificationReason) (*UserNotificationSetting, error) {
+	setting := &UserNotificationSetting{UserID: userID, Reason: reason}
+	has, err := e.Get(setting)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		setting.Web = true
+		setting.Email = true
+	}
+	return setting, nil
+}
+
+// GetUserNotificationSettings returns the notification settings of a user
+// for every reason
+func GetUserNotificationSettings(userID int64) ([]*UserNotificationSetting, error) {
+	existing := make([]*UserNotificationSetting, 0, len(NotificationReasons))
+	if err := x.Where("user_id = ?", userID).Find(&existing); err != nil {
+		return nil, err
+	}
+
+	settings := make([]*UserNotificationSetting, 0, len(NotificationReasons))
+	for _, reason := range NotificationReasons {
+		setting := &UserNotificationSetting{
+			UserID: userID,
+			Reason: reason,
+			Web:    true,
+			Email:  true,
+		}
+		for _, s := range existing {
+			if s.Reason == reason {
+				setting = s
+				break
+			}
+		}
+		settings = append(settings, setting)
+	}
+	return settings, nil
+}
+
+// UpdateUserNotificationSettings creates or updates the notification settings
+// of a user
+func UpdateUserNotificationSettings(userID int64, settings []*UserNotificationSetting) error {
+	sess := x.NewSession()
+	defer sess.Close()
+	if err := sess.Begin(); err != nil {
+		return err
+	}
+
+	for _, setting := range settings {
+		setting.UserID = userID
+		existing := &UserNotificationSetting{UserID: userID, Reason: setting.Reason}
+		has, err := sess.Get(existing)
+		if err != nil {
+			return err
+		}
+		if has {
+			setting.ID = existing.ID
+			_, err = sess.ID(setting.ID).Cols("web", "email").Update(setting)
+		} else {
+			_, err = sess.Insert(setting)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return sess.Commit()
+}
+
+// isWebNotificationEnabled returns true if the user wants notifications
+// for the given reason in the web interface
+func isWebNotificationEnabled(e Engine, userID int64, reason NotificationReason) (bool, error) {
+	setting, err := getUserNotificationSetting(e, userID, reason)
+	if err != nil {
+		return false, err
+	}
+	return setting.Web, nil
+}
+
+// isEmailNotificationEnabled returns true if the user wants notifications
+// for the given reason by email
+func isEmailNotificationEnabled(e Engine, userID int64, reason NotificationReason) (bool, error) {
+	setting, err := getUserNotificationSetting(e, userID, reason)
+	if err != nil {
+		return false, err
+	}
+	return setting.Email, nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetUserNotificationSettings(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	settings, err := GetUserNotificationSettings(2)
+	assert.NoError(t, err)
+	if assert.Len(t, settings, len(NotificationReasons)) {
+		for i, setting := range settings {
+			assert.Equal(t, NotificationReasons[i], setting.Reason)
+			assert.True(t, setting.Web)
+			assert.True(t, setting.Email)
+		}
+	}
+}
+
+func TestUpdateUserNotificationSettings(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	assert.NoError(t, UpdateUserNotificationSettings(2, []*UserNotificationSetting{
+		{Reason: NotificationReasonMention, Web: false, Email: true},
+		{Reason: NotificationReasonRelease, Web: true, Email: false},
+	}))
+	AssertExistsAndLoadBean(t, &UserNotificationSetting{UserID: 2, Reason: NotificationReasonMention})
+
+	// an existing setting is updated
+	assert.NoError(t, UpdateUserNotificationSettings(2, []*UserNotificationSetting{
+		{Reason: NotificationReasonMention, Web: true, Email: false},
+	}))
+	settings, err := GetUserNotificationSettings(2)
+	assert.NoError(t, err)
+	for _, setting := range settings {
+		switch setting.Reason {
+		case NotificationReasonMention:
+			assert.True(t, setting.Web)
+			assert.False(t, setting.Email)
+		case NotificationReasonRelease:
+			assert.True(t, setting.Web)
+			assert.False(t, setting.Email)
+		default:
+			assert.True(t, setting.Web)
+			assert.True(t, setting.Email)
+		}
+	}
+
+	enabled, err := isWebNotificationEnabled(x, 2, NotificationReasonMention)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+	enabled, err = isEmailNotificationEnabled(x, 2, NotificationReasonRelease)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+}
@@ -6,9 +6,11 @@ package models
 
 import (
 	"math/rand"
+	"os"
 	"testing"
 
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
@@ -40,6 +42,24 @@ func TestCanCreateOrganization(t *testing.T) {
 	assert.False(t, user.CanCreateOrganization())
 }
 
+func TestUser_SizedRelAvatarLink(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(disableGravatar bool) {
+		setting.DisableGravatar = disableGravatar
+	}(setting.DisableGravatar)
+	setting.DisableGravatar = true
+
+	// the random avatar of the fixture is missing from the storage
+	user := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	_, err := storage.Avatars.Stat(user.CustomAvatarRelativePath())
+	assert.True(t, os.IsNotExist(err))
+
+	assert.EqualValues(t, setting.AppSubURL+"/avatars/avatar2", user.SizedRelAvatarLink(28))
+	_, err = storage.Avatars.Stat(user.CustomAvatarRelativePath())
+	assert.NoError(t, err)
+	assert.NoError(t, storage.Avatars.Delete(user.CustomAvatarRelativePath()))
+}
+
 func TestSearchUsers(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	testSuccess := func(opts *SearchUserOptions, expectedUserOrOrgIDs []int64) {
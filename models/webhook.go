@@ -25,7 +25,10 @@ import (
 )
 
 // HookQueue is a global queue of web hooks
-var HookQueue = sync.NewUniqueQueue(setting.Webhook.QueueLength)
+var HookQueue = sync.NewWorkQueue("webhook_sender")
+
+// maxHookTaskRetryDelay the maximum delay between two attempts of a delivery
+const maxHookTaskRetryDelay = 24 * time.Hour
 
 // HookContentType is the content type of a web hook
 type HookContentType int
@@ -66,15 +69,28 @@ func IsValidHookContentType(name string) bool {
 
 // HookEvents is a set of web hook events
 type HookEvents struct {
-	Create       bool `json:"create"`
-	Delete       bool `json:"delete"`
-	Fork         bool `json:"fork"`
-	Issues       bool `json:"issues"`
-	IssueComment bool `json:"issue_comment"`
-	Push         bool `json:"push"`
-	PullRequest  bool `json:"pull_request"`
-	Repository   bool `json:"repository"`
-	Release      bool `json:"release"`
+	Create               bool `json:"create"`
+	Delete               bool `json:"delete"`
+	Fork                 bool `json:"fork"`
+	Issues               bool `json:"issues"`
+	IssueAssign          bool `json:"issue_assign"`
+	IssueLabel           bool `json:"issue_label"`
+	IssueMilestone       bool `json:"issue_milestone"`
+	IssueComment         bool `json:"issue_comment"`
+	Push                 bool `json:"push"`
+	PullRequest          bool `json:"pull_request"`
+	PullRequestAssign    bool `json:"pull_request_assign"`
+	PullRequestLabel     bool `json:"pull_request_label"`
+	PullRequestMilestone bool `json:"pull_request_milestone"`
+	PullRequestReview    bool `json:"pull_request_review"`
+	PullRequestSync      bool `json:"pull_request_sync"`
+	Repository           bool `json:"repository"`
+	Release              bool `json:"release"`
+	Wiki                 bool `json:"wiki"`
+	Status               bool `json:"status"`
+	Star                 bool `json:"star"`
+	Watch                bool `json:"watch"`
+	BranchProtection     bool `json:"branch_protection"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -111,6 +127,13 @@ type Webhook struct {
 	HookTaskType HookTaskType
 	Meta         string     `xorm:"TEXT"` // store hook-specific attributes
 	LastStatus   HookStatus // Last delivery status
+	// FailureCount number of consecutive deliveries which failed after all
+	// their attempts
+	FailureCount int `xorm:"NOT NULL DEFAULT 0"`
+	// IsSystemWebhook is set on the webhooks of the site administrators which
+	// receive the events of all the repositories. The other webhooks of the
+	// administrators are default webhooks, copied to the new repositories.
+	IsSystemWebhook bool `xorm:"NOT NULL DEFAULT false"`
 
 	CreatedUnix util.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix util.TimeStamp `xorm:"INDEX updated"`
@@ -142,6 +165,33 @@ func (w *Webhook) GetDiscordHook() *DiscordMeta {
 	return s
 }
 
+// GetTelegramHook returns telegram metadata
+func (w *Webhook) GetTelegramHook() *TelegramMeta {
+	s := &TelegramMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error(4, "webhook.GetTelegramHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// GetMatrixHook returns matrix metadata
+func (w *Webhook) GetMatrixHook() *MatrixMeta {
+	s := &MatrixMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error(4, "webhook.GetMatrixHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// GetCustomHook returns custom metadata
+func (w *Webhook) GetCustomHook() *CustomMeta {
+	s := &CustomMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error(4, "webhook.GetCustomHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
 // History returns history of webhook by given conditions.
 func (w *Webhook) History(page int) ([]*HookTask, error) {
 	return HookTasks(w.ID, page)
@@ -178,6 +228,24 @@ func (w *Webhook) HasIssuesEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Issues)
 }
 
+// HasIssueAssignEvent returns true if hook enabled issue_assign event.
+func (w *Webhook) HasIssueAssignEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.IssueAssign)
+}
+
+// HasIssueLabelEvent returns true if hook enabled issue_label event.
+func (w *Webhook) HasIssueLabelEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.IssueLabel)
+}
+
+// HasIssueMilestoneEvent returns true if hook enabled issue_milestone event.
+func (w *Webhook) HasIssueMilestoneEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.IssueMilestone)
+}
+
 // HasIssueCommentEvent returns true if hook enabled issue_comment event.
 func (w *Webhook) HasIssueCommentEvent() bool {
 	return w.SendEverything ||
@@ -196,6 +264,36 @@ func (w *Webhook) HasPullRequestEvent() bool {
 		(w.ChooseEvents && w.HookEvents.PullRequest)
 }
 
+// HasPullRequestAssignEvent returns true if hook enabled pull_request_assign event.
+func (w *Webhook) HasPullRequestAssignEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.PullRequestAssign)
+}
+
+// HasPullRequestLabelEvent returns true if hook enabled pull_request_label event.
+func (w *Webhook) HasPullRequestLabelEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.PullRequestLabel)
+}
+
+// HasPullRequestMilestoneEvent returns true if hook enabled pull_request_milestone event.
+func (w *Webhook) HasPullRequestMilestoneEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.PullRequestMilestone)
+}
+
+// HasPullRequestReviewEvent returns true if hook enabled pull_request_review event.
+func (w *Webhook) HasPullRequestReviewEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.PullRequestReview)
+}
+
+// HasPullRequestSyncEvent returns true if hook enabled pull_request_sync event.
+func (w *Webhook) HasPullRequestSyncEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.PullRequestSync)
+}
+
 // HasReleaseEvent returns if hook enabled release event.
 func (w *Webhook) HasReleaseEvent() bool {
 	return w.SendEverything ||
@@ -208,6 +306,36 @@ func (w *Webhook) HasRepositoryEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Repository)
 }
 
+// HasWikiEvent returns if hook enabled wiki event.
+func (w *Webhook) HasWikiEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Wiki)
+}
+
+// HasStatusEvent returns if hook enabled status event.
+func (w *Webhook) HasStatusEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Status)
+}
+
+// HasStarEvent returns if hook enabled star event.
+func (w *Webhook) HasStarEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Star)
+}
+
+// HasWatchEvent returns if hook enabled watch event.
+func (w *Webhook) HasWatchEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.Watch)
+}
+
+// HasBranchProtectionEvent returns if hook enabled branch_protection event.
+func (w *Webhook) HasBranchProtectionEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.BranchProtection)
+}
+
 func (w *Webhook) eventCheckers() []struct {
 	has func() bool
 	typ HookEventType
@@ -221,10 +349,23 @@ func (w *Webhook) eventCheckers() []struct {
 		{w.HasForkEvent, HookEventFork},
 		{w.HasPushEvent, HookEventPush},
 		{w.HasIssuesEvent, HookEventIssues},
+		{w.HasIssueAssignEvent, HookEventIssueAssign},
+		{w.HasIssueLabelEvent, HookEventIssueLabel},
+		{w.HasIssueMilestoneEvent, HookEventIssueMilestone},
 		{w.HasIssueCommentEvent, HookEventIssueComment},
 		{w.HasPullRequestEvent, HookEventPullRequest},
+		{w.HasPullRequestAssignEvent, HookEventPullRequestAssign},
+		{w.HasPullRequestLabelEvent, HookEventPullRequestLabel},
+		{w.HasPullRequestMilestoneEvent, HookEventPullRequestMilestone},
+		{w.HasPullRequestReviewEvent, HookEventPullRequestReview},
+		{w.HasPullRequestSyncEvent, HookEventPullRequestSync},
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
+		{w.HasWikiEvent, HookEventWiki},
+		{w.HasStatusEvent, HookEventStatus},
+		{w.HasStarEvent, HookEventStar},
+		{w.HasWatchEvent, HookEventWatch},
+		{w.HasBranchProtectionEvent, HookEventBranchProtection},
 	}
 }
 
@@ -317,8 +458,86 @@ func GetWebhooksByOrgID(orgID int64) (ws []*Webhook, err error) {
 	return ws, err
 }
 
-// UpdateWebhook updates information of webhook.
+// GetSystemOrDefaultWebhook returns the system or default webhook by given ID.
+func GetSystemOrDefaultWebhook(id int64) (*Webhook, error) {
+	w := new(Webhook)
+	has, err := x.
+		Where("id=?", id).
+		And("repo_id=?", 0).
+		And("org_id=?", 0).
+		Get(w)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrWebhookNotExist{id}
+	}
+	return w, nil
+}
+
+// GetSystemWebhooks returns all the system webhooks.
+func GetSystemWebhooks() ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0, 5)
+	return webhooks, x.
+		Where("repo_id=?", 0).
+		And("org_id=?", 0).
+		And("is_system_webhook=?", true).
+		Find(&webhooks)
+}
+
+func getActiveSystemWebhooks(e Engine) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0, 5)
+	return webhooks, e.
+		Where("repo_id=?", 0).
+		And("org_id=?", 0).
+		And("is_system_webhook=?", true).
+		And("is_active=?", true).
+		Find(&webhooks)
+}
+
+// GetDefaultWebhooks returns all the default webhooks.
+func GetDefaultWebhooks() ([]*Webhook, error) {
+	return getDefaultWebhooks(x)
+}
+
+func getDefaultWebhooks(e Engine) ([]*Webhook, error) {
+	webhooks := make([]*Webhook, 0, 5)
+	return webhooks, e.
+		Where("repo_id=?", 0).
+		And("org_id=?", 0).
+		And("is_system_webhook=?", false).
+		Find(&webhooks)
+}
+
+// copyDefaultWebhooksToRepo creates a copy of every default webhook for the
+// given repository
+func copyDefaultWebhooksToRepo(e Engine, repoID int64) error {
+	ws, err := getDefaultWebhooks(e)
+	if err != nil {
+		return fmt.Errorf("getDefaultWebhooks: %v", err)
+	}
+
+	for _, w := range ws {
+		if _, err = e.Insert(&Webhook{
+			RepoID:       repoID,
+			URL:          w.URL,
+			ContentType:  w.ContentType,
+			Secret:       w.Secret,
+			Events:       w.Events,
+			IsSSL:        w.IsSSL,
+			IsActive:     w.IsActive,
+			HookTaskType: w.HookTaskType,
+			Meta:         w.Meta,
+		}); err != nil {
+			return fmt.Errorf("Insert: %v", err)
+		}
+	}
+	return nil
+}
+
+// UpdateWebhook updates information of webhook. The count of failed
+// deliveries is reset, since the hook has been changed or re-activated.
 func UpdateWebhook(w *Webhook) error {
+	w.FailureCount = 0
 	_, err := x.ID(w.ID).AllCols().Update(w)
 	return err
 }
@@ -329,6 +548,34 @@ func UpdateWebhookLastStatus(w *Webhook) error {
 	return err
 }
 
+// updateWebhookDeliveryStatus updates the status of a webhook after the
+// last attempt of a delivery, and disables it once too many deliveries in a
+// row have failed.
+func updateWebhookDeliveryStatus(w *Webhook, isSucceed bool) error {
+	if isSucceed {
+		w.LastStatus = HookStatusSucceed
+		w.FailureCount = 0
+	} else {
+		w.LastStatus = HookStatusFail
+		w.FailureCount++
+	}
+
+	disabled := w.IsActive && setting.Webhook.DisableAfterFailures > 0 &&
+		w.FailureCount >= setting.Webhook.DisableAfterFailures
+	if disabled {
+		w.IsActive = false
+	}
+	if _, err := x.ID(w.ID).Cols("last_status", "failure_count", "is_active").Update(w); err != nil {
+		return err
+	}
+
+	if disabled {
+		log.Warn("Webhook %d has been disabled after %d failed deliveries", w.ID, w.FailureCount)
+		SendWebhookDisabledMail(w)
+	}
+	return nil
+}
+
 // deleteWebhook uses argument bean as query condition,
 // ID must be specified and do not assign unnecessary fields.
 func deleteWebhook(bean *Webhook) (err error) {
@@ -365,6 +612,15 @@ func DeleteWebhookByOrgID(orgID, id int64) error {
 	})
 }
 
+// DeleteSystemOrDefaultWebhook deletes the system or default webhook by given
+// ID.
+func DeleteSystemOrDefaultWebhook(id int64) error {
+	if _, err := GetSystemOrDefaultWebhook(id); err != nil {
+		return err
+	}
+	return deleteWebhook(&Webhook{ID: id})
+}
+
 //   ___ ___                __   ___________              __
 //  /   |   \  ____   ____ |  | _\__    ___/____    _____|  | __
 // /    ~    \/  _ \ /  _ \|  |/ / |    |  \__  \  /  ___/  |/ /
@@ -382,6 +638,10 @@ const (
 	GITEA
 	DISCORD
 	DINGTALK
+	MSTEAMS
+	TELEGRAM
+	MATRIX
+	CUSTOM
 )
 
 var hookTaskTypes = map[string]HookTaskType{
@@ -390,6 +650,10 @@ var hookTaskTypes = map[string]HookTaskType{
 	"slack":    SLACK,
 	"discord":  DISCORD,
 	"dingtalk": DINGTALK,
+	"msteams":  MSTEAMS,
+	"telegram": TELEGRAM,
+	"matrix":   MATRIX,
+	"custom":   CUSTOM,
 }
 
 // ToHookTaskType returns HookTaskType by given name.
@@ -410,6 +674,14 @@ func (t HookTaskType) Name() string {
 		return "discord"
 	case DINGTALK:
 		return "dingtalk"
+	case MSTEAMS:
+		return "msteams"
+	case TELEGRAM:
+		return "telegram"
+	case MATRIX:
+		return "matrix"
+	case CUSTOM:
+		return "custom"
 	}
 	return ""
 }
@@ -425,17 +697,73 @@ type HookEventType string
 
 // Types of hook events
 const (
-	HookEventCreate       HookEventType = "create"
-	HookEventDelete       HookEventType = "delete"
-	HookEventFork         HookEventType = "fork"
-	HookEventPush         HookEventType = "push"
-	HookEventIssues       HookEventType = "issues"
-	HookEventIssueComment HookEventType = "issue_comment"
-	HookEventPullRequest  HookEventType = "pull_request"
-	HookEventRepository   HookEventType = "repository"
-	HookEventRelease      HookEventType = "release"
+	HookEventCreate               HookEventType = "create"
+	HookEventDelete               HookEventType = "delete"
+	HookEventFork                 HookEventType = "fork"
+	HookEventPush                 HookEventType = "push"
+	HookEventIssues               HookEventType = "issues"
+	HookEventIssueAssign          HookEventType = "issue_assign"
+	HookEventIssueLabel           HookEventType = "issue_label"
+	HookEventIssueMilestone       HookEventType = "issue_milestone"
+	HookEventIssueComment         HookEventType = "issue_comment"
+	HookEventPullRequest          HookEventType = "pull_request"
+	HookEventPullRequestAssign    HookEventType = "pull_request_assign"
+	HookEventPullRequestLabel     HookEventType = "pull_request_label"
+	HookEventPullRequestMilestone HookEventType = "pull_request_milestone"
+	HookEventPullRequestReview    HookEventType = "pull_request_review"
+	HookEventPullRequestSync      HookEventType = "pull_request_sync"
+	HookEventRepository           HookEventType = "repository"
+	HookEventRelease              HookEventType = "release"
+	HookEventWiki                 HookEventType = "wiki"
+	HookEventStatus               HookEventType = "status"
+	HookEventStar                 HookEventType = "star"
+	HookEventWatch                HookEventType = "watch"
+	HookEventBranchProtection     HookEventType = "branch_protection"
 )
 
+// isValidHookEventType returns true if the given event type is known
+func isValidHookEventType(event HookEventType) bool {
+	switch event {
+	case HookEventCreate, HookEventDelete, HookEventFork, HookEventPush,
+		HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone,
+		HookEventIssueComment, HookEventPullRequest, HookEventPullRequestAssign,
+		HookEventPullRequestLabel, HookEventPullRequestMilestone, HookEventPullRequestReview,
+		HookEventPullRequestSync, HookEventRepository, HookEventRelease, HookEventWiki,
+		HookEventStatus, HookEventStar, HookEventWatch, HookEventBranchProtection:
+		return true
+	}
+	return false
+}
+
+// Event returns the name of the event sent in the X-Gitea-Event header.
+// Issue and pull request changes which used to be sent as issues and
+// pull_request events keep their names so that existing receivers still
+// recognize them, the precise type being sent in X-Gitea-Event-Type.
+func (t HookEventType) Event() string {
+	switch t {
+	case HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
+		return string(HookEventIssues)
+	case HookEventPullRequestAssign, HookEventPullRequestLabel, HookEventPullRequestMilestone,
+		HookEventPullRequestSync:
+		return string(HookEventPullRequest)
+	}
+	return string(t)
+}
+
+// wikiPageURL returns the URL of the given page of the wiki of a repository
+func wikiPageURL(repo *api.Repository, page string) string {
+	return repo.HTMLURL + "/wiki/" + WikiNameToSubURL(page)
+}
+
+// statusURL returns the URL linked by a commit status, or the URL of the
+// commit if the status has none
+func statusURL(p *api.StatusPayload) string {
+	if p.TargetURL != "" {
+		return p.TargetURL
+	}
+	return p.Repository.HTMLURL + "/commit/" + p.SHA
+}
+
 // HookRequest represents hook task request information.
 type HookRequest struct {
 	Headers map[string]string `json:"headers"`
@@ -464,6 +792,11 @@ type HookTask struct {
 	IsDelivered     bool
 	Delivered       int64
 	DeliveredString string `xorm:"-"`
+	// Attempts number of delivery attempts so far
+	Attempts int `xorm:"NOT NULL DEFAULT 0"`
+	// NextRetryUnix time of the next attempt of a failed delivery which has
+	// attempts left
+	NextRetryUnix util.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
 
 	// History info.
 	IsSucceed       bool
@@ -513,16 +846,66 @@ func (t *HookTask) simpleMarshalJSON(v interface{}) string {
 	return string(p)
 }
 
+// IsPending returns true if the delivery has failed and will be attempted
+// again
+func (t *HookTask) IsPending() bool {
+	return !t.IsDelivered && t.Attempts > 0
+}
+
 // HookTasks returns a list of hook tasks by given conditions.
 func HookTasks(hookID int64, page int) ([]*HookTask, error) {
-	tasks := make([]*HookTask, 0, setting.Webhook.PagingNum)
+	return GetHookTasks(hookID, page, setting.Webhook.PagingNum)
+}
+
+// GetHookTasks returns a page of the hook tasks of a webhook, the latest
+// first.
+func GetHookTasks(hookID int64, page, pageSize int) ([]*HookTask, error) {
+	tasks := make([]*HookTask, 0, pageSize)
 	return tasks, x.
-		Limit(setting.Webhook.PagingNum, (page-1)*setting.Webhook.PagingNum).
+		Limit(pageSize, (page-1)*pageSize).
 		Where("hook_id=?", hookID).
 		Desc("id").
 		Find(&tasks)
 }
 
+// CountHookTasks returns the number of hook tasks of a webhook.
+func CountHookTasks(hookID int64) (int64, error) {
+	return x.Where("hook_id=?", hookID).Count(new(HookTask))
+}
+
+// GetHookTaskByHookID returns the hook task of a webhook by its ID.
+func GetHookTaskByHookID(hookID, id int64) (*HookTask, error) {
+	t := &HookTask{ID: id, HookID: hookID}
+	has, err := x.Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrHookTaskNotExist{id, hookID}
+	}
+	return t, nil
+}
+
+// RedeliverHookTask creates a new delivery of the payload of a hook task to
+// the current URL of its webhook, and adds it to the queue.
+func RedeliverHookTask(w *Webhook, t *HookTask) (*HookTask, error) {
+	redelivery := &HookTask{
+		RepoID:         t.RepoID,
+		HookID:         w.ID,
+		UUID:           gouuid.NewV4().String(),
+		Type:           t.Type,
+		URL:            w.URL,
+		PayloadContent: t.PayloadContent,
+		ContentType:    w.ContentType,
+		EventType:      t.EventType,
+		IsSSL:          w.IsSSL,
+	}
+	if _, err := x.Insert(redelivery); err != nil {
+		return nil, err
+	}
+	go HookQueue.Add(redelivery.RepoID)
+	return redelivery, nil
+}
+
 // CreateHookTask creates a new hook task,
 // it handles conversion from Payload to PayloadContent.
 func CreateHookTask(t *HookTask) error {
@@ -579,6 +962,26 @@ func prepareWebhook(e Engine, w *Webhook, repo *Repository, event HookEventType,
 		if err != nil {
 			return fmt.Errorf("GetDingtalkPayload: %v", err)
 		}
+	case MSTEAMS:
+		payloader, err = GetMSTeamsPayload(p, event, w.Meta)
+		if err != nil {
+			return fmt.Errorf("GetMSTeamsPayload: %v", err)
+		}
+	case TELEGRAM:
+		payloader, err = GetTelegramPayload(p, event, w.Meta)
+		if err != nil {
+			return fmt.Errorf("GetTelegramPayload: %v", err)
+		}
+	case MATRIX:
+		payloader, err = GetMatrixPayload(p, event, w.Meta)
+		if err != nil {
+			return fmt.Errorf("GetMatrixPayload: %v", err)
+		}
+	case CUSTOM:
+		payloader, err = GetCustomPayload(p, event, w.Meta)
+		if err != nil {
+			return fmt.Errorf("GetCustomPayload: %v", err)
+		}
 	default:
 		p.SetSecret(w.Secret)
 		payloader = p
@@ -620,6 +1023,12 @@ func prepareWebhooks(e Engine, repo *Repository, event HookEventType, p api.Payl
 		ws = append(ws, orgHooks...)
 	}
 
+	systemHooks, err := getActiveSystemWebhooks(e)
+	if err != nil {
+		return fmt.Errorf("getActiveSystemWebhooks: %v", err)
+	}
+	ws = append(ws, systemHooks...)
+
 	if len(ws) == 0 {
 		return nil
 	}
@@ -632,17 +1041,41 @@ func prepareWebhooks(e Engine, repo *Repository, event HookEventType, p api.Payl
 	return nil
 }
 
+// hookTaskRetryDelay returns the delay before the next attempt of a failed
+// delivery, which doubles after every attempt
+func hookTaskRetryDelay(attempts int) time.Duration {
+	delay := setting.Webhook.RetryBackoff
+	for i := 1; i < attempts && delay < maxHookTaskRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxHookTaskRetryDelay {
+		delay = maxHookTaskRetryDelay
+	}
+	return delay
+}
+
 func (t *HookTask) deliver() {
+	t.Attempts++
 	t.IsDelivered = true
 
+	var req *httplib.Request
+	if t.Type == MATRIX {
+		// the task UUID is the transaction ID of the message, which makes the
+		// retries of the delivery idempotent
+		req = httplib.Put(t.URL + "/" + t.UUID)
+	} else {
+		req = httplib.Post(t.URL)
+	}
+
 	timeout := time.Duration(setting.Webhook.DeliverTimeout) * time.Second
-	req := httplib.Post(t.URL).SetTimeout(timeout, timeout).
+	req = req.SetTimeout(timeout, timeout).
 		Header("X-Gitea-Delivery", t.UUID).
-		Header("X-Gitea-Event", string(t.EventType)).
+		Header("X-Gitea-Event", t.EventType.Event()).
+		Header("X-Gitea-Event-Type", string(t.EventType)).
 		Header("X-Gogs-Delivery", t.UUID).
-		Header("X-Gogs-Event", string(t.EventType)).
+		Header("X-Gogs-Event", t.EventType.Event()).
 		HeaderWithSensitiveCase("X-GitHub-Delivery", t.UUID).
-		HeaderWithSensitiveCase("X-GitHub-Event", string(t.EventType)).
+		HeaderWithSensitiveCase("X-GitHub-Event", t.EventType.Event()).
 		SetTLSClientConfig(&tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify})
 
 	switch t.ContentType {
@@ -660,14 +1093,35 @@ func (t *HookTask) deliver() {
 		t.RequestInfo.Headers[k] = strings.Join(vals, ",")
 	}
 
+	// The access token is added once the headers are recorded so that it does
+	// not show in the history of the hook.
+	if t.Type == MATRIX {
+		if w, err := GetWebhookByID(t.HookID); err != nil {
+			log.Error(4, "GetWebhookByID [%d]: %v", t.HookID, err)
+		} else {
+			req.Header("Authorization", "Bearer "+w.GetMatrixHook().AccessToken)
+		}
+	}
+
 	t.ResponseInfo = &HookResponse{
 		Headers: map[string]string{},
 	}
 
 	defer func() {
 		t.Delivered = time.Now().UnixNano()
+		t.NextRetryUnix = 0
 		if t.IsSucceed {
 			log.Trace("Hook delivered: %s", t.UUID)
+		} else if t.Attempts < setting.Webhook.MaxAttempts {
+			// keep the task undelivered until its next attempt
+			delay := hookTaskRetryDelay(t.Attempts)
+			t.IsDelivered = false
+			t.NextRetryUnix = util.TimeStampNow().AddDuration(delay)
+			log.Trace("Hook delivery failed: %s, attempt %d in %v", t.UUID, t.Attempts+1, delay)
+			repoID := t.RepoID
+			time.AfterFunc(delay, func() {
+				HookQueue.Add(repoID)
+			})
 		} else {
 			log.Trace("Hook delivery failed: %s", t.UUID)
 		}
@@ -682,12 +1136,13 @@ func (t *HookTask) deliver() {
 			log.Error(5, "GetWebhookByID: %v", err)
 			return
 		}
-		if t.IsSucceed {
-			w.LastStatus = HookStatusSucceed
-		} else {
+		if t.IsPending() {
 			w.LastStatus = HookStatusFail
+			err = UpdateWebhookLastStatus(w)
+		} else {
+			err = updateWebhookDeliveryStatus(w, t.IsSucceed)
 		}
-		if err = UpdateWebhookLastStatus(w); err != nil {
+		if err != nil {
 			log.Error(5, "UpdateWebhookLastStatus: %v", err)
 			return
 		}
@@ -725,31 +1180,40 @@ func DeliverHooks() {
 		return
 	}
 
-	// Update hook task status.
+	// Update hook task status, the failed deliveries which are not due yet
+	// are scheduled again.
+	now := util.TimeStampNow()
 	for _, t := range tasks {
-		t.deliver()
+		if t.NextRetryUnix <= now {
+			t.deliver()
+			continue
+		}
+		repoID := t.RepoID
+		time.AfterFunc(time.Duration(t.NextRetryUnix-now)*time.Second, func() {
+			HookQueue.Add(repoID)
+		})
 	}
 
 	// Start listening on new hook requests.
-	for repoIDStr := range HookQueue.Queue() {
+	HookQueue.Run(func(repoIDStr string) {
 		log.Trace("DeliverHooks [repo_id: %v]", repoIDStr)
-		HookQueue.Remove(repoIDStr)
 
 		repoID, err := com.StrTo(repoIDStr).Int64()
 		if err != nil {
 			log.Error(4, "Invalid repo ID: %s", repoIDStr)
-			continue
+			return
 		}
 
-		tasks = make([]*HookTask, 0, 5)
-		if err := x.Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
+		tasks := make([]*HookTask, 0, 5)
+		if err := x.Where("repo_id=? AND is_delivered=? AND next_retry_unix<=?", repoID, false, util.TimeStampNow()).
+			Find(&tasks); err != nil {
 			log.Error(4, "Get repository [%s] hook tasks: %v", repoID, err)
-			continue
+			return
 		}
 		for _, t := range tasks {
 			t.deliver()
 		}
-	}
+	})
 }
 
 // InitDeliverHooks starts the hooks delivery thread
@@ -0,0 +1,120 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"text/template"
+
+	api "code.gitea.io/sdk/gitea"
+)
+
+type (
+	// CustomMeta contains the templates rendering the payloads of a custom
+	// hook
+	CustomMeta struct {
+		// Template renders the payloads of the events without their own
+		// template
+		Template       string                   `json:"template"`
+		EventTemplates map[HookEventType]string `json:"event_templates"`
+	}
+
+	// CustomPayload represents a payload rendered from a template
+	CustomPayload struct {
+		content []byte
+	}
+)
+
+// SetSecret sets the custom secret
+func (p *CustomPayload) SetSecret(_ string) {}
+
+// JSONPayload returns the rendered payload
+func (p *CustomPayload) JSONPayload() ([]byte, error) {
+	return p.content, nil
+}
+
+// customTemplateFuncs returns the functions available in the templates
+// rendering the payloads of the given event
+func customTemplateFuncs(event HookEventType) template.FuncMap {
+	return template.FuncMap{
+		"event": func() string {
+			return string(event)
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+}
+
+// EventTemplate returns the template of the given event if it has its own
+func (m *CustomMeta) EventTemplate(event string) string {
+	return m.EventTemplates[HookEventType(event)]
+}
+
+// templateFor returns the template rendering the payloads of the given event
+func (m *CustomMeta) templateFor(event HookEventType) string {
+	if tpl := m.EventTemplates[event]; tpl != "" {
+		return tpl
+	}
+	return m.Template
+}
+
+// Validate checks that the templates of the hook can be parsed
+func (m *CustomMeta) Validate() error {
+	if strings.TrimSpace(m.Template) == "" {
+		return ErrInvalidWebhookTemplate{Reason: "template is empty"}
+	}
+	if _, err := template.New("").Funcs(customTemplateFuncs("")).Parse(m.Template); err != nil {
+		return ErrInvalidWebhookTemplate{Reason: err.Error()}
+	}
+	for event, tpl := range m.EventTemplates {
+		if !isValidHookEventType(event) {
+			return ErrInvalidWebhookTemplate{Event: event, Reason: "unknown event"}
+		}
+		if _, err := template.New(string(event)).Funcs(customTemplateFuncs(event)).Parse(tpl); err != nil {
+			return ErrInvalidWebhookTemplate{Event: event, Reason: err.Error()}
+		}
+	}
+	return nil
+}
+
+// GetCustomPayload renders the payload of a custom webhook from the template
+// of the event. The template is given the JSON payload a Gitea webhook would
+// receive, so its fields are accessed by their JSON names.
+func GetCustomPayload(p api.Payloader, event HookEventType, meta string) (*CustomPayload, error) {
+	custom := &CustomMeta{}
+	if err := json.Unmarshal([]byte(meta), custom); err != nil {
+		return nil, errors.New("GetCustomPayload meta json:" + err.Error())
+	}
+
+	tpl, err := template.New(string(event)).Funcs(customTemplateFuncs(event)).Parse(custom.templateFor(event))
+	if err != nil {
+		return nil, ErrInvalidWebhookTemplate{Event: event, Reason: err.Error()}
+	}
+
+	content, err := p.JSONPayload()
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.UseNumber()
+	if err = decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	// the payload is shared with the other hooks of the repository, one of
+	// them may have set its secret
+	delete(data, "secret")
+
+	var buf bytes.Buffer
+	if err = tpl.Execute(&buf, data); err != nil {
+		return nil, ErrInvalidWebhookTemplate{Event: event, Reason: err.Error()}
+	}
+	return &CustomPayload{content: buf.Bytes()}, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomMeta_Validate(t *testing.T) {
+	meta := &CustomMeta{
+		Template: `{"text": {{json .ref}}}`,
+		EventTemplates: map[HookEventType]string{
+			HookEventPush: `{{event}} to {{.repository.full_name}}`,
+		},
+	}
+	assert.NoError(t, meta.Validate())
+
+	meta.EventTemplates[HookEventIssues] = `{{.issue.title`
+	err := meta.Validate()
+	assert.True(t, IsErrInvalidWebhookTemplate(err))
+	assert.EqualValues(t, HookEventIssues, err.(ErrInvalidWebhookTemplate).Event)
+
+	delete(meta.EventTemplates, HookEventIssues)
+	meta.EventTemplates["unknown"] = "event"
+	assert.True(t, IsErrInvalidWebhookTemplate(meta.Validate()))
+
+	assert.True(t, IsErrInvalidWebhookTemplate((&CustomMeta{Template: " "}).Validate()))
+}
+
+func TestGetCustomPayload(t *testing.T) {
+	meta, err := json.Marshal(&CustomMeta{
+		Template: `{"text": {{json (printf "%s %s" (event) .ref)}}}`,
+		EventTemplates: map[HookEventType]string{
+			HookEventPush: `{{.pusher.login}} pushed {{len .commits}} commits to {{.repository.full_name}}{{with .secret}} {{.}}{{end}}`,
+		},
+	})
+	assert.NoError(t, err)
+
+	repo := &api.Repository{FullName: "user2/repo1"}
+	user := &api.User{UserName: "user2"}
+	push := &api.PushPayload{
+		Secret:  "secret",
+		Ref:     "refs/heads/master",
+		Commits: []*api.PayloadCommit{{ID: "2a47ca4b614a9f5a43abbd5ad851a54a616ffee6"}},
+		Repo:    repo,
+		Pusher:  user,
+		Sender:  user,
+	}
+	payload, err := GetCustomPayload(push, HookEventPush, string(meta))
+	assert.NoError(t, err)
+	content, err := payload.JSONPayload()
+	assert.NoError(t, err)
+	assert.Equal(t, "user2 pushed 1 commits to user2/repo1", string(content))
+
+	create := &api.CreatePayload{Ref: "v1.0\"", RefType: "tag", Repo: repo, Sender: user}
+	payload, err = GetCustomPayload(create, HookEventCreate, string(meta))
+	assert.NoError(t, err)
+	content, err = payload.JSONPayload()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"text": "create v1.0\""}`, string(content))
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/base"
 	api "code.gitea.io/sdk/gitea"
 
 	dingtalk "github.com/lunny/dingtalk_webhook"
@@ -256,6 +257,9 @@ func getDingtalkPullRequestPayload(p *api.PullRequestPayload) (*DingtalkPayload,
 	case api.HookIssueDemilestoned:
 		title = fmt.Sprintf("[%s] Pull request clear milestone: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
 		text = p.PullRequest.Body
+	case api.HookIssueReviewed:
+		title = fmt.Sprintf("[%s] Pull request review %s: #%d %s", p.Repository.FullName, p.Review.Type, p.Index, p.PullRequest.Title)
+		text = p.Review.Content
 	}
 
 	return &DingtalkPayload{
@@ -350,6 +354,69 @@ func getDingtalkReleasePayload(p *api.ReleasePayload) (*DingtalkPayload, error)
 	return nil, nil
 }
 
+func newDingtalkActionCardPayload(title, text, singleTitle, singleURL string) *DingtalkPayload {
+	if text != "" {
+		text = title + "\r\n\r\n" + text
+	} else {
+		text = title
+	}
+	return &DingtalkPayload{
+		MsgType: "actionCard",
+		ActionCard: dingtalk.ActionCard{
+			Text:        text,
+			Title:       title,
+			HideAvatar:  "0",
+			SingleTitle: singleTitle,
+			SingleURL:   singleURL,
+		},
+	}
+}
+
+func getDingtalkWikiPayload(p *api.WikiPayload) (*DingtalkPayload, error) {
+	var title string
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("[%s] Wiki page created: %s", p.Repository.FullName, p.Page)
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("[%s] Wiki page edited: %s", p.Repository.FullName, p.Page)
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("[%s] Wiki page deleted: %s", p.Repository.FullName, p.Page)
+		return newDingtalkActionCardPayload(title, "", "view wiki", p.Repository.HTMLURL+"/wiki"), nil
+	}
+
+	return newDingtalkActionCardPayload(title, p.Comment, "view wiki page", wikiPageURL(p.Repository, p.Page)), nil
+}
+
+func getDingtalkStatusPayload(p *api.StatusPayload) (*DingtalkPayload, error) {
+	title := fmt.Sprintf("[%s] Commit status %s: %s %s", p.Repository.FullName, p.State, base.ShortSha(p.SHA), p.Context)
+
+	return newDingtalkActionCardPayload(title, p.Description, "view status", statusURL(p)), nil
+}
+
+func getDingtalkStarPayload(p *api.StarPayload) (*DingtalkPayload, error) {
+	title := fmt.Sprintf("[%s] Repository starred by %s", p.Repository.FullName, p.Sender.UserName)
+	if p.Action == api.HookStarUnstarred {
+		title = fmt.Sprintf("[%s] Repository unstarred by %s", p.Repository.FullName, p.Sender.UserName)
+	}
+
+	return newDingtalkActionCardPayload(title, "", "view repository", p.Repository.HTMLURL), nil
+}
+
+func getDingtalkWatchPayload(p *api.WatchPayload) (*DingtalkPayload, error) {
+	title := fmt.Sprintf("[%s] Repository watched by %s", p.Repository.FullName, p.Sender.UserName)
+	if p.Action == api.HookWatchUnwatched {
+		title = fmt.Sprintf("[%s] Repository unwatched by %s", p.Repository.FullName, p.Sender.UserName)
+	}
+
+	return newDingtalkActionCardPayload(title, "", "view repository", p.Repository.HTMLURL), nil
+}
+
+func getDingtalkBranchProtectionPayload(p *api.BranchProtectionPayload) (*DingtalkPayload, error) {
+	title := fmt.Sprintf("[%s] Branch protection %s: %s", p.Repository.FullName, p.Action, p.Branch)
+
+	return newDingtalkActionCardPayload(title, "", "view branch", p.Repository.HTMLURL+"/src/branch/"+p.Branch), nil
+}
+
 // GetDingtalkPayload converts a ding talk webhook into a DingtalkPayload
 func GetDingtalkPayload(p api.Payloader, event HookEventType, meta string) (*DingtalkPayload, error) {
 	s := new(DingtalkPayload)
@@ -361,18 +428,29 @@ func GetDingtalkPayload(p api.Payloader, event HookEventType, meta string) (*Din
 		return getDingtalkDeletePayload(p.(*api.DeletePayload))
 	case HookEventFork:
 		return getDingtalkForkPayload(p.(*api.ForkPayload))
-	case HookEventIssues:
+	case HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
 		return getDingtalkIssuesPayload(p.(*api.IssuePayload))
 	case HookEventIssueComment:
 		return getDingtalkIssueCommentPayload(p.(*api.IssueCommentPayload))
 	case HookEventPush:
 		return getDingtalkPushPayload(p.(*api.PushPayload))
-	case HookEventPullRequest:
+	case HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel,
+		HookEventPullRequestMilestone, HookEventPullRequestReview, HookEventPullRequestSync:
 		return getDingtalkPullRequestPayload(p.(*api.PullRequestPayload))
 	case HookEventRepository:
 		return getDingtalkRepositoryPayload(p.(*api.RepositoryPayload))
 	case HookEventRelease:
 		return getDingtalkReleasePayload(p.(*api.ReleasePayload))
+	case HookEventWiki:
+		return getDingtalkWikiPayload(p.(*api.WikiPayload))
+	case HookEventStatus:
+		return getDingtalkStatusPayload(p.(*api.StatusPayload))
+	case HookEventStar:
+		return getDingtalkStarPayload(p.(*api.StarPayload))
+	case HookEventWatch:
+		return getDingtalkWatchPayload(p.(*api.WatchPayload))
+	case HookEventBranchProtection:
+		return getDingtalkBranchProtectionPayload(p.(*api.BranchProtectionPayload))
 	}
 
 	return s, nil
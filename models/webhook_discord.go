@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/sdk/gitea"
 )
@@ -80,6 +81,28 @@ var (
 	failedColor  = color("ff3232")
 )
 
+// reviewColor returns the color of a pull request review of the given type
+func reviewColor(reviewType string) int {
+	switch reviewType {
+	case "approved":
+		return successColor
+	case "rejected":
+		return failedColor
+	}
+	return warnColor
+}
+
+// statusColor returns the color of a commit status in the given state
+func statusColor(state api.StatusState) int {
+	switch state {
+	case api.StatusSuccess:
+		return successColor
+	case api.StatusError, api.StatusFailure:
+		return failedColor
+	}
+	return warnColor
+}
+
 // SetSecret sets the discord secret
 func (p *DiscordPayload) SetSecret(_ string) {}
 
@@ -379,6 +402,10 @@ func getDiscordPullRequestPayload(p *api.PullRequestPayload, meta *DiscordMeta)
 		title = fmt.Sprintf("[%s] Pull request clear milestone: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
 		text = p.PullRequest.Body
 		color = warnColor
+	case api.HookIssueReviewed:
+		title = fmt.Sprintf("[%s] Pull request review %s: #%d %s", p.Repository.FullName, p.Review.Type, p.Index, p.PullRequest.Title)
+		text = p.Review.Content
+		color = reviewColor(p.Review.Type)
 	}
 
 	return &DiscordPayload{
@@ -468,6 +495,81 @@ func getDiscordReleasePayload(p *api.ReleasePayload, meta *DiscordMeta) (*Discor
 	}, nil
 }
 
+func newDiscordRepoPayload(repo *api.Repository, sender *api.User, meta *DiscordMeta, title, text, url string, color int) *DiscordPayload {
+	return &DiscordPayload{
+		Username:  meta.Username,
+		AvatarURL: meta.IconURL,
+		Embeds: []DiscordEmbed{
+			{
+				Title:       fmt.Sprintf("[%s] %s", repo.FullName, title),
+				Description: text,
+				URL:         url,
+				Color:       color,
+				Author: DiscordEmbedAuthor{
+					Name:    sender.UserName,
+					URL:     setting.AppURL + sender.UserName,
+					IconURL: sender.AvatarURL,
+				},
+			},
+		},
+	}
+}
+
+func getDiscordWikiPayload(p *api.WikiPayload, meta *DiscordMeta) (*DiscordPayload, error) {
+	var title, url string
+	var color int
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("Wiki page created: %s", p.Page)
+		url = wikiPageURL(p.Repository, p.Page)
+		color = successColor
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("Wiki page edited: %s", p.Page)
+		url = wikiPageURL(p.Repository, p.Page)
+		color = warnColor
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("Wiki page deleted: %s", p.Page)
+		url = p.Repository.HTMLURL + "/wiki"
+		color = failedColor
+	}
+
+	return newDiscordRepoPayload(p.Repository, p.Sender, meta, title, p.Comment, url, color), nil
+}
+
+func getDiscordStatusPayload(p *api.StatusPayload, meta *DiscordMeta) (*DiscordPayload, error) {
+	title := fmt.Sprintf("Commit status %s: %s %s", p.State, base.ShortSha(p.SHA), p.Context)
+
+	return newDiscordRepoPayload(p.Repository, p.Sender, meta, title, p.Description, statusURL(p), statusColor(p.State)), nil
+}
+
+func getDiscordStarPayload(p *api.StarPayload, meta *DiscordMeta) (*DiscordPayload, error) {
+	title := "Repository starred"
+	if p.Action == api.HookStarUnstarred {
+		title = "Repository unstarred"
+	}
+
+	return newDiscordRepoPayload(p.Repository, p.Sender, meta, title, "", p.Repository.HTMLURL, successColor), nil
+}
+
+func getDiscordWatchPayload(p *api.WatchPayload, meta *DiscordMeta) (*DiscordPayload, error) {
+	title := "Repository watched"
+	if p.Action == api.HookWatchUnwatched {
+		title = "Repository unwatched"
+	}
+
+	return newDiscordRepoPayload(p.Repository, p.Sender, meta, title, "", p.Repository.HTMLURL, successColor), nil
+}
+
+func getDiscordBranchProtectionPayload(p *api.BranchProtectionPayload, meta *DiscordMeta) (*DiscordPayload, error) {
+	title := fmt.Sprintf("Branch protection %s: %s", p.Action, p.Branch)
+	color := warnColor
+	if p.Action == api.HookBranchProtectionDeleted {
+		color = failedColor
+	}
+
+	return newDiscordRepoPayload(p.Repository, p.Sender, meta, title, "", p.Repository.HTMLURL+"/settings/branches", color), nil
+}
+
 // GetDiscordPayload converts a discord webhook into a DiscordPayload
 func GetDiscordPayload(p api.Payloader, event HookEventType, meta string) (*DiscordPayload, error) {
 	s := new(DiscordPayload)
@@ -484,18 +586,29 @@ func GetDiscordPayload(p api.Payloader, event HookEventType, meta string) (*Disc
 		return getDiscordDeletePayload(p.(*api.DeletePayload), discord)
 	case HookEventFork:
 		return getDiscordForkPayload(p.(*api.ForkPayload), discord)
-	case HookEventIssues:
+	case HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
 		return getDiscordIssuesPayload(p.(*api.IssuePayload), discord)
 	case HookEventIssueComment:
 		return getDiscordIssueCommentPayload(p.(*api.IssueCommentPayload), discord)
 	case HookEventPush:
 		return getDiscordPushPayload(p.(*api.PushPayload), discord)
-	case HookEventPullRequest:
+	case HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel,
+		HookEventPullRequestMilestone, HookEventPullRequestReview, HookEventPullRequestSync:
 		return getDiscordPullRequestPayload(p.(*api.PullRequestPayload), discord)
 	case HookEventRepository:
 		return getDiscordRepositoryPayload(p.(*api.RepositoryPayload), discord)
 	case HookEventRelease:
 		return getDiscordReleasePayload(p.(*api.ReleasePayload), discord)
+	case HookEventWiki:
+		return getDiscordWikiPayload(p.(*api.WikiPayload), discord)
+	case HookEventStatus:
+		return getDiscordStatusPayload(p.(*api.StatusPayload), discord)
+	case HookEventStar:
+		return getDiscordStarPayload(p.(*api.StarPayload), discord)
+	case HookEventWatch:
+		return getDiscordWatchPayload(p.(*api.WatchPayload), discord)
+	case HookEventBranchProtection:
+		return getDiscordBranchProtectionPayload(p.(*api.BranchProtectionPayload), discord)
 	}
 
 	return s, nil
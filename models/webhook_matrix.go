@@ -0,0 +1,92 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	api "code.gitea.io/sdk/gitea"
+)
+
+type (
+	// MatrixPayload represents a m.room.message event
+	MatrixPayload struct {
+		MsgType       string `json:"msgtype"`
+		Body          string `json:"body"`
+		Format        string `json:"format"`
+		FormattedBody string `json:"formatted_body"`
+	}
+
+	// MatrixMeta contains the matrix metadata
+	MatrixMeta struct {
+		HomeserverURL string `json:"homeserver_url"`
+		Room          string `json:"room_id"`
+		AccessToken   string `json:"access_token"`
+		MessageType   string `json:"message_type"`
+	}
+)
+
+// Matrix message types the hook can send
+const (
+	MatrixMessageTypeText   = "m.text"
+	MatrixMessageTypeNotice = "m.notice"
+)
+
+// IsValidMatrixMessageType returns true if the given message type can be sent
+// by a matrix hook
+func IsValidMatrixMessageType(msgType string) bool {
+	return msgType == MatrixMessageTypeText || msgType == MatrixMessageTypeNotice
+}
+
+// URL returns the client-server API endpoint sending messages to the room of
+// the hook. The transaction ID of each message is appended when delivering it.
+func (m *MatrixMeta) URL() string {
+	return strings.TrimRight(m.HomeserverURL, "/") + "/_matrix/client/r0/rooms/" +
+		url.PathEscape(m.Room) + "/send/m.room.message"
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// SetSecret sets the matrix secret
+func (p *MatrixPayload) SetSecret(_ string) {}
+
+// JSONPayload Marshals the MatrixPayload to json
+func (p *MatrixPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// GetMatrixPayload converts a matrix webhook into a MatrixPayload
+func GetMatrixPayload(p api.Payloader, event HookEventType, meta string) (*MatrixPayload, error) {
+	matrix := &MatrixMeta{}
+	if err := json.Unmarshal([]byte(meta), &matrix); err != nil {
+		return nil, errors.New("GetMatrixPayload meta json:" + err.Error())
+	}
+
+	message, err := getHTMLMessage(p, event)
+	if err != nil {
+		return nil, err
+	}
+
+	msgType := matrix.MessageType
+	if !IsValidMatrixMessageType(msgType) {
+		msgType = MatrixMessageTypeNotice
+	}
+
+	return &MatrixPayload{
+		MsgType:       msgType,
+		Body:          html.UnescapeString(htmlTagPattern.ReplaceAllString(message, "")),
+		Format:        "org.matrix.custom.html",
+		FormattedBody: strings.Replace(message, "\n", "<br>", -1),
+	}, nil
+}
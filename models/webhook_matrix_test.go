@@ -0,0 +1,82 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrixMeta_URL(t *testing.T) {
+	meta := &MatrixMeta{HomeserverURL: "https://matrix.example.com/", Room: "#room:example.com"}
+	assert.Equal(t, "https://matrix.example.com/_matrix/client/r0/rooms/%23room:example.com/send/m.room.message", meta.URL())
+}
+
+func TestGetMatrixPayload(t *testing.T) {
+	meta := `{"homeserver_url":"https://matrix.example.com","room_id":"!room:example.com","message_type":"m.text"}`
+	p := &api.IssuePayload{
+		Action: api.HookIssueOpened,
+		Index:  1,
+		Issue:  &api.Issue{Title: "issue & title", Body: "content"},
+		Repository: &api.Repository{
+			FullName: "user2/repo1",
+			HTMLURL:  "https://try.gitea.io/user2/repo1",
+		},
+		Sender: &api.User{UserName: "user2"},
+	}
+
+	payload, err := GetMatrixPayload(p, HookEventIssues, meta)
+	assert.NoError(t, err)
+	assert.Equal(t, MatrixMessageTypeText, payload.MsgType)
+	assert.Equal(t, "org.matrix.custom.html", payload.Format)
+	assert.Contains(t, payload.FormattedBody, `<a href="https://try.gitea.io/user2/repo1/issues/1">#1 issue &amp; title</a>`)
+	assert.Contains(t, payload.FormattedBody, "<br><br>content")
+	assert.Equal(t, "[user2/repo1] Issue opened: #1 issue & title by user2\n\ncontent", payload.Body)
+}
+
+func TestHookTask_deliverMatrix(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	var method, path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path, authorization = r.Method, r.URL.Path, r.Header.Get("Authorization")
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	matrix := &MatrixMeta{HomeserverURL: server.URL, Room: "!room:example.com", AccessToken: "token"}
+	meta, err := json.Marshal(matrix)
+	assert.NoError(t, err)
+	hook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	hook.HookTaskType = MATRIX
+	hook.URL = matrix.URL()
+	hook.Meta = string(meta)
+	assert.NoError(t, UpdateWebhook(hook))
+
+	hookTask := &HookTask{
+		RepoID:    1,
+		HookID:    1,
+		Type:      MATRIX,
+		URL:       hook.URL,
+		Payloader: &MatrixPayload{},
+	}
+	assert.NoError(t, CreateHookTask(hookTask))
+	hookTask.deliver()
+	hookTask = AssertExistsAndLoadBean(t, &HookTask{ID: hookTask.ID}).(*HookTask)
+	assert.True(t, hookTask.IsSucceed)
+	assert.Equal(t, "PUT", method)
+	assert.Equal(t, "/_matrix/client/r0/rooms/!room:example.com/send/m.room.message/"+hookTask.UUID, path)
+	assert.Equal(t, "Bearer token", authorization)
+	// the access token is not recorded
+	assert.NotContains(t, hookTask.RequestInfo.Headers, "Authorization")
+}
@@ -0,0 +1,311 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// htmlLinkFormatter creates a HTML link
+func htmlLinkFormatter(url string, text string) string {
+	return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(text))
+}
+
+// newHTMLMessage creates a HTML message made of the title line of the event
+// and the optional text below it
+func newHTMLMessage(repo *api.Repository, sender *api.User, title, text string) string {
+	message := fmt.Sprintf("[%s] %s by %s", htmlLinkFormatter(repo.HTMLURL, repo.FullName), title,
+		htmlLinkFormatter(setting.AppURL+sender.UserName, sender.UserName))
+	if text = strings.TrimSpace(text); text != "" {
+		message += "\n\n" + text
+	}
+	return message
+}
+
+func getHTMLCreateMessage(p *api.CreatePayload) (string, error) {
+	// created tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("%s %s created", p.RefType, htmlLinkFormatter(p.Repo.HTMLURL+"/src/"+refName, refName))
+
+	return newHTMLMessage(p.Repo, p.Sender, title, ""), nil
+}
+
+func getHTMLDeleteMessage(p *api.DeletePayload) (string, error) {
+	// deleted tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("%s %s deleted", p.RefType, html.EscapeString(refName))
+
+	return newHTMLMessage(p.Repo, p.Sender, title, ""), nil
+}
+
+func getHTMLForkMessage(p *api.ForkPayload) (string, error) {
+	title := fmt.Sprintf("forked from %s", htmlLinkFormatter(p.Forkee.HTMLURL, p.Forkee.FullName))
+
+	return newHTMLMessage(p.Repo, p.Sender, title, ""), nil
+}
+
+func getHTMLPushMessage(p *api.PushPayload) (string, error) {
+	var (
+		branchName = git.RefEndName(p.Ref)
+		commitDesc string
+	)
+
+	var titleLink string
+	if len(p.Commits) == 1 {
+		commitDesc = "1 new commit"
+		titleLink = p.Commits[0].URL
+	} else {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+		titleLink = p.CompareURL
+	}
+	if titleLink == "" {
+		titleLink = p.Repo.HTMLURL + "/src/" + branchName
+	}
+
+	title := fmt.Sprintf("%s pushed to %s", htmlLinkFormatter(titleLink, commitDesc),
+		htmlLinkFormatter(p.Repo.HTMLURL+"/src/"+branchName, branchName))
+
+	var text string
+	// for each commit, generate a new line text
+	for i, commit := range p.Commits {
+		text += fmt.Sprintf("%s: %s", htmlLinkFormatter(commit.URL, commit.ID[:7]),
+			html.EscapeString(strings.TrimRight(commit.Message, "\r\n")))
+		if commit.Author != nil {
+			text += " - " + html.EscapeString(commit.Author.Name)
+		}
+		// add linebreak to each commit but the last
+		if i < len(p.Commits)-1 {
+			text += "\n"
+		}
+	}
+
+	return newHTMLMessage(p.Repo, p.Sender, title, text), nil
+}
+
+func getHTMLIssuesMessage(p *api.IssuePayload) (string, error) {
+	var action string
+	switch p.Action {
+	case api.HookIssueOpened:
+		action = "Issue opened"
+	case api.HookIssueClosed:
+		action = "Issue closed"
+	case api.HookIssueReOpened:
+		action = "Issue re-opened"
+	case api.HookIssueEdited:
+		action = "Issue edited"
+	case api.HookIssueAssigned:
+		action = "Issue assigned"
+		if p.Issue.Assignee != nil {
+			action += " to " + html.EscapeString(p.Issue.Assignee.UserName)
+		}
+	case api.HookIssueUnassigned:
+		action = "Issue unassigned"
+	case api.HookIssueLabelUpdated:
+		action = "Issue labels updated"
+	case api.HookIssueLabelCleared:
+		action = "Issue labels cleared"
+	case api.HookIssueSynchronized:
+		action = "Issue synchronized"
+	case api.HookIssueMilestoned:
+		action = "Issue milestone"
+	case api.HookIssueDemilestoned:
+		action = "Issue clear milestone"
+	}
+
+	url := fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Index)
+	title := fmt.Sprintf("%s: %s", action, htmlLinkFormatter(url, fmt.Sprintf("#%d %s", p.Index, p.Issue.Title)))
+	var text string
+	if p.Action == api.HookIssueOpened {
+		text = html.EscapeString(p.Issue.Body)
+	}
+
+	return newHTMLMessage(p.Repository, p.Sender, title, text), nil
+}
+
+func getHTMLIssueCommentMessage(p *api.IssueCommentPayload) (string, error) {
+	issue := fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title)
+	url := fmt.Sprintf("%s/issues/%d#%s", p.Repository.HTMLURL, p.Issue.Index, CommentHashTag(p.Comment.ID))
+	var title, text string
+	switch p.Action {
+	case api.HookIssueCommentCreated:
+		title = "New comment: " + htmlLinkFormatter(url, issue)
+		text = html.EscapeString(p.Comment.Body)
+	case api.HookIssueCommentEdited:
+		title = "Comment edited: " + htmlLinkFormatter(url, issue)
+		text = html.EscapeString(p.Comment.Body)
+	case api.HookIssueCommentDeleted:
+		url = fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Issue.Index)
+		title = "Comment deleted: " + htmlLinkFormatter(url, issue)
+	}
+
+	return newHTMLMessage(p.Repository, p.Sender, title, text), nil
+}
+
+func getHTMLPullRequestMessage(p *api.PullRequestPayload) (string, error) {
+	var action string
+	switch p.Action {
+	case api.HookIssueOpened:
+		action = "Pull request opened"
+	case api.HookIssueClosed:
+		if p.PullRequest.HasMerged {
+			action = "Pull request merged"
+		} else {
+			action = "Pull request closed"
+		}
+	case api.HookIssueReOpened:
+		action = "Pull request re-opened"
+	case api.HookIssueEdited:
+		action = "Pull request edited"
+	case api.HookIssueAssigned:
+		list, err := MakeAssigneeList(&Issue{ID: p.PullRequest.ID})
+		if err != nil {
+			return "", err
+		}
+		action = "Pull request assigned to " + html.EscapeString(list)
+	case api.HookIssueUnassigned:
+		action = "Pull request unassigned"
+	case api.HookIssueLabelUpdated:
+		action = "Pull request labels updated"
+	case api.HookIssueLabelCleared:
+		action = "Pull request labels cleared"
+	case api.HookIssueSynchronized:
+		action = "Pull request synchronized"
+	case api.HookIssueMilestoned:
+		action = "Pull request milestone"
+	case api.HookIssueDemilestoned:
+		action = "Pull request clear milestone"
+	case api.HookIssueReviewed:
+		action = "Pull request review " + html.EscapeString(p.Review.Type)
+	}
+
+	title := fmt.Sprintf("%s: %s", action,
+		htmlLinkFormatter(p.PullRequest.HTMLURL, fmt.Sprintf("#%d %s", p.Index, p.PullRequest.Title)))
+	var text string
+	switch p.Action {
+	case api.HookIssueOpened:
+		text = html.EscapeString(p.PullRequest.Body)
+	case api.HookIssueReviewed:
+		text = html.EscapeString(p.Review.Content)
+	}
+
+	return newHTMLMessage(p.Repository, p.Sender, title, text), nil
+}
+
+func getHTMLRepositoryMessage(p *api.RepositoryPayload) (string, error) {
+	var title string
+	switch p.Action {
+	case api.HookRepoCreated:
+		title = "Repository created"
+	case api.HookRepoDeleted:
+		title = "Repository deleted"
+	}
+
+	return newHTMLMessage(p.Repository, p.Sender, title, ""), nil
+}
+
+func getHTMLReleaseMessage(p *api.ReleasePayload) (string, error) {
+	var action string
+	switch p.Action {
+	case api.HookReleasePublished:
+		action = "Release created"
+	case api.HookReleaseUpdated:
+		action = "Release updated"
+	case api.HookReleaseDeleted:
+		action = "Release deleted"
+	}
+	title := fmt.Sprintf("%s: %s", action, htmlLinkFormatter(p.Release.URL, p.Release.TagName))
+
+	return newHTMLMessage(p.Repository, p.Sender, title, ""), nil
+}
+
+func getHTMLWikiMessage(p *api.WikiPayload) (string, error) {
+	var title string
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = "Wiki page created: " + htmlLinkFormatter(wikiPageURL(p.Repository, p.Page), p.Page)
+	case api.HookWikiEdited:
+		title = "Wiki page edited: " + htmlLinkFormatter(wikiPageURL(p.Repository, p.Page), p.Page)
+	case api.HookWikiDeleted:
+		title = "Wiki page deleted: " + html.EscapeString(p.Page)
+	}
+
+	return newHTMLMessage(p.Repository, p.Sender, title, html.EscapeString(p.Comment)), nil
+}
+
+func getHTMLStatusMessage(p *api.StatusPayload) (string, error) {
+	title := fmt.Sprintf("Commit status %s: %s %s", p.State,
+		htmlLinkFormatter(statusURL(p), base.ShortSha(p.SHA)), html.EscapeString(p.Context))
+
+	return newHTMLMessage(p.Repository, p.Sender, title, html.EscapeString(p.Description)), nil
+}
+
+func getHTMLStarMessage(p *api.StarPayload) (string, error) {
+	title := "Repository starred"
+	if p.Action == api.HookStarUnstarred {
+		title = "Repository unstarred"
+	}
+
+	return newHTMLMessage(p.Repository, p.Sender, title, ""), nil
+}
+
+func getHTMLWatchMessage(p *api.WatchPayload) (string, error) {
+	title := "Repository watched"
+	if p.Action == api.HookWatchUnwatched {
+		title = "Repository unwatched"
+	}
+
+	return newHTMLMessage(p.Repository, p.Sender, title, ""), nil
+}
+
+func getHTMLBranchProtectionMessage(p *api.BranchProtectionPayload) (string, error) {
+	title := fmt.Sprintf("Branch protection %s: %s", p.Action, html.EscapeString(p.Branch))
+
+	return newHTMLMessage(p.Repository, p.Sender, title, ""), nil
+}
+
+// getHTMLMessage describes the event of a payload in a HTML message, for the
+// chat services accepting this format
+func getHTMLMessage(p api.Payloader, event HookEventType) (string, error) {
+	switch event {
+	case HookEventCreate:
+		return getHTMLCreateMessage(p.(*api.CreatePayload))
+	case HookEventDelete:
+		return getHTMLDeleteMessage(p.(*api.DeletePayload))
+	case HookEventFork:
+		return getHTMLForkMessage(p.(*api.ForkPayload))
+	case HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
+		return getHTMLIssuesMessage(p.(*api.IssuePayload))
+	case HookEventIssueComment:
+		return getHTMLIssueCommentMessage(p.(*api.IssueCommentPayload))
+	case HookEventPush:
+		return getHTMLPushMessage(p.(*api.PushPayload))
+	case HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel,
+		HookEventPullRequestMilestone, HookEventPullRequestReview, HookEventPullRequestSync:
+		return getHTMLPullRequestMessage(p.(*api.PullRequestPayload))
+	case HookEventRepository:
+		return getHTMLRepositoryMessage(p.(*api.RepositoryPayload))
+	case HookEventRelease:
+		return getHTMLReleaseMessage(p.(*api.ReleasePayload))
+	case HookEventWiki:
+		return getHTMLWikiMessage(p.(*api.WikiPayload))
+	case HookEventStatus:
+		return getHTMLStatusMessage(p.(*api.StatusPayload))
+	case HookEventStar:
+		return getHTMLStarMessage(p.(*api.StarPayload))
+	case HookEventWatch:
+		return getHTMLWatchMessage(p.(*api.WatchPayload))
+	case HookEventBranchProtection:
+		return getHTMLBranchProtectionMessage(p.(*api.BranchProtectionPayload))
+	}
+
+	return "", nil
+}
@@ -0,0 +1,400 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/modules/base"
+	api "code.gitea.io/sdk/gitea"
+)
+
+type (
+	// MSTeamsFact for Fact Structure
+	MSTeamsFact struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+
+	// MSTeamsSection is a MessageCard section
+	MSTeamsSection struct {
+		ActivityTitle    string        `json:"activityTitle"`
+		ActivitySubtitle string        `json:"activitySubtitle"`
+		ActivityImage    string        `json:"activityImage"`
+		Facts            []MSTeamsFact `json:"facts"`
+		Text             string        `json:"text"`
+	}
+
+	// MSTeamsAction is an action (creates buttons, links etc)
+	MSTeamsAction struct {
+		Type    string                `json:"@type"`
+		Name    string                `json:"name"`
+		Targets []MSTeamsActionTarget `json:"targets,omitempty"`
+	}
+
+	// MSTeamsActionTarget is the actual link to follow, etc
+	MSTeamsActionTarget struct {
+		Os  string `json:"os"`
+		URI string `json:"uri"`
+	}
+
+	// MSTeamsPayload is the parent object of a MessageCard
+	MSTeamsPayload struct {
+		Type            string           `json:"@type"`
+		Context         string           `json:"@context"`
+		ThemeColor      string           `json:"themeColor"`
+		Title           string           `json:"title"`
+		Summary         string           `json:"summary"`
+		Sections        []MSTeamsSection `json:"sections"`
+		PotentialAction []MSTeamsAction  `json:"potentialAction"`
+	}
+)
+
+// SetSecret sets the MSTeams secret
+func (p *MSTeamsPayload) SetSecret(_ string) {}
+
+// JSONPayload Marshals the MSTeamsPayload to json
+func (p *MSTeamsPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// newMSTeamsPayload creates a MessageCard with a single section describing
+// the event and a button opening actionURL
+func newMSTeamsPayload(repo *api.Repository, sender *api.User, title, text, actionURL string, color int, facts ...MSTeamsFact) *MSTeamsPayload {
+	facts = append([]MSTeamsFact{{Name: "Repository:", Value: repo.FullName}}, facts...)
+
+	return &MSTeamsPayload{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		ThemeColor: fmt.Sprintf("%x", color),
+		Title:      title,
+		Summary:    title,
+		Sections: []MSTeamsSection{
+			{
+				ActivityTitle:    sender.FullName,
+				ActivitySubtitle: sender.UserName,
+				ActivityImage:    sender.AvatarURL,
+				Text:             text,
+				Facts:            facts,
+			},
+		},
+		PotentialAction: []MSTeamsAction{
+			{
+				Type: "OpenUri",
+				Name: "View in Gitea",
+				Targets: []MSTeamsActionTarget{
+					{
+						Os:  "default",
+						URI: actionURL,
+					},
+				},
+			},
+		},
+	}
+}
+
+func getMSTeamsCreatePayload(p *api.CreatePayload) (*MSTeamsPayload, error) {
+	// created tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s created", p.Repo.FullName, p.RefType, refName)
+
+	return newMSTeamsPayload(p.Repo, p.Sender, title, "", p.Repo.HTMLURL+"/src/"+refName, successColor,
+		MSTeamsFact{Name: fmt.Sprintf("%s:", p.RefType), Value: refName}), nil
+}
+
+func getMSTeamsDeletePayload(p *api.DeletePayload) (*MSTeamsPayload, error) {
+	// deleted tag/branch
+	refName := git.RefEndName(p.Ref)
+	title := fmt.Sprintf("[%s] %s %s deleted", p.Repo.FullName, p.RefType, refName)
+
+	return newMSTeamsPayload(p.Repo, p.Sender, title, "", p.Repo.HTMLURL, warnColor,
+		MSTeamsFact{Name: fmt.Sprintf("%s:", p.RefType), Value: refName}), nil
+}
+
+func getMSTeamsForkPayload(p *api.ForkPayload) (*MSTeamsPayload, error) {
+	title := fmt.Sprintf("%s is forked to %s", p.Forkee.FullName, p.Repo.FullName)
+
+	return newMSTeamsPayload(p.Repo, p.Sender, title, "", p.Repo.HTMLURL, successColor,
+		MSTeamsFact{Name: "Forkee:", Value: p.Forkee.FullName}), nil
+}
+
+func getMSTeamsPushPayload(p *api.PushPayload) (*MSTeamsPayload, error) {
+	var (
+		branchName = git.RefEndName(p.Ref)
+		commitDesc string
+	)
+
+	var titleLink string
+	if len(p.Commits) == 1 {
+		commitDesc = "1 new commit"
+		titleLink = p.Commits[0].URL
+	} else {
+		commitDesc = fmt.Sprintf("%d new commits", len(p.Commits))
+		titleLink = p.CompareURL
+	}
+	if titleLink == "" {
+		titleLink = p.Repo.HTMLURL + "/src/" + branchName
+	}
+
+	title := fmt.Sprintf("[%s:%s] %s", p.Repo.FullName, branchName, commitDesc)
+
+	var text string
+	// for each commit, generate a new line text
+	for i, commit := range p.Commits {
+		text += fmt.Sprintf("[%s](%s) %s", commit.ID[:7], commit.URL,
+			strings.TrimRight(commit.Message, "\r\n"))
+		if commit.Author != nil {
+			text += " - " + commit.Author.Name
+		}
+		// add linebreak to each commit but the last
+		if i < len(p.Commits)-1 {
+			text += "\n\n"
+		}
+	}
+
+	return newMSTeamsPayload(p.Repo, p.Sender, title, text, titleLink, successColor,
+		MSTeamsFact{Name: "Commit count:", Value: fmt.Sprintf("%d", len(p.Commits))}), nil
+}
+
+func getMSTeamsIssuesPayload(p *api.IssuePayload) (*MSTeamsPayload, error) {
+	var title string
+	color := warnColor
+	url := fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Index)
+	switch p.Action {
+	case api.HookIssueOpened:
+		title = fmt.Sprintf("[%s] Issue opened: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueClosed:
+		title = fmt.Sprintf("[%s] Issue closed: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+		color = failedColor
+	case api.HookIssueReOpened:
+		title = fmt.Sprintf("[%s] Issue re-opened: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueEdited:
+		title = fmt.Sprintf("[%s] Issue edited: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueAssigned:
+		var assignee string
+		if p.Issue.Assignee != nil {
+			assignee = p.Issue.Assignee.UserName
+		}
+		title = fmt.Sprintf("[%s] Issue assigned to %s: #%d %s", p.Repository.FullName,
+			assignee, p.Index, p.Issue.Title)
+		color = successColor
+	case api.HookIssueUnassigned:
+		title = fmt.Sprintf("[%s] Issue unassigned: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueLabelUpdated:
+		title = fmt.Sprintf("[%s] Issue labels updated: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueLabelCleared:
+		title = fmt.Sprintf("[%s] Issue labels cleared: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueSynchronized:
+		title = fmt.Sprintf("[%s] Issue synchronized: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueMilestoned:
+		title = fmt.Sprintf("[%s] Issue milestone: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	case api.HookIssueDemilestoned:
+		title = fmt.Sprintf("[%s] Issue clear milestone: #%d %s", p.Repository.FullName, p.Index, p.Issue.Title)
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, p.Issue.Body, url, color,
+		MSTeamsFact{Name: "Issue #:", Value: fmt.Sprintf("%d", p.Index)}), nil
+}
+
+func getMSTeamsIssueCommentPayload(p *api.IssueCommentPayload) (*MSTeamsPayload, error) {
+	title := fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title)
+	url := fmt.Sprintf("%s/issues/%d#%s", p.Repository.HTMLURL, p.Issue.Index, CommentHashTag(p.Comment.ID))
+	color := successColor
+	switch p.Action {
+	case api.HookIssueCommentCreated:
+		title = fmt.Sprintf("[%s] New comment: %s", p.Repository.FullName, title)
+	case api.HookIssueCommentEdited:
+		title = fmt.Sprintf("[%s] Comment edited: %s", p.Repository.FullName, title)
+		color = warnColor
+	case api.HookIssueCommentDeleted:
+		title = fmt.Sprintf("[%s] Comment deleted: %s", p.Repository.FullName, title)
+		url = fmt.Sprintf("%s/issues/%d", p.Repository.HTMLURL, p.Issue.Index)
+		color = warnColor
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, p.Comment.Body, url, color,
+		MSTeamsFact{Name: "Issue #:", Value: fmt.Sprintf("%d", p.Issue.Index)}), nil
+}
+
+func getMSTeamsPullRequestPayload(p *api.PullRequestPayload) (*MSTeamsPayload, error) {
+	var title string
+	color := warnColor
+	switch p.Action {
+	case api.HookIssueOpened:
+		title = fmt.Sprintf("[%s] Pull request opened: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+		color = successColor
+	case api.HookIssueClosed:
+		if p.PullRequest.HasMerged {
+			title = fmt.Sprintf("[%s] Pull request merged: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+			color = successColor
+		} else {
+			title = fmt.Sprintf("[%s] Pull request closed: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+			color = failedColor
+		}
+	case api.HookIssueReOpened:
+		title = fmt.Sprintf("[%s] Pull request re-opened: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueEdited:
+		title = fmt.Sprintf("[%s] Pull request edited: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueAssigned:
+		list, err := MakeAssigneeList(&Issue{ID: p.PullRequest.ID})
+		if err != nil {
+			return &MSTeamsPayload{}, err
+		}
+		title = fmt.Sprintf("[%s] Pull request assigned to %s: #%d %s", p.Repository.FullName,
+			list, p.Index, p.PullRequest.Title)
+		color = successColor
+	case api.HookIssueUnassigned:
+		title = fmt.Sprintf("[%s] Pull request unassigned: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueLabelUpdated:
+		title = fmt.Sprintf("[%s] Pull request labels updated: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueLabelCleared:
+		title = fmt.Sprintf("[%s] Pull request labels cleared: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueSynchronized:
+		title = fmt.Sprintf("[%s] Pull request synchronized: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueMilestoned:
+		title = fmt.Sprintf("[%s] Pull request milestone: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueDemilestoned:
+		title = fmt.Sprintf("[%s] Pull request clear milestone: #%d %s", p.Repository.FullName, p.Index, p.PullRequest.Title)
+	case api.HookIssueReviewed:
+		title = fmt.Sprintf("[%s] Pull request review %s: #%d %s", p.Repository.FullName, p.Review.Type, p.Index, p.PullRequest.Title)
+		return newMSTeamsPayload(p.Repository, p.Sender, title, p.Review.Content, p.PullRequest.HTMLURL, reviewColor(p.Review.Type),
+			MSTeamsFact{Name: "Pull request #:", Value: fmt.Sprintf("%d", p.Index)}), nil
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, p.PullRequest.Body, p.PullRequest.HTMLURL, color,
+		MSTeamsFact{Name: "Pull request #:", Value: fmt.Sprintf("%d", p.Index)}), nil
+}
+
+func getMSTeamsRepositoryPayload(p *api.RepositoryPayload) (*MSTeamsPayload, error) {
+	var title string
+	var color int
+	switch p.Action {
+	case api.HookRepoCreated:
+		title = fmt.Sprintf("[%s] Repository created", p.Repository.FullName)
+		color = successColor
+	case api.HookRepoDeleted:
+		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
+		color = failedColor
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, "", p.Repository.HTMLURL, color), nil
+}
+
+func getMSTeamsReleasePayload(p *api.ReleasePayload) (*MSTeamsPayload, error) {
+	var title string
+	var color int
+	switch p.Action {
+	case api.HookReleasePublished:
+		title = fmt.Sprintf("[%s] Release created: %s", p.Repository.FullName, p.Release.TagName)
+		color = successColor
+	case api.HookReleaseUpdated:
+		title = fmt.Sprintf("[%s] Release updated: %s", p.Repository.FullName, p.Release.TagName)
+		color = warnColor
+	case api.HookReleaseDeleted:
+		title = fmt.Sprintf("[%s] Release deleted: %s", p.Repository.FullName, p.Release.TagName)
+		color = failedColor
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, p.Release.Note, p.Release.URL, color,
+		MSTeamsFact{Name: "Tag:", Value: p.Release.TagName}), nil
+}
+
+func getMSTeamsWikiPayload(p *api.WikiPayload) (*MSTeamsPayload, error) {
+	var title string
+	color := warnColor
+	url := wikiPageURL(p.Repository, p.Page)
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("[%s] Wiki page created: %s", p.Repository.FullName, p.Page)
+		color = successColor
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("[%s] Wiki page edited: %s", p.Repository.FullName, p.Page)
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("[%s] Wiki page deleted: %s", p.Repository.FullName, p.Page)
+		color = failedColor
+		url = p.Repository.HTMLURL + "/wiki"
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, p.Comment, url, color,
+		MSTeamsFact{Name: "Page:", Value: p.Page}), nil
+}
+
+func getMSTeamsStatusPayload(p *api.StatusPayload) (*MSTeamsPayload, error) {
+	title := fmt.Sprintf("[%s] Commit status %s: %s %s", p.Repository.FullName, p.State, base.ShortSha(p.SHA), p.Context)
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, p.Description, statusURL(p), statusColor(p.State),
+		MSTeamsFact{Name: "Commit:", Value: p.SHA}), nil
+}
+
+func getMSTeamsStarPayload(p *api.StarPayload) (*MSTeamsPayload, error) {
+	title := fmt.Sprintf("[%s] Repository starred", p.Repository.FullName)
+	if p.Action == api.HookStarUnstarred {
+		title = fmt.Sprintf("[%s] Repository unstarred", p.Repository.FullName)
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, "", p.Repository.HTMLURL, warnColor), nil
+}
+
+func getMSTeamsWatchPayload(p *api.WatchPayload) (*MSTeamsPayload, error) {
+	title := fmt.Sprintf("[%s] Repository watched", p.Repository.FullName)
+	if p.Action == api.HookWatchUnwatched {
+		title = fmt.Sprintf("[%s] Repository unwatched", p.Repository.FullName)
+	}
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, "", p.Repository.HTMLURL, warnColor), nil
+}
+
+func getMSTeamsBranchProtectionPayload(p *api.BranchProtectionPayload) (*MSTeamsPayload, error) {
+	title := fmt.Sprintf("[%s] Branch protection %s: %s", p.Repository.FullName, p.Action, p.Branch)
+
+	return newMSTeamsPayload(p.Repository, p.Sender, title, "", p.Repository.HTMLURL+"/settings/branches", warnColor,
+		MSTeamsFact{Name: "Branch:", Value: p.Branch}), nil
+}
+
+// GetMSTeamsPayload converts a MSTeams webhook into a MSTeamsPayload
+func GetMSTeamsPayload(p api.Payloader, event HookEventType, meta string) (*MSTeamsPayload, error) {
+	s := new(MSTeamsPayload)
+
+	switch event {
+	case HookEventCreate:
+		return getMSTeamsCreatePayload(p.(*api.CreatePayload))
+	case HookEventDelete:
+		return getMSTeamsDeletePayload(p.(*api.DeletePayload))
+	case HookEventFork:
+		return getMSTeamsForkPayload(p.(*api.ForkPayload))
+	case HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
+		return getMSTeamsIssuesPayload(p.(*api.IssuePayload))
+	case HookEventIssueComment:
+		return getMSTeamsIssueCommentPayload(p.(*api.IssueCommentPayload))
+	case HookEventPush:
+		return getMSTeamsPushPayload(p.(*api.PushPayload))
+	case HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel,
+		HookEventPullRequestMilestone, HookEventPullRequestReview, HookEventPullRequestSync:
+		return getMSTeamsPullRequestPayload(p.(*api.PullRequestPayload))
+	case HookEventRepository:
+		return getMSTeamsRepositoryPayload(p.(*api.RepositoryPayload))
+	case HookEventRelease:
+		return getMSTeamsReleasePayload(p.(*api.ReleasePayload))
+	case HookEventWiki:
+		return getMSTeamsWikiPayload(p.(*api.WikiPayload))
+	case HookEventStatus:
+		return getMSTeamsStatusPayload(p.(*api.StatusPayload))
+	case HookEventStar:
+		return getMSTeamsStarPayload(p.(*api.StarPayload))
+	case HookEventWatch:
+		return getMSTeamsWatchPayload(p.(*api.WatchPayload))
+	case HookEventBranchProtection:
+		return getMSTeamsBranchProtectionPayload(p.(*api.BranchProtectionPayload))
+	}
+
+	return s, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMSTeamsPayload(t *testing.T) {
+	p := &api.PullRequestPayload{
+		Action: api.HookIssueClosed,
+		Index:  2,
+		PullRequest: &api.PullRequest{
+			Title:     "pull title",
+			HTMLURL:   "https://try.gitea.io/user2/repo1/pulls/2",
+			HasMerged: true,
+		},
+		Repository: &api.Repository{FullName: "user2/repo1"},
+		Sender:     &api.User{UserName: "user2", FullName: "User Two"},
+	}
+
+	payload, err := GetMSTeamsPayload(p, HookEventPullRequest, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "MessageCard", payload.Type)
+	assert.Equal(t, "[user2/repo1] Pull request merged: #2 pull title", payload.Title)
+	assert.Equal(t, "1ac600", payload.ThemeColor)
+	if assert.Len(t, payload.Sections, 1) {
+		assert.Equal(t, "User Two", payload.Sections[0].ActivityTitle)
+		assert.Equal(t, []MSTeamsFact{
+			{Name: "Repository:", Value: "user2/repo1"},
+			{Name: "Pull request #:", Value: "2"},
+		}, payload.Sections[0].Facts)
+	}
+	if assert.Len(t, payload.PotentialAction, 1) {
+		assert.Equal(t, "https://try.gitea.io/user2/repo1/pulls/2", payload.PotentialAction[0].Targets[0].URI)
+	}
+}
+
+func TestGetMSTeamsPayload_Review(t *testing.T) {
+	p := &api.PullRequestPayload{
+		Action: api.HookIssueReviewed,
+		Index:  2,
+		PullRequest: &api.PullRequest{
+			Title:   "pull title",
+			HTMLURL: "https://try.gitea.io/user2/repo1/pulls/2",
+		},
+		Repository: &api.Repository{FullName: "user2/repo1"},
+		Sender:     &api.User{UserName: "user2", FullName: "User Two"},
+		Review:     &api.ReviewPayload{Type: "rejected", Content: "needs tests"},
+	}
+
+	payload, err := GetMSTeamsPayload(p, HookEventPullRequestReview, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "[user2/repo1] Pull request review rejected: #2 pull title", payload.Title)
+	assert.Equal(t, "ff3232", payload.ThemeColor)
+	if assert.Len(t, payload.Sections, 1) {
+		assert.Equal(t, "needs tests", payload.Sections[0].Text)
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/git"
 	api "code.gitea.io/sdk/gitea"
 
+	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -161,6 +162,10 @@ func getSlackIssuesPayload(p *api.IssuePayload, slack *SlackMeta) (*SlackPayload
 		text = fmt.Sprintf("[%s] Issue labels cleared: %s by %s", p.Repository.FullName, titleLink, senderLink)
 	case api.HookIssueSynchronized:
 		text = fmt.Sprintf("[%s] Issue synchronized: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueMilestoned:
+		text = fmt.Sprintf("[%s] Issue milestone: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueDemilestoned:
+		text = fmt.Sprintf("[%s] Issue clear milestone: %s by %s", p.Repository.FullName, titleLink, senderLink)
 	}
 
 	return &SlackPayload{
@@ -313,6 +318,13 @@ func getSlackPullRequestPayload(p *api.PullRequestPayload, slack *SlackMeta) (*S
 		text = fmt.Sprintf("[%s] Pull request labels cleared: %s by %s", p.Repository.FullName, titleLink, senderLink)
 	case api.HookIssueSynchronized:
 		text = fmt.Sprintf("[%s] Pull request synchronized: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueMilestoned:
+		text = fmt.Sprintf("[%s] Pull request milestone: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueDemilestoned:
+		text = fmt.Sprintf("[%s] Pull request clear milestone: %s by %s", p.Repository.FullName, titleLink, senderLink)
+	case api.HookIssueReviewed:
+		text = fmt.Sprintf("[%s] Pull request review %s: %s by %s", p.Repository.FullName, p.Review.Type, titleLink, senderLink)
+		attachmentText = SlackTextFormatter(p.Review.Content)
 	}
 
 	return &SlackPayload{
@@ -352,6 +364,70 @@ func getSlackRepositoryPayload(p *api.RepositoryPayload, slack *SlackMeta) (*Sla
 	}, nil
 }
 
+func newSlackRepoPayload(repo *api.Repository, sender *api.User, slack *SlackMeta, title, attachmentText string) *SlackPayload {
+	repoLink := SlackLinkFormatter(repo.HTMLURL, repo.FullName)
+	senderLink := SlackLinkFormatter(setting.AppURL+sender.UserName, sender.UserName)
+	payload := &SlackPayload{
+		Channel:  slack.Channel,
+		Text:     fmt.Sprintf("[%s] %s by %s", repoLink, title, senderLink),
+		Username: slack.Username,
+		IconURL:  slack.IconURL,
+	}
+	if attachmentText != "" {
+		payload.Attachments = []SlackAttachment{{
+			Color: slack.Color,
+			Text:  attachmentText,
+		}}
+	}
+	return payload
+}
+
+func getSlackWikiPayload(p *api.WikiPayload, slack *SlackMeta) (*SlackPayload, error) {
+	pageLink := SlackLinkFormatter(wikiPageURL(p.Repository, p.Page), p.Page)
+	var title string
+	switch p.Action {
+	case api.HookWikiCreated:
+		title = fmt.Sprintf("Wiki page created: %s", pageLink)
+	case api.HookWikiEdited:
+		title = fmt.Sprintf("Wiki page edited: %s", pageLink)
+	case api.HookWikiDeleted:
+		title = fmt.Sprintf("Wiki page deleted: %s", SlackTextFormatter(p.Page))
+	}
+
+	return newSlackRepoPayload(p.Repository, p.Sender, slack, title, SlackTextFormatter(p.Comment)), nil
+}
+
+func getSlackStatusPayload(p *api.StatusPayload, slack *SlackMeta) (*SlackPayload, error) {
+	title := fmt.Sprintf("Commit status %s: %s %s", p.State,
+		SlackLinkFormatter(statusURL(p), base.ShortSha(p.SHA)), SlackTextFormatter(p.Context))
+
+	return newSlackRepoPayload(p.Repository, p.Sender, slack, title, SlackTextFormatter(p.Description)), nil
+}
+
+func getSlackStarPayload(p *api.StarPayload, slack *SlackMeta) (*SlackPayload, error) {
+	title := "Repository starred"
+	if p.Action == api.HookStarUnstarred {
+		title = "Repository unstarred"
+	}
+
+	return newSlackRepoPayload(p.Repository, p.Sender, slack, title, ""), nil
+}
+
+func getSlackWatchPayload(p *api.WatchPayload, slack *SlackMeta) (*SlackPayload, error) {
+	title := "Repository watched"
+	if p.Action == api.HookWatchUnwatched {
+		title = "Repository unwatched"
+	}
+
+	return newSlackRepoPayload(p.Repository, p.Sender, slack, title, ""), nil
+}
+
+func getSlackBranchProtectionPayload(p *api.BranchProtectionPayload, slack *SlackMeta) (*SlackPayload, error) {
+	title := fmt.Sprintf("Branch protection %s: %s", p.Action, SlackTextFormatter(p.Branch))
+
+	return newSlackRepoPayload(p.Repository, p.Sender, slack, title, ""), nil
+}
+
 // GetSlackPayload converts a slack webhook into a SlackPayload
 func GetSlackPayload(p api.Payloader, event HookEventType, meta string) (*SlackPayload, error) {
 	s := new(SlackPayload)
@@ -368,18 +444,29 @@ func GetSlackPayload(p api.Payloader, event HookEventType, meta string) (*SlackP
 		return getSlackDeletePayload(p.(*api.DeletePayload), slack)
 	case HookEventFork:
 		return getSlackForkPayload(p.(*api.ForkPayload), slack)
-	case HookEventIssues:
+	case HookEventIssues, HookEventIssueAssign, HookEventIssueLabel, HookEventIssueMilestone:
 		return getSlackIssuesPayload(p.(*api.IssuePayload), slack)
 	case HookEventIssueComment:
 		return getSlackIssueCommentPayload(p.(*api.IssueCommentPayload), slack)
 	case HookEventPush:
 		return getSlackPushPayload(p.(*api.PushPayload), slack)
-	case HookEventPullRequest:
+	case HookEventPullRequest, HookEventPullRequestAssign, HookEventPullRequestLabel,
+		HookEventPullRequestMilestone, HookEventPullRequestReview, HookEventPullRequestSync:
 		return getSlackPullRequestPayload(p.(*api.PullRequestPayload), slack)
 	case HookEventRepository:
 		return getSlackRepositoryPayload(p.(*api.RepositoryPayload), slack)
 	case HookEventRelease:
 		return getSlackReleasePayload(p.(*api.ReleasePayload), slack)
+	case HookEventWiki:
+		return getSlackWikiPayload(p.(*api.WikiPayload), slack)
+	case HookEventStatus:
+		return getSlackStatusPayload(p.(*api.StatusPayload), slack)
+	case HookEventStar:
+		return getSlackStarPayload(p.(*api.StarPayload), slack)
+	case HookEventWatch:
+		return getSlackWatchPayload(p.(*api.WatchPayload), slack)
+	case HookEventBranchProtection:
+		return getSlackBranchProtectionPayload(p.(*api.BranchProtectionPayload), slack)
 	}
 
 	return s, nil
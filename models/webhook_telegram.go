@@ -0,0 +1,60 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	api "code.gitea.io/sdk/gitea"
+)
+
+type (
+	// TelegramPayload represents a message sent through the Bot API
+	TelegramPayload struct {
+		Message           string `json:"text"`
+		ParseMode         string `json:"parse_mode"`
+		DisableWebPreview bool   `json:"disable_web_page_preview"`
+	}
+
+	// TelegramMeta contains the telegram metadata
+	TelegramMeta struct {
+		BotToken string `json:"bot_token"`
+		ChatID   string `json:"chat_id"`
+	}
+)
+
+// URL returns the Bot API endpoint sending messages to the chat of the hook
+func (m *TelegramMeta) URL() string {
+	return fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s",
+		url.PathEscape(m.BotToken), url.QueryEscape(m.ChatID))
+}
+
+// SetSecret sets the telegram secret
+func (p *TelegramPayload) SetSecret(_ string) {}
+
+// JSONPayload Marshals the TelegramPayload to json
+func (p *TelegramPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// GetTelegramPayload converts a telegram webhook into a TelegramPayload
+func GetTelegramPayload(p api.Payloader, event HookEventType, meta string) (*TelegramPayload, error) {
+	message, err := getHTMLMessage(p, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TelegramPayload{
+		Message:           message,
+		ParseMode:         "HTML",
+		DisableWebPreview: true,
+	}, nil
+}
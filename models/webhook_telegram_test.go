@@ -0,0 +1,61 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	api "code.gitea.io/sdk/gitea"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTelegramMeta_URL(t *testing.T) {
+	meta := &TelegramMeta{BotToken: "123:token", ChatID: "-100"}
+	assert.Equal(t, "https://api.telegram.org/bot123:token/sendMessage?chat_id=-100", meta.URL())
+}
+
+func TestGetTelegramPayload(t *testing.T) {
+	p := &api.PushPayload{
+		Ref: "refs/heads/master",
+		Commits: []*api.PayloadCommit{
+			{
+				ID:      "2a47ca4b614a9f5a43abbd5ad851a54a616ffee6",
+				Message: "fix <b>\n",
+				URL:     "https://try.gitea.io/user2/repo1/commit/2a47ca4b614a9f5a43abbd5ad851a54a616ffee6",
+				Author:  &api.PayloadUser{Name: "User Two"},
+			},
+		},
+		Repo: &api.Repository{
+			FullName: "user2/repo1",
+			HTMLURL:  "https://try.gitea.io/user2/repo1",
+		},
+		Sender: &api.User{UserName: "user2"},
+	}
+
+	payload, err := GetTelegramPayload(p, HookEventPush, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "HTML", payload.ParseMode)
+	assert.Contains(t, payload.Message, `[<a href="https://try.gitea.io/user2/repo1">user2/repo1</a>] <a href="https://try.gitea.io/user2/repo1/commit/2a47ca4b614a9f5a43abbd5ad851a54a616ffee6">1 new commit</a> pushed to`)
+	assert.Contains(t, payload.Message, "\n\n<a href=\"https://try.gitea.io/user2/repo1/commit/2a47ca4b614a9f5a43abbd5ad851a54a616ffee6\">2a47ca4</a>: fix &lt;b&gt; - User Two")
+}
+
+func TestGetTelegramPayload_Wiki(t *testing.T) {
+	p := &api.WikiPayload{
+		Action:  api.HookWikiEdited,
+		Page:    "Home Page",
+		Comment: "fix <typo>",
+		Repository: &api.Repository{
+			FullName: "user2/repo1",
+			HTMLURL:  "https://try.gitea.io/user2/repo1",
+		},
+		Sender: &api.User{UserName: "user2"},
+	}
+
+	payload, err := GetTelegramPayload(p, HookEventWiki, "")
+	assert.NoError(t, err)
+	assert.Contains(t, payload.Message, `Wiki page edited: <a href="https://try.gitea.io/user2/repo1/wiki/Home-Page">Home Page</a> by`)
+	assert.Contains(t, payload.Message, "\n\nfix &lt;typo&gt;")
+}
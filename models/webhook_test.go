@@ -6,8 +6,13 @@ package models
 
 import (
 	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	api "code.gitea.io/sdk/gitea"
 
 	"github.com/stretchr/testify/assert"
@@ -73,12 +78,28 @@ func TestWebhook_UpdateEvent(t *testing.T) {
 }
 
 func TestWebhook_EventsArray(t *testing.T) {
-	assert.Equal(t, []string{"create", "delete", "fork", "push", "issues", "issue_comment", "pull_request", "repository", "release"},
+	assert.Equal(t, []string{"create", "delete", "fork", "push", "issues", "issue_assign", "issue_label", "issue_milestone",
+		"issue_comment", "pull_request", "pull_request_assign", "pull_request_label", "pull_request_milestone",
+		"pull_request_review", "pull_request_sync", "repository", "release", "wiki", "status", "star", "watch",
+		"branch_protection"},
 		(&Webhook{
 			HookEvent: &HookEvent{SendEverything: true},
 		}).EventsArray(),
 	)
 
+	assert.Equal(t, []string{"issue_label", "pull_request_review", "star"},
+		(&Webhook{
+			HookEvent: &HookEvent{
+				ChooseEvents: true,
+				HookEvents: HookEvents{
+					IssueLabel:        true,
+					PullRequestReview: true,
+					Star:              true,
+				},
+			},
+		}).EventsArray(),
+	)
+
 	assert.Equal(t, []string{"push"},
 		(&Webhook{
 			HookEvent: &HookEvent{PushOnly: true},
@@ -86,6 +107,27 @@ func TestWebhook_EventsArray(t *testing.T) {
 	)
 }
 
+func TestHookEventType_Event(t *testing.T) {
+	for eventType, event := range map[HookEventType]string{
+		HookEventPush:                 "push",
+		HookEventIssues:               "issues",
+		HookEventIssueAssign:          "issues",
+		HookEventIssueLabel:           "issues",
+		HookEventIssueMilestone:       "issues",
+		HookEventIssueComment:         "issue_comment",
+		HookEventPullRequest:          "pull_request",
+		HookEventPullRequestAssign:    "pull_request",
+		HookEventPullRequestLabel:     "pull_request",
+		HookEventPullRequestMilestone: "pull_request",
+		HookEventPullRequestSync:      "pull_request",
+		HookEventPullRequestReview:    "pull_request_review",
+		HookEventWiki:                 "wiki",
+		HookEventStar:                 "star",
+	} {
+		assert.Equal(t, event, eventType.Event())
+	}
+}
+
 func TestCreateWebhook(t *testing.T) {
 	hook := &Webhook{
 		RepoID:      3,
@@ -161,6 +203,50 @@ func TestGetWebhooksByOrgID(t *testing.T) {
 
 }
 
+func TestGetSystemOrDefaultWebhook(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hook, err := GetSystemOrDefaultWebhook(4)
+	assert.NoError(t, err)
+	assert.True(t, hook.IsSystemWebhook)
+
+	hook, err = GetSystemOrDefaultWebhook(5)
+	assert.NoError(t, err)
+	assert.False(t, hook.IsSystemWebhook)
+
+	// the webhooks of the repositories are not returned
+	_, err = GetSystemOrDefaultWebhook(1)
+	assert.Error(t, err)
+	assert.True(t, IsErrWebhookNotExist(err))
+}
+
+func TestGetSystemWebhooks(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hooks, err := GetSystemWebhooks()
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 1) {
+		assert.Equal(t, int64(4), hooks[0].ID)
+	}
+}
+
+func TestGetDefaultWebhooks(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	hooks, err := GetDefaultWebhooks()
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 1) {
+		assert.Equal(t, int64(5), hooks[0].ID)
+	}
+}
+
+func TestCopyDefaultWebhooksToRepo(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	AssertNotExistsBean(t, &Webhook{RepoID: 3, URL: "www.example.com/url5"})
+	assert.NoError(t, copyDefaultWebhooksToRepo(x, 3))
+	hook := AssertExistsAndLoadBean(t, &Webhook{RepoID: 3, URL: "www.example.com/url5"}).(*Webhook)
+	assert.NotEqual(t, int64(5), hook.ID)
+	assert.False(t, hook.IsSystemWebhook)
+	assert.True(t, hook.HasPushEvent())
+}
+
 func TestUpdateWebhook(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	hook := AssertExistsAndLoadBean(t, &Webhook{ID: 2}).(*Webhook)
@@ -182,6 +268,18 @@ func TestDeleteWebhookByRepoID(t *testing.T) {
 	assert.True(t, IsErrWebhookNotExist(err))
 }
 
+func TestDeleteSystemOrDefaultWebhook(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	AssertExistsAndLoadBean(t, &Webhook{ID: 4})
+	assert.NoError(t, DeleteSystemOrDefaultWebhook(4))
+	AssertNotExistsBean(t, &Webhook{ID: 4})
+
+	err := DeleteSystemOrDefaultWebhook(1)
+	assert.Error(t, err)
+	assert.True(t, IsErrWebhookNotExist(err))
+	AssertExistsAndLoadBean(t, &Webhook{ID: 1})
+}
+
 func TestDeleteWebhookByOrgID(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 	AssertExistsAndLoadBean(t, &Webhook{ID: 3, OrgID: 3})
@@ -197,18 +295,21 @@ func TestToHookTaskType(t *testing.T) {
 	assert.Equal(t, GOGS, ToHookTaskType("gogs"))
 	assert.Equal(t, SLACK, ToHookTaskType("slack"))
 	assert.Equal(t, GITEA, ToHookTaskType("gitea"))
+	assert.Equal(t, MATRIX, ToHookTaskType("matrix"))
 }
 
 func TestHookTaskType_Name(t *testing.T) {
 	assert.Equal(t, "gogs", GOGS.Name())
 	assert.Equal(t, "slack", SLACK.Name())
 	assert.Equal(t, "gitea", GITEA.Name())
+	assert.Equal(t, "msteams", MSTEAMS.Name())
 }
 
 func TestIsValidHookTaskType(t *testing.T) {
 	assert.True(t, IsValidHookTaskType("gogs"))
 	assert.True(t, IsValidHookTaskType("slack"))
 	assert.True(t, IsValidHookTaskType("gitea"))
+	assert.True(t, IsValidHookTaskType("custom"))
 	assert.False(t, IsValidHookTaskType("invalid"))
 }
 
@@ -251,6 +352,145 @@ func TestUpdateHookTask(t *testing.T) {
 	AssertExistsAndLoadBean(t, hook)
 }
 
+func TestGetHookTaskByHookID(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hookTask, err := GetHookTaskByHookID(1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "uuid1", hookTask.UUID)
+
+	_, err = GetHookTaskByHookID(2, 1)
+	assert.True(t, IsErrHookTaskNotExist(err))
+}
+
+func TestRedeliverHookTask(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	hookTask := AssertExistsAndLoadBean(t, &HookTask{ID: 1}).(*HookTask)
+	redelivery, err := RedeliverHookTask(hook, hookTask)
+	assert.NoError(t, err)
+	assert.NotEqual(t, hookTask.UUID, redelivery.UUID)
+	AssertExistsAndLoadBean(t, &HookTask{
+		ID:          redelivery.ID,
+		RepoID:      1,
+		HookID:      1,
+		URL:         hook.URL,
+		IsDelivered: false,
+		Attempts:    0,
+	})
+}
+
+func TestHookTaskRetryDelay(t *testing.T) {
+	defer func(backoff time.Duration) {
+		setting.Webhook.RetryBackoff = backoff
+	}(setting.Webhook.RetryBackoff)
+	setting.Webhook.RetryBackoff = time.Minute
+
+	assert.Equal(t, time.Minute, hookTaskRetryDelay(1))
+	assert.Equal(t, 2*time.Minute, hookTaskRetryDelay(2))
+	assert.Equal(t, 8*time.Minute, hookTaskRetryDelay(4))
+	assert.Equal(t, maxHookTaskRetryDelay, hookTaskRetryDelay(100))
+}
+
+func TestHookTask_deliver(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+	defer func(maxAttempts, disableAfterFailures int) {
+		setting.Webhook.MaxAttempts = maxAttempts
+		setting.Webhook.DisableAfterFailures = disableAfterFailures
+	}(setting.Webhook.MaxAttempts, setting.Webhook.DisableAfterFailures)
+	setting.Webhook.MaxAttempts = 2
+	setting.Webhook.DisableAfterFailures = 2
+
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	hook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	hook.URL = server.URL
+	assert.NoError(t, UpdateWebhook(hook))
+	newTask := func() *HookTask {
+		hookTask := &HookTask{
+			RepoID:    1,
+			HookID:    1,
+			Type:      GITEA,
+			URL:       server.URL,
+			Payloader: &api.PushPayload{},
+		}
+		assert.NoError(t, CreateHookTask(hookTask))
+		return hookTask
+	}
+
+	// the first failure is retried
+	hookTask := newTask()
+	hookTask.deliver()
+	hookTask = AssertExistsAndLoadBean(t, &HookTask{ID: hookTask.ID}).(*HookTask)
+	assert.True(t, hookTask.IsPending())
+	assert.True(t, hookTask.NextRetryUnix > util.TimeStampNow())
+	hook = AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.EqualValues(t, HookStatusFail, hook.LastStatus)
+	assert.EqualValues(t, 0, hook.FailureCount)
+
+	// the last attempt fails
+	hookTask.deliver()
+	hookTask = AssertExistsAndLoadBean(t, &HookTask{ID: hookTask.ID}).(*HookTask)
+	assert.True(t, hookTask.IsDelivered)
+	assert.False(t, hookTask.IsSucceed)
+	assert.EqualValues(t, 2, hookTask.Attempts)
+	hook = AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.EqualValues(t, 1, hook.FailureCount)
+	assert.True(t, hook.IsActive)
+
+	// a success resets the count of failures
+	status = http.StatusOK
+	hookTask = newTask()
+	hookTask.deliver()
+	hookTask = AssertExistsAndLoadBean(t, &HookTask{ID: hookTask.ID}).(*HookTask)
+	assert.True(t, hookTask.IsSucceed)
+	assert.EqualValues(t, 1, hookTask.Attempts)
+	hook = AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.EqualValues(t, HookStatusSucceed, hook.LastStatus)
+	assert.EqualValues(t, 0, hook.FailureCount)
+
+	// the hook is disabled after too many failures
+	status = http.StatusInternalServerError
+	for i := 0; i < 2; i++ {
+		hookTask = newTask()
+		hookTask.deliver()
+		hookTask.deliver()
+	}
+	hook = AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	assert.EqualValues(t, 2, hook.FailureCount)
+	assert.False(t, hook.IsActive)
+}
+
+func TestWebhookOwnerEmails(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hook := AssertExistsAndLoadBean(t, &Webhook{ID: 1}).(*Webhook)
+	name, link, emails, err := webhookOwnerEmails(hook)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "user2/repo1", name)
+	assert.EqualValues(t, setting.AppURL+"user2/repo1/settings/hooks/1", link)
+	assert.EqualValues(t, []string{"user2@example.com"}, emails)
+
+	// the site administrators own the system and default webhooks
+	hook = AssertExistsAndLoadBean(t, &Webhook{ID: 4}).(*Webhook)
+	name, link, emails, err = webhookOwnerEmails(hook)
+	assert.NoError(t, err)
+	assert.EqualValues(t, setting.AppName, name)
+	assert.EqualValues(t, setting.AppURL+"admin/hooks/system/4", link)
+	assert.EqualValues(t, []string{"user1@example.com"}, emails)
+
+	hook = AssertExistsAndLoadBean(t, &Webhook{ID: 5}).(*Webhook)
+	_, link, emails, err = webhookOwnerEmails(hook)
+	assert.NoError(t, err)
+	assert.EqualValues(t, setting.AppURL+"admin/hooks/default/5", link)
+	assert.EqualValues(t, []string{"user1@example.com"}, emails)
+}
+
 func TestPrepareWebhooks(t *testing.T) {
 	assert.NoError(t, PrepareTestDatabase())
 
@@ -270,3 +510,17 @@ func TestPrepareWebhooks(t *testing.T) {
 // TODO TestHookTask_deliver
 
 // TODO TestDeliverHooks
+
+func TestPrepareWebhooks_SystemWebhook(t *testing.T) {
+	assert.NoError(t, PrepareTestDatabase())
+
+	hook := AssertExistsAndLoadBean(t, &Webhook{ID: 4}).(*Webhook)
+	hook.IsActive = true
+	assert.NoError(t, UpdateWebhook(hook))
+
+	repo := AssertExistsAndLoadBean(t, &Repository{ID: 2}).(*Repository)
+	hookTask := &HookTask{RepoID: repo.ID, HookID: hook.ID, EventType: HookEventPush}
+	AssertNotExistsBean(t, hookTask)
+	assert.NoError(t, PrepareWebhooks(repo, HookEventPush, &api.PushPayload{}))
+	AssertExistsAndLoadBean(t, hookTask)
+}
@@ -153,10 +153,14 @@ func (repo *Repository) updateWikiPage(doer *User, oldWikiName, newWikiName, con
 	// If not a new file, show perform update not create.
 	if isNew {
 		if com.IsExist(newWikiPath) {
-			return ErrWikiAlreadyExist{newWikiPath}
+			return ErrWikiAlreadyExist{newWikiName}
 		}
 	} else {
 		oldWikiPath := path.Join(localPath, WikiNameToFilename(oldWikiName))
+		// Renaming must not overwrite another page.
+		if oldWikiPath != newWikiPath && com.IsExist(newWikiPath) {
+			return ErrWikiAlreadyExist{newWikiName}
+		}
 		if err := os.Remove(oldWikiPath); err != nil {
 			return fmt.Errorf("Failed to remove %s: %v", oldWikiPath, err)
 		}
@@ -207,7 +211,7 @@ func (repo *Repository) EditWikiPage(doer *User, oldWikiName, newWikiName, conte
 }
 
 // DeleteWikiPage deletes a wiki page identified by its path.
-func (repo *Repository) DeleteWikiPage(doer *User, wikiName string) (err error) {
+func (repo *Repository) DeleteWikiPage(doer *User, wikiName, message string) (err error) {
 	wikiWorkingPool.CheckIn(com.ToStr(repo.ID))
 	defer wikiWorkingPool.CheckOut(com.ToStr(repo.ID))
 
@@ -224,7 +228,9 @@ func (repo *Repository) DeleteWikiPage(doer *User, wikiName string) (err error)
 		return fmt.Errorf("Failed to remove %s: %v", filename, err)
 	}
 
-	message := "Delete page '" + wikiName + "'"
+	if len(message) == 0 {
+		message = "Delete page '" + wikiName + "'"
+	}
 
 	if err = git.AddChanges(localPath, true); err != nil {
 		return fmt.Errorf("AddChanges: %v", err)
@@ -207,13 +207,20 @@ func TestRepository_EditWikiPage(t *testing.T) {
 			assert.False(t, com.IsExist(oldPath))
 		}
 	}
+
+	// renaming onto an existing page
+	PrepareTestEnv(t)
+	assert.NoError(t, repo.AddWikiPage(doer, "Other page", newWikiContent, commitMsg))
+	err := repo.EditWikiPage(doer, "Home", "Other page", newWikiContent, commitMsg)
+	assert.True(t, IsErrWikiAlreadyExist(err))
+	assert.True(t, com.IsExist(path.Join(repo.LocalWikiPath(), "Home.md")))
 }
 
 func TestRepository_DeleteWikiPage(t *testing.T) {
 	PrepareTestEnv(t)
 	repo := AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
 	doer := AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
-	assert.NoError(t, repo.DeleteWikiPage(doer, "Home"))
+	assert.NoError(t, repo.DeleteWikiPage(doer, "Home", ""))
 	wikiPath := path.Join(repo.LocalWikiPath(), "Home.md")
 	assert.False(t, com.IsExist(wikiPath))
 }
@@ -44,12 +44,26 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 			auHead := ctx.Req.Header.Get("Authorization")
 			if len(auHead) > 0 {
 				auths := strings.Fields(auHead)
-				if len(auths) == 2 && auths[0] == "token" {
+				if len(auths) == 2 && (auths[0] == "token" || strings.ToLower(auths[0]) == "bearer") {
 					tokenSHA = auths[1]
 				}
 			}
 		}
 
+		// Access tokens issued through OAuth2 are signed JWTs.
+		if len(tokenSHA) > 0 && models.IsOAuth2Token(tokenSHA) {
+			t, err := models.GetOAuth2AccessToken(tokenSHA)
+			if err != nil {
+				if !models.IsErrAccessTokenNotExist(err) {
+					log.Error(4, "GetOAuth2AccessToken: %v", err)
+				}
+				return 0
+			}
+			ctx.Data["IsApiToken"] = true
+			ctx.Data["ApiToken"] = t
+			return t.UID
+		}
+
 		// Let's see if token is valid.
 		if len(tokenSHA) > 0 {
 			t, err := models.GetAccessTokenBySHA(tokenSHA)
@@ -59,11 +73,16 @@ func SignedInID(ctx *macaron.Context, sess session.Store) int64 {
 				}
 				return 0
 			}
+			if t.IsExpired() {
+				log.Trace("Access token expired [id: %d]", t.ID)
+				return 0
+			}
 			t.UpdatedUnix = util.TimeStampNow()
 			if err = models.UpdateAccessToken(t); err != nil {
 				log.Error(4, "UpdateAccessToken: %v", err)
 			}
 			ctx.Data["IsApiToken"] = true
+			ctx.Data["ApiToken"] = t
 			return t.UID
 		}
 	}
@@ -0,0 +1,139 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/Unknwon/com"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// ErrInvalidSigningKey is returned when no signing key has been initialized
+var ErrInvalidSigningKey = errors.New("the JWT signing key is not initialized")
+
+// signingKey is the RSA key the tokens issued by the OAuth2 provider are signed with
+var signingKey *rsa.PrivateKey
+
+// InitSigningKey loads the private key used to sign the issued tokens from the
+// given file, generating a new key if the file does not exist.
+func InitSigningKey(keyPath string) error {
+	if !com.IsFile(keyPath) {
+		log.Info("Generating JWT signing key: %s", keyPath)
+		if err := generateSigningKey(keyPath); err != nil {
+			return fmt.Errorf("generateSigningKey: %v", err)
+		}
+	}
+
+	data, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return fmt.Errorf("no PEM data found in %s", keyPath)
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+
+	signingKey = key
+	return nil
+}
+
+func generateSigningKey(keyPath string) error {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(keyPath), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(keyPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return pem.Encode(f, &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	})
+}
+
+// SigningKeyID returns the identifier of the signing key, derived from its public part
+func SigningKeyID() string {
+	if signingKey == nil {
+		return ""
+	}
+	der, err := x509.MarshalPKIXPublicKey(&signingKey.PublicKey)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// SignToken signs the given claims with the signing key and returns the serialized token
+func SignToken(claims jwt.Claims) (string, error) {
+	if signingKey == nil {
+		return "", ErrInvalidSigningKey
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = SigningKeyID()
+	return token.SignedString(signingKey)
+}
+
+// ParseToken verifies the signature of a serialized token and fills the given claims
+func ParseToken(tokenString string, claims jwt.Claims) error {
+	if signingKey == nil {
+		return ErrInvalidSigningKey
+	}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if _, ok := t.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing algorithm: %v", t.Header["alg"])
+		}
+		return &signingKey.PublicKey, nil
+	})
+	if err != nil {
+		return err
+	} else if !token.Valid {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+// JSONWebKeySet returns the public signing key as a JSON Web Key Set
+func JSONWebKeySet() map[string]interface{} {
+	keys := make([]map[string]string, 0, 1)
+	if signingKey != nil {
+		keys = append(keys, map[string]string{
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"kid": SigningKeyID(),
+			"n":   base64.RawURLEncoding.EncodeToString(signingKey.PublicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signingKey.PublicKey.E)).Bytes()),
+		})
+	}
+	return map[string]interface{}{
+		"keys": keys,
+	}
+}
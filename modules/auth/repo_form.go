@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/utils"
 
 	"github.com/Unknwon/com"
@@ -34,6 +35,12 @@ type CreateRepoForm struct {
 	Gitignores  string
 	License     string
 	Readme      string
+
+	RepoTemplate int64
+	GitContent   bool
+	Topics       bool
+	Webhooks     bool
+	Labels       bool
 }
 
 // Validate validates the fields
@@ -97,8 +104,16 @@ type RepoSettingForm struct {
 	Interval      string
 	MirrorAddress string
 	Private       bool
+	Template      bool
 	EnablePrune   bool
 
+	// Push mirror settings
+	PushMirrorID       int64
+	PushMirrorAddress  string
+	PushMirrorUsername string
+	PushMirrorPassword string
+	PushMirrorInterval string
+
 	// Advanced settings
 	EnableWiki                       bool
 	EnableExternalWiki               bool
@@ -109,6 +124,7 @@ type RepoSettingForm struct {
 	TrackerURLFormat                 string
 	TrackerIssueStyle                string
 	EnablePulls                      bool
+	EnableProjects                   bool
 	PullsIgnoreWhitespace            bool
 	PullsAllowMerge                  bool
 	PullsAllowRebase                 bool
@@ -117,6 +133,9 @@ type RepoSettingForm struct {
 	AllowOnlyContributorsToTrackTime bool
 	EnableIssueDependencies          bool
 
+	// Code indexer settings
+	IndexerRefs string
+
 	// Admin settings
 	EnableHealthCheck bool
 }
@@ -142,6 +161,9 @@ type ProtectBranchForm struct {
 	EnableMergeWhitelist bool
 	MergeWhitelistUsers  string
 	MergeWhitelistTeams  string
+	RequiredApprovals    int64
+	EnableStatusCheck    bool
+	StatusCheckContexts  string
 }
 
 // Validate validates the fields
@@ -158,17 +180,30 @@ func (f *ProtectBranchForm) Validate(ctx *macaron.Context, errs binding.Errors)
 
 // WebhookForm form for changing web hook
 type WebhookForm struct {
-	Events       string
-	Create       bool
-	Delete       bool
-	Fork         bool
-	Issues       bool
-	IssueComment bool
-	Release      bool
-	Push         bool
-	PullRequest  bool
-	Repository   bool
-	Active       bool
+	Events               string
+	Create               bool
+	Delete               bool
+	Fork                 bool
+	Issues               bool
+	IssueAssign          bool
+	IssueLabel           bool
+	IssueMilestone       bool
+	IssueComment         bool
+	Release              bool
+	Push                 bool
+	PullRequest          bool
+	PullRequestAssign    bool
+	PullRequestLabel     bool
+	PullRequestMilestone bool
+	PullRequestReview    bool
+	PullRequestSync      bool
+	Repository           bool
+	Wiki                 bool
+	Status               bool
+	Star                 bool
+	Watch                bool
+	BranchProtection     bool
+	Active               bool
 }
 
 // PushOnly if the hook will be triggered when push
@@ -256,6 +291,112 @@ func (f *NewDingtalkHookForm) Validate(ctx *macaron.Context, errs binding.Errors
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewMSTeamsHookForm form for creating MS Teams hook
+type NewMSTeamsHookForm struct {
+	PayloadURL string `binding:"Required;ValidUrl"`
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewMSTeamsHookForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewTelegramHookForm form for creating telegram hook
+type NewTelegramHookForm struct {
+	BotToken string `binding:"Required"`
+	ChatID   string `binding:"Required"`
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewTelegramHookForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewMatrixHookForm form for creating matrix hook
+type NewMatrixHookForm struct {
+	HomeserverURL string `binding:"Required;ValidUrl"`
+	RoomID        string `binding:"Required"`
+	AccessToken   string `binding:"Required"`
+	MessageType   string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewMatrixHookForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// NewCustomHookForm form for creating custom hook
+type NewCustomHookForm struct {
+	PayloadURL                   string `binding:"Required;ValidUrl"`
+	ContentType                  int    `binding:"Required"`
+	Template                     string `binding:"Required"`
+	CreateTemplate               string
+	DeleteTemplate               string
+	ForkTemplate                 string
+	IssuesTemplate               string
+	IssueAssignTemplate          string
+	IssueLabelTemplate           string
+	IssueMilestoneTemplate       string
+	IssueCommentTemplate         string
+	PushTemplate                 string
+	PullRequestTemplate          string
+	PullRequestAssignTemplate    string
+	PullRequestLabelTemplate     string
+	PullRequestMilestoneTemplate string
+	PullRequestReviewTemplate    string
+	PullRequestSyncTemplate      string
+	RepositoryTemplate           string
+	ReleaseTemplate              string
+	WikiTemplate                 string
+	StatusTemplate               string
+	StarTemplate                 string
+	WatchTemplate                string
+	BranchProtectionTemplate     string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewCustomHookForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// EventTemplates returns the templates of the events which have their own
+func (f NewCustomHookForm) EventTemplates() map[models.HookEventType]string {
+	templates := make(map[models.HookEventType]string)
+	for event, tpl := range map[models.HookEventType]string{
+		models.HookEventCreate:               f.CreateTemplate,
+		models.HookEventDelete:               f.DeleteTemplate,
+		models.HookEventFork:                 f.ForkTemplate,
+		models.HookEventIssues:               f.IssuesTemplate,
+		models.HookEventIssueAssign:          f.IssueAssignTemplate,
+		models.HookEventIssueLabel:           f.IssueLabelTemplate,
+		models.HookEventIssueMilestone:       f.IssueMilestoneTemplate,
+		models.HookEventIssueComment:         f.IssueCommentTemplate,
+		models.HookEventPush:                 f.PushTemplate,
+		models.HookEventPullRequest:          f.PullRequestTemplate,
+		models.HookEventPullRequestAssign:    f.PullRequestAssignTemplate,
+		models.HookEventPullRequestLabel:     f.PullRequestLabelTemplate,
+		models.HookEventPullRequestMilestone: f.PullRequestMilestoneTemplate,
+		models.HookEventPullRequestReview:    f.PullRequestReviewTemplate,
+		models.HookEventPullRequestSync:      f.PullRequestSyncTemplate,
+		models.HookEventRepository:           f.RepositoryTemplate,
+		models.HookEventRelease:              f.ReleaseTemplate,
+		models.HookEventWiki:                 f.WikiTemplate,
+		models.HookEventStatus:               f.StatusTemplate,
+		models.HookEventStar:                 f.StarTemplate,
+		models.HookEventWatch:                f.WatchTemplate,
+		models.HookEventBranchProtection:     f.BranchProtectionTemplate,
+	} {
+		if strings.TrimSpace(tpl) != "" {
+			templates[event] = tpl
+		}
+	}
+	return templates
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -302,6 +443,31 @@ func (f *ReactionForm) Validate(ctx *macaron.Context, errs binding.Errors) bindi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// IssueLockForm form for locking an issue
+type IssueLockForm struct {
+	Reason string
+}
+
+// Validate validates the fields
+func (f *IssueLockForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// HasValidReason checks to make sure that the reason submitted in
+// the form matches any of the values in the config
+func (f IssueLockForm) HasValidReason() bool {
+	if strings.TrimSpace(f.Reason) == "" {
+		return true
+	}
+
+	for _, v := range setting.Repository.Issue.LockReasons {
+		if v == f.Reason {
+			return true
+		}
+	}
+	return false
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -321,6 +487,37 @@ func (f *CreateMilestoneForm) Validate(ctx *macaron.Context, errs binding.Errors
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// __________                   __               __
+// \______   \_______  ____    |__| ____   _____/  |_
+//  |     ___/\_  __ \/  _ \   |  |/ __ \_/ ___\   __\
+//  |    |     |  | \(  <_> )  |  \  ___/\  \___|  |
+//  |____|     |__|   \____/\__|  |\___  >\___  >__|
+//                         \______|    \/     \/
+
+// CreateProjectForm form for creating and editing a project
+type CreateProjectForm struct {
+	Title        string `binding:"Required;MaxSize(100)"`
+	Content      string
+	BoardType    models.ProjectBoardType
+	CloseBoardID int64
+}
+
+// Validate validates the fields
+func (f *CreateProjectForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// EditProjectBoardForm form for creating and editing a project board
+type EditProjectBoardForm struct {
+	Title   string `binding:"Required;MaxSize(100)"`
+	Sorting int
+}
+
+// Validate validates the fields
+func (f *EditProjectBoardForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .____          ___.          .__
 // |    |   _____ \_ |__   ____ |  |
 // |    |   \__  \ | __ \_/ __ \|  |
@@ -7,6 +7,8 @@ package auth
 import (
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,3 +41,27 @@ func TestSubmitReviewForm_IsEmpty(t *testing.T) {
 		assert.Equal(t, v.expected, v.form.HasEmptyContent())
 	}
 }
+
+func TestIssueLock_HasValidReason(t *testing.T) {
+
+	// the default lock reasons are used
+	assert.Len(t, setting.Repository.Issue.LockReasons, 4)
+
+	cases := []struct {
+		form     IssueLockForm
+		expected bool
+	}{
+		{IssueLockForm{""}, true}, // an empty reason is accepted
+		{IssueLockForm{"Off-topic"}, true},
+		{IssueLockForm{"Too heated"}, true},
+		{IssueLockForm{"Spam"}, true},
+		{IssueLockForm{"Resolved"}, true},
+
+		{IssueLockForm{"ZZZZ"}, false},
+		{IssueLockForm{"I want to lock this issue"}, false},
+	}
+
+	for _, v := range cases {
+		assert.Equal(t, v.expected, v.form.HasValidReason())
+	}
+}
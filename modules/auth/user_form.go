@@ -189,6 +189,18 @@ func (f *AddEmailForm) Validate(ctx *macaron.Context, errs binding.Errors) bindi
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// UpdateNotificationSettingsForm form for updating the notification settings,
+// the values are the names of the enabled notification reasons
+type UpdateNotificationSettingsForm struct {
+	Web   []string
+	Email []string
+}
+
+// Validate validates the fields
+func (f *UpdateNotificationSettingsForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ChangePasswordForm form for changing password
 type ChangePasswordForm struct {
 	OldPassword string `form:"old_password" binding:"MaxSize(255)"`
@@ -226,7 +238,10 @@ func (f *AddKeyForm) Validate(ctx *macaron.Context, errs binding.Errors) binding
 
 // NewAccessTokenForm form for creating access token
 type NewAccessTokenForm struct {
-	Name string `binding:"Required;MaxSize(255)"`
+	Name         string `binding:"Required;MaxSize(255)"`
+	Scope        []string
+	Expires      string
+	Repositories string
 }
 
 // Validate valideates the fields
@@ -234,6 +249,68 @@ func (f *NewAccessTokenForm) Validate(ctx *macaron.Context, errs binding.Errors)
 	return validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// EditOAuth2ApplicationForm form for creating and editing OAuth2 applications
+type EditOAuth2ApplicationForm struct {
+	ApplicationName string `binding:"Required;MaxSize(255)"`
+	RedirectURIs    string `binding:"Required" form:"redirect_uris"`
+}
+
+// Validate valideates the fields
+func (f *EditOAuth2ApplicationForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AuthorizationForm form for authorizing oauth2 clients
+type AuthorizationForm struct {
+	ResponseType string `binding:"Required;In(code)" form:"response_type"`
+	ClientID     string `binding:"Required" form:"client_id"`
+	RedirectURI  string `form:"redirect_uri"`
+	State        string `form:"state"`
+	Scope        string `form:"scope"`
+	Nonce        string `form:"nonce"`
+
+	// PKCE support
+	CodeChallengeMethod string `form:"code_challenge_method"` // S256, plain
+	CodeChallenge       string `form:"code_challenge"`
+}
+
+// Validate valideates the fields
+func (f *AuthorizationForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// GrantApplicationForm form for authorizing oauth2 clients
+type GrantApplicationForm struct {
+	ClientID    string `binding:"Required" form:"client_id"`
+	RedirectURI string `form:"redirect_uri"`
+	State       string `form:"state"`
+	Scope       string `form:"scope"`
+	Nonce       string `form:"nonce"`
+}
+
+// Validate valideates the fields
+func (f *GrantApplicationForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AccessTokenForm for issuing access tokens from authorization codes or refresh tokens
+type AccessTokenForm struct {
+	GrantType    string `form:"grant_type"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+	RedirectURI  string `form:"redirect_uri"`
+	Code         string `form:"code"`
+	RefreshToken string `form:"refresh_token"`
+
+	// PKCE support
+	CodeVerifier string `form:"code_verifier"`
+}
+
+// Validate valideates the fields
+func (f *AccessTokenForm) Validate(ctx *macaron.Context, errs binding.Errors) binding.Errors {
+	return validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // TwoFactorAuthForm for logging in with 2FA token.
 type TwoFactorAuthForm struct {
 	Passcode string `binding:"Required"`
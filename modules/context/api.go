@@ -77,6 +77,13 @@ func (ctx *APIContext) Error(status int, title string, obj interface{}) {
 	})
 }
 
+// AccessToken returns the access token the request is authenticated with,
+// or nil for any other kind of authentication.
+func (ctx *APIContext) AccessToken() *models.AccessToken {
+	t, _ := ctx.Data["ApiToken"].(*models.AccessToken)
+	return t
+}
+
 // SetLinkHeader sets pagination link header by given total number and page size.
 func (ctx *APIContext) SetLinkHeader(total, pageSize int) {
 	page := paginater.New(total, pageSize, ctx.QueryInt("page"), 0)
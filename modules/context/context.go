@@ -271,6 +271,7 @@ func Contexter() macaron.Handler {
 
 		ctx.Data["EnableSwagger"] = setting.API.EnableSwagger
 		ctx.Data["EnableOpenIDSignIn"] = setting.Service.EnableOpenIDSignIn
+		ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 
 		c.Map(ctx)
 	}
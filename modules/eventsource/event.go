@@ -0,0 +1,70 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Event is a server-sent event, as streamed to an EventSource. The empty
+// fields are not written.
+type Event struct {
+	// Name is the type of the event, "message" for the clients when empty
+	Name string
+	// Data is written as is if it is a string or a []byte, as JSON otherwise
+	Data interface{}
+	// ID is the last event ID of the clients
+	ID string
+	// Retry is the time the clients wait before reconnecting
+	Retry time.Duration
+}
+
+// WriteTo writes the event to w in the event stream format
+func (e *Event) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	if len(e.Name) > 0 {
+		writeField(&buf, "event", e.Name)
+	}
+	if len(e.ID) > 0 {
+		writeField(&buf, "id", e.ID)
+	}
+	if e.Retry > 0 {
+		writeField(&buf, "retry", fmt.Sprint(int64(e.Retry/time.Millisecond)))
+	}
+
+	var data string
+	switch v := e.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		bs, err := json.Marshal(v)
+		if err != nil {
+			return 0, err
+		}
+		data = string(bs)
+	}
+	// every line of the data is a field, the clients join them back
+	for _, line := range strings.Split(data, "\n") {
+		writeField(&buf, "data", line)
+	}
+	buf.WriteString("\n")
+
+	return buf.WriteTo(w)
+}
+
+func writeField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	buf.WriteString(": ")
+	buf.WriteString(value)
+	buf.WriteString("\n")
+}
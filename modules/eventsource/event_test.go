@@ -0,0 +1,34 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvent_WriteTo(t *testing.T) {
+	for _, c := range []struct {
+		event    Event
+		expected string
+	}{
+		{Event{Data: "hello"}, "data: hello\n\n"},
+		{Event{Data: []byte("first\nsecond")}, "data: first\ndata: second\n\n"},
+		{
+			Event{Name: "count", ID: "1", Retry: 3 * time.Second, Data: map[string]int{"new": 2}},
+			"event: count\nid: 1\nretry: 3000\ndata: {\"new\":2}\n\n",
+		},
+		{Event{Name: "ping"}, "event: ping\ndata: \n\n"},
+	} {
+		var buf bytes.Buffer
+		n, err := c.event.WriteTo(&buf)
+		assert.NoError(t, err)
+		assert.EqualValues(t, len(c.expected), n)
+		assert.Equal(t, c.expected, buf.String())
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import (
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// EventNotificationCount is the name of the events sending the number of
+// unread notifications of a user
+const EventNotificationCount = "notification-count"
+
+// Manager dispatches the events sent to a user to all the event streams the
+// user is connected to
+type Manager struct {
+	mutex    sync.Mutex
+	channels map[int64]map[chan *Event]struct{}
+}
+
+var (
+	manager     *Manager
+	managerOnce sync.Once
+)
+
+// GetManager returns the Manager of the event streams
+func GetManager() *Manager {
+	managerOnce.Do(func() {
+		manager = &Manager{
+			channels: make(map[int64]map[chan *Event]struct{}),
+		}
+	})
+	return manager
+}
+
+// Register returns the channel of the events sent to the user by a new
+// event stream, it must be unregistered when the stream ends
+func (m *Manager) Register(uid int64) <-chan *Event {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	channels, ok := m.channels[uid]
+	if !ok {
+		channels = make(map[chan *Event]struct{})
+		m.channels[uid] = channels
+	}
+	// the channel is buffered so that the events are not held up by a slow
+	// stream
+	channel := make(chan *Event, 1)
+	channels[channel] = struct{}{}
+	return channel
+}
+
+// Unregister closes the channel of an event stream of the user
+func (m *Manager) Unregister(uid int64, channel <-chan *Event) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	channels, ok := m.channels[uid]
+	if !ok {
+		return
+	}
+	for c := range channels {
+		if c == channel {
+			delete(channels, c)
+			close(c)
+		}
+	}
+	if len(channels) == 0 {
+		delete(m.channels, uid)
+	}
+}
+
+// SendMessage sends an event to all the event streams of the user. The
+// streams which still have an event pending miss it.
+func (m *Manager) SendMessage(uid int64, event *Event) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	for channel := range m.channels[uid] {
+		select {
+		case channel <- event:
+		default:
+		}
+	}
+}
+
+// hasChannels returns whether any user is connected to an event stream
+func (m *Manager) hasChannels() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return len(m.channels) > 0
+}
+
+// sendNotificationCounts sends the number of unread notifications to the
+// connected users whose notifications changed since the given time and
+// returns the time to look up the next changes from
+func (m *Manager) sendNotificationCounts(since util.TimeStamp) util.TimeStamp {
+	now := util.TimeStampNow()
+	if !m.hasChannels() {
+		return now
+	}
+
+	counts, err := models.GetUIDsAndNotificationCounts(since, now)
+	if err != nil {
+		log.Error(4, "GetUIDsAndNotificationCounts: %v", err)
+		return since
+	}
+	for _, count := range counts {
+		m.SendMessage(count.UserID, &Event{
+			Name: EventNotificationCount,
+			Data: &api.NotificationCount{New: count.Count},
+		})
+	}
+	return now
+}
+
+// Init starts looking up the changes of the notifications to send them to
+// the event streams, unless the streams are disabled
+func Init() {
+	if setting.UI.Notification.EventSourceUpdateTime <= 0 {
+		return
+	}
+	go GetManager().run(setting.UI.Notification.EventSourceUpdateTime)
+}
+
+func (m *Manager) run(updateTime time.Duration) {
+	ticker := time.NewTicker(updateTime)
+	defer ticker.Stop()
+	since := util.TimeStampNow()
+	for range ticker.C {
+		since = m.sendNotificationCounts(since)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package eventsource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManager(t *testing.T) {
+	m := &Manager{channels: make(map[int64]map[chan *Event]struct{})}
+	assert.False(t, m.hasChannels())
+
+	first := m.Register(1)
+	second := m.Register(1)
+	other := m.Register(2)
+	assert.True(t, m.hasChannels())
+
+	event := &Event{Name: "count"}
+	m.SendMessage(1, event)
+	assert.Equal(t, event, <-first)
+	assert.Equal(t, event, <-second)
+	assert.Len(t, other, 0)
+
+	// the events are dropped instead of waiting for the slow streams
+	m.SendMessage(2, &Event{Name: "first"})
+	m.SendMessage(2, &Event{Name: "second"})
+	assert.Equal(t, "first", (<-other).Name)
+
+	m.Unregister(1, first)
+	_, ok := <-first
+	assert.False(t, ok)
+	m.Unregister(1, second)
+	m.Unregister(2, other)
+	assert.False(t, m.hasChannels())
+}
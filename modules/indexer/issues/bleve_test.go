@@ -0,0 +1,89 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testIssues() []*IndexerData {
+	return []*IndexerData{
+		{
+			ID:      1,
+			RepoID:  2,
+			Title:   "Issue search",
+			Content: "Searching for issues should work",
+		},
+		{
+			ID:       2,
+			RepoID:   2,
+			Title:    "Another issue",
+			Content:  "Nothing to see here",
+			Comments: []string{"except a comment about searching"},
+		},
+		{
+			ID:      3,
+			RepoID:  3,
+			Title:   "Search in another repository",
+			Content: "This repository is not searched",
+		},
+	}
+}
+
+func testIndexer(t *testing.T, indexer Indexer) {
+	search := func(keyword string, repoID int64) []int64 {
+		res, err := indexer.Search(keyword, repoID, 10, 0)
+		assert.NoError(t, err)
+		ids := make([]int64, 0, len(res.Hits))
+		for _, hit := range res.Hits {
+			assert.EqualValues(t, repoID, hit.RepoID)
+			ids = append(ids, hit.ID)
+		}
+		return ids
+	}
+
+	issues := testIssues()
+	assert.NoError(t, indexer.Index(issues))
+
+	assert.Equal(t, []int64{1}, search("should work", 2))
+	assert.ElementsMatch(t, []int64{1, 2}, search("searching", 2))
+	assert.Equal(t, []int64{2}, search("comment", 2))
+	assert.Equal(t, []int64{3}, search("repository", 3))
+	assert.Empty(t, search("nonexistent", 2))
+
+	issues[1].Comments = nil
+	assert.NoError(t, indexer.Index(issues[1:2]))
+	assert.Empty(t, search("comment", 2))
+
+	assert.NoError(t, indexer.Delete(1, 4))
+	assert.Empty(t, search("searching", 2))
+	assert.Equal(t, []int64{3}, search("repository", 3))
+}
+
+func TestBleveIndexer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bleve.index")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	indexDir := filepath.Join(dir, "issues.bleve")
+
+	indexer := NewBleveIndexer(indexDir)
+	exist, err := indexer.Init()
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	testIndexer(t, indexer)
+	assert.NoError(t, indexer.indexer.Close())
+
+	// the index is kept between two runs
+	indexer = NewBleveIndexer(indexDir)
+	exist, err = indexer.Init()
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.NoError(t, indexer.indexer.Close())
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeElasticSearch is a minimal in-memory Elasticsearch server supporting
+// the requests sent by ElasticSearchIndexer
+type fakeElasticSearch struct {
+	sync.Mutex
+	t       *testing.T
+	indices map[string]map[string]map[string]interface{}
+}
+
+func (f *fakeElasticSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if user, password, ok := r.BasicAuth(); !ok || user != "elastic" || password != "changeme" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	index, exist := f.indices[parts[0]]
+	switch {
+	case len(parts) == 1 && r.Method == "HEAD":
+		if !exist {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case len(parts) == 1 && r.Method == "PUT":
+		var mapping map[string]interface{}
+		assert.NoError(f.t, json.NewDecoder(r.Body).Decode(&mapping))
+		assert.Contains(f.t, mapping, "mappings")
+		f.indices[parts[0]] = map[string]map[string]interface{}{}
+	case !exist:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error": map[string]string{"type": "index_not_found_exception", "reason": "no such index"},
+		})
+	case parts[1] == "_bulk":
+		f.bulk(w, r, index)
+	case parts[1] == "_search":
+		f.search(w, r, index)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (f *fakeElasticSearch) bulk(w http.ResponseWriter, r *http.Request, index map[string]map[string]interface{}) {
+	assert.Equal(f.t, "application/x-ndjson", r.Header.Get("Content-Type"))
+
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		var action map[string]map[string]string
+		assert.NoError(f.t, json.Unmarshal(scanner.Bytes(), &action))
+		if meta, ok := action["index"]; ok {
+			assert.True(f.t, scanner.Scan())
+			var doc map[string]interface{}
+			assert.NoError(f.t, json.Unmarshal(scanner.Bytes(), &doc))
+			index[meta["_id"]] = doc
+		} else if meta, ok := action["delete"]; ok {
+			delete(index, meta["_id"])
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": false})
+}
+
+// search supports a multi_match query filtered by repository, matching the
+// documents whose fields contain the query
+func (f *fakeElasticSearch) search(w http.ResponseWriter, r *http.Request, index map[string]map[string]interface{}) {
+	var req struct {
+		Query struct {
+			Bool struct {
+				Must struct {
+					MultiMatch struct {
+						Query  string   `json:"query"`
+						Fields []string `json:"fields"`
+					} `json:"multi_match"`
+				} `json:"must"`
+				Filter struct {
+					Term struct {
+						RepoID int64 `json:"repo_id"`
+					} `json:"term"`
+				} `json:"filter"`
+			} `json:"bool"`
+		} `json:"query"`
+	}
+	assert.NoError(f.t, json.NewDecoder(r.Body).Decode(&req))
+	match := req.Query.Bool.Must.MultiMatch
+	keyword := strings.ToLower(match.Query)
+
+	hits := []map[string]interface{}{}
+	for _, doc := range index {
+		if int64(doc["repo_id"].(float64)) != req.Query.Bool.Filter.Term.RepoID {
+			continue
+		}
+		data, _ := json.Marshal(doc)
+		var fields map[string]interface{}
+		json.Unmarshal(data, &fields)
+		for _, field := range match.Fields {
+			content, _ := json.Marshal(fields[field])
+			if strings.Contains(strings.ToLower(string(content)), keyword) {
+				hits = append(hits, map[string]interface{}{"_score": 1, "_source": doc})
+				break
+			}
+		}
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"hits": map[string]interface{}{"hits": hits},
+	})
+}
+
+func TestElasticSearchIndexer(t *testing.T) {
+	server := httptest.NewServer(&fakeElasticSearch{
+		t:       t,
+		indices: map[string]map[string]map[string]interface{}{},
+	})
+	defer server.Close()
+	connStr := strings.Replace(server.URL, "http://", "http://elastic:changeme@", 1)
+
+	indexer, err := NewElasticSearchIndexer(connStr, "gitea_issues")
+	assert.NoError(t, err)
+	exist, err := indexer.Init()
+	assert.NoError(t, err)
+	assert.False(t, exist)
+	testIndexer(t, indexer)
+
+	// the index is shared and kept by the cluster
+	indexer, err = NewElasticSearchIndexer(connStr, "gitea_issues")
+	assert.NoError(t, err)
+	exist, err = indexer.Init()
+	assert.NoError(t, err)
+	assert.True(t, exist)
+
+	indexer, err = NewElasticSearchIndexer(server.URL, "gitea_issues")
+	assert.NoError(t, err)
+	_, err = indexer.Init()
+	assert.Error(t, err)
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+// IndexerData data stored in the issue indexer
+type IndexerData struct {
+	ID       int64
+	RepoID   int64
+	Title    string
+	Content  string
+	Comments []string
+}
+
+// Match represents one search result
+type Match struct {
+	ID     int64
+	RepoID int64
+	Score  float64
+}
+
+// SearchResult represents search results
+type SearchResult struct {
+	Hits []Match
+}
+
+// Indexer defines an interface to index issue contents
+type Indexer interface {
+	// Init prepares the index and returns whether it already existed, an
+	// index which did not exist has to be populated
+	Init() (bool, error)
+	// Index adds or updates the given issues
+	Index(issues []*IndexerData) error
+	// Delete removes the issues with the given IDs
+	Delete(ids ...int64) error
+	// Search returns the issues of a repository matching the keyword
+	Search(kw string, repoID int64, limit, start int) (*SearchResult, error)
+}
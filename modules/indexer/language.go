@@ -0,0 +1,138 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexer
+
+import (
+	"path"
+	"strings"
+)
+
+var (
+	// languageFileNames languages of the files recognized by their name
+	languageFileNames = map[string]string{
+		"cmakelists.txt": "CMake",
+		"dockerfile":     "Dockerfile",
+		"gemfile":        "Ruby",
+		"gnumakefile":    "Makefile",
+		"jenkinsfile":    "Groovy",
+		"makefile":       "Makefile",
+		"rakefile":       "Ruby",
+		"vagrantfile":    "Ruby",
+	}
+
+	// languageExtensions languages of the files recognized by their
+	// extension, as named by GitHub Linguist
+	languageExtensions = map[string]string{
+		".as":       "ActionScript",
+		".asm":      "Assembly",
+		".bash":     "Shell",
+		".bat":      "Batchfile",
+		".c":        "C",
+		".cc":       "C++",
+		".clj":      "Clojure",
+		".cljs":     "Clojure",
+		".cmake":    "CMake",
+		".cmd":      "Batchfile",
+		".coffee":   "CoffeeScript",
+		".cpp":      "C++",
+		".cs":       "C#",
+		".css":      "CSS",
+		".cxx":      "C++",
+		".d":        "D",
+		".dart":     "Dart",
+		".elm":      "Elm",
+		".erl":      "Erlang",
+		".ex":       "Elixir",
+		".exs":      "Elixir",
+		".f90":      "Fortran",
+		".fs":       "F#",
+		".go":       "Go",
+		".gradle":   "Gradle",
+		".groovy":   "Groovy",
+		".h":        "C",
+		".hh":       "C++",
+		".hpp":      "C++",
+		".hrl":      "Erlang",
+		".hs":       "Haskell",
+		".htm":      "HTML",
+		".html":     "HTML",
+		".ini":      "INI",
+		".java":     "Java",
+		".jl":       "Julia",
+		".js":       "JavaScript",
+		".json":     "JSON",
+		".jsx":      "JavaScript",
+		".kt":       "Kotlin",
+		".kts":      "Kotlin",
+		".less":     "Less",
+		".lisp":     "Common Lisp",
+		".lua":      "Lua",
+		".m":        "Objective-C",
+		".markdown": "Markdown",
+		".md":       "Markdown",
+		".ml":       "OCaml",
+		".mm":       "Objective-C++",
+		".nim":      "Nim",
+		".php":      "PHP",
+		".pl":       "Perl",
+		".pm":       "Perl",
+		".proto":    "Protocol Buffer",
+		".ps1":      "PowerShell",
+		".py":       "Python",
+		".r":        "R",
+		".rb":       "Ruby",
+		".rs":       "Rust",
+		".rst":      "reStructuredText",
+		".sass":     "Sass",
+		".scala":    "Scala",
+		".scss":     "SCSS",
+		".sh":       "Shell",
+		".sql":      "SQL",
+		".swift":    "Swift",
+		".tex":      "TeX",
+		".tf":       "HCL",
+		".tmpl":     "Go Template",
+		".toml":     "TOML",
+		".ts":       "TypeScript",
+		".tsx":      "TypeScript",
+		".vb":       "Visual Basic",
+		".vue":      "Vue",
+		".xml":      "XML",
+		".yaml":     "YAML",
+		".yml":      "YAML",
+		".zsh":      "Shell",
+	}
+
+	// languageNames languages indexed by their lower case name
+	languageNames = map[string]string{}
+)
+
+func init() {
+	for _, language := range languageFileNames {
+		languageNames[strings.ToLower(language)] = language
+	}
+	for _, language := range languageExtensions {
+		languageNames[strings.ToLower(language)] = language
+	}
+}
+
+// FileLanguage returns the programming language of a file based on its
+// name, or an empty string if it is unknown
+func FileLanguage(filename string) string {
+	name := strings.ToLower(path.Base(filename))
+	if language, ok := languageFileNames[name]; ok {
+		return language
+	}
+	return languageExtensions[path.Ext(name)]
+}
+
+// LanguageName returns the name of a language as returned by FileLanguage,
+// given a name which may differ in case. An unknown name is returned as is.
+func LanguageName(name string) string {
+	if language, ok := languageNames[strings.ToLower(name)]; ok {
+		return language
+	}
+	return name
+}
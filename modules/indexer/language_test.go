@@ -0,0 +1,32 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package indexer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLanguage(t *testing.T) {
+	for filename, language := range map[string]string{
+		"main.go":             "Go",
+		"src/lib/Index.JS":    "JavaScript",
+		"Makefile":            "Makefile",
+		"docker/Dockerfile":   "Dockerfile",
+		"include/string.hpp":  "C++",
+		"README":              "",
+		"archive.tar.unknown": "",
+	} {
+		assert.Equal(t, language, FileLanguage(filename), filename)
+	}
+}
+
+func TestLanguageName(t *testing.T) {
+	assert.Equal(t, "Go", LanguageName("go"))
+	assert.Equal(t, "C++", LanguageName("c++"))
+	assert.Equal(t, "JavaScript", LanguageName("JAVASCRIPT"))
+	assert.Equal(t, "Brainfuck", LanguageName("Brainfuck"))
+}
@@ -11,20 +11,29 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/analysis/analyzer/custom"
 	"github.com/blevesearch/bleve/analysis/token/camelcase"
 	"github.com/blevesearch/bleve/analysis/token/lowercase"
 	"github.com/blevesearch/bleve/analysis/token/unique"
 	"github.com/blevesearch/bleve/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/registry"
 	"github.com/blevesearch/bleve/search/query"
 	"github.com/ethantkoenig/rupture"
 )
 
 const (
-	repoIndexerAnalyzer = "repoIndexerAnalyzer"
-	repoIndexerDocType  = "repoIndexerDocType"
+	repoIndexerAnalyzer        = "repoIndexerAnalyzer"
+	repoIndexerKeywordAnalyzer = "repoIndexerKeywordAnalyzer"
+	repoIndexerDocType         = "repoIndexerDocType"
 
-	repoIndexerLatestVersion = 1
+	repoIndexerLatestVersion = 3
+
+	keywordTokenizerName = "repoIndexerKeywordTokenizer"
+
+	// maxRepoSearchLanguages the maximum number of languages counted in
+	// the results of a search
+	maxRepoSearchLanguages = 10
 )
 
 // repoIndexer (thread-safe) index for repository contents
@@ -43,8 +52,12 @@ const (
 
 // RepoIndexerData data stored in the repo indexer
 type RepoIndexerData struct {
-	RepoID  int64
-	Content string
+	RepoID int64
+	// Ref the full name of the branch or tag, e.g. refs/heads/master
+	Ref      string
+	Filename string
+	Language string
+	Content  string
 }
 
 // Type returns the document type, for bleve's mapping.Classifier interface.
@@ -61,7 +74,7 @@ type RepoIndexerUpdate struct {
 
 // AddToFlushingBatch adds the update to the given flushing batch.
 func (update RepoIndexerUpdate) AddToFlushingBatch(batch rupture.FlushingBatch) error {
-	id := filenameIndexerID(update.Data.RepoID, update.Filepath)
+	id := filenameIndexerID(update.Data.RepoID, update.Data.Ref, update.Filepath)
 	switch update.Op {
 	case RepoIndexerOpUpdate:
 		return batch.Index(id, update.Data)
@@ -104,6 +117,14 @@ func createRepoIndexer() error {
 	textFieldMapping.IncludeInAll = false
 	docMapping.AddFieldMappingsAt("Content", textFieldMapping)
 
+	// refs, file names and languages are matched as a whole
+	keywordFieldMapping := bleve.NewTextFieldMapping()
+	keywordFieldMapping.IncludeInAll = false
+	keywordFieldMapping.Analyzer = repoIndexerKeywordAnalyzer
+	docMapping.AddFieldMappingsAt("Ref", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("Filename", keywordFieldMapping)
+	docMapping.AddFieldMappingsAt("Language", keywordFieldMapping)
+
 	mapping := bleve.NewIndexMapping()
 	if err = addUnicodeNormalizeTokenFilter(mapping); err != nil {
 		return err
@@ -114,25 +135,55 @@ func createRepoIndexer() error {
 		"token_filters": []string{unicodeNormalizeName, camelcase.Name, lowercase.Name, unique.Name},
 	}); err != nil {
 		return err
+	} else if err = mapping.AddCustomAnalyzer(repoIndexerKeywordAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"char_filters":  []string{},
+		"tokenizer":     keywordTokenizerName,
+		"token_filters": []string{},
+	}); err != nil {
+		return err
 	}
 	mapping.DefaultAnalyzer = repoIndexerAnalyzer
 	mapping.AddDocumentMapping(repoIndexerDocType, docMapping)
 	mapping.AddDocumentMapping("_all", bleve.NewDocumentDisabledMapping())
 
 	repoIndexer, err = bleve.New(setting.Indexer.RepoPath, mapping)
-	return err
+	if err != nil {
+		return err
+	}
+	return rupture.WriteIndexMetadata(setting.Indexer.RepoPath, &rupture.IndexMetadata{
+		Version: repoIndexerLatestVersion,
+	})
 }
 
-func filenameIndexerID(repoID int64, filename string) string {
-	return indexerID(repoID) + "_" + filename
-}
+// keywordTokenizer a tokenizer which returns its whole input as one token
+type keywordTokenizer struct{}
 
-func filenameOfIndexerID(indexerID string) string {
-	index := strings.IndexByte(indexerID, '_')
-	if index == -1 {
-		log.Error(4, "Unexpected ID in repo indexer: %s", indexerID)
+func (t *keywordTokenizer) Tokenize(input []byte) analysis.TokenStream {
+	if len(input) == 0 {
+		return analysis.TokenStream{}
+	}
+	return analysis.TokenStream{
+		&analysis.Token{
+			Term:     input,
+			Start:    0,
+			End:      len(input),
+			Position: 1,
+			Type:     analysis.AlphaNumeric,
+		},
 	}
-	return indexerID[index+1:]
+}
+
+func init() {
+	registry.RegisterTokenizer(keywordTokenizerName, func(config map[string]interface{}, cache *registry.Cache) (analysis.Tokenizer, error) {
+		return &keywordTokenizer{}, nil
+	})
+}
+
+// filenameIndexerID returns the ID of a file of a ref in the indexer. Git
+// does not allow colons in ref names, so the ID is never ambiguous.
+func filenameIndexerID(repoID int64, ref, filename string) string {
+	return indexerID(repoID) + "_" + ref + ":" + filename
 }
 
 // RepoIndexerBatch batch to add updates to
@@ -142,8 +193,19 @@ func RepoIndexerBatch() rupture.FlushingBatch {
 
 // DeleteRepoFromIndexer delete all of a repo's files from indexer
 func DeleteRepoFromIndexer(repoID int64) error {
-	query := numericEqualityQuery(repoID, "RepoID")
-	searchRequest := bleve.NewSearchRequestOptions(query, 2147483647, 0, false)
+	return deleteFromRepoIndexer(numericEqualityQuery(repoID, "RepoID"))
+}
+
+// DeleteRefFromIndexer delete the files of a branch or tag of a repo from
+// indexer
+func DeleteRefFromIndexer(repoID int64, ref string) error {
+	refQuery := bleve.NewTermQuery(ref)
+	refQuery.SetField("Ref")
+	return deleteFromRepoIndexer(bleve.NewConjunctionQuery(numericEqualityQuery(repoID, "RepoID"), refQuery))
+}
+
+func deleteFromRepoIndexer(q query.Query) error {
+	searchRequest := bleve.NewSearchRequestOptions(q, 2147483647, 0, false)
 	result, err := repoIndexer.Search(searchRequest)
 	if err != nil {
 		return err
@@ -160,42 +222,73 @@ func DeleteRepoFromIndexer(repoID int64) error {
 // RepoSearchResult result of performing a search in a repo
 type RepoSearchResult struct {
 	RepoID     int64
+	Ref        string
 	StartIndex int
 	EndIndex   int
 	Filename   string
+	Language   string
 	Content    string
 }
 
-// SearchRepoByKeyword searches for files in the specified repo.
-// Returns the matching file-paths
-func SearchRepoByKeyword(repoIDs []int64, keyword string, page, pageSize int) (int64, []*RepoSearchResult, error) {
-	phraseQuery := bleve.NewMatchPhraseQuery(keyword)
-	phraseQuery.FieldVal = "Content"
-	phraseQuery.Analyzer = repoIndexerAnalyzer
+// RepoSearchLanguage number of files of a language matching a search
+type RepoSearchLanguage struct {
+	Language string
+	Count    int
+}
+
+// RepoSearchOptions options of a search in the repo indexer
+type RepoSearchOptions struct {
+	// RepoIDs the repositories to search, all of them if empty
+	RepoIDs []int64
+	// Ref the full name of the branch or tag, all of them if empty
+	Ref     string
+	Keyword string
+	// Language the name of the language of the files, as returned by
+	// FileLanguage
+	Language string
+	// PathPrefix the beginning of the path of the files
+	PathPrefix string
+	Page       int
+	PageSize   int
+}
 
-	var indexerQuery query.Query
-	if len(repoIDs) > 0 {
-		var repoQueries = make([]query.Query, 0, len(repoIDs))
-		for _, repoID := range repoIDs {
+// SearchRepoByKeyword searches for files in the specified repos.
+// Returns the number of matching files, a page of them and the number of
+// matching files of the most common languages
+func SearchRepoByKeyword(opts *RepoSearchOptions) (int64, []*RepoSearchResult, []*RepoSearchLanguage, error) {
+	queries := []query.Query{newMatchPhraseQuery(opts.Keyword, "Content", repoIndexerAnalyzer)}
+	if len(opts.RepoIDs) > 0 {
+		var repoQueries = make([]query.Query, 0, len(opts.RepoIDs))
+		for _, repoID := range opts.RepoIDs {
 			repoQueries = append(repoQueries, numericEqualityQuery(repoID, "RepoID"))
 		}
-
-		indexerQuery = bleve.NewConjunctionQuery(
-			bleve.NewDisjunctionQuery(repoQueries...),
-			phraseQuery,
-		)
-	} else {
-		indexerQuery = phraseQuery
+		queries = append(queries, bleve.NewDisjunctionQuery(repoQueries...))
+	}
+	if len(opts.Ref) > 0 {
+		refQuery := bleve.NewTermQuery(opts.Ref)
+		refQuery.SetField("Ref")
+		queries = append(queries, refQuery)
+	}
+	if len(opts.Language) > 0 {
+		languageQuery := bleve.NewTermQuery(opts.Language)
+		languageQuery.SetField("Language")
+		queries = append(queries, languageQuery)
+	}
+	if len(opts.PathPrefix) > 0 {
+		pathQuery := bleve.NewPrefixQuery(strings.TrimPrefix(opts.PathPrefix, "/"))
+		pathQuery.SetField("Filename")
+		queries = append(queries, pathQuery)
 	}
 
-	from := (page - 1) * pageSize
-	searchRequest := bleve.NewSearchRequestOptions(indexerQuery, pageSize, from, false)
-	searchRequest.Fields = []string{"Content", "RepoID"}
+	from := (opts.Page - 1) * opts.PageSize
+	searchRequest := bleve.NewSearchRequestOptions(bleve.NewConjunctionQuery(queries...), opts.PageSize, from, false)
+	searchRequest.Fields = []string{"Content", "RepoID", "Ref", "Filename", "Language"}
 	searchRequest.IncludeLocations = true
+	searchRequest.AddFacet("languages", bleve.NewFacetRequest("Language", maxRepoSearchLanguages))
 
 	result, err := repoIndexer.Search(searchRequest)
 	if err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
 	}
 
 	searchResults := make([]*RepoSearchResult, len(result.Hits))
@@ -212,13 +305,28 @@ func SearchRepoByKeyword(repoIDs []int64, keyword string, page, pageSize int) (i
 				endIndex = locationEnd
 			}
 		}
+		// files without a known language have no Language field
+		language, _ := hit.Fields["Language"].(string)
 		searchResults[i] = &RepoSearchResult{
 			RepoID:     int64(hit.Fields["RepoID"].(float64)),
+			Ref:        hit.Fields["Ref"].(string),
 			StartIndex: startIndex,
 			EndIndex:   endIndex,
-			Filename:   filenameOfIndexerID(hit.ID),
+			Filename:   hit.Fields["Filename"].(string),
+			Language:   language,
 			Content:    hit.Fields["Content"].(string),
 		}
 	}
-	return int64(result.Total), searchResults, nil
+
+	var languages []*RepoSearchLanguage
+	if facet, ok := result.Facets["languages"]; ok {
+		languages = make([]*RepoSearchLanguage, 0, len(facet.Terms))
+		for _, term := range facet.Terms {
+			languages = append(languages, &RepoSearchLanguage{
+				Language: term.Term,
+				Count:    term.Count,
+			})
+		}
+	}
+	return int64(result.Total), searchResults, languages, nil
 }
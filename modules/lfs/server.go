@@ -16,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
 	"github.com/dgrijalva/jwt-go"
 	"gopkg.in/macaron.v1"
@@ -130,6 +131,11 @@ func getAuthenticatedRepoAndMeta(ctx *context.Context, rv *RequestVars, requireW
 		return nil, nil
 	}
 
+	if requireWrite && repository.IsArchived {
+		writeStatus(ctx, 403)
+		return nil, nil
+	}
+
 	meta, err := repository.GetLFSMetaObjectByOid(rv.Oid)
 	if err != nil {
 		writeStatus(ctx, 404)
@@ -161,7 +167,25 @@ func getContentHandler(ctx *context.Context) {
 		}
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	var decodedFilename []byte
+	if filename := ctx.Params("filename"); len(filename) > 0 {
+		decodedFilename, _ = base64.RawURLEncoding.DecodeString(filename)
+	}
+
+	if setting.LFSStorage.ServeDirect && statusCode == 200 {
+		// Let the client download the object straight from the storage
+		name := meta.Oid
+		if len(decodedFilename) > 0 {
+			name = string(decodedFilename)
+		}
+		if u, err := storage.LFS.URL(meta.RelativePath(), name); err == nil {
+			ctx.Redirect(u.String())
+			logRequest(ctx.Req, http.StatusFound)
+			return
+		}
+	}
+
+	contentStore := &ContentStore{ObjectStorage: storage.LFS}
 	content, err := contentStore.Get(meta, fromByte)
 	if err != nil {
 		writeStatus(ctx, 404)
@@ -171,12 +195,8 @@ func getContentHandler(ctx *context.Context) {
 	ctx.Resp.Header().Set("Content-Length", strconv.FormatInt(meta.Size-fromByte, 10))
 	ctx.Resp.Header().Set("Content-Type", "application/octet-stream")
 
-	filename := ctx.Params("filename")
-	if len(filename) > 0 {
-		decodedFilename, err := base64.RawURLEncoding.DecodeString(filename)
-		if err == nil {
-			ctx.Resp.Header().Set("Content-Disposition", "attachment; filename=\""+string(decodedFilename)+"\"")
-		}
+	if len(decodedFilename) > 0 {
+		ctx.Resp.Header().Set("Content-Disposition", "attachment; filename=\""+string(decodedFilename)+"\"")
 	}
 
 	ctx.Resp.WriteHeader(statusCode)
@@ -230,6 +250,11 @@ func PostHandler(ctx *context.Context) {
 		return
 	}
 
+	if repository.IsArchived {
+		writeStatus(ctx, 403)
+		return
+	}
+
 	if !isOidValid(rv.Oid) {
 		writeStatus(ctx, 404)
 		return
@@ -244,7 +269,7 @@ func PostHandler(ctx *context.Context) {
 	ctx.Resp.Header().Set("Content-Type", metaMediaType)
 
 	sentStatus := 202
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := &ContentStore{ObjectStorage: storage.LFS}
 	if meta.Existing && contentStore.Exists(meta) {
 		sentStatus = 200
 	}
@@ -295,7 +320,12 @@ func BatchHandler(ctx *context.Context) {
 			return
 		}
 
-		contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+		if requireWrite && repository.IsArchived {
+			writeStatus(ctx, 403)
+			return
+		}
+
+		contentStore := &ContentStore{ObjectStorage: storage.LFS}
 
 		meta, err := repository.GetLFSMetaObjectByOid(object.Oid)
 		if err == nil && contentStore.Exists(meta) { // Object is found and exists
@@ -328,7 +358,7 @@ func PutHandler(ctx *context.Context) {
 		return
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := &ContentStore{ObjectStorage: storage.LFS}
 	if err := contentStore.Put(meta, ctx.Req.Body().ReadCloser()); err != nil {
 		ctx.Resp.WriteHeader(500)
 		fmt.Fprintf(ctx.Resp, `{"message":"%s"}`, err)
@@ -360,7 +390,7 @@ func VerifyHandler(ctx *context.Context) {
 		return
 	}
 
-	contentStore := &ContentStore{BasePath: setting.LFS.ContentPath}
+	contentStore := &ContentStore{ObjectStorage: storage.LFS}
 	ok, err := contentStore.Verify(meta)
 	if err != nil {
 		ctx.Resp.WriteHeader(500)
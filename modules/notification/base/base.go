@@ -17,11 +17,13 @@ type Notifier interface {
 	NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Repository)
 	NotifyDeleteRepository(doer *models.User, repo *models.Repository)
 	NotifyForkRepository(doer *models.User, oldRepo, repo *models.Repository)
+	NotifyStarRepository(doer *models.User, repo *models.Repository, star bool)
+	NotifyWatchRepository(doer *models.User, repo *models.Repository, watch bool)
 
 	NotifyNewIssue(*models.Issue)
 	NotifyIssueChangeStatus(*models.User, *models.Issue, bool)
-	NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue)
-	NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, removed bool)
+	NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64)
+	NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool)
 	NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string)
 	NotifyIssueClearLabels(doer *models.User, issue *models.Issue)
 	NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string)
@@ -31,6 +33,7 @@ type Notifier interface {
 	NotifyNewPullRequest(*models.PullRequest)
 	NotifyMergePullRequest(*models.PullRequest, *models.User, *git.Repository)
 	NotifyPullRequestReview(*models.PullRequest, *models.Review, *models.Comment)
+	NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool)
 
 	NotifyCreateIssueComment(*models.User, *models.Repository,
 		*models.Issue, *models.Comment)
@@ -40,4 +43,13 @@ type Notifier interface {
 	NotifyNewRelease(rel *models.Release)
 	NotifyUpdateRelease(doer *models.User, rel *models.Release)
 	NotifyDeleteRelease(doer *models.User, rel *models.Release)
+
+	NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string)
+	NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string)
+	NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string)
+
+	NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, status *models.CommitStatus)
+
+	NotifyUpdateProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch, isNew bool)
+	NotifyDeleteProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch)
 }
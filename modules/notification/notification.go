@@ -9,6 +9,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/notification/base"
 	"code.gitea.io/gitea/modules/notification/ui"
+	"code.gitea.io/gitea/modules/notification/webhook"
 )
 
 var (
@@ -23,6 +24,7 @@ func RegisterNotifier(notifier base.Notifier) {
 
 func init() {
 	RegisterNotifier(ui.NewNotifier())
+	RegisterNotifier(webhook.NewNotifier())
 }
 
 // NotifyCreateIssueComment notifies issue comment related message to notifiers
@@ -68,6 +70,14 @@ func NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comm
 	}
 }
 
+// NotifyPullRequestReviewRequest notifies a review requested from or withdrawn
+// from reviewer to notifiers
+func NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPullRequestReviewRequest(doer, issue, reviewer, isRequest)
+	}
+}
+
 // NotifyUpdateComment notifies update comment to notifiers
 func NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
 	for _, notifier := range notifiers {
@@ -118,9 +128,9 @@ func NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 }
 
 // NotifyIssueChangeMilestone notifies change milestone to notifiers
-func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue) {
+func NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeMilestone(doer, issue)
+		notifier.NotifyIssueChangeMilestone(doer, issue, oldMilestoneID)
 	}
 }
 
@@ -131,10 +141,10 @@ func NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent
 	}
 }
 
-// NotifyIssueChangeAssignee notifies change content to notifiers
-func NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, removed bool) {
+// NotifyIssueChangeAssignee notifies change assignee to notifiers
+func NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool) {
 	for _, notifier := range notifiers {
-		notifier.NotifyIssueChangeAssignee(doer, issue, removed)
+		notifier.NotifyIssueChangeAssignee(doer, issue, assignee, removed)
 	}
 }
 
@@ -173,3 +183,59 @@ func NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Rep
 		notifier.NotifyMigrateRepository(doer, u, repo)
 	}
 }
+
+// NotifyStarRepository notifies star or unstar repository to notifiers
+func NotifyStarRepository(doer *models.User, repo *models.Repository, star bool) {
+	for _, notifier := range notifiers {
+		notifier.NotifyStarRepository(doer, repo, star)
+	}
+}
+
+// NotifyWatchRepository notifies watch or unwatch repository to notifiers
+func NotifyWatchRepository(doer *models.User, repo *models.Repository, watch bool) {
+	for _, notifier := range notifiers {
+		notifier.NotifyWatchRepository(doer, repo, watch)
+	}
+}
+
+// NotifyNewWikiPage notifies new wiki page to notifiers
+func NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyEditWikiPage notifies edit wiki page to notifiers
+func NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyEditWikiPage(doer, repo, page, comment)
+	}
+}
+
+// NotifyDeleteWikiPage notifies delete wiki page to notifiers
+func NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteWikiPage(doer, repo, page)
+	}
+}
+
+// NotifyCreateCommitStatus notifies new commit status to notifiers
+func NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, status *models.CommitStatus) {
+	for _, notifier := range notifiers {
+		notifier.NotifyCreateCommitStatus(doer, repo, status)
+	}
+}
+
+// NotifyUpdateProtectedBranch notifies new or updated branch protection to notifiers
+func NotifyUpdateProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch, isNew bool) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUpdateProtectedBranch(doer, repo, protectBranch, isNew)
+	}
+}
+
+// NotifyDeleteProtectedBranch notifies delete branch protection to notifiers
+func NotifyDeleteProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteProtectedBranch(doer, repo, protectBranch)
+	}
+}
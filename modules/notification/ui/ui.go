@@ -8,17 +8,32 @@ import (
 	"code.gitea.io/git"
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/notification/base"
 )
 
 type (
 	notificationService struct {
-		issueQueue chan issueNotificationOpts
+		issueQueue   chan issueNotificationOpts
+		releaseQueue chan *models.Release
+		statusQueue  chan commitStatusNotificationOpts
 	}
 
 	issueNotificationOpts struct {
 		issue                *models.Issue
 		notificationAuthorID int64
+		// mentions are the names of the users mentioned by the activity
+		mentions []string
+		// reviewRequest notifies the users whose approvals are required by
+		// the protected base branch of the pull request
+		reviewRequest *models.PullRequest
+		receivers     map[int64]models.NotificationReason
+		onlyReceivers bool
+	}
+
+	commitStatusNotificationOpts struct {
+		repo   *models.Repository
+		status *models.CommitStatus
 	}
 )
 
@@ -29,7 +44,9 @@ var (
 // NewNotifier create a new notificationService notifier
 func NewNotifier() base.Notifier {
 	return &notificationService{
-		issueQueue: make(chan issueNotificationOpts, 100),
+		issueQueue:   make(chan issueNotificationOpts, 100),
+		releaseQueue: make(chan *models.Release, 100),
+		statusQueue:  make(chan commitStatusNotificationOpts, 100),
 	}
 }
 
@@ -37,53 +54,139 @@ func (ns *notificationService) Run() {
 	for {
 		select {
 		case opts := <-ns.issueQueue:
-			if err := models.CreateOrUpdateIssueNotifications(opts.issue, opts.notificationAuthorID); err != nil {
+			if err := createIssueNotifications(opts); err != nil {
 				log.Error(4, "Was unable to create issue notification: %v", err)
 			}
+		case rel := <-ns.releaseQueue:
+			if err := models.CreateReleaseNotifications(rel); err != nil {
+				log.Error(4, "Was unable to create release notification: %v", err)
+			}
+		case opts := <-ns.statusQueue:
+			if err := notifyFailedCommitStatus(opts); err != nil {
+				log.Error(4, "Was unable to create commit status notification: %v", err)
+			}
 		}
 	}
 }
 
+func createIssueNotifications(opts issueNotificationOpts) error {
+	receivers := make(map[int64]models.NotificationReason, len(opts.receivers)+len(opts.mentions))
+	for _, userID := range models.GetUserIDsByNames(opts.mentions) {
+		receivers[userID] = models.NotificationReasonMention
+	}
+
+	if pr := opts.reviewRequest; pr != nil {
+		if err := pr.LoadProtectedBranch(); err != nil {
+			return err
+		}
+		if pr.ProtectedBranch != nil {
+			approverIDs, err := pr.ProtectedBranch.GetApproverIDs(pr.BaseRepo)
+			if err != nil {
+				return err
+			}
+			for _, userID := range approverIDs {
+				receivers[userID] = models.NotificationReasonReviewRequested
+			}
+		}
+	}
+
+	for userID, reason := range opts.receivers {
+		receivers[userID] = reason
+	}
+
+	return models.CreateOrUpdateIssueNotifications(models.IssueNotificationOptions{
+		Issue:                opts.issue,
+		NotificationAuthorID: opts.notificationAuthorID,
+		Receivers:            receivers,
+		OnlyReceivers:        opts.onlyReceivers,
+	})
+}
+
+// notifyFailedCommitStatus notifies the posters of the pull requests whose
+// head commit has the failed status
+func notifyFailedCommitStatus(opts commitStatusNotificationOpts) error {
+	prs, err := models.GetUnmergedPullRequestsByHeadCommit(opts.repo, opts.status.SHA)
+	if err != nil {
+		return err
+	}
+
+	for _, pr := range prs {
+		if err := pr.LoadIssue(); err != nil {
+			return err
+		}
+		if err := createIssueNotifications(issueNotificationOpts{
+			issue:                pr.Issue,
+			notificationAuthorID: opts.status.CreatorID,
+			receivers: map[int64]models.NotificationReason{
+				pr.Issue.PosterID: models.NotificationReasonCIFailed,
+			},
+			onlyReceivers: true,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (ns *notificationService) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
 	issue *models.Issue, comment *models.Comment) {
 	ns.issueQueue <- issueNotificationOpts{
-		issue,
-		doer.ID,
+		issue:                issue,
+		notificationAuthorID: doer.ID,
+		mentions:             markup.FindAllMentions(comment.Content),
 	}
 }
 
 func (ns *notificationService) NotifyNewIssue(issue *models.Issue) {
 	ns.issueQueue <- issueNotificationOpts{
-		issue,
-		issue.Poster.ID,
+		issue:                issue,
+		notificationAuthorID: issue.Poster.ID,
+		mentions:             markup.FindAllMentions(issue.Content),
 	}
 }
 
 func (ns *notificationService) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, isClosed bool) {
 	ns.issueQueue <- issueNotificationOpts{
-		issue,
-		doer.ID,
+		issue:                issue,
+		notificationAuthorID: doer.ID,
 	}
 }
 
 func (ns *notificationService) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User, gitRepo *git.Repository) {
 	ns.issueQueue <- issueNotificationOpts{
-		pr.Issue,
-		doer.ID,
+		issue:                pr.Issue,
+		notificationAuthorID: doer.ID,
 	}
 }
 
 func (ns *notificationService) NotifyNewPullRequest(pr *models.PullRequest) {
 	ns.issueQueue <- issueNotificationOpts{
-		pr.Issue,
-		pr.Issue.PosterID,
+		issue:                pr.Issue,
+		notificationAuthorID: pr.Issue.PosterID,
+		mentions:             markup.FindAllMentions(pr.Issue.Content),
+		reviewRequest:        pr,
 	}
 }
 
 func (ns *notificationService) NotifyPullRequestReview(pr *models.PullRequest, r *models.Review, c *models.Comment) {
 	ns.issueQueue <- issueNotificationOpts{
-		pr.Issue,
-		r.Reviewer.ID,
+		issue:                pr.Issue,
+		notificationAuthorID: r.Reviewer.ID,
+		mentions:             markup.FindAllMentions(r.Content),
+	}
+}
+
+func (ns *notificationService) NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool) {
+	if !isRequest {
+		return
+	}
+	ns.issueQueue <- issueNotificationOpts{
+		issue:                issue,
+		notificationAuthorID: doer.ID,
+		receivers: map[int64]models.NotificationReason{
+			reviewer.ID: models.NotificationReasonReviewRequested,
+		},
+		onlyReceivers: true,
 	}
 }
 
@@ -100,6 +203,10 @@ func (ns *notificationService) NotifyForkRepository(doer *models.User, oldRepo,
 }
 
 func (ns *notificationService) NotifyNewRelease(rel *models.Release) {
+	if rel.IsDraft {
+		return
+	}
+	ns.releaseQueue <- rel
 }
 
 func (ns *notificationService) NotifyUpdateRelease(doer *models.User, rel *models.Release) {
@@ -108,13 +215,24 @@ func (ns *notificationService) NotifyUpdateRelease(doer *models.User, rel *model
 func (ns *notificationService) NotifyDeleteRelease(doer *models.User, rel *models.Release) {
 }
 
-func (ns *notificationService) NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue) {
+func (ns *notificationService) NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
 }
 
 func (ns *notificationService) NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
 }
 
-func (ns *notificationService) NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, removed bool) {
+func (ns *notificationService) NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool) {
+	if removed {
+		return
+	}
+	ns.issueQueue <- issueNotificationOpts{
+		issue:                issue,
+		notificationAuthorID: doer.ID,
+		receivers: map[int64]models.NotificationReason{
+			assignee.ID: models.NotificationReasonAssigned,
+		},
+		onlyReceivers: true,
+	}
 }
 
 func (ns *notificationService) NotifyIssueClearLabels(doer *models.User, issue *models.Issue) {
@@ -132,3 +250,34 @@ func (ns *notificationService) NotifyCreateRepository(doer *models.User, u *mode
 
 func (ns *notificationService) NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Repository) {
 }
+
+func (ns *notificationService) NotifyStarRepository(doer *models.User, repo *models.Repository, star bool) {
+}
+
+func (ns *notificationService) NotifyWatchRepository(doer *models.User, repo *models.Repository, watch bool) {
+}
+
+func (ns *notificationService) NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+}
+
+func (ns *notificationService) NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+}
+
+func (ns *notificationService) NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+}
+
+func (ns *notificationService) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, status *models.CommitStatus) {
+	if status.State != models.CommitStatusFailure && status.State != models.CommitStatusError {
+		return
+	}
+	ns.statusQueue <- commitStatusNotificationOpts{
+		repo:   repo,
+		status: status,
+	}
+}
+
+func (ns *notificationService) NotifyUpdateProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch, isNew bool) {
+}
+
+func (ns *notificationService) NotifyDeleteProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+}
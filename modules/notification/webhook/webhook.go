@@ -0,0 +1,277 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	api "code.gitea.io/sdk/gitea"
+)
+
+type webhookNotifier struct {
+}
+
+var (
+	_ base.Notifier = &webhookNotifier{}
+)
+
+// NewNotifier create a new webhookNotifier notifier
+func NewNotifier() base.Notifier {
+	return &webhookNotifier{}
+}
+
+func (m *webhookNotifier) Run() {
+}
+
+// prepareWebhooks adds the webhooks of the repository to the task queue for
+// the given payload and starts their delivery
+func prepareWebhooks(repo *models.Repository, event models.HookEventType, p api.Payloader) {
+	if err := models.PrepareWebhooks(repo, event, p); err != nil {
+		log.Error(4, "PrepareWebhooks [repo_id: %d, event: %s]: %v", repo.ID, event, err)
+		return
+	}
+	go models.HookQueue.Add(repo.ID)
+}
+
+// notifyIssueChange sends the webhooks of a change of an issue or of a pull
+// request, depending on which one the issue is
+func notifyIssueChange(doer *models.User, issue *models.Issue, issueEvent, pullEvent models.HookEventType, action api.HookIssueAction) {
+	if err := issue.LoadAttributes(); err != nil {
+		log.Error(4, "LoadAttributes [issue_id: %d]: %v", issue.ID, err)
+		return
+	}
+
+	mode, _ := models.AccessLevel(doer, issue.Repo)
+	if issue.IsPull {
+		issue.PullRequest.Issue = issue
+		prepareWebhooks(issue.Repo, pullEvent, &api.PullRequestPayload{
+			Action:      action,
+			Index:       issue.Index,
+			PullRequest: issue.PullRequest.APIFormat(),
+			Repository:  issue.Repo.APIFormat(mode),
+			Sender:      doer.APIFormat(),
+		})
+	} else {
+		prepareWebhooks(issue.Repo, issueEvent, &api.IssuePayload{
+			Action:     action,
+			Index:      issue.Index,
+			Issue:      issue.APIFormat(),
+			Repository: issue.Repo.APIFormat(mode),
+			Sender:     doer.APIFormat(),
+		})
+	}
+}
+
+func (m *webhookNotifier) NotifyCreateIssueComment(doer *models.User, repo *models.Repository,
+	issue *models.Issue, comment *models.Comment) {
+}
+
+func (m *webhookNotifier) NotifyNewIssue(issue *models.Issue) {
+}
+
+func (m *webhookNotifier) NotifyIssueChangeStatus(doer *models.User, issue *models.Issue, isClosed bool) {
+}
+
+func (m *webhookNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *models.User, gitRepo *git.Repository) {
+}
+
+func (m *webhookNotifier) NotifyNewPullRequest(pr *models.PullRequest) {
+}
+
+func (m *webhookNotifier) NotifyPullRequestReview(pr *models.PullRequest, review *models.Review, comment *models.Comment) {
+	var reviewType string
+	switch review.Type {
+	case models.ReviewTypeApprove:
+		reviewType = "approved"
+	case models.ReviewTypeReject:
+		reviewType = "rejected"
+	case models.ReviewTypeComment:
+		reviewType = "comment"
+	default:
+		return
+	}
+
+	if err := review.LoadAttributes(); err != nil {
+		log.Error(4, "LoadAttributes [review_id: %d]: %v", review.ID, err)
+		return
+	}
+	if err := pr.LoadAttributes(); err != nil {
+		log.Error(4, "LoadAttributes [pull_id: %d]: %v", pr.ID, err)
+		return
+	} else if err = pr.LoadIssue(); err != nil {
+		log.Error(4, "LoadIssue [pull_id: %d]: %v", pr.ID, err)
+		return
+	} else if err = pr.Issue.LoadAttributes(); err != nil {
+		log.Error(4, "LoadAttributes [issue_id: %d]: %v", pr.IssueID, err)
+		return
+	}
+
+	mode, _ := models.AccessLevel(review.Reviewer, pr.Issue.Repo)
+	prepareWebhooks(pr.Issue.Repo, models.HookEventPullRequestReview, &api.PullRequestPayload{
+		Action:      api.HookIssueReviewed,
+		Index:       pr.Issue.Index,
+		PullRequest: pr.APIFormat(),
+		Repository:  pr.Issue.Repo.APIFormat(mode),
+		Sender:      review.Reviewer.APIFormat(),
+		Review: &api.ReviewPayload{
+			Type:    reviewType,
+			Content: review.Content,
+		},
+	})
+}
+
+func (m *webhookNotifier) NotifyPullRequestReviewRequest(doer *models.User, issue *models.Issue, reviewer *models.User, isRequest bool) {
+}
+
+func (m *webhookNotifier) NotifyUpdateComment(doer *models.User, c *models.Comment, oldContent string) {
+}
+
+func (m *webhookNotifier) NotifyDeleteComment(doer *models.User, c *models.Comment) {
+}
+
+func (m *webhookNotifier) NotifyDeleteRepository(doer *models.User, repo *models.Repository) {
+}
+
+func (m *webhookNotifier) NotifyForkRepository(doer *models.User, oldRepo, repo *models.Repository) {
+}
+
+func (m *webhookNotifier) NotifyStarRepository(doer *models.User, repo *models.Repository, star bool) {
+	action := api.HookStarStarred
+	if !star {
+		action = api.HookStarUnstarred
+	}
+	mode, _ := models.AccessLevel(doer, repo)
+	prepareWebhooks(repo, models.HookEventStar, &api.StarPayload{
+		Action:     action,
+		Repository: repo.APIFormat(mode),
+		Sender:     doer.APIFormat(),
+	})
+}
+
+func (m *webhookNotifier) NotifyWatchRepository(doer *models.User, repo *models.Repository, watch bool) {
+	action := api.HookWatchWatched
+	if !watch {
+		action = api.HookWatchUnwatched
+	}
+	mode, _ := models.AccessLevel(doer, repo)
+	prepareWebhooks(repo, models.HookEventWatch, &api.WatchPayload{
+		Action:     action,
+		Repository: repo.APIFormat(mode),
+		Sender:     doer.APIFormat(),
+	})
+}
+
+func (m *webhookNotifier) NotifyNewRelease(rel *models.Release) {
+}
+
+func (m *webhookNotifier) NotifyUpdateRelease(doer *models.User, rel *models.Release) {
+}
+
+func (m *webhookNotifier) NotifyDeleteRelease(doer *models.User, rel *models.Release) {
+}
+
+func (m *webhookNotifier) NotifyIssueChangeMilestone(doer *models.User, issue *models.Issue, oldMilestoneID int64) {
+	action := api.HookIssueMilestoned
+	if issue.MilestoneID == 0 {
+		action = api.HookIssueDemilestoned
+	}
+	// the milestone loaded before the change must not be sent
+	if issue.Milestone != nil && issue.Milestone.ID != issue.MilestoneID {
+		issue.Milestone = nil
+	}
+	notifyIssueChange(doer, issue, models.HookEventIssueMilestone, models.HookEventPullRequestMilestone, action)
+}
+
+func (m *webhookNotifier) NotifyIssueChangeContent(doer *models.User, issue *models.Issue, oldContent string) {
+}
+
+func (m *webhookNotifier) NotifyIssueChangeAssignee(doer *models.User, issue *models.Issue, assignee *models.User, removed bool) {
+	action := api.HookIssueAssigned
+	if removed {
+		action = api.HookIssueUnassigned
+	}
+	notifyIssueChange(doer, issue, models.HookEventIssueAssign, models.HookEventPullRequestAssign, action)
+}
+
+func (m *webhookNotifier) NotifyIssueClearLabels(doer *models.User, issue *models.Issue) {
+	issue.Labels = nil
+	notifyIssueChange(doer, issue, models.HookEventIssueLabel, models.HookEventPullRequestLabel, api.HookIssueLabelCleared)
+}
+
+func (m *webhookNotifier) NotifyIssueChangeTitle(doer *models.User, issue *models.Issue, oldTitle string) {
+}
+
+func (m *webhookNotifier) NotifyIssueChangeLabels(doer *models.User, issue *models.Issue,
+	addedLabels []*models.Label, removedLabels []*models.Label) {
+	// the labels loaded before the change must not be sent
+	issue.Labels = nil
+	notifyIssueChange(doer, issue, models.HookEventIssueLabel, models.HookEventPullRequestLabel, api.HookIssueLabelUpdated)
+}
+
+func (m *webhookNotifier) NotifyCreateRepository(doer *models.User, u *models.User, repo *models.Repository) {
+}
+
+func (m *webhookNotifier) NotifyMigrateRepository(doer *models.User, u *models.User, repo *models.Repository) {
+}
+
+func (m *webhookNotifier) notifyWikiPage(doer *models.User, repo *models.Repository, action api.HookWikiAction, page, comment string) {
+	mode, _ := models.AccessLevel(doer, repo)
+	prepareWebhooks(repo, models.HookEventWiki, &api.WikiPayload{
+		Action:     action,
+		Repository: repo.APIFormat(mode),
+		Sender:     doer.APIFormat(),
+		Page:       page,
+		Comment:    comment,
+	})
+}
+
+func (m *webhookNotifier) NotifyNewWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	m.notifyWikiPage(doer, repo, api.HookWikiCreated, page, comment)
+}
+
+func (m *webhookNotifier) NotifyEditWikiPage(doer *models.User, repo *models.Repository, page, comment string) {
+	m.notifyWikiPage(doer, repo, api.HookWikiEdited, page, comment)
+}
+
+func (m *webhookNotifier) NotifyDeleteWikiPage(doer *models.User, repo *models.Repository, page string) {
+	m.notifyWikiPage(doer, repo, api.HookWikiDeleted, page, "")
+}
+
+func (m *webhookNotifier) NotifyCreateCommitStatus(doer *models.User, repo *models.Repository, status *models.CommitStatus) {
+	mode, _ := models.AccessLevel(doer, repo)
+	prepareWebhooks(repo, models.HookEventStatus, &api.StatusPayload{
+		SHA:         status.SHA,
+		State:       api.StatusState(status.State),
+		TargetURL:   status.TargetURL,
+		Description: status.Description,
+		Context:     status.Context,
+		Repository:  repo.APIFormat(mode),
+		Sender:      doer.APIFormat(),
+	})
+}
+
+func (m *webhookNotifier) notifyProtectedBranch(doer *models.User, repo *models.Repository, action api.HookBranchProtectionAction, branch string) {
+	mode, _ := models.AccessLevel(doer, repo)
+	prepareWebhooks(repo, models.HookEventBranchProtection, &api.BranchProtectionPayload{
+		Action:     action,
+		Branch:     branch,
+		Repository: repo.APIFormat(mode),
+		Sender:     doer.APIFormat(),
+	})
+}
+
+func (m *webhookNotifier) NotifyUpdateProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch, isNew bool) {
+	action := api.HookBranchProtectionUpdated
+	if isNew {
+		action = api.HookBranchProtectionCreated
+	}
+	m.notifyProtectedBranch(doer, repo, action, protectBranch.BranchName)
+}
+
+func (m *webhookNotifier) NotifyDeleteProtectedBranch(doer *models.User, repo *models.Repository, protectBranch *models.ProtectedBranch) {
+	m.notifyProtectedBranch(doer, repo, api.HookBranchProtectionDeleted, protectBranch.BranchName)
+}
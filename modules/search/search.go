@@ -10,6 +10,7 @@ import (
 	gotemplate "html/template"
 	"strings"
 
+	"code.gitea.io/git"
 	"code.gitea.io/gitea/modules/highlight"
 	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/util"
@@ -17,11 +18,39 @@ import (
 
 // Result a search result to display
 type Result struct {
-	RepoID         int64
+	RepoID int64
+	// Ref the full name of the branch or tag of the file
+	Ref            string
 	Filename       string
+	Language       string
 	HighlightClass string
 	LineNumbers    []int
 	FormattedLines gotemplate.HTML
+	Lines          []*ResultLine
+}
+
+// RefName returns the short name of the branch or tag of the file
+func (r *Result) RefName() string {
+	return git.RefEndName(r.Ref)
+}
+
+// RefSubURL returns the path of the branch or tag of the file relative to
+// the source view of its repository, e.g. "branch/master"
+func (r *Result) RefSubURL() string {
+	if strings.HasPrefix(r.Ref, git.TagPrefix) {
+		return "tag/" + r.RefName()
+	}
+	return "branch/" + r.RefName()
+}
+
+// ResultLine a line of the content around a match
+type ResultLine struct {
+	Number  int
+	Content string
+	// MatchStart and MatchEnd are the byte offsets of the match in the
+	// content, both are -1 if the line does not contain it
+	MatchStart int
+	MatchEnd   int
 }
 
 func indices(content string, selectionStartIndex, selectionEndIndex int) (int, int) {
@@ -67,14 +96,23 @@ func searchResult(result *indexer.RepoSearchResult, startIndex, endIndex int) (*
 
 	contentLines := strings.SplitAfter(result.Content[startIndex:endIndex], "\n")
 	lineNumbers := make([]int, len(contentLines))
+	lines := make([]*ResultLine, len(contentLines))
 	index := startIndex
 	for i, line := range contentLines {
 		var err error
+		lines[i] = &ResultLine{
+			Number:     startLineNum + i,
+			Content:    strings.TrimSuffix(line, "\n"),
+			MatchStart: -1,
+			MatchEnd:   -1,
+		}
 		if index < result.EndIndex &&
 			result.StartIndex < index+len(line) &&
 			result.StartIndex < result.EndIndex {
 			openActiveIndex := util.Max(result.StartIndex-index, 0)
 			closeActiveIndex := util.Min(result.EndIndex-index, len(line))
+			lines[i].MatchStart = openActiveIndex
+			lines[i].MatchEnd = util.Min(closeActiveIndex, len(lines[i].Content))
 			err = writeStrings(&formattedLinesBuffer,
 				`<li>`,
 				html.EscapeString(line[:openActiveIndex]),
@@ -100,22 +138,27 @@ func searchResult(result *indexer.RepoSearchResult, startIndex, endIndex int) (*
 	}
 	return &Result{
 		RepoID:         result.RepoID,
+		Ref:            result.Ref,
 		Filename:       result.Filename,
+		Language:       result.Language,
 		HighlightClass: highlight.FileNameToHighlightClass(result.Filename),
 		LineNumbers:    lineNumbers,
 		FormattedLines: gotemplate.HTML(formattedLinesBuffer.String()),
+		Lines:          lines,
 	}, nil
 }
 
-// PerformSearch perform a search on a repository
-func PerformSearch(repoIDs []int64, keyword string, page, pageSize int) (int, []*Result, error) {
-	if len(keyword) == 0 {
-		return 0, nil, nil
+// PerformSearch perform a search on repositories, it returns the number of
+// matching files, a page of them and the number of matching files of the
+// most common languages
+func PerformSearch(opts *indexer.RepoSearchOptions) (int, []*Result, []*indexer.RepoSearchLanguage, error) {
+	if len(opts.Keyword) == 0 {
+		return 0, nil, nil, nil
 	}
 
-	total, results, err := indexer.SearchRepoByKeyword(repoIDs, keyword, page, pageSize)
+	total, results, languages, err := indexer.SearchRepoByKeyword(opts)
 	if err != nil {
-		return 0, nil, err
+		return 0, nil, nil, err
 	}
 
 	displayResults := make([]*Result, len(results))
@@ -124,8 +167,8 @@ func PerformSearch(repoIDs []int64, keyword string, page, pageSize int) (int, []
 		startIndex, endIndex := indices(result.Content, result.StartIndex, result.EndIndex)
 		displayResults[i], err = searchResult(result, startIndex, endIndex)
 		if err != nil {
-			return 0, nil, err
+			return 0, nil, nil, err
 		}
 	}
-	return int(total), displayResults, nil
+	return int(total), displayResults, languages, nil
 }
@@ -0,0 +1,84 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+// Queue types
+const (
+	ChannelQueueType = "channel"
+	DiskQueueType    = "disk"
+	RedisQueueType   = "redis"
+)
+
+// QueueSettings represents the configuration of a work queue
+type QueueSettings struct {
+	Type string
+	// Length is the maximum number of items waiting in a channel queue
+	Length int
+	// Workers is the number of items handled at the same time
+	Workers int
+	// DataDir is the directory of a disk queue
+	DataDir string
+	// ConnStr is the address of the Redis server of a redis queue, e.g.
+	// redis://:password@127.0.0.1:6379/0
+	ConnStr string
+}
+
+// legacyQueueLength returns the length of a queue as configured before the
+// [queue] sections existed
+func legacyQueueLength(name string) int {
+	switch name {
+	case "issue_indexer", "repo_indexer":
+		return Indexer.UpdateQueueLength
+	case "pr_patch_checker":
+		return Repository.PullRequestQueueLength
+	case "webhook_sender":
+		return Webhook.QueueLength
+	case "mirror", "push_mirror":
+		return Repository.MirrorQueueLength
+	}
+	return 100
+}
+
+// GetQueueSettings returns the settings of the named queue from the
+// [queue.name] section. Keys missing from it are read from the [queue]
+// section.
+func GetQueueSettings(name string) QueueSettings {
+	cfg := Cfg
+	if cfg == nil {
+		cfg = ini.Empty()
+	}
+	sec := cfg.Section("queue." + name)
+	parent := cfg.Section("queue")
+	key := func(name string) *ini.Key {
+		if sec.HasKey(name) {
+			return sec.Key(name)
+		}
+		return parent.Key(name)
+	}
+
+	queue := QueueSettings{
+		Type:    key("TYPE").In(ChannelQueueType, []string{ChannelQueueType, DiskQueueType, RedisQueueType}),
+		Length:  key("LENGTH").MustInt(legacyQueueLength(name)),
+		Workers: key("WORKERS").MustInt(1),
+		DataDir: sec.Key("DATADIR").MustString(filepath.Join(parent.Key("DATADIR").MustString("queues"), name)),
+		ConnStr: key("CONN_STR").MustString("redis://127.0.0.1:6379/0"),
+	}
+	if queue.Length <= 0 {
+		queue.Length = 100
+	}
+	if queue.Workers <= 0 {
+		queue.Workers = 1
+	}
+	if !filepath.IsAbs(queue.DataDir) {
+		queue.DataDir = filepath.Join(AppDataPath, queue.DataDir)
+	}
+	return queue
+}
@@ -177,7 +177,10 @@ var (
 
 	// Indexer settings
 	Indexer struct {
+		IssueType          string
 		IssuePath          string
+		IssueConnStr       string
+		IssueIndexerName   string
 		RepoIndexerEnabled bool
 		RepoPath           string
 		UpdateQueueLength  int
@@ -186,16 +189,22 @@ var (
 
 	// Webhook settings
 	Webhook = struct {
-		QueueLength    int
-		DeliverTimeout int
-		SkipTLSVerify  bool
-		Types          []string
-		PagingNum      int
+		QueueLength          int
+		DeliverTimeout       int
+		SkipTLSVerify        bool
+		Types                []string
+		PagingNum            int
+		MaxAttempts          int
+		RetryBackoff         time.Duration
+		DisableAfterFailures int
 	}{
-		QueueLength:    1000,
-		DeliverTimeout: 5,
-		SkipTLSVerify:  false,
-		PagingNum:      10,
+		QueueLength:          1000,
+		DeliverTimeout:       5,
+		SkipTLSVerify:        false,
+		PagingNum:            10,
+		MaxAttempts:          5,
+		RetryBackoff:         time.Minute,
+		DisableAfterFailures: 10,
 	}
 
 	// Repository settings
@@ -235,6 +244,11 @@ var (
 		PullRequest struct {
 			WorkInProgressPrefixes []string
 		} `ini:"repository.pull-request"`
+
+		// Issue settings
+		Issue struct {
+			LockReasons []string
+		} `ini:"repository.issue"`
 	}{
 		AnsiCharset:            "",
 		ForcePrivate:           false,
@@ -285,6 +299,13 @@ var (
 		}{
 			WorkInProgressPrefixes: defaultPullRequestWorkInProgressPrefixes,
 		},
+
+		// Issue settings
+		Issue: struct {
+			LockReasons []string
+		}{
+			LockReasons: strings.Split("Too heated,Off-topic,Resolved,Spam", ","),
+		},
 	}
 	RepoRootPath string
 	ScriptType   = "bash"
@@ -317,6 +338,9 @@ var (
 			Description string
 			Keywords    string
 		} `ini:"ui.meta"`
+		Notification struct {
+			EventSourceUpdateTime time.Duration
+		} `ini:"ui.notification"`
 	}{
 		ExplorePagingNum:    20,
 		IssuePagingNum:      10,
@@ -353,6 +377,11 @@ var (
 			Description: "Gitea (Git with a cup of tea) is a painless self-hosted Git service written in Go",
 			Keywords:    "go,git,self-hosted,gitea",
 		},
+		Notification: struct {
+			EventSourceUpdateTime time.Duration
+		}{
+			EventSourceUpdateTime: 10 * time.Second,
+		},
 	}
 
 	// Markdown settings
@@ -555,6 +584,30 @@ var (
 		MaxResponseItems: 50,
 	}
 
+	// OAuth2 provider settings
+	OAuth2 = struct {
+		Enable                     bool
+		AccessTokenExpirationTime  int64
+		RefreshTokenExpirationTime int64
+		InvalidateRefreshTokens    bool
+		JWTSigningPrivateKeyFile   string `ini:"JWT_SIGNING_PRIVATE_KEY_FILE"`
+	}{
+		Enable:                     true,
+		AccessTokenExpirationTime:  3600,
+		RefreshTokenExpirationTime: 730,
+		InvalidateRefreshTokens:    false,
+		JWTSigningPrivateKeyFile:   "jwt/private.pem",
+	}
+
+	// Project settings
+	Project = struct {
+		ProjectBoardBasicKanbanType []string
+		ProjectBoardBugTriageType   []string
+	}{
+		ProjectBoardBasicKanbanType: []string{"To Do", "In Progress", "Done"},
+		ProjectBoardBugTriageType:   []string{"Needs Triage", "High Priority", "Low Priority", "Closed"},
+	}
+
 	U2F = struct {
 		AppID         string
 		TrustedFacets []string
@@ -1053,6 +1106,8 @@ func NewContext() {
 		log.Fatal(4, "Failed to map Repository.Local settings: %v", err)
 	} else if err = Cfg.Section("repository.pull-request").MapTo(&Repository.PullRequest); err != nil {
 		log.Fatal(4, "Failed to map Repository.PullRequest settings: %v", err)
+	} else if err = Cfg.Section("repository.issue").MapTo(&Repository.Issue); err != nil {
+		log.Fatal(4, "Failed to map Repository.Issue settings: %v", err)
 	}
 
 	if !filepath.IsAbs(Repository.Upload.TempPath) {
@@ -1119,6 +1174,13 @@ func NewContext() {
 		log.Fatal(4, "Failed to map API settings: %v", err)
 	} else if err = Cfg.Section("metrics").MapTo(&Metrics); err != nil {
 		log.Fatal(4, "Failed to map Metrics settings: %v", err)
+	} else if err = Cfg.Section("oauth2").MapTo(&OAuth2); err != nil {
+		log.Fatal(4, "Failed to map OAuth2 settings: %v", err)
+	} else if err = Cfg.Section("project").MapTo(&Project); err != nil {
+		log.Fatal(4, "Failed to map Project settings: %v", err)
+	}
+	if !filepath.IsAbs(OAuth2.JWTSigningPrivateKeyFile) {
+		OAuth2.JWTSigningPrivateKeyFile = filepath.Join(AppDataPath, OAuth2.JWTSigningPrivateKeyFile)
 	}
 
 	sec = Cfg.Section("mirror")
@@ -1192,6 +1254,8 @@ func NewContext() {
 	U2F.TrustedFacets, _ = shellquote.Split(sec.Key("TRUSTED_FACETS").MustString(strings.TrimRight(AppURL, "/")))
 	U2F.AppID = sec.Key("APP_ID").MustString(strings.TrimRight(AppURL, "/"))
 
+	newStorageService()
+
 	binVersion, err := git.BinVersion()
 	if err != nil {
 		log.Fatal(4, "Error retrieving git version: %v", err)
@@ -1606,8 +1670,14 @@ func newWebhookService() {
 	Webhook.QueueLength = sec.Key("QUEUE_LENGTH").MustInt(1000)
 	Webhook.DeliverTimeout = sec.Key("DELIVER_TIMEOUT").MustInt(5)
 	Webhook.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool()
-	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk"}
+	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "msteams", "telegram", "matrix", "custom"}
 	Webhook.PagingNum = sec.Key("PAGING_NUM").MustInt(10)
+	Webhook.MaxAttempts = sec.Key("MAX_ATTEMPTS").MustInt(5)
+	if Webhook.MaxAttempts < 1 {
+		Webhook.MaxAttempts = 1
+	}
+	Webhook.RetryBackoff = sec.Key("RETRY_BACKOFF").MustDuration(time.Minute)
+	Webhook.DisableAfterFailures = sec.Key("DISABLE_AFTER_FAILURES").MustInt(10)
 }
 
 // NewServices initializes the services
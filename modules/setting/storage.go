@@ -0,0 +1,81 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+// Storage types
+const (
+	LocalStorageType = "local"
+	S3StorageType    = "s3"
+)
+
+// Storage represents the configuration of the storage used by one kind of
+// data (attachments, LFS objects or avatars)
+type Storage struct {
+	Type string
+	// Path is the directory used by the local storage
+	Path string
+	// ServeDirect redirects downloads to the storage instead of serving the
+	// content through Gitea, if the storage supports it
+	ServeDirect bool
+
+	S3Endpoint        string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3Bucket          string
+	S3Location        string
+	S3BasePath        string
+	S3UseSSL          bool
+}
+
+// Storage settings
+var (
+	AttachmentStorage Storage
+	LFSStorage        Storage
+	AvatarStorage     Storage
+)
+
+func newStorageService() {
+	AttachmentStorage = getStorage("attachments", AttachmentPath)
+	LFSStorage = getStorage("lfs", LFS.ContentPath)
+	AvatarStorage = getStorage("avatars", AvatarUploadPath)
+}
+
+// getStorage reads the [storage.name] section. Keys missing from it are
+// taken from the [storage] section, except for the local path which has
+// to be different for each kind of data.
+func getStorage(name, defaultPath string) Storage {
+	sec := Cfg.Section("storage." + name)
+	parent := Cfg.Section("storage")
+	key := func(name string) *ini.Key {
+		if sec.HasKey(name) {
+			return sec.Key(name)
+		}
+		return parent.Key(name)
+	}
+
+	storage := Storage{
+		Type:        key("STORAGE_TYPE").In(LocalStorageType, []string{LocalStorageType, S3StorageType}),
+		Path:        sec.Key("PATH").MustString(defaultPath),
+		ServeDirect: key("SERVE_DIRECT").MustBool(false),
+
+		S3Endpoint:        key("S3_ENDPOINT").MustString("localhost:9000"),
+		S3AccessKeyID:     key("S3_ACCESS_KEY_ID").String(),
+		S3SecretAccessKey: key("S3_SECRET_ACCESS_KEY").String(),
+		S3Bucket:          key("S3_BUCKET").MustString("gitea"),
+		S3Location:        key("S3_LOCATION").MustString("us-east-1"),
+		S3BasePath:        sec.Key("S3_BASE_PATH").MustString(name + "/"),
+		S3UseSSL:          key("S3_USE_SSL").MustBool(false),
+	}
+	if !filepath.IsAbs(storage.Path) {
+		storage.Path = filepath.Join(AppWorkPath, storage.Path)
+	}
+	return storage
+}
@@ -0,0 +1,107 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+var _ ObjectStorage = &LocalStorage{}
+
+// LocalStorage represents a storage in a directory of the local file system
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage returns a local storage in dir, creating it if needed
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+func (l *LocalStorage) buildLocalPath(p string) string {
+	return filepath.Join(l.dir, filepath.FromSlash(path.Clean("/"+p)))
+}
+
+// Open opens a file
+func (l *LocalStorage) Open(path string) (Object, error) {
+	return os.Open(l.buildLocalPath(path))
+}
+
+// Save writes a file. The content is first written to a temporary file so
+// that a failed write does not leave a partial file behind.
+func (l *LocalStorage) Save(path string, r io.Reader, size int64) (int64, error) {
+	p := l.buildLocalPath(path)
+	if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(p), filepath.Base(p)+".tmp")
+	if err != nil {
+		return 0, err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, err
+	}
+	if err = os.Chmod(tmpPath, 0644); err != nil {
+		return 0, err
+	}
+	if err = os.Rename(tmpPath, p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Stat returns the info of a file
+func (l *LocalStorage) Stat(path string) (os.FileInfo, error) {
+	return os.Stat(l.buildLocalPath(path))
+}
+
+// Delete deletes a file
+func (l *LocalStorage) Delete(path string) error {
+	return os.Remove(l.buildLocalPath(path))
+}
+
+// URL is not supported by the local storage
+func (l *LocalStorage) URL(path, name string) (*url.URL, error) {
+	return nil, ErrURLNotSupported
+}
+
+// IterateObjects iterates over all files of the storage
+func (l *LocalStorage) IterateObjects(fn func(path string, obj Object) error) error {
+	return filepath.Walk(l.dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(l.dir, p)
+		if err != nil {
+			return err
+		}
+		obj, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer obj.Close()
+		return fn(filepath.ToSlash(relPath), obj)
+	})
+}
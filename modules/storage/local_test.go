@@ -0,0 +1,69 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testStorage(t *testing.T, s ObjectStorage) {
+	n, err := s.Save("a/b/object", strings.NewReader("hello world"), -1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, n)
+	_, err = s.Save("c", strings.NewReader("gitea"), 5)
+	assert.NoError(t, err)
+
+	fi, err := s.Stat("a/b/object")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, fi.Size())
+	_, err = s.Stat("missing")
+	assert.True(t, os.IsNotExist(err))
+
+	obj, err := s.Open("a/b/object")
+	assert.NoError(t, err)
+	_, err = obj.Seek(6, os.SEEK_SET)
+	assert.NoError(t, err)
+	data, err := ioutil.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "world", string(data))
+	assert.NoError(t, obj.Close())
+
+	objects := map[string]string{}
+	assert.NoError(t, s.IterateObjects(func(path string, obj Object) error {
+		data, err := ioutil.ReadAll(obj)
+		objects[path] = string(data)
+		return err
+	}))
+	assert.Equal(t, map[string]string{"a/b/object": "hello world", "c": "gitea"}, objects)
+
+	assert.NoError(t, s.Delete("c"))
+	_, err = s.Stat("c")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLocalStorage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := NewLocalStorage(filepath.Join(dir, "data"))
+	assert.NoError(t, err)
+	testStorage(t, s)
+
+	_, err = s.URL("a/b/object", "object.txt")
+	assert.Equal(t, ErrURLNotSupported, err)
+
+	// paths cannot leave the directory of the storage
+	_, err = s.Save("../outside", strings.NewReader("x"), 1)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "outside"))
+	assert.True(t, os.IsNotExist(err))
+}
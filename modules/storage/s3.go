@@ -0,0 +1,386 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+)
+
+var _ ObjectStorage = &S3Storage{}
+
+// presignedURLExpiry is the lifetime of the URLs returned by S3Storage.URL
+const presignedURLExpiry = 5 * time.Minute
+
+// S3Options represents the options of a S3 compatible storage
+type S3Options struct {
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	Location        string
+	BasePath        string
+	UseSSL          bool
+}
+
+// S3Storage represents a storage in a bucket of a S3 compatible server.
+// Objects are addressed in path style (endpoint/bucket/key) which is
+// understood by Amazon S3 as well as MinIO and other implementations.
+type S3Storage struct {
+	endpoint *url.URL
+	bucket   string
+	basePath string
+	signer   *s3Signer
+	client   *http.Client
+}
+
+// S3Error represents an error response of the S3 API
+type S3Error struct {
+	StatusCode int    `xml:"-"`
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+func (err *S3Error) Error() string {
+	if err.Code == "" {
+		return fmt.Sprintf("S3 error: %s", http.StatusText(err.StatusCode))
+	}
+	return fmt.Sprintf("S3 error %s: %s", err.Code, err.Message)
+}
+
+// NewS3Storage returns a S3 storage, the bucket is created if it does not
+// exist yet
+func NewS3Storage(opts S3Options) (*S3Storage, error) {
+	scheme := "http"
+	if opts.UseSSL {
+		scheme = "https"
+	}
+	endpoint, err := url.Parse(scheme + "://" + opts.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Location == "" {
+		opts.Location = "us-east-1"
+	}
+
+	s := &S3Storage{
+		endpoint: endpoint,
+		bucket:   opts.Bucket,
+		basePath: strings.Trim(opts.BasePath, "/"),
+		signer: &s3Signer{
+			accessKeyID:     opts.AccessKeyID,
+			secretAccessKey: opts.SecretAccessKey,
+			region:          opts.Location,
+		},
+		client: http.DefaultClient,
+	}
+
+	resp, err := s.do("HEAD", "", nil, nil, 0)
+	if err == nil {
+		resp.Body.Close()
+		return s, nil
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var body io.Reader
+	var size int64
+	if opts.Location != "us-east-1" {
+		config := fmt.Sprintf(`<CreateBucketConfiguration><LocationConstraint>%s</LocationConstraint></CreateBucketConfiguration>`, opts.Location)
+		body, size = strings.NewReader(config), int64(len(config))
+	}
+	if resp, err = s.do("PUT", "", nil, body, size); err != nil {
+		return nil, fmt.Errorf("create bucket %s: %v", s.bucket, err)
+	}
+	resp.Body.Close()
+	return s, nil
+}
+
+func (s *S3Storage) objectKey(p string) string {
+	return strings.TrimPrefix(path.Join(s.basePath, path.Clean("/"+p)), "/")
+}
+
+func (s *S3Storage) newRequest(method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	u := *s.endpoint
+	u.Path = "/" + s.bucket
+	u.RawPath = "/" + s3Escape(s.bucket, false)
+	if key != "" {
+		u.Path += "/" + key
+		u.RawPath += "/" + s3Escape(key, true)
+	}
+	u.RawQuery = canonicalQuery(query)
+	return http.NewRequest(method, u.String(), body)
+}
+
+// do sends a signed request for the object key, or for the bucket if key
+// is empty. A missing bucket or object is reported as os.ErrNotExist.
+func (s *S3Storage) do(method, key string, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	req, err := s.newRequest(method, key, nil, body)
+	if err != nil {
+		return nil, err
+	}
+	return s.doRequest(req, header, size)
+}
+
+func (s *S3Storage) doRequest(req *http.Request, header http.Header, size int64) (*http.Response, error) {
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	payloadHash := emptySHA256
+	if req.Body != nil {
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	s.signer.sign(req, payloadHash, time.Now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, &os.PathError{Op: req.Method, Path: req.URL.Path, Err: os.ErrNotExist}
+	}
+	s3Err := &S3Error{StatusCode: resp.StatusCode}
+	if data, err := ioutil.ReadAll(resp.Body); err == nil && len(data) > 0 {
+		xml.Unmarshal(data, s3Err)
+	}
+	return nil, s3Err
+}
+
+// Open opens an object. The content is only requested once it is read, so
+// that a Seek before the first Read turns into a ranged request.
+func (s *S3Storage) Open(path string) (Object, error) {
+	fi, err := s.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &s3Object{storage: s, key: s.objectKey(path), size: fi.Size()}, nil
+}
+
+// Save stores an object. If its size is unknown, the content is buffered in
+// a temporary file first as S3 needs the length of uploads beforehand.
+// Objects are uploaded in a single request, which limits them to 5GB.
+func (s *S3Storage) Save(path string, r io.Reader, size int64) (int64, error) {
+	if size < 0 {
+		tmp, err := ioutil.TempFile("", "gitea-s3")
+		if err != nil {
+			return 0, err
+		}
+		defer os.Remove(tmp.Name())
+		defer tmp.Close()
+
+		if size, err = io.Copy(tmp, r); err != nil {
+			return 0, err
+		}
+		if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+			return 0, err
+		}
+		r = tmp
+	}
+
+	var body io.Reader = http.NoBody
+	if size > 0 {
+		body = ioutil.NopCloser(io.LimitReader(r, size))
+	}
+	header := http.Header{"Content-Type": []string{"application/octet-stream"}}
+	resp, err := s.do("PUT", s.objectKey(path), header, body, size)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return size, nil
+}
+
+type s3FileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (fi *s3FileInfo) Name() string       { return fi.name }
+func (fi *s3FileInfo) Size() int64        { return fi.size }
+func (fi *s3FileInfo) Mode() os.FileMode  { return 0644 }
+func (fi *s3FileInfo) ModTime() time.Time { return fi.modTime }
+func (fi *s3FileInfo) IsDir() bool        { return false }
+func (fi *s3FileInfo) Sys() interface{}   { return nil }
+
+// Stat returns the info of an object
+func (s *S3Storage) Stat(p string) (os.FileInfo, error) {
+	resp, err := s.do("HEAD", s.objectKey(p), nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	modTime, _ := http.ParseTime(resp.Header.Get("Last-Modified"))
+	return &s3FileInfo{
+		name:    path.Base(p),
+		size:    resp.ContentLength,
+		modTime: modTime,
+	}, nil
+}
+
+// Delete deletes an object
+func (s *S3Storage) Delete(path string) error {
+	resp, err := s.do("DELETE", s.objectKey(path), nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// URL returns a presigned URL to download an object as name
+func (s *S3Storage) URL(path, name string) (*url.URL, error) {
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	query := url.Values{}
+	query.Set("response-content-disposition", disposition)
+	req, err := s.newRequest("GET", s.objectKey(path), query, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.signer.presign(req, presignedURLExpiry, time.Now())
+	return req.URL, nil
+}
+
+type listBucketResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+}
+
+// IterateObjects iterates over all objects below the base path of the storage
+func (s *S3Storage) IterateObjects(fn func(path string, obj Object) error) error {
+	prefix := ""
+	if s.basePath != "" {
+		prefix = s.basePath + "/"
+	}
+
+	query := url.Values{}
+	query.Set("list-type", "2")
+	query.Set("prefix", prefix)
+	for {
+		req, err := s.newRequest("GET", "", query, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := s.doRequest(req, nil, 0)
+		if err != nil {
+			return err
+		}
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, content := range result.Contents {
+			obj := &s3Object{storage: s, key: content.Key, size: content.Size}
+			err := fn(strings.TrimPrefix(content.Key, prefix), obj)
+			obj.Close()
+			if err != nil {
+				return err
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// s3Object reads an object with ranged requests
+type s3Object struct {
+	storage *S3Storage
+	key     string
+	offset  int64
+	size    int64
+	body    io.ReadCloser
+}
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.body == nil {
+		if o.size >= 0 && o.offset >= o.size {
+			return 0, io.EOF
+		}
+		var header http.Header
+		if o.offset > 0 {
+			header = http.Header{"Range": []string{fmt.Sprintf("bytes=%d-", o.offset)}}
+		}
+		resp, err := o.storage.do("GET", o.key, header, nil, 0)
+		if err != nil {
+			if s3Err, ok := err.(*S3Error); ok && s3Err.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+				return 0, io.EOF
+			}
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		if o.size < 0 {
+			resp, err := o.storage.do("HEAD", o.key, nil, nil, 0)
+			if err != nil {
+				return 0, err
+			}
+			resp.Body.Close()
+			o.size = resp.ContentLength
+		}
+		offset += o.size
+	default:
+		return 0, errors.New("Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek: invalid offset")
+	}
+
+	if offset != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+func (o *s3Object) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrURLNotSupported is returned by storages which cannot give direct
+// access to their objects
+var ErrURLNotSupported = errors.New("url method not supported")
+
+// Object represents an object read from a storage
+type Object interface {
+	io.ReadCloser
+	io.Seeker
+}
+
+// ObjectStorage represents a storage of objects addressed by a relative path
+type ObjectStorage interface {
+	// Open opens the object stored at path
+	Open(path string) (Object, error)
+	// Save stores the content of r at path and returns the number of bytes
+	// written. size may be -1 if the size of the content is unknown.
+	Save(path string, r io.Reader, size int64) (int64, error)
+	// Stat returns the information of the object stored at path, the error
+	// satisfies os.IsNotExist if there is no such object
+	Stat(path string) (os.FileInfo, error)
+	// Delete removes the object stored at path
+	Delete(path string) error
+	// URL returns a temporary URL to download the object stored at path
+	// with the given file name, or ErrURLNotSupported
+	URL(path, name string) (*url.URL, error)
+	// IterateObjects calls fn for every object of the storage
+	IterateObjects(fn func(path string, obj Object) error) error
+}
+
+// Copy copies the object at srcPath in srcStorage to dstPath in dstStorage
+func Copy(dstStorage ObjectStorage, dstPath string, srcStorage ObjectStorage, srcPath string) (int64, error) {
+	f, err := srcStorage.Open(srcPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	size := int64(-1)
+	if fi, err := srcStorage.Stat(srcPath); err == nil {
+		size = fi.Size()
+	}
+	return dstStorage.Save(dstPath, f, size)
+}
+
+var (
+	// Attachments represents attachments storage
+	Attachments ObjectStorage
+	// LFS represents LFS objects storage
+	LFS ObjectStorage
+	// Avatars represents user avatars storage
+	Avatars ObjectStorage
+)
+
+// Init initializes the storages from the settings
+func Init() (err error) {
+	if Attachments, err = NewStorage(setting.AttachmentStorage); err != nil {
+		return fmt.Errorf("attachments storage: %v", err)
+	}
+	if LFS, err = NewStorage(setting.LFSStorage); err != nil {
+		return fmt.Errorf("LFS storage: %v", err)
+	}
+	if Avatars, err = NewStorage(setting.AvatarStorage); err != nil {
+		return fmt.Errorf("avatars storage: %v", err)
+	}
+	return nil
+}
+
+// NewStorage creates a storage from its configuration
+func NewStorage(cfg setting.Storage) (ObjectStorage, error) {
+	switch cfg.Type {
+	case setting.LocalStorageType:
+		return NewLocalStorage(cfg.Path)
+	case setting.S3StorageType:
+		return NewS3Storage(S3Options{
+			Endpoint:        cfg.S3Endpoint,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+			Bucket:          cfg.S3Bucket,
+			Location:        cfg.S3Location,
+			BasePath:        cfg.S3BasePath,
+			UseSSL:          cfg.S3UseSSL,
+		})
+	}
+	return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/Unknwon/com"
+)
+
+// ErrQueueClosed is returned by Pop once the queue is closed
+var ErrQueueClosed = errors.New("queue closed")
+
+// Queue is a queue of items which guarantees only one instance of an item
+// is in the line. Pushing an item which is already waiting in the queue
+// has no effect.
+type Queue interface {
+	// Push adds an item to the queue
+	Push(data string) error
+	// Pop removes the oldest item from the queue, waiting for one if the
+	// queue is empty. ErrQueueClosed is returned once the queue is closed.
+	Pop() (string, error)
+	// TryPop removes the oldest item from the queue without waiting, ok is
+	// false if the queue is empty
+	TryPop() (data string, ok bool, err error)
+	// Done acknowledges that a popped item has been handled. A persistent
+	// queue keeps the popped items until then, so that they are handled
+	// again after a restart.
+	Done(data string) error
+	// Has returns true if the item is waiting in the queue
+	Has(data string) (bool, error)
+	// Close stops the waiting Pop calls and releases the queue
+	Close() error
+}
+
+// NewQueue creates a queue from its settings
+func NewQueue(name string, cfg setting.QueueSettings) (Queue, error) {
+	switch cfg.Type {
+	case setting.ChannelQueueType:
+		return NewChannelQueue(cfg.Length), nil
+	case setting.DiskQueueType:
+		return NewDiskQueue(cfg.DataDir)
+	case setting.RedisQueueType:
+		return NewRedisQueue(cfg.ConnStr, name)
+	}
+	return nil, fmt.Errorf("unsupported queue type: %s", cfg.Type)
+}
+
+// WorkQueue is a named queue of work handled in the background. Its backend
+// and number of workers are read from the [queue] settings when it is
+// first used, so that it can be declared before the settings are loaded.
+type WorkQueue struct {
+	name    string
+	lock    sync.Mutex
+	queue   Queue
+	workers int
+	// running holds the items being handled, the value is true if an item
+	// was popped again meanwhile and has to be handled once more
+	running map[string]bool
+}
+
+// NewWorkQueue returns a new WorkQueue
+func NewWorkQueue(name string) *WorkQueue {
+	return &WorkQueue{
+		name:    name,
+		running: make(map[string]bool),
+	}
+}
+
+// SetQueue replaces the backend of the queue, it has to be called before
+// the queue is used
+func (q *WorkQueue) SetQueue(queue Queue, workers int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	q.queue, q.workers = queue, workers
+}
+
+func (q *WorkQueue) get() (Queue, int) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.queue != nil {
+		return q.queue, q.workers
+	}
+
+	cfg := setting.GetQueueSettings(q.name)
+	queue, err := NewQueue(q.name, cfg)
+	if err != nil {
+		// the work is still done, but it does not survive a restart
+		log.Error(4, "Unable to create %s queue %s, using an in-memory queue: %v", cfg.Type, q.name, err)
+		queue = NewChannelQueue(cfg.Length)
+	}
+	q.queue, q.workers = queue, cfg.Workers
+	return q.queue, q.workers
+}
+
+// Add adds an instance with the given identity to the queue, unless one is
+// already waiting
+func (q *WorkQueue) Add(id interface{}) {
+	queue, _ := q.get()
+	if err := queue.Push(com.ToStr(id)); err != nil {
+		log.Error(4, "Unable to add %v to queue %s: %v", id, q.name, err)
+	}
+}
+
+// Exist returns true if an instance with given identity is waiting in the
+// queue
+func (q *WorkQueue) Exist(id interface{}) bool {
+	queue, _ := q.get()
+	has, err := queue.Has(com.ToStr(id))
+	if err != nil {
+		log.Error(4, "Unable to look for %v in queue %s: %v", id, q.name, err)
+	}
+	return has
+}
+
+// Run handles the instances of the queue with the configured number of
+// workers, until the queue is closed. An instance is never handled by two
+// workers at the same time.
+func (q *WorkQueue) Run(handle func(id string)) {
+	q.RunBatch(1, func(ids []string) {
+		handle(ids[0])
+	})
+}
+
+// RunBatch is like Run, but each worker handles up to size of the instances
+// waiting in the queue at once
+func (q *WorkQueue) RunBatch(size int, handle func(ids []string)) {
+	queue, workers := q.get()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				ids, err := q.pop(queue, size)
+				if err == ErrQueueClosed {
+					return
+				} else if err != nil {
+					log.Error(4, "Unable to read queue %s: %v", q.name, err)
+					time.Sleep(time.Second)
+					continue
+				}
+				for len(ids) > 0 {
+					handle(ids)
+					ids = q.finish(queue, ids)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// pop waits for the next instances of the queue and returns up to size of
+// them, leaving out the ones another worker is handling
+func (q *WorkQueue) pop(queue Queue, size int) ([]string, error) {
+	id, err := queue.Pop()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, size)
+	if q.start(id) {
+		ids = append(ids, id)
+	}
+	for len(ids) < size {
+		id, ok, err := queue.TryPop()
+		if err != nil {
+			log.Error(4, "Unable to read queue %s: %v", q.name, err)
+			break
+		} else if !ok {
+			break
+		}
+		if q.start(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// start marks an instance as being handled, it returns false if another
+// worker is handling it already, which then handles it once more
+func (q *WorkQueue) start(id string) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if _, running := q.running[id]; running {
+		q.running[id] = true
+		return false
+	}
+	q.running[id] = false
+	return true
+}
+
+// finish acknowledges the handled instances and returns the ones which
+// were popped again while being handled
+func (q *WorkQueue) finish(queue Queue, ids []string) []string {
+	var again, done []string
+	q.lock.Lock()
+	for _, id := range ids {
+		if q.running[id] {
+			q.running[id] = false
+			again = append(again, id)
+		} else {
+			delete(q.running, id)
+			done = append(done, id)
+		}
+	}
+	q.lock.Unlock()
+
+	for _, id := range done {
+		if err := queue.Done(id); err != nil {
+			log.Error(4, "Unable to acknowledge %s in queue %s: %v", id, q.name, err)
+		}
+	}
+	return again
+}
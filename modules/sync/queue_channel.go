@@ -0,0 +1,80 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+var _ Queue = &ChannelQueue{}
+
+// ChannelQueue is an in-memory queue, its content is lost on restart
+type ChannelQueue struct {
+	table  *StatusTable
+	queue  chan string
+	closed chan struct{}
+}
+
+// NewChannelQueue initializes and returns a new ChannelQueue which holds
+// up to queueLength items, Push blocks while it is full.
+func NewChannelQueue(queueLength int) *ChannelQueue {
+	if queueLength <= 0 {
+		queueLength = 100
+	}
+
+	return &ChannelQueue{
+		table:  NewStatusTable(),
+		queue:  make(chan string, queueLength),
+		closed: make(chan struct{}),
+	}
+}
+
+// Push adds an item to the queue
+func (q *ChannelQueue) Push(data string) error {
+	if !q.table.StartIfNotRunning(data) {
+		return nil
+	}
+	select {
+	case q.queue <- data:
+		return nil
+	case <-q.closed:
+		q.table.Stop(data)
+		return ErrQueueClosed
+	}
+}
+
+// Pop removes the oldest item from the queue
+func (q *ChannelQueue) Pop() (string, error) {
+	select {
+	case data := <-q.queue:
+		q.table.Stop(data)
+		return data, nil
+	case <-q.closed:
+		return "", ErrQueueClosed
+	}
+}
+
+// TryPop removes the oldest item from the queue without waiting
+func (q *ChannelQueue) TryPop() (string, bool, error) {
+	select {
+	case data := <-q.queue:
+		q.table.Stop(data)
+		return data, true, nil
+	default:
+		return "", false, nil
+	}
+}
+
+// Done does nothing as the content of the queue is lost on restart anyway
+func (q *ChannelQueue) Done(data string) error {
+	return nil
+}
+
+// Has returns true if the item is waiting in the queue
+func (q *ChannelQueue) Has(data string) (bool, error) {
+	return q.table.IsRunning(data), nil
+}
+
+// Close closes the queue, the waiting items are dropped
+func (q *ChannelQueue) Close() error {
+	close(q.closed)
+	return nil
+}
@@ -0,0 +1,184 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/boltdb/bolt"
+)
+
+var _ Queue = &DiskQueue{}
+
+var (
+	diskQueueItemsBucket   = []byte("items")
+	diskQueueIndexBucket   = []byte("index")
+	diskQueueRunningBucket = []byte("running")
+)
+
+// DiskQueue is a queue persisted in a local database file, so that its
+// content survives a restart. The file can only be used by one process.
+type DiskQueue struct {
+	db       *bolt.DB
+	notifier chan struct{}
+	closed   chan struct{}
+}
+
+// NewDiskQueue opens or creates a DiskQueue in the given directory
+func NewDiskQueue(dataDir string) (*DiskQueue, error) {
+	if err := os.MkdirAll(dataDir, os.ModePerm); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(dataDir, "queue.db"), 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	if err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{diskQueueItemsBucket, diskQueueIndexBucket, diskQueueRunningBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	q := &DiskQueue{
+		db:       db,
+		notifier: make(chan struct{}, 1),
+		closed:   make(chan struct{}),
+	}
+	if err = q.requeueRunning(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// requeueRunning adds back the items which were popped but not handled
+// before the queue was closed
+func (q *DiskQueue) requeueRunning() error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		running := tx.Bucket(diskQueueRunningBucket)
+		cursor := running.Cursor()
+		for key, _ := cursor.First(); key != nil; key, _ = cursor.First() {
+			if err := push(tx, string(key)); err != nil {
+				return err
+			}
+			if err := running.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// push adds an item to the queue in the transaction, unless it is waiting
+// already
+func push(tx *bolt.Tx, data string) error {
+	index := tx.Bucket(diskQueueIndexBucket)
+	if index.Get([]byte(data)) != nil {
+		return nil
+	}
+
+	items := tx.Bucket(diskQueueItemsBucket)
+	seq, err := items.NextSequence()
+	if err != nil {
+		return err
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	if err = items.Put(key, []byte(data)); err != nil {
+		return err
+	}
+	return index.Put([]byte(data), key)
+}
+
+// Push adds an item to the queue
+func (q *DiskQueue) Push(data string) error {
+	if err := q.db.Update(func(tx *bolt.Tx) error {
+		return push(tx, data)
+	}); err != nil {
+		return err
+	}
+
+	select {
+	case q.notifier <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// TryPop removes the oldest item from the queue without waiting, it is
+// kept aside until Done is called
+func (q *DiskQueue) TryPop() (data string, ok bool, err error) {
+	err = q.db.Update(func(tx *bolt.Tx) error {
+		items := tx.Bucket(diskQueueItemsBucket)
+		key, value := items.Cursor().First()
+		if key == nil {
+			return nil
+		}
+		data, ok = string(value), true
+		if err := items.Delete(key); err != nil {
+			return err
+		}
+		if err := tx.Bucket(diskQueueIndexBucket).Delete(value); err != nil {
+			return err
+		}
+		return tx.Bucket(diskQueueRunningBucket).Put(value, []byte{})
+	})
+	return data, ok, err
+}
+
+// Pop removes the oldest item from the queue
+func (q *DiskQueue) Pop() (string, error) {
+	for {
+		select {
+		case <-q.closed:
+			return "", ErrQueueClosed
+		default:
+		}
+
+		data, ok, err := q.TryPop()
+		if err != nil || ok {
+			return data, err
+		}
+
+		select {
+		case <-q.notifier:
+		case <-q.closed:
+			return "", ErrQueueClosed
+		}
+	}
+}
+
+// Done removes a popped item once it is handled
+func (q *DiskQueue) Done(data string) error {
+	return q.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(diskQueueRunningBucket).Delete([]byte(data))
+	})
+}
+
+// Has returns true if the item is waiting in the queue
+func (q *DiskQueue) Has(data string) (bool, error) {
+	var has bool
+	err := q.db.View(func(tx *bolt.Tx) error {
+		has = tx.Bucket(diskQueueIndexBucket).Get([]byte(data)) != nil
+		return nil
+	})
+	return has, err
+}
+
+// Close closes the queue, the waiting items and the ones not handled yet
+// are kept for the next time it is opened
+func (q *DiskQueue) Close() error {
+	close(q.closed)
+	return q.db.Close()
+}
@@ -0,0 +1,154 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gopkg.in/redis.v2"
+)
+
+var _ Queue = &RedisQueue{}
+
+// RedisQueue is a queue stored in a Redis server, so that its content
+// survives a restart of Gitea. A queue of a name can only be used by one
+// Gitea instance.
+type RedisQueue struct {
+	client     *redis.Client
+	listKey    string
+	setKey     string
+	runningKey string
+	closed     chan struct{}
+}
+
+// parseRedisConnStr parses a connection string of the form
+// redis://:password@host:port/db
+func parseRedisConnStr(connStr string) (*redis.Options, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" {
+		return nil, fmt.Errorf("unsupported Redis URL: %s", connStr)
+	}
+
+	opts := &redis.Options{
+		Network: "tcp",
+		Addr:    u.Host,
+	}
+	if u.User != nil {
+		opts.Password, _ = u.User.Password()
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		if opts.DB, err = strconv.ParseInt(db, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid Redis database %q: %v", db, err)
+		}
+	}
+	return opts, nil
+}
+
+// NewRedisQueue connects to the Redis server of connStr, e.g.
+// redis://:password@127.0.0.1:6379/0, and returns the queue of this name
+func NewRedisQueue(connStr, name string) (*RedisQueue, error) {
+	opts, err := parseRedisConnStr(connStr)
+	if err != nil {
+		return nil, err
+	}
+	client := redis.NewClient(opts)
+	if err = client.Ping().Err(); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	q := &RedisQueue{
+		client:     client,
+		listKey:    "gitea:queue:" + name,
+		setKey:     "gitea:queue:" + name + ":set",
+		runningKey: "gitea:queue:" + name + ":running",
+		closed:     make(chan struct{}),
+	}
+	if err = q.requeueRunning(); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+// requeueRunning adds back the items which were popped but not handled
+// before the queue was closed
+func (q *RedisQueue) requeueRunning() error {
+	for {
+		data, err := q.client.RPop(q.runningKey).Result()
+		if err == redis.Nil {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if err = q.Push(data); err != nil {
+			return err
+		}
+	}
+}
+
+// Push adds an item to the queue, the newest items are at the head of the
+// list
+func (q *RedisQueue) Push(data string) error {
+	added, err := q.client.SAdd(q.setKey, data).Result()
+	if err != nil || added == 0 {
+		return err
+	}
+	return q.client.LPush(q.listKey, data).Err()
+}
+
+// Pop removes the oldest item from the queue
+func (q *RedisQueue) Pop() (string, error) {
+	for {
+		select {
+		case <-q.closed:
+			return "", ErrQueueClosed
+		default:
+		}
+
+		// wait at most a second so that closing the queue is noticed, the
+		// item is kept aside until Done is called
+		data, err := q.client.BRPopLPush(q.listKey, q.runningKey, 1).Result()
+		if err == redis.Nil {
+			continue
+		} else if err != nil {
+			return "", err
+		}
+		return data, q.client.SRem(q.setKey, data).Err()
+	}
+}
+
+// TryPop removes the oldest item from the queue without waiting
+func (q *RedisQueue) TryPop() (string, bool, error) {
+	data, err := q.client.RPopLPush(q.listKey, q.runningKey).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	} else if err != nil {
+		return "", false, err
+	}
+	return data, true, q.client.SRem(q.setKey, data).Err()
+}
+
+// Done removes a popped item once it is handled
+func (q *RedisQueue) Done(data string) error {
+	return q.client.LRem(q.runningKey, 0, data).Err()
+}
+
+// Has returns true if the item is waiting in the queue
+func (q *RedisQueue) Has(data string) (bool, error) {
+	return q.client.SIsMember(q.setKey, data).Result()
+}
+
+// Close closes the queue, the waiting items are kept in the server
+func (q *RedisQueue) Close() error {
+	close(q.closed)
+	return q.client.Close()
+}
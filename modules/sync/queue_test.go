@@ -0,0 +1,229 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testQueue(t *testing.T, q Queue) {
+	assert.NoError(t, q.Push("a"))
+	assert.NoError(t, q.Push("b"))
+	// an item which is already waiting is not added twice
+	assert.NoError(t, q.Push("a"))
+
+	has, err := q.Has("a")
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = q.Has("c")
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	data, err := q.Pop()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", data)
+	has, err = q.Has("a")
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	assert.NoError(t, q.Done("a"))
+
+	data, ok, err := q.TryPop()
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "b", data)
+	assert.NoError(t, q.Done("b"))
+	_, ok, err = q.TryPop()
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// Pop waits for the next item
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.Push("c")
+	}()
+	data, err = q.Pop()
+	assert.NoError(t, err)
+	assert.Equal(t, "c", data)
+	assert.NoError(t, q.Done("c"))
+}
+
+func testQueueClose(t *testing.T, q Queue) {
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		q.Close()
+	}()
+	_, err := q.Pop()
+	assert.Equal(t, ErrQueueClosed, err)
+}
+
+func TestChannelQueue(t *testing.T) {
+	testQueue(t, NewChannelQueue(10))
+	testQueueClose(t, NewChannelQueue(10))
+}
+
+func TestDiskQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "disk-queue")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := NewDiskQueue(dir)
+	assert.NoError(t, err)
+	testQueue(t, q)
+
+	// the waiting items and the ones not handled yet survive a restart
+	assert.NoError(t, q.Push("d"))
+	assert.NoError(t, q.Push("e"))
+	assert.NoError(t, q.Push("f"))
+	data, err := q.Pop()
+	assert.NoError(t, err)
+	assert.Equal(t, "d", data)
+	assert.NoError(t, q.Close())
+
+	q, err = NewDiskQueue(dir)
+	assert.NoError(t, err)
+	has, err := q.Has("d")
+	assert.NoError(t, err)
+	assert.True(t, has)
+	for _, expected := range []string{"e", "f", "d"} {
+		data, err = q.Pop()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, data)
+		assert.NoError(t, q.Done(data))
+	}
+
+	testQueueClose(t, q)
+}
+
+func TestParseRedisConnStr(t *testing.T) {
+	opts, err := parseRedisConnStr("redis://:secret@localhost:6380/2")
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:6380", opts.Addr)
+	assert.Equal(t, "secret", opts.Password)
+	assert.EqualValues(t, 2, opts.DB)
+
+	opts, err = parseRedisConnStr("redis://127.0.0.1:6379")
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:6379", opts.Addr)
+	assert.Empty(t, opts.Password)
+	assert.EqualValues(t, 0, opts.DB)
+
+	_, err = parseRedisConnStr("http://127.0.0.1:6379")
+	assert.Error(t, err)
+	_, err = parseRedisConnStr("redis://127.0.0.1:6379/db")
+	assert.Error(t, err)
+}
+
+func TestWorkQueue(t *testing.T) {
+	q := NewWorkQueue("test")
+	q.SetQueue(NewChannelQueue(10), 2)
+
+	q.Add(1)
+	q.Add(int64(2))
+	q.Add("3")
+	assert.True(t, q.Exist(1))
+
+	handled := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		q.Run(func(id string) {
+			handled <- id
+		})
+		close(done)
+	}()
+
+	ids := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		select {
+		case id := <-handled:
+			ids = append(ids, id)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "Timeout: the queue was not handled")
+		}
+	}
+	sort.Strings(ids)
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	assert.False(t, q.Exist(1))
+
+	// Run returns once the queue is closed
+	queue, _ := q.get()
+	assert.NoError(t, queue.Close())
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "Timeout: Run did not return")
+	}
+}
+
+func TestWorkQueueBatch(t *testing.T) {
+	q := NewWorkQueue("test")
+	q.SetQueue(NewChannelQueue(10), 1)
+
+	for i := 1; i <= 5; i++ {
+		q.Add(i)
+	}
+
+	handled := make(chan []string)
+	go q.RunBatch(3, func(ids []string) {
+		handled <- ids
+	})
+
+	for _, expected := range [][]string{{"1", "2", "3"}, {"4", "5"}} {
+		select {
+		case ids := <-handled:
+			assert.Equal(t, expected, ids)
+		case <-time.After(time.Second):
+			assert.FailNow(t, "Timeout: the queue was not handled")
+		}
+	}
+
+	queue, _ := q.get()
+	assert.NoError(t, queue.Close())
+}
+
+func TestWorkQueueRunning(t *testing.T) {
+	q := NewWorkQueue("test")
+	q.SetQueue(NewChannelQueue(10), 2)
+
+	started := make(chan string)
+	release := make(chan struct{})
+	go q.Run(func(id string) {
+		started <- id
+		<-release
+	})
+
+	q.Add(1)
+	select {
+	case <-started:
+	case <-time.After(time.Second):
+		assert.FailNow(t, "Timeout: the queue was not handled")
+	}
+
+	// the second worker does not handle the item while the first one does,
+	// the first one handles it again once it is done
+	q.Add(1)
+	select {
+	case <-started:
+		assert.FailNow(t, "The item is handled twice at the same time")
+	case <-time.After(100 * time.Millisecond):
+	}
+	release <- struct{}{}
+	select {
+	case id := <-started:
+		assert.Equal(t, "1", id)
+	case <-time.After(time.Second):
+		assert.FailNow(t, "Timeout: the item was not handled again")
+	}
+	release <- struct{}{}
+
+	queue, _ := q.get()
+	assert.NoError(t, queue.Close())
+}
@@ -20,6 +20,7 @@ import (
 	"strings"
 	"time"
 
+	"code.gitea.io/git"
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
@@ -109,6 +110,7 @@ func NewFuncMap() []template.FuncMap {
 		"DiffLineTypeToStr":     DiffLineTypeToStr,
 		"Sha1":                  Sha1,
 		"ShortSha":              base.ShortSha,
+		"RefEndName":            git.RefEndName,
 		"MD5":                   base.EncodeMD5,
 		"ActionContent2Commits": ActionContent2Commits,
 		"PathEscape":            url.PathEscape,
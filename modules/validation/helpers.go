@@ -38,6 +38,25 @@ func isLoopbackIP(ip string) bool {
 	return false
 }
 
+// IsValidGitRefName checks if the name is valid for a Git branch or tag
+func IsValidGitRefName(name string) bool {
+	if GitRefNamePattern.MatchString(name) {
+		return false
+	}
+	// Additional rules as described at https://www.kernel.org/pub/software/scm/git/docs/git-check-ref-format.html
+	if strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") ||
+		strings.HasSuffix(name, ".") || strings.Contains(name, "..") ||
+		strings.Contains(name, "//") || strings.HasPrefix(name, "-") {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasSuffix(part, ".lock") || strings.HasPrefix(part, ".") {
+			return false
+		}
+	}
+	return true
+}
+
 // IsValidURL checks if URL is valid
 func IsValidURL(uri string) bool {
 	if u, err := url.ParseRequestURI(uri); err != nil ||
@@ -129,6 +129,19 @@ var gitRefNameValidationTestCases = []validationTestCase{
 			},
 		},
 	},
+	{
+		description: "Reference name starts with dash",
+		data: TestForm{
+			BranchName: "-test",
+		},
+		expectedErrors: binding.Errors{
+			binding.Error{
+				FieldNames:     []string{"BranchName"},
+				Classification: ErrGitRefName,
+				Message:        "GitRefName",
+			},
+		},
+	},
 }
 
 func Test_GitRefNameValidation(t *testing.T) {
@@ -393,6 +393,7 @@ function initCommentForm() {
             }
             switch (input_id) {
                 case '#milestone_id':
+                case '#project_id':
                     $list.find('.selected').html('<a class="item" href=' + $(this).data('href') + '>' +
                         $(this).text() + '</a>');
                     break;
@@ -425,8 +426,9 @@ function initCommentForm() {
         });
     }
 
-    // Milestone and assignee
+    // Milestone, project and assignee
     selectItem('.select-milestone', '#milestone_id');
+    selectItem('.select-project', '#project_id');
     selectItem('.select-assignee', '#assignee_id');
 }
 
@@ -963,6 +965,65 @@ function initTeamSettings() {
     });
 }
 
+function initProjects() {
+    var $boards = $('.project-boards.editable');
+    if ($boards.length === 0) {
+        return;
+    }
+
+    $boards.find('.project-board-edit').click(function () {
+        $($(this).data('form')).toggleClass('hide');
+        return false;
+    });
+
+    // Drag and drop the cards between and within the boards
+    var $dragged = null;
+    $boards.find('.project-card').on('dragstart', function (e) {
+        $dragged = $(this);
+        e.originalEvent.dataTransfer.effectAllowed = 'move';
+        e.originalEvent.dataTransfer.setData('text/plain', $dragged.data('issue'));
+        $dragged.addClass('dragging');
+    }).on('dragend', function () {
+        $(this).removeClass('dragging');
+        $dragged = null;
+    });
+
+    $boards.find('.project-board-cards').on('dragover', function (e) {
+        if ($dragged === null) {
+            return;
+        }
+        e.preventDefault();
+
+        // Put the card before the first card below the cursor
+        var y = e.originalEvent.clientY;
+        var $next = null;
+        $(this).children('.project-card').not($dragged).each(function () {
+            var rect = this.getBoundingClientRect();
+            if (y < rect.top + rect.height / 2) {
+                $next = $(this);
+                return false;
+            }
+        });
+        if ($next !== null) {
+            $dragged.insertBefore($next);
+        } else {
+            $(this).append($dragged);
+        }
+    }).on('drop', function (e) {
+        e.preventDefault();
+        var issueIDs = $(this).children('.project-card').map(function () {
+            return $(this).data('issue');
+        }).get();
+        $.post($(this).closest('.project-board').data('url'), {
+            "_csrf": csrf,
+            "issue_ids": issueIDs.join(',')
+        });
+        $boards.find('.project-board').each(function () {
+            $(this).find('.project-board-title .label').text($(this).find('.project-card').length);
+        });
+    });
+}
+
 function initWikiForm() {
     var $editArea = $('.repository.wiki textarea#edit_area');
     if ($editArea.length > 0) {
@@ -1961,8 +2022,10 @@ $(document).ready(function () {
     initCodeView();
     initVueApp();
     initTeamSettings();
+    initProjects();
     initCtrlEnterSubmit();
     initNavbarContentToggle();
+    initNotificationCount();
     initTopicbar();
     initU2FAuth();
     initU2FRegister();
@@ -2579,6 +2642,27 @@ $(".commit-button").click(function() {
     $(this).parent().find('.commit-body').toggle();
 });
 
+function initNotificationCount() {
+    var $count = $('.notification_count');
+    if (!$count.data('events') || !window.EventSource) {
+        return;
+    }
+
+    var source = new EventSource($count.data('events') + '?_csrf=' + encodeURIComponent(csrf));
+    source.addEventListener('notification-count', function (e) {
+        var count = JSON.parse(e.data).new;
+        $count.text(count);
+        if (count > 0) {
+            $count.show();
+        } else {
+            $count.hide();
+        }
+    });
+    $(window).on('beforeunload', function () {
+        source.close();
+    });
+}
+
 function initNavbarContentToggle() {
     var content = $('#navbar');
     var toggle = $('#navbar-expand-toggle');
@@ -0,0 +1,59 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplAdminHooks base.TplName = "admin/hooks"
+)
+
+// Webhooks render the system or default webhooks list page
+func Webhooks(ctx *context.Context) {
+	isSystem := ctx.Params(":kind") == "system"
+
+	ctx.Data["Title"] = ctx.Tr("admin.hooks")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminHooks"] = true
+	ctx.Data["PageIsAdminSystemHooks"] = isSystem
+	ctx.Data["BaseLink"] = setting.AppSubURL + "/admin/hooks/" + ctx.Params(":kind")
+
+	var (
+		ws  []*models.Webhook
+		err error
+	)
+	if isSystem {
+		ctx.Data["Description"] = ctx.Tr("admin.hooks.system_desc")
+		ws, err = models.GetSystemWebhooks()
+	} else {
+		ctx.Data["Description"] = ctx.Tr("admin.hooks.default_desc")
+		ws, err = models.GetDefaultWebhooks()
+	}
+	if err != nil {
+		ctx.ServerError("GetWebhooks", err)
+		return
+	}
+	ctx.Data["Webhooks"] = ws
+
+	ctx.HTML(200, tplAdminHooks)
+}
+
+// DeleteWebhook response for deleting a system or default webhook
+func DeleteWebhook(ctx *context.Context) {
+	if err := models.DeleteSystemOrDefaultWebhook(ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteSystemOrDefaultWebhook: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/hooks/" + ctx.Params(":kind"),
+	})
+}
@@ -0,0 +1,287 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	api "code.gitea.io/sdk/gitea"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListHooks list the system and default webhooks
+func ListHooks(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks admin adminListHooks
+	// ---
+	// summary: List the system and default webhooks
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: type
+	//   in: query
+	//   description: kind of the webhooks to list
+	//   type: string
+	//   enum: [system, default, all]
+	//   default: all
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	var (
+		sysHooks []*models.Webhook
+		err      error
+	)
+	switch ctx.Query("type") {
+	case "system":
+		sysHooks, err = models.GetSystemWebhooks()
+	case "default":
+		sysHooks, err = models.GetDefaultWebhooks()
+	case "", "all":
+		if sysHooks, err = models.GetSystemWebhooks(); err == nil {
+			var defaultHooks []*models.Webhook
+			defaultHooks, err = models.GetDefaultWebhooks()
+			sysHooks = append(sysHooks, defaultHooks...)
+		}
+	default:
+		ctx.Error(422, "", "type must be system, default or all")
+		return
+	}
+	if err != nil {
+		ctx.Error(500, "GetSystemOrDefaultWebhooks", err)
+		return
+	}
+	hooks := make([]*api.Hook, len(sysHooks))
+	for i, hook := range sysHooks {
+		hooks[i] = utils.ToAdminHook(hook)
+	}
+	ctx.JSON(200, hooks)
+}
+
+// GetHook get a system or default hook by id
+func GetHook(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks/{id} admin adminGetHook
+	// ---
+	// summary: Get a system or default hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Hook"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetAdminHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	ctx.JSON(200, utils.ToAdminHook(hook))
+}
+
+// ListHookDeliveries list the deliveries of a system or default hook
+func ListHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks/{id}/deliveries admin adminListHookDeliveries
+	// ---
+	// summary: List the deliveries of a system or default hook, the latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetAdminHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListHookDeliveries(ctx, hook)
+}
+
+// GetHookDelivery get a delivery of a system or default hook
+func GetHookDelivery(ctx *context.APIContext) {
+	// swagger:operation GET /admin/hooks/{id}/deliveries/{delivery} admin adminGetHookDelivery
+	// ---
+	// summary: Get a delivery of a system or default hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetAdminHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.GetHookDelivery(ctx, hook)
+}
+
+// RedeliverHookDelivery deliver again the payload of a delivery of a system
+// or default hook
+func RedeliverHookDelivery(ctx *context.APIContext) {
+	// swagger:operation POST /admin/hooks/{id}/deliveries/{delivery}/redeliver admin adminRedeliverHookDelivery
+	// ---
+	// summary: Deliver again the payload of a delivery of a system or default hook to its current URL
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetAdminHook(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverHookDelivery(ctx, hook)
+}
+
+// CreateHook create a system or default hook
+func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
+	// swagger:operation POST /admin/hooks admin adminCreateHook
+	// ---
+	// summary: Create a system or default hook
+	// description: A system hook is triggered by the events of every
+	//   repository, a default hook is copied into every new repository.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateHookOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Hook"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if !utils.CheckCreateHookOption(ctx, &form) {
+		return
+	}
+	utils.AddAdminHook(ctx, &form)
+}
+
+// EditHook modify a system or default hook
+func EditHook(ctx *context.APIContext, form api.EditHookOption) {
+	// swagger:operation PATCH /admin/hooks/{id} admin adminEditHook
+	// ---
+	// summary: Update a system or default hook
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to update
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditHookOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Hook"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	utils.EditAdminHook(ctx, &form, ctx.ParamsInt64(":id"))
+}
+
+// DeleteHook delete a system or default hook
+func DeleteHook(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/hooks/{id} admin adminDeleteHook
+	// ---
+	// summary: Delete a system or default hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the hook to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if err := models.DeleteSystemOrDefaultWebhook(ctx.ParamsInt64(":id")); err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "DeleteSystemOrDefaultWebhook", err)
+		}
+		return
+	}
+	ctx.Status(204)
+}
@@ -58,6 +58,7 @@
 package v1
 
 import (
+	"fmt"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -67,6 +68,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/v1/admin"
 	"code.gitea.io/gitea/routers/api/v1/misc"
+	"code.gitea.io/gitea/routers/api/v1/notify"
 	"code.gitea.io/gitea/routers/api/v1/org"
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	_ "code.gitea.io/gitea/routers/api/v1/swagger" // for swagger generation
@@ -85,6 +87,12 @@ func sudo() macaron.Handler {
 		}
 
 		if len(sudo) > 0 {
+			if t := ctx.AccessToken(); t != nil && !t.Scope.Has(models.AccessTokenScopeSudo) {
+				ctx.JSON(403, map[string]string{
+					"message": "Access token does not have the sudo scope.",
+				})
+				return
+			}
 			if ctx.User.IsAdmin {
 				user, err := models.GetUserByName(sudo)
 				if err != nil {
@@ -158,6 +166,9 @@ func repoAssignment() macaron.Handler {
 			ctx.Error(500, "GetUserRepoPermission", err)
 			return
 		}
+		if t := ctx.AccessToken(); t != nil {
+			ctx.Repo.Permission = t.LimitRepoPermission(repo, ctx.Repo.Permission)
+		}
 
 		if !ctx.Repo.HasAccess() {
 			ctx.Status(404)
@@ -180,6 +191,23 @@ func reqToken() macaron.Handler {
 	}
 }
 
+// reqScope requires an access token, if the request is authenticated with
+// one, to be granted any of the scopes.
+func reqScope(scopes ...models.AccessTokenScope) macaron.Handler {
+	return func(ctx *context.APIContext) {
+		t := ctx.AccessToken()
+		if t == nil {
+			return
+		}
+		for _, scope := range scopes {
+			if t.Scope.Has(scope) {
+				return
+			}
+		}
+		ctx.Error(403, "", fmt.Sprintf("Access token requires scope: %s", scopes[0]))
+	}
+}
+
 func reqBasicAuth() macaron.Handler {
 	return func(ctx *context.Context) {
 		if !ctx.IsBasicAuth {
@@ -249,6 +277,16 @@ func reqRepoWriter(unitTypes ...models.UnitType) macaron.Handler {
 	}
 }
 
+// reqRepoNotArchived rejects any request changing an archived repository
+func reqRepoNotArchived() macaron.Handler {
+	return func(ctx *context.Context) {
+		if ctx.Repo.Repository.IsArchived && ctx.Req.Method != "GET" && ctx.Req.Method != "HEAD" {
+			ctx.Error(403)
+			return
+		}
+	}
+}
+
 func reqOrgMembership() macaron.Handler {
 	return func(ctx *context.APIContext) {
 		var orgID int64
@@ -301,6 +339,72 @@ func reqOrgOwnership() macaron.Handler {
 	}
 }
 
+// projectAssignment loads the project of the request and checks that the user
+// can see it, that is read the projects of its repository or be a member of
+// its organization.
+func projectAssignment() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		p, err := models.GetProjectByID(ctx.ParamsInt64(":id"))
+		if err != nil {
+			if models.IsErrProjectNotExist(err) {
+				ctx.Status(404)
+			} else {
+				ctx.Error(500, "GetProjectByID", err)
+			}
+			return
+		}
+		if err = p.LoadAttributes(); err != nil {
+			ctx.Error(500, "LoadAttributes", err)
+			return
+		}
+
+		var canRead, canWrite bool
+		if p.IsRepositoryProject() {
+			perm, err := models.GetUserRepoPermission(p.Repo, ctx.User)
+			if err != nil {
+				ctx.Error(500, "GetUserRepoPermission", err)
+				return
+			}
+			if t := ctx.AccessToken(); t != nil {
+				perm = t.LimitRepoPermission(p.Repo, perm)
+			}
+			canRead = perm.CanRead(models.UnitTypeProjects)
+			canWrite = perm.CanWrite(models.UnitTypeProjects) && !p.Repo.IsArchived
+		} else if ctx.IsSigned {
+			if ctx.User.IsAdmin {
+				canRead, canWrite = true, true
+			} else {
+				if canRead, err = models.IsOrganizationMember(p.OwnerID, ctx.User.ID); err != nil {
+					ctx.Error(500, "IsOrganizationMember", err)
+					return
+				}
+				if canWrite, err = models.IsOrganizationOwner(p.OwnerID, ctx.User.ID); err != nil {
+					ctx.Error(500, "IsOrganizationOwner", err)
+					return
+				}
+			}
+		}
+		if !canRead {
+			ctx.Status(404)
+			return
+		}
+
+		ctx.Data["Project"] = p
+		ctx.Data["CanWriteProject"] = canWrite
+	}
+}
+
+// reqProjectWriter requires the user to be allowed to change the project
+// loaded by projectAssignment
+func reqProjectWriter() macaron.Handler {
+	return func(ctx *context.APIContext) {
+		if canWrite, _ := ctx.Data["CanWriteProject"].(bool); !canWrite {
+			ctx.Status(403)
+			return
+		}
+	}
+}
+
 func orgAssignment(args ...bool) macaron.Handler {
 	var (
 		assignOrg  bool
@@ -425,14 +529,16 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 		m.Group("/user", func() {
 			m.Get("", user.GetAuthenticatedUser)
-			m.Combo("/emails").Get(user.ListEmails).
+			m.Combo("/emails", reqScope(models.AccessTokenScopeUserEmail)).Get(user.ListEmails).
 				Post(bind(api.CreateEmailOption{}), user.AddEmail).
 				Delete(bind(api.DeleteEmailOption{}), user.DeleteEmail)
 
 			m.Get("/followers", user.ListMyFollowers)
 			m.Group("/following", func() {
 				m.Get("", user.ListMyFollowing)
-				m.Combo("/:username").Get(user.CheckMyFollowing).Put(user.Follow).Delete(user.Unfollow)
+				m.Combo("/:username").Get(user.CheckMyFollowing).
+					Put(reqScope(models.AccessTokenScopeAll), user.Follow).
+					Delete(reqScope(models.AccessTokenScopeAll), user.Unfollow)
 			})
 
 			m.Group("/keys", func() {
@@ -440,17 +546,17 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Post(bind(api.CreateKeyOption{}), user.CreatePublicKey)
 				m.Combo("/:id").Get(user.GetPublicKey).
 					Delete(user.DeletePublicKey)
-			})
+			}, reqScope(models.AccessTokenScopeAll))
 
 			m.Group("/gpg_keys", func() {
 				m.Combo("").Get(user.ListMyGPGKeys).
 					Post(bind(api.CreateGPGKeyOption{}), user.CreateGPGKey)
 				m.Combo("/:id").Get(user.GetGPGKey).
 					Delete(user.DeleteGPGKey)
-			})
+			}, reqScope(models.AccessTokenScopeAll))
 
-			m.Combo("/repos").Get(user.ListMyRepos).
-				Post(bind(api.CreateRepoOption{}), repo.Create)
+			m.Combo("/repos").Get(reqScope(models.AccessTokenScopeRepoRead), user.ListMyRepos).
+				Post(reqScope(models.AccessTokenScopeRepoWrite), bind(api.CreateRepoOption{}), repo.Create)
 
 			m.Group("/starred", func() {
 				m.Get("", user.GetMyStarredRepos)
@@ -459,14 +565,26 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Put("", user.Star)
 					m.Delete("", user.Unstar)
 				}, repoAssignment())
-			})
-			m.Get("/times", repo.ListMyTrackedTimes)
+			}, reqScope(models.AccessTokenScopeRepoRead))
+			m.Get("/times", reqScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeIssue), repo.ListMyTrackedTimes)
 
-			m.Get("/subscriptions", user.GetMyWatchedRepos)
+			m.Get("/subscriptions", reqScope(models.AccessTokenScopeRepoRead), user.GetMyWatchedRepos)
 		}, reqToken())
 
+		// Notifications
+		m.Group("/notifications", func() {
+			m.Combo("").
+				Get(notify.ListNotifications).
+				Put(notify.ReadNotifications)
+			m.Get("/new", notify.NewAvailable)
+			m.Get("/events", notify.Events)
+			m.Combo("/threads/:id").
+				Get(notify.GetThread).
+				Patch(notify.ReadThread)
+		}, reqToken(), reqScope(models.AccessTokenScopeNotification))
+
 		// Repositories
-		m.Post("/org/:org/repos", reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
+		m.Post("/org/:org/repos", reqToken(), reqScope(models.AccessTokenScopeRepoWrite), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
 
 		m.Group("/repos", func() {
 			m.Get("/search", repo.Search)
@@ -475,11 +593,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Combo("/repositories/:id", reqToken()).Get(repo.GetByID)
 
 		m.Group("/repos", func() {
-			m.Post("/migrate", reqToken(), bind(auth.MigrateRepoForm{}), repo.Migrate)
+			m.Post("/migrate", reqToken(), reqScope(models.AccessTokenScopeRepoWrite), bind(auth.MigrateRepoForm{}), repo.Migrate)
 
 			m.Group("/:username/:reponame", func() {
 				m.Combo("").Get(reqAnyRepoReader(), repo.Get).
+					Patch(reqToken(), reqOwner(), bind(api.EditRepoOption{}), repo.Edit).
 					Delete(reqToken(), reqOwner(), repo.Delete)
+				m.Post("/generate", reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.GenerateRepoOption{}), repo.Generate)
 				m.Group("/hooks", func() {
 					m.Combo("").Get(repo.ListHooks).
 						Post(bind(api.CreateHookOption{}), repo.CreateHook)
@@ -488,6 +608,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 							Patch(bind(api.EditHookOption{}), repo.EditHook).
 							Delete(repo.DeleteHook)
 						m.Post("/tests", context.RepoRef(), repo.TestHook)
+						m.Get("/deliveries", repo.ListHookDeliveries)
+						m.Group("/deliveries/:delivery", func() {
+							m.Get("", repo.GetHookDelivery)
+							m.Post("/redeliver", repo.RedeliverHookDelivery)
+						})
 					})
 				}, reqToken(), reqAdmin())
 				m.Group("/collaborators", func() {
@@ -498,12 +623,34 @@ func RegisterRoutes(m *macaron.Macaron) {
 				}, reqToken(), reqAdmin())
 				m.Get("/raw/*", context.RepoRefByType(context.RepoRefAny), reqRepoReader(models.UnitTypeCode), repo.GetRawFile)
 				m.Get("/archive/*", reqRepoReader(models.UnitTypeCode), repo.GetArchive)
+				m.Get("/contents", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(), repo.GetContentsList)
+				m.Combo("/contents/*", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo()).
+					Get(repo.GetContents).
+					Post(reqToken(), reqRepoWriter(models.UnitTypeCode), reqRepoNotArchived(), bind(api.CreateFileOptions{}), repo.CreateFile).
+					Put(reqToken(), reqRepoWriter(models.UnitTypeCode), reqRepoNotArchived(), bind(api.UpdateFileOptions{}), repo.UpdateFile).
+					Delete(reqToken(), reqRepoWriter(models.UnitTypeCode), reqRepoNotArchived(), bind(api.DeleteFileOptions{}), repo.DeleteFile)
 				m.Combo("/forks").Get(repo.ListForks).
 					Post(reqToken(), reqRepoReader(models.UnitTypeCode), bind(api.CreateForkOption{}), repo.CreateFork)
 				m.Group("/branches", func() {
 					m.Get("", repo.ListBranches)
 					m.Get("/*", context.RepoRefByType(context.RepoRefBranch), repo.GetBranch)
 				}, reqRepoReader(models.UnitTypeCode))
+				m.Group("/branch_protections", func() {
+					m.Combo("").Get(repo.ListBranchProtections).
+						Post(bind(api.CreateBranchProtectionOption{}), repo.CreateBranchProtection)
+					m.Combo("/*").Get(repo.GetBranchProtection).
+						Patch(bind(api.EditBranchProtectionOption{}), repo.EditBranchProtection).
+						Delete(repo.DeleteBranchProtection)
+				}, reqToken(), reqAdmin(), reqRepoNotArchived(), context.ReferencesGitRepo())
+				m.Group("/push_mirrors", func() {
+					m.Combo("").Get(repo.ListPushMirrors).
+						Post(bind(api.CreatePushMirrorOption{}), repo.CreatePushMirror)
+					m.Group("/:id", func() {
+						m.Combo("").Get(repo.GetPushMirror).
+							Delete(repo.DeletePushMirror)
+						m.Post("/sync", repo.SyncPushMirror)
+					})
+				}, reqToken(), reqAdmin())
 				m.Group("/keys", func() {
 					m.Combo("").Get(repo.ListDeployKeys).
 						Post(bind(api.CreateKeyOption{}), repo.CreateDeployKey)
@@ -548,22 +695,28 @@ func RegisterRoutes(m *macaron.Macaron) {
 						})
 
 						m.Combo("/deadline").Post(reqToken(), bind(api.EditDeadlineOption{}), repo.UpdateIssueDeadline)
+						m.Combo("/lock", reqToken()).Put(bind(api.LockIssueOption{}), repo.LockIssue).
+							Delete(repo.UnlockIssue)
 					})
-				}, mustEnableIssuesOrPulls)
+				}, mustEnableIssuesOrPulls, reqRepoNotArchived())
 				m.Group("/labels", func() {
 					m.Combo("").Get(repo.ListLabels).
 						Post(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.CreateLabelOption{}), repo.CreateLabel)
 					m.Combo("/:id").Get(repo.GetLabel).
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditLabelOption{}), repo.EditLabel).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteLabel)
-				})
+				}, reqRepoNotArchived())
+				m.Group("/projects", func() {
+					m.Combo("").Get(repo.ListProjects).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeProjects), bind(api.CreateProjectOption{}), repo.CreateProject)
+				}, reqRepoReader(models.UnitTypeProjects), reqRepoNotArchived())
 				m.Group("/milestones", func() {
 					m.Combo("").Get(repo.ListMilestones).
 						Post(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
 					m.Combo("/:id").Get(repo.GetMilestone).
 						Patch(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), bind(api.EditMilestoneOption{}), repo.EditMilestone).
 						Delete(reqToken(), reqRepoWriter(models.UnitTypeIssues, models.UnitTypePullRequests), repo.DeleteMilestone)
-				})
+				}, reqRepoNotArchived())
 				m.Get("/stargazers", repo.ListStargazers)
 				m.Get("/subscribers", repo.ListSubscribers)
 				m.Group("/subscription", func() {
@@ -571,6 +724,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Put("", reqToken(), user.Watch)
 					m.Delete("", reqToken(), user.Unwatch)
 				})
+				m.Combo("/notifications", reqToken(), reqScope(models.AccessTokenScopeNotification)).
+					Get(notify.ListRepoNotifications).
+					Put(notify.ReadRepoNotifications)
 				m.Group("/releases", func() {
 					m.Combo("").Get(repo.ListReleases).
 						Post(reqToken(), reqRepoWriter(models.UnitTypeReleases), context.ReferencesGitRepo(), bind(api.CreateReleaseOption{}), repo.CreateRelease)
@@ -586,9 +742,17 @@ func RegisterRoutes(m *macaron.Macaron) {
 								Delete(reqToken(), reqRepoWriter(models.UnitTypeReleases), repo.DeleteReleaseAttachment)
 						})
 					})
-				}, reqRepoReader(models.UnitTypeReleases))
+				}, reqRepoReader(models.UnitTypeReleases), reqRepoNotArchived())
 				m.Post("/mirror-sync", reqToken(), reqRepoWriter(models.UnitTypeCode), repo.MirrorSync)
 				m.Get("/editorconfig/:filename", context.RepoRef(), reqRepoReader(models.UnitTypeCode), repo.GetEditorconfig)
+				m.Group("/wiki", func() {
+					m.Combo("/pages").Get(repo.ListWikiPages).
+						Post(reqToken(), reqRepoWriter(models.UnitTypeWiki), bind(api.CreateWikiPageOptions{}), repo.CreateWikiPage)
+					m.Combo("/pages/*").Get(repo.GetWikiPage).
+						Patch(reqToken(), reqRepoWriter(models.UnitTypeWiki), bind(api.EditWikiPageOptions{}), repo.EditWikiPage).
+						Delete(reqToken(), reqRepoWriter(models.UnitTypeWiki), bind(api.DeleteWikiPageOptions{}), repo.DeleteWikiPage)
+					m.Get("/revisions/*", repo.ListWikiPageRevisions)
+				}, reqRepoReader(models.UnitTypeWiki), reqRepoNotArchived())
 				m.Group("/pulls", func() {
 					m.Combo("").Get(bind(api.ListPullRequestsOptions{}), repo.ListPullRequests).
 						Post(reqToken(), bind(api.CreatePullRequestOption{}), repo.CreatePullRequest)
@@ -597,16 +761,34 @@ func RegisterRoutes(m *macaron.Macaron) {
 							Patch(reqToken(), reqRepoWriter(models.UnitTypePullRequests), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
 							Post(reqToken(), reqRepoWriter(models.UnitTypePullRequests), bind(auth.MergePullRequestForm{}), repo.MergePullRequest)
+						m.Group("/reviews", func() {
+							m.Combo("").Get(repo.ListPullReviews).
+								Post(reqToken(), bind(api.CreatePullReviewOptions{}), repo.CreatePullReview)
+							m.Group("/:id", func() {
+								m.Combo("").Get(repo.GetPullReview).
+									Post(reqToken(), bind(api.SubmitPullReviewOptions{}), repo.SubmitPullReview)
+								m.Get("/comments", repo.GetPullReviewComments)
+								m.Post("/dismissals", reqToken(), reqRepoWriter(models.UnitTypePullRequests), repo.DismissPullReview)
+							})
+						})
+						m.Combo("/requested_reviewers", reqToken(), reqRepoWriter(models.UnitTypePullRequests)).
+							Post(bind(api.PullReviewRequestOptions{}), repo.CreateReviewRequests).
+							Delete(bind(api.PullReviewRequestOptions{}), repo.DeleteReviewRequests)
 					})
-				}, mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo())
+				}, mustAllowPulls, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(), reqRepoNotArchived())
 				m.Group("/statuses", func() {
 					m.Combo("/:sha").Get(repo.GetCommitStatuses).
 						Post(reqToken(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
 				}, reqRepoReader(models.UnitTypeCode))
-				m.Group("/commits/:ref", func() {
-					m.Get("/status", repo.GetCombinedCommitStatusByRef)
-					m.Get("/statuses", repo.GetCommitStatusesByRef)
-				}, reqRepoReader(models.UnitTypeCode))
+				m.Group("/commits", func() {
+					m.Get("", repo.GetAllCommits)
+					m.Group("/:ref", func() {
+						m.Get("", repo.GetSingleCommit)
+						m.Get("/status", repo.GetCombinedCommitStatusByRef)
+						m.Get("/statuses", repo.GetCommitStatusesByRef)
+					})
+				}, reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo())
+				m.Get("/compare/*", reqRepoReader(models.UnitTypeCode), context.ReferencesGitRepo(), repo.CompareCommits)
 				m.Group("/git", func() {
 					m.Get("/refs", repo.GetGitAllRefs)
 					m.Get("/refs/*", repo.GetGitRefs)
@@ -618,31 +800,37 @@ func RegisterRoutes(m *macaron.Macaron) {
 		// Organizations
 		m.Get("/user/orgs", reqToken(), org.ListMyOrgs)
 		m.Get("/users/:username/orgs", org.ListUserOrgs)
-		m.Post("/orgs", reqToken(), bind(api.CreateOrgOption{}), org.Create)
+		m.Post("/orgs", reqToken(), reqScope(models.AccessTokenScopeAdminOrg), bind(api.CreateOrgOption{}), org.Create)
 		m.Group("/orgs/:orgname", func() {
 			m.Get("/repos", user.ListOrgRepos)
 			m.Combo("").Get(org.Get).
-				Patch(reqToken(), reqOrgOwnership(), bind(api.EditOrgOption{}), org.Edit)
+				Patch(reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgOwnership(), bind(api.EditOrgOption{}), org.Edit)
 			m.Group("/members", func() {
 				m.Get("", org.ListMembers)
 				m.Combo("/:username").Get(org.IsMember).
-					Delete(reqToken(), reqOrgOwnership(), org.DeleteMember)
+					Delete(reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgOwnership(), org.DeleteMember)
 			})
 			m.Group("/public_members", func() {
 				m.Get("", org.ListPublicMembers)
 				m.Combo("/:username").Get(org.IsPublicMember).
-					Put(reqToken(), reqOrgMembership(), org.PublicizeMember).
-					Delete(reqToken(), reqOrgMembership(), org.ConcealMember)
+					Put(reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgMembership(), org.PublicizeMember).
+					Delete(reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgMembership(), org.ConcealMember)
 			})
-			m.Combo("/teams", reqToken(), reqOrgMembership()).Get(org.ListTeams).
+			m.Combo("/teams", reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgMembership()).Get(org.ListTeams).
 				Post(bind(api.CreateTeamOption{}), org.CreateTeam)
+			m.Combo("/projects", reqToken(), reqOrgMembership()).
+				Get(reqScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeIssue), org.ListProjects).
+				Post(reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqOrgOwnership(), bind(api.CreateProjectOption{}), org.CreateProject)
 			m.Group("/hooks", func() {
 				m.Combo("").Get(org.ListHooks).
 					Post(bind(api.CreateHookOption{}), org.CreateHook)
 				m.Combo("/:id").Get(org.GetHook).
 					Patch(reqOrgOwnership(), bind(api.EditHookOption{}), org.EditHook).
 					Delete(reqOrgOwnership(), org.DeleteHook)
-			}, reqToken(), reqOrgMembership())
+				m.Get("/:id/deliveries", org.ListHookDeliveries)
+				m.Get("/:id/deliveries/:delivery", org.GetHookDelivery)
+				m.Post("/:id/deliveries/:delivery/redeliver", reqOrgOwnership(), org.RedeliverHookDelivery)
+			}, reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgMembership())
 		}, orgAssignment(true))
 		m.Group("/teams/:teamid", func() {
 			m.Combo("").Get(org.GetTeam).
@@ -660,7 +848,24 @@ func RegisterRoutes(m *macaron.Macaron) {
 					Put(org.AddTeamRepository).
 					Delete(org.RemoveTeamRepository)
 			})
-		}, orgAssignment(false, true), reqToken(), reqOrgMembership())
+		}, orgAssignment(false, true), reqToken(), reqScope(models.AccessTokenScopeAdminOrg), reqOrgMembership())
+
+		m.Group("/projects/:id", func() {
+			m.Combo("").Get(repo.GetProject).
+				Patch(reqToken(), reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqProjectWriter(), bind(api.EditProjectOption{}), repo.EditProject).
+				Delete(reqToken(), reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqProjectWriter(), repo.DeleteProject)
+			m.Group("/boards", func() {
+				m.Combo("").Get(repo.ListProjectBoards).
+					Post(reqToken(), reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqProjectWriter(), bind(api.CreateProjectBoardOption{}), repo.CreateProjectBoard)
+				m.Group("/:boardid", func() {
+					m.Combo("").Patch(reqToken(), reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqProjectWriter(), bind(api.EditProjectBoardOption{}), repo.EditProjectBoard).
+						Delete(reqToken(), reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqProjectWriter(), repo.DeleteProjectBoard)
+					m.Combo("/issues").Get(repo.ListProjectBoardIssues).
+						Post(reqToken(), reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqProjectWriter(), bind(api.MoveProjectIssueOption{}), repo.MoveProjectIssue)
+				})
+			})
+			m.Delete("/issues/:issueid", reqToken(), reqScope(models.AccessTokenScopeRepoWrite, models.AccessTokenScopeIssue), reqProjectWriter(), repo.RemoveProjectIssue)
+		}, reqScope(models.AccessTokenScopeRepoRead, models.AccessTokenScopeIssue), projectAssignment())
 
 		m.Any("/*", func(ctx *context.Context) {
 			ctx.Error(404)
@@ -680,10 +885,24 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/repos", bind(api.CreateRepoOption{}), admin.CreateRepo)
 				})
 			})
-		}, reqToken(), reqSiteAdmin())
+			m.Group("/hooks", func() {
+				m.Combo("").Get(admin.ListHooks).
+					Post(bind(api.CreateHookOption{}), admin.CreateHook)
+				m.Combo("/:id").Get(admin.GetHook).
+					Patch(bind(api.EditHookOption{}), admin.EditHook).
+					Delete(admin.DeleteHook)
+				m.Get("/:id/deliveries", admin.ListHookDeliveries)
+				m.Get("/:id/deliveries/:delivery", admin.GetHookDelivery)
+				m.Post("/:id/deliveries/:delivery/redeliver", admin.RedeliverHookDelivery)
+			})
+		}, reqToken(), reqScope(models.AccessTokenScopeSudo), reqSiteAdmin())
 
 		m.Group("/topics", func() {
 			m.Get("/search", repo.TopicSearch)
 		})
+
+		m.Group("/code", func() {
+			m.Get("/search", repo.SearchCode)
+		}, reqScope(models.AccessTokenScopeRepoRead))
 	}, context.APIContexter(), sudo())
 }
@@ -6,6 +6,7 @@ package convert
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/Unknwon/com"
 
@@ -81,6 +82,113 @@ func ToCommit(repo *models.Repository, c *git.Commit) *api.PayloadCommit {
 	}
 }
 
+// ToCommitUser convert a git.Signature to an api.CommitUser
+func ToCommitUser(sig *git.Signature) *api.CommitUser {
+	return &api.CommitUser{
+		Name:  sig.Name,
+		Email: sig.Email,
+		Date:  sig.When.Format(time.RFC3339),
+	}
+}
+
+// ToRepoCommit convert a commit to api.Commit, the users the emails of the
+// commit belong to are looked up in userCache first and added to it
+func ToRepoCommit(repo *models.Repository, c *git.Commit, userCache map[string]*models.User) *api.Commit {
+	getUser := func(email string) *api.User {
+		user, ok := userCache[email]
+		if !ok {
+			var err error
+			if user, err = models.GetUserByEmail(email); err != nil {
+				if !models.IsErrUserNotExist(err) {
+					log.Error(4, "GetUserByEmail: %v", err)
+				}
+				user = nil
+			}
+			if userCache != nil {
+				userCache[email] = user
+			}
+		}
+		if user == nil {
+			return nil
+		}
+		return user.APIFormat()
+	}
+
+	commitURL := func(sha string) string {
+		return repo.APIURL() + "/commits/" + sha
+	}
+
+	parents := make([]*api.CommitMeta, 0, c.ParentCount())
+	for i := 0; i < c.ParentCount(); i++ {
+		sha, _ := c.ParentID(i)
+		parents = append(parents, &api.CommitMeta{
+			SHA: sha.String(),
+			URL: commitURL(sha.String()),
+		})
+	}
+
+	return &api.Commit{
+		SHA:     c.ID.String(),
+		URL:     commitURL(c.ID.String()),
+		HTMLURL: util.URLJoin(repo.HTMLURL(), "commit", c.ID.String()),
+		RepoCommit: &api.RepoCommit{
+			URL:       commitURL(c.ID.String()),
+			Author:    ToCommitUser(c.Author),
+			Committer: ToCommitUser(c.Committer),
+			Message:   c.Message(),
+			Tree: &api.CommitMeta{
+				SHA: c.Tree.ID.String(),
+				URL: repo.APIURL() + "/git/trees/" + c.Tree.ID.String(),
+			},
+		},
+		Author:    getUser(c.Author.Email),
+		Committer: getUser(c.Committer.Email),
+		Parents:   parents,
+	}
+}
+
+// ToWikiCommit convert a commit of a wiki to api.WikiCommit
+func ToWikiCommit(c *git.Commit) *api.WikiCommit {
+	return &api.WikiCommit{
+		SHA:       c.ID.String(),
+		Author:    ToCommitUser(c.Author),
+		Committer: ToCommitUser(c.Committer),
+		Message:   c.Message(),
+	}
+}
+
+// ToCommitAffectedFiles convert the files of a models.Diff to
+// api.CommitAffectedFiles and their total api.CommitStats
+func ToCommitAffectedFiles(diff *models.Diff) ([]*api.CommitAffectedFiles, *api.CommitStats) {
+	files := make([]*api.CommitAffectedFiles, 0, len(diff.Files))
+	for _, f := range diff.Files {
+		file := &api.CommitAffectedFiles{
+			Filename:  f.Name,
+			Additions: f.Addition,
+			Deletions: f.Deletion,
+			Changes:   f.Addition + f.Deletion,
+			Binary:    f.IsBin,
+		}
+		switch f.Type {
+		case models.DiffFileAdd:
+			file.Status = "added"
+		case models.DiffFileDel:
+			file.Status = "removed"
+		case models.DiffFileRename:
+			file.Status = "renamed"
+			file.PreviousFilename = f.OldName
+		default:
+			file.Status = "modified"
+		}
+		files = append(files, file)
+	}
+	return files, &api.CommitStats{
+		Total:     diff.TotalAddition + diff.TotalDeletion,
+		Additions: diff.TotalAddition,
+		Deletions: diff.TotalDeletion,
+	}
+}
+
 // ToPublicKey convert models.PublicKey to api.PublicKey
 func ToPublicKey(apiLink string, key *models.PublicKey) *api.PublicKey {
 	return &api.PublicKey{
@@ -144,24 +252,80 @@ func ToHook(repoLink string, w *models.Webhook) *api.Hook {
 		"url":          w.URL,
 		"content_type": w.ContentType.Name(),
 	}
-	if w.HookTaskType == models.SLACK {
+	switch w.HookTaskType {
+	case models.SLACK:
 		s := w.GetSlackHook()
 		config["channel"] = s.Channel
 		config["username"] = s.Username
 		config["icon_url"] = s.IconURL
 		config["color"] = s.Color
+	case models.TELEGRAM:
+		config["chat_id"] = w.GetTelegramHook().ChatID
+	case models.MATRIX:
+		s := w.GetMatrixHook()
+		config["homeserver_url"] = s.HomeserverURL
+		config["room_id"] = s.Room
+		config["message_type"] = s.MessageType
+	case models.CUSTOM:
+		s := w.GetCustomHook()
+		config["template"] = s.Template
+		for event, tpl := range s.EventTemplates {
+			config["template_"+string(event)] = tpl
+		}
 	}
 
 	return &api.Hook{
-		ID:      w.ID,
-		Type:    w.HookTaskType.Name(),
-		URL:     fmt.Sprintf("%s/settings/hooks/%d", repoLink, w.ID),
-		Active:  w.IsActive,
-		Config:  config,
-		Events:  w.EventsArray(),
-		Updated: w.UpdatedUnix.AsTime(),
-		Created: w.CreatedUnix.AsTime(),
+		ID:              w.ID,
+		Type:            w.HookTaskType.Name(),
+		URL:             fmt.Sprintf("%s/settings/hooks/%d", repoLink, w.ID),
+		Active:          w.IsActive,
+		Config:          config,
+		Events:          w.EventsArray(),
+		Updated:         w.UpdatedUnix.AsTime(),
+		Created:         w.CreatedUnix.AsTime(),
+		IsSystemWebhook: w.IsSystemWebhook,
+	}
+}
+
+// ToHookDelivery convert models.HookTask to api.HookDelivery
+func ToHookDelivery(t *models.HookTask) *api.HookDelivery {
+	delivery := &api.HookDelivery{
+		ID:       t.ID,
+		UUID:     t.UUID,
+		Event:    string(t.EventType),
+		URL:      t.URL,
+		Attempts: t.Attempts,
+	}
+	switch {
+	case t.IsSucceed:
+		delivery.Status = api.HookDeliveryStatusSucceeded
+	case t.IsPending():
+		delivery.Status = api.HookDeliveryStatusPending
+		nextAttempt := t.NextRetryUnix.AsTime()
+		delivery.NextAttempt = &nextAttempt
+	case !t.IsDelivered:
+		delivery.Status = api.HookDeliveryStatusQueued
+	default:
+		delivery.Status = api.HookDeliveryStatusFailed
+	}
+	if t.Delivered > 0 {
+		delivered := time.Unix(0, t.Delivered)
+		delivery.Delivered = &delivered
+	}
+	if t.RequestInfo != nil {
+		delivery.Request = &api.HookDeliveryRequest{
+			Headers: t.RequestInfo.Headers,
+			Payload: t.PayloadContent,
+		}
+	}
+	if t.ResponseInfo != nil {
+		delivery.Response = &api.HookDeliveryResponse{
+			Status:  t.ResponseInfo.Status,
+			Headers: t.ResponseInfo.Headers,
+			Body:    t.ResponseInfo.Body,
+		}
 	}
+	return delivery
 }
 
 // ToDeployKey convert models.DeployKey to api.DeployKey
@@ -178,6 +342,24 @@ func ToDeployKey(apiLink string, key *models.DeployKey) *api.DeployKey {
 	}
 }
 
+// ToPushMirror convert models.PushMirror to api.PushMirror
+func ToPushMirror(m *models.PushMirror) *api.PushMirror {
+	apiMirror := &api.PushMirror{
+		ID:            m.ID,
+		RemoteName:    m.RemoteName,
+		RemoteAddress: m.Address(),
+		Interval:      m.Interval.String(),
+		Created:       m.CreatedUnix.AsTime(),
+		LastError:     m.LastError,
+		Syncing:       m.IsSyncing(),
+	}
+	if m.LastUpdateUnix > 0 {
+		lastUpdate := m.LastUpdateUnix.AsTime()
+		apiMirror.LastUpdate = &lastUpdate
+	}
+	return apiMirror
+}
+
 // ToOrganization convert models.User to api.Organization
 func ToOrganization(org *models.User) *api.Organization {
 	return &api.Organization{
@@ -201,3 +383,126 @@ func ToTeam(team *models.Team) *api.Team {
 		Units:       team.GetUnitNames(),
 	}
 }
+
+// ToAccessToken convert models.AccessToken to api.AccessToken,
+// the repositories of the token must be loaded
+func ToAccessToken(t *models.AccessToken) *api.AccessToken {
+	apiToken := &api.AccessToken{
+		ID:           t.ID,
+		Name:         t.Name,
+		Sha1:         t.Sha1,
+		Scopes:       make([]string, 0, 1),
+		Repositories: make([]string, 0, len(t.Repos)),
+	}
+	for _, scope := range t.Scope.Scopes() {
+		apiToken.Scopes = append(apiToken.Scopes, string(scope))
+	}
+	for _, repo := range t.Repos {
+		apiToken.Repositories = append(apiToken.Repositories, repo.FullName())
+	}
+	if t.ExpiresUnix > 0 {
+		expires := t.ExpiresUnix.AsTime()
+		apiToken.Expires = &expires
+	}
+	return apiToken
+}
+
+// ToPullReview convert a review to api.PullReview, the reviewer and the issue
+// of the review must be loaded
+func ToPullReview(r *models.Review) *api.PullReview {
+	apiReview := &api.PullReview{
+		ID:                r.ID,
+		Reviewer:          r.Reviewer.APIFormat(),
+		Body:              r.Content,
+		Dismissed:         r.Dismissed,
+		CodeCommentsCount: r.GetCodeCommentsCount(),
+		Submitted:         r.UpdatedUnix.AsTime(),
+		HTMLPullURL:       r.Issue.HTMLURL(),
+	}
+	switch r.Type {
+	case models.ReviewTypePending:
+		apiReview.State = api.ReviewStatePending
+	case models.ReviewTypeApprove:
+		apiReview.State = api.ReviewStateApproved
+	case models.ReviewTypeComment:
+		apiReview.State = api.ReviewStateComment
+	case models.ReviewTypeReject:
+		apiReview.State = api.ReviewStateRequestChanges
+	case models.ReviewTypeRequest:
+		apiReview.State = api.ReviewStateRequestReview
+	default:
+		apiReview.State = api.ReviewStateUnknown
+	}
+	return apiReview
+}
+
+// ToPullReviewComment convert a code comment to api.PullReviewComment
+func ToPullReviewComment(c *models.Comment) *api.PullReviewComment {
+	apiComment := &api.PullReviewComment{
+		ID:          c.ID,
+		Body:        c.Content,
+		Reviewer:    c.Poster.APIFormat(),
+		ReviewID:    c.ReviewID,
+		Path:        c.TreePath,
+		CommitID:    c.CommitSHA,
+		DiffHunk:    c.Patch,
+		Created:     c.CreatedUnix.AsTime(),
+		Updated:     c.UpdatedUnix.AsTime(),
+		HTMLURL:     c.HTMLURL(),
+		HTMLPullURL: c.PRURL(),
+	}
+	if c.Line < 0 {
+		apiComment.OldLineNum = c.UnsignedLine()
+	} else {
+		apiComment.LineNum = c.UnsignedLine()
+	}
+	return apiComment
+}
+
+// ToBranchProtection convert a models.ProtectedBranch to api.BranchProtection
+func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
+	userNames := func(ids []int64) []string {
+		users, err := models.GetUsersByIDs(ids)
+		if err != nil {
+			log.Error(4, "GetUsersByIDs: %v", err)
+		}
+		names := make([]string, len(users))
+		for i, user := range users {
+			names[i] = user.Name
+		}
+		return names
+	}
+	teamNames := func(ids []int64) []string {
+		names := make([]string, 0, len(ids))
+		for _, id := range ids {
+			team, err := models.GetTeamByID(id)
+			if err != nil {
+				if err != models.ErrTeamNotExist {
+					log.Error(4, "GetTeamByID: %v", err)
+				}
+				continue
+			}
+			names = append(names, team.Name)
+		}
+		return names
+	}
+
+	contexts := bp.StatusCheckContexts
+	if contexts == nil {
+		contexts = []string{}
+	}
+	return &api.BranchProtection{
+		BranchName:              bp.BranchName,
+		EnablePushWhitelist:     bp.EnableWhitelist,
+		PushWhitelistUsernames:  userNames(bp.WhitelistUserIDs),
+		PushWhitelistTeams:      teamNames(bp.WhitelistTeamIDs),
+		EnableMergeWhitelist:    bp.EnableMergeWhitelist,
+		MergeWhitelistUsernames: userNames(bp.MergeWhitelistUserIDs),
+		MergeWhitelistTeams:     teamNames(bp.MergeWhitelistTeamIDs),
+		EnableStatusCheck:       bp.EnableStatusCheck,
+		StatusCheckContexts:     contexts,
+		RequiredApprovals:       bp.RequiredApprovals,
+		Created:                 bp.CreatedUnix.AsTime(),
+		Updated:                 bp.UpdatedUnix.AsTime(),
+	}
+}
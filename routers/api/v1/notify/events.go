@@ -0,0 +1,89 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/eventsource"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// pingInterval is how often a comment is written to the idle event streams,
+// so that the proxies do not close them
+const pingInterval = 30 * time.Second
+
+// Events streams the changes of the notifications of the authenticated user
+func Events(ctx *context.APIContext) {
+	// swagger:operation GET /notifications/events notification notifyEvents
+	// ---
+	// summary: Stream the changes of the notifications of the authenticated user
+	// description: The server-sent events named `notification-count` give the
+	//   number of unread notifications, as a NotificationCount, when the stream
+	//   starts and whenever the notifications change.
+	// produces:
+	// - text/event-stream
+	// responses:
+	//   "200":
+	//     description: the event stream
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if setting.UI.Notification.EventSourceUpdateTime <= 0 {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	count, err := models.GetNotificationCount(ctx.User, models.NotificationStatusUnread)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetNotificationCount", err)
+		return
+	}
+
+	manager := eventsource.GetManager()
+	events := manager.Register(ctx.User.ID)
+	defer manager.Unregister(ctx.User.ID, events)
+
+	ctx.Resp.Header().Set("Content-Type", "text/event-stream")
+	ctx.Resp.Header().Set("Cache-Control", "no-cache")
+	ctx.Resp.Header().Set("Connection", "keep-alive")
+	ctx.Resp.Header().Set("X-Accel-Buffering", "no")
+	ctx.Resp.WriteHeader(http.StatusOK)
+
+	event := &eventsource.Event{
+		Name:  eventsource.EventNotificationCount,
+		Data:  &api.NotificationCount{New: count},
+		Retry: setting.UI.Notification.EventSourceUpdateTime,
+	}
+	if _, err = event.WriteTo(ctx.Resp); err != nil {
+		return
+	}
+	ctx.Resp.Flush()
+
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	done := ctx.Req.Context().Done()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			if _, err = ctx.Resp.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if _, err = event.WriteTo(ctx.Resp); err != nil {
+				return
+			}
+		}
+		ctx.Resp.Flush()
+	}
+}
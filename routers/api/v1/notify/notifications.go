@@ -0,0 +1,213 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// canAccessRepo returns false if the request is authenticated with an access
+// token restricted to other repositories
+func canAccessRepo(ctx *context.APIContext, repoID int64) bool {
+	t := ctx.AccessToken()
+	return t == nil || t.CanAccessRepo(repoID)
+}
+
+// tokenRepoIDs returns the repositories the access token of the request is
+// restricted to, nil if it is not
+func tokenRepoIDs(ctx *context.APIContext) []int64 {
+	if t := ctx.AccessToken(); t != nil && t.IsRepoRestricted() {
+		return t.RepoIDs
+	}
+	return nil
+}
+
+// getFindNotificationOptions returns the filters of the notifications given
+// in the query. If there is an error, write to `ctx` accordingly and return
+// nil
+func getFindNotificationOptions(ctx *context.APIContext) *models.FindNotificationOptions {
+	opts := &models.FindNotificationOptions{
+		UserID:  ctx.User.ID,
+		RepoIDs: tokenRepoIDs(ctx),
+	}
+	if !ctx.QueryBool("all") {
+		opts.Status = []models.NotificationStatus{models.NotificationStatusUnread, models.NotificationStatusPinned}
+	}
+	if len(ctx.Query("since")) > 0 {
+		since, err := time.Parse(time.RFC3339, ctx.Query("since"))
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", "since must be a RFC 3339 time")
+			return nil
+		}
+		opts.UpdatedAfterUnix = since.Unix()
+	}
+	if len(ctx.Query("before")) > 0 {
+		before, err := time.Parse(time.RFC3339, ctx.Query("before"))
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", "before must be a RFC 3339 time")
+			return nil
+		}
+		opts.UpdatedBeforeUnix = before.Unix()
+	}
+	return opts
+}
+
+// listNotifications writes a page of the notifications matching the filters
+// of the query to `ctx`, the latest updated first
+func listNotifications(ctx *context.APIContext, repoID int64) {
+	if repoID != 0 && !canAccessRepo(ctx, repoID) {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	opts := getFindNotificationOptions(ctx)
+	if opts == nil {
+		return
+	}
+	opts.RepoID = repoID
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	limit := convert.ToCorrectPageSize(ctx.QueryInt("limit"))
+
+	notifications, err := models.GetNotifications(*opts, page, limit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetNotifications", err)
+		return
+	}
+	count, err := models.CountNotifications(*opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountNotifications", err)
+		return
+	}
+	if err = notifications.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+
+	threads := make([]*api.NotificationThread, len(notifications))
+	for i, n := range notifications {
+		threads[i] = n.APIFormat()
+	}
+	ctx.SetLinkHeader(int(count), limit)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, threads)
+}
+
+// readNotifications marks as read the unread notifications updated before
+// the time given in the query, now by default
+func readNotifications(ctx *context.APIContext, repoID int64) {
+	if repoID != 0 && !canAccessRepo(ctx, repoID) {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+	lastReadAt := time.Now()
+	if len(ctx.Query("last_read_at")) > 0 {
+		var err error
+		if lastReadAt, err = time.Parse(time.RFC3339, ctx.Query("last_read_at")); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", "last_read_at must be a RFC 3339 time")
+			return
+		}
+	}
+
+	if err := models.SetNotificationsStatus(models.FindNotificationOptions{
+		UserID:            ctx.User.ID,
+		RepoID:            repoID,
+		RepoIDs:           tokenRepoIDs(ctx),
+		Status:            []models.NotificationStatus{models.NotificationStatusUnread},
+		UpdatedBeforeUnix: lastReadAt.Unix(),
+	}, models.NotificationStatusRead); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetNotificationsStatus", err)
+		return
+	}
+	ctx.Status(http.StatusResetContent)
+}
+
+// ListNotifications list the notifications of the authenticated user
+func ListNotifications(ctx *context.APIContext) {
+	// swagger:operation GET /notifications notification notifyGetList
+	// ---
+	// summary: List the notifications of the authenticated user, the latest updated first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: all
+	//   in: query
+	//   description: include the notifications already read
+	//   type: boolean
+	// - name: since
+	//   in: query
+	//   description: only list the notifications updated after the given time, in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: only list the notifications updated before the given time, in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/NotificationThreadList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	listNotifications(ctx, 0)
+}
+
+// ReadNotifications mark as read the notifications of the authenticated user
+func ReadNotifications(ctx *context.APIContext) {
+	// swagger:operation PUT /notifications notification notifyReadList
+	// ---
+	// summary: Mark as read the unread notifications of the authenticated user
+	// parameters:
+	// - name: last_read_at
+	//   in: query
+	//   description: only mark as read the notifications updated before the given time, in RFC 3339 format, now by default
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "205":
+	//     "$ref": "#/responses/empty"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	readNotifications(ctx, 0)
+}
+
+// NewAvailable returns the number of unread notifications of the
+// authenticated user
+func NewAvailable(ctx *context.APIContext) {
+	// swagger:operation GET /notifications/new notification notifyNewAvailable
+	// ---
+	// summary: Check whether the authenticated user has unread notifications and how many
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/NotificationCount"
+	count, err := models.CountNotifications(models.FindNotificationOptions{
+		UserID:  ctx.User.ID,
+		RepoIDs: tokenRepoIDs(ctx),
+		Status:  []models.NotificationStatus{models.NotificationStatusUnread},
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountNotifications", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.NotificationCount{New: count})
+}
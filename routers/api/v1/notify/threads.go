@@ -0,0 +1,113 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package notify
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+)
+
+// getThread returns the notification of the authenticated user given in the
+// URL. If there is an error, write to `ctx` accordingly and return nil
+func getThread(ctx *context.APIContext) *models.Notification {
+	n, err := models.GetNotificationByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrNotificationNotExist(err) {
+			ctx.Status(http.StatusNotFound)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetNotificationByID", err)
+		}
+		return nil
+	}
+	// the notifications of the other users are not disclosed
+	if n.UserID != ctx.User.ID || !canAccessRepo(ctx, n.RepoID) {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	return n
+}
+
+// GetThread get a notification of the authenticated user
+func GetThread(ctx *context.APIContext) {
+	// swagger:operation GET /notifications/threads/{id} notification notifyGetThread
+	// ---
+	// summary: Get a notification of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the notification
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/NotificationThread"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	n := getThread(ctx)
+	if n == nil {
+		return
+	}
+	notifications := models.NotificationList{n}
+	if err := notifications.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, n.APIFormat())
+}
+
+// ReadThread change the status of a notification of the authenticated user
+func ReadThread(ctx *context.APIContext) {
+	// swagger:operation PATCH /notifications/threads/{id} notification notifyReadThread
+	// ---
+	// summary: Mark a notification of the authenticated user as read, unread or pinned
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the notification
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: to-status
+	//   in: query
+	//   description: new status of the notification
+	//   type: string
+	//   enum: [read, unread, pinned]
+	//   default: read
+	// responses:
+	//   "205":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	n := getThread(ctx)
+	if n == nil {
+		return
+	}
+
+	var status models.NotificationStatus
+	switch ctx.Query("to-status") {
+	case "", "read":
+		status = models.NotificationStatusRead
+	case "unread":
+		status = models.NotificationStatusUnread
+	case "pinned":
+		status = models.NotificationStatusPinned
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", "to-status must be read, unread or pinned")
+		return
+	}
+
+	if err := models.SetNotificationStatus(n.ID, ctx.User, status); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetNotificationStatus", err)
+		return
+	}
+	ctx.Status(http.StatusResetContent)
+}
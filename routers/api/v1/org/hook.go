@@ -73,6 +73,120 @@ func GetHook(ctx *context.APIContext) {
 	ctx.JSON(200, convert.ToHook(org.HomeLink(), hook))
 }
 
+// ListHookDeliveries list the deliveries of a organization's hook
+func ListHookDeliveries(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/hooks/{id}/deliveries organization orgListHookDeliveries
+	// ---
+	// summary: List the deliveries of a hook, the latest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDeliveryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.ListHookDeliveries(ctx, hook)
+}
+
+// GetHookDelivery get a delivery of a organization's hook
+func GetHookDelivery(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/hooks/{id}/deliveries/{delivery} organization orgGetHookDelivery
+	// ---
+	// summary: Get a delivery of a hook
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.GetHookDelivery(ctx, hook)
+}
+
+// RedeliverHookDelivery deliver again the payload of a delivery of a
+// organization's hook
+func RedeliverHookDelivery(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/hooks/{id}/deliveries/{delivery}/redeliver organization orgRedeliverHookDelivery
+	// ---
+	// summary: Deliver again the payload of a delivery of a hook to its current URL
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the hook
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: delivery
+	//   in: path
+	//   description: id of the delivery
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/HookDelivery"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetOrgHook(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	utils.RedeliverHookDelivery(ctx, hook)
+}
+
 // CreateHook create a hook for an organization
 func CreateHook(ctx *context.APIContext, form api.CreateHookOption) {
 	// swagger:operation POST /orgs/{org}/hooks/ organization orgCreateHook
@@ -0,0 +1,373 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+
+	api "code.gitea.io/sdk/gitea"
+)
+
+// ListBranchProtections list the branch protections of a repository
+func ListBranchProtections(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/branch_protections repository repoListBranchProtection
+	// ---
+	// summary: List branch protections for a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BranchProtectionList"
+	bps, err := ctx.Repo.Repository.GetProtectedBranches()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProtectedBranches", err)
+		return
+	}
+
+	apiBps := make([]*api.BranchProtection, len(bps))
+	for i := range bps {
+		apiBps[i] = convert.ToBranchProtection(bps[i])
+	}
+	ctx.JSON(http.StatusOK, apiBps)
+}
+
+// GetBranchProtection gets the protection of a branch
+func GetBranchProtection(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/branch_protections/{name} repository repoGetBranchProtection
+	// ---
+	// summary: Get a specific branch protection for the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the protected branch
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BranchProtection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	bp := getBranchProtection(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToBranchProtection(bp))
+}
+
+// CreateBranchProtection protects a branch of a repository
+func CreateBranchProtection(ctx *context.APIContext, form api.CreateBranchProtectionOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/branch_protections repository repoCreateBranchProtection
+	// ---
+	// summary: Create a branch protection for a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateBranchProtectionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/BranchProtection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if !ctx.Repo.GitRepo.IsBranchExist(form.BranchName) {
+		ctx.Error(http.StatusNotFound, "", fmt.Sprintf("branch does not exist [name: %s]", form.BranchName))
+		return
+	}
+	bp, err := models.GetProtectedBranchBy(ctx.Repo.Repository.ID, form.BranchName)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProtectedBranchBy", err)
+		return
+	} else if bp != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("branch is already protected [name: %s]", form.BranchName))
+		return
+	}
+	if form.RequiredApprovals < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "required_approvals cannot be negative")
+		return
+	}
+
+	whitelistUsers := getWhitelistUserIDs(ctx, form.PushWhitelistUsernames)
+	if ctx.Written() {
+		return
+	}
+	whitelistTeams := getWhitelistTeamIDs(ctx, form.PushWhitelistTeams)
+	if ctx.Written() {
+		return
+	}
+	mergeWhitelistUsers := getWhitelistUserIDs(ctx, form.MergeWhitelistUsernames)
+	if ctx.Written() {
+		return
+	}
+	mergeWhitelistTeams := getWhitelistTeamIDs(ctx, form.MergeWhitelistTeams)
+	if ctx.Written() {
+		return
+	}
+
+	bp = &models.ProtectedBranch{
+		RepoID:               ctx.Repo.Repository.ID,
+		BranchName:           form.BranchName,
+		EnableWhitelist:      form.EnablePushWhitelist,
+		EnableMergeWhitelist: form.EnableMergeWhitelist,
+		EnableStatusCheck:    form.EnableStatusCheck,
+		StatusCheckContexts:  cleanStatusCheckContexts(form.StatusCheckContexts),
+		RequiredApprovals:    form.RequiredApprovals,
+	}
+	if err = models.UpdateProtectBranch(ctx.Repo.Repository, bp, whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProtectBranch", err)
+		return
+	}
+	notification.NotifyUpdateProtectedBranch(ctx.User, ctx.Repo.Repository, bp, true)
+	log.Trace("Branch protected: %s/%s", ctx.Repo.Repository.FullName(), bp.BranchName)
+
+	ctx.JSON(http.StatusCreated, convert.ToBranchProtection(bp))
+}
+
+// EditBranchProtection edits the protection of a branch
+func EditBranchProtection(ctx *context.APIContext, form api.EditBranchProtectionOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/branch_protections/{name} repository repoEditBranchProtection
+	// ---
+	// summary: Edit a branch protection for a repository. Only fields that are set will be changed
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the protected branch
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditBranchProtectionOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BranchProtection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	bp := getBranchProtection(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	whitelistUsers, whitelistTeams := bp.WhitelistUserIDs, bp.WhitelistTeamIDs
+	mergeWhitelistUsers, mergeWhitelistTeams := bp.MergeWhitelistUserIDs, bp.MergeWhitelistTeamIDs
+	if form.PushWhitelistUsernames != nil {
+		if whitelistUsers = getWhitelistUserIDs(ctx, form.PushWhitelistUsernames); ctx.Written() {
+			return
+		}
+	}
+	if form.PushWhitelistTeams != nil {
+		if whitelistTeams = getWhitelistTeamIDs(ctx, form.PushWhitelistTeams); ctx.Written() {
+			return
+		}
+	}
+	if form.MergeWhitelistUsernames != nil {
+		if mergeWhitelistUsers = getWhitelistUserIDs(ctx, form.MergeWhitelistUsernames); ctx.Written() {
+			return
+		}
+	}
+	if form.MergeWhitelistTeams != nil {
+		if mergeWhitelistTeams = getWhitelistTeamIDs(ctx, form.MergeWhitelistTeams); ctx.Written() {
+			return
+		}
+	}
+
+	if form.EnablePushWhitelist != nil {
+		bp.EnableWhitelist = *form.EnablePushWhitelist
+	}
+	if form.EnableMergeWhitelist != nil {
+		bp.EnableMergeWhitelist = *form.EnableMergeWhitelist
+	}
+	if form.EnableStatusCheck != nil {
+		bp.EnableStatusCheck = *form.EnableStatusCheck
+	}
+	if form.StatusCheckContexts != nil {
+		bp.StatusCheckContexts = cleanStatusCheckContexts(form.StatusCheckContexts)
+	}
+	if form.RequiredApprovals != nil {
+		if *form.RequiredApprovals < 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "required_approvals cannot be negative")
+			return
+		}
+		bp.RequiredApprovals = *form.RequiredApprovals
+	}
+
+	if err := models.UpdateProtectBranch(ctx.Repo.Repository, bp, whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams); err != nil {
+		ctx.Error(http.StatusInternalServerError, "UpdateProtectBranch", err)
+		return
+	}
+	notification.NotifyUpdateProtectedBranch(ctx.User, ctx.Repo.Repository, bp, false)
+
+	ctx.JSON(http.StatusOK, convert.ToBranchProtection(bp))
+}
+
+// DeleteBranchProtection removes the protection of a branch
+func DeleteBranchProtection(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/branch_protections/{name} repository repoDeleteBranchProtection
+	// ---
+	// summary: Delete a specific branch protection for the repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the protected branch
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	bp := getBranchProtection(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := ctx.Repo.Repository.DeleteProtectedBranch(bp.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
+		return
+	}
+	notification.NotifyDeleteProtectedBranch(ctx.User, ctx.Repo.Repository, bp)
+	log.Trace("Branch protection removed: %s/%s", ctx.Repo.Repository.FullName(), bp.BranchName)
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// getBranchProtection returns the protection of the branch named in the path
+func getBranchProtection(ctx *context.APIContext) *models.ProtectedBranch {
+	bp, err := models.GetProtectedBranchBy(ctx.Repo.Repository.ID, ctx.Params("*"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetProtectedBranchBy", err)
+		return nil
+	} else if bp == nil {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	return bp
+}
+
+// getWhitelistUserIDs returns the IDs of the users of the names
+func getWhitelistUserIDs(ctx *context.APIContext, names []string) []int64 {
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		user, err := models.GetUserByName(name)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("user does not exist [name: %s]", name))
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return nil
+		}
+		ids = append(ids, user.ID)
+	}
+	return ids
+}
+
+// getWhitelistTeamIDs returns the IDs of the teams of the names, which belong
+// to the organization owning the repository
+func getWhitelistTeamIDs(ctx *context.APIContext, names []string) []int64 {
+	if len(names) > 0 && !ctx.Repo.Owner.IsOrganization() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "teams can only be whitelisted for the repositories of an organization")
+		return nil
+	}
+
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		team, err := models.GetTeam(ctx.Repo.Owner.ID, name)
+		if err != nil {
+			if err == models.ErrTeamNotExist {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("team does not exist [name: %s]", name))
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetTeam", err)
+			}
+			return nil
+		}
+		ids = append(ids, team.ID)
+	}
+	return ids
+}
+
+func cleanStatusCheckContexts(contexts []string) []string {
+	cleaned := make([]string, 0, len(contexts))
+	for _, statusContext := range contexts {
+		if statusContext = strings.TrimSpace(statusContext); len(statusContext) > 0 {
+			cleaned = append(cleaned, statusContext)
+		}
+	}
+	return cleaned
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/indexer"
+	"code.gitea.io/gitea/modules/search"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// SearchCode searches the files of the repositories the user can read
+func SearchCode(ctx *context.APIContext) {
+	// swagger:operation GET /code/search repository codeSearch
+	// ---
+	// summary: Search the code of all the repositories the user can read
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: q
+	//   in: query
+	//   description: keyword
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: search only the files of this branch or tag, given by its full name, e.g. "refs/heads/master"
+	//   type: string
+	// - name: language
+	//   in: query
+	//   description: search only the files of this language, e.g. "Go"
+	//   type: string
+	// - name: path
+	//   in: query
+	//   description: search only the files whose path starts with this prefix
+	//   type: string
+	// - name: owner
+	//   in: query
+	//   description: search only the repositories of this user or organization
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeSearchResults"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if !setting.Indexer.RepoIndexerEnabled {
+		ctx.Error(http.StatusNotFound, "", "code search is disabled")
+		return
+	}
+
+	opts := &indexer.RepoSearchOptions{
+		Keyword:    strings.TrimSpace(ctx.Query("q")),
+		Ref:        strings.TrimSpace(ctx.Query("ref")),
+		Language:   indexer.LanguageName(strings.TrimSpace(ctx.Query("language"))),
+		PathPrefix: strings.TrimSpace(ctx.Query("path")),
+		Page:       ctx.QueryInt("page"),
+		PageSize:   convert.ToCorrectPageSize(ctx.QueryInt("limit")),
+	}
+	if len(opts.Keyword) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "keyword is empty")
+		return
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+
+	var ownerID int64
+	if ownerName := ctx.Query("owner"); len(ownerName) > 0 {
+		owner, err := models.GetUserByName(ownerName)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		ownerID = owner.ID
+	}
+
+	var err error
+	opts.RepoIDs, err = models.FindUserCodeAccessibleRepoIDs(ctx.User, ownerID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindUserCodeAccessibleRepoIDs", err)
+		return
+	}
+	// an access token restricted to some repositories only searches them
+	if t := ctx.AccessToken(); t != nil && t.IsRepoRestricted() {
+		if opts.RepoIDs == nil {
+			opts.RepoIDs = append([]int64{}, t.RepoIDs...)
+		} else {
+			repoIDs := opts.RepoIDs[:0]
+			for _, id := range opts.RepoIDs {
+				if t.CanAccessRepo(id) {
+					repoIDs = append(repoIDs, id)
+				}
+			}
+			opts.RepoIDs = repoIDs
+		}
+	}
+
+	results := api.CodeSearchResults{
+		OK:        true,
+		Data:      []*api.CodeSearchResult{},
+		Languages: []*api.CodeSearchLanguage{},
+	}
+	// a nil list means all the repositories
+	if opts.RepoIDs != nil && len(opts.RepoIDs) == 0 {
+		ctx.JSON(http.StatusOK, results)
+		return
+	}
+
+	total, searchResults, languages, err := search.PerformSearch(opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "PerformSearch", err)
+		return
+	}
+
+	repos := make(map[int64]*api.Repository)
+	for _, result := range searchResults {
+		apiRepo, ok := repos[result.RepoID]
+		if !ok {
+			repo, err := models.GetRepositoryByID(result.RepoID)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetRepositoryByID", err)
+				return
+			}
+			accessMode, err := models.AccessLevel(ctx.User, repo)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "AccessLevel", err)
+				return
+			}
+			apiRepo = repo.APIFormat(accessMode)
+			repos[result.RepoID] = apiRepo
+		}
+
+		lines := make([]*api.CodeSearchLine, len(result.Lines))
+		for i, line := range result.Lines {
+			lines[i] = &api.CodeSearchLine{
+				Number:     line.Number,
+				Content:    line.Content,
+				MatchStart: line.MatchStart,
+				MatchEnd:   line.MatchEnd,
+			}
+		}
+		results.Data = append(results.Data, &api.CodeSearchResult{
+			Repository: apiRepo,
+			Ref:        result.Ref,
+			Path:       result.Filename,
+			Language:   result.Language,
+			HTMLURL:    fmt.Sprintf("%s/src/%s/%s", apiRepo.HTMLURL, result.RefSubURL(), result.Filename),
+			Lines:      lines,
+		})
+	}
+	for _, language := range languages {
+		results.Languages = append(results.Languages, &api.CodeSearchLanguage{
+			Language: language.Language,
+			Count:    language.Count,
+		})
+	}
+	results.Total = total
+
+	ctx.SetLinkHeader(total, opts.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", total))
+	ctx.JSON(http.StatusOK, results)
+}
@@ -0,0 +1,288 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// GetAllCommits lists the commits of a repository
+func GetAllCommits(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/commits repository repoGetAllCommits
+	// ---
+	// summary: Get a list of all commits from a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: sha
+	//   in: query
+	//   description: SHA or branch to start listing commits from (usually 'master')
+	//   type: string
+	// - name: path
+	//   in: query
+	//   description: only commits changing this file or directory will be returned
+	//   type: string
+	// - name: author
+	//   in: query
+	//   description: only commits with an author name or email containing this will be returned
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: only commits after this date will be returned (ISO 8601 format)
+	//   type: string
+	//   format: date-time
+	// - name: until
+	//   in: query
+	//   description: only commits before this date will be returned (ISO 8601 format)
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of requested commits
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CommitList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if ctx.Repo.Repository.IsBare {
+		ctx.JSON(http.StatusOK, []*api.Commit{})
+		return
+	}
+
+	ref := ctx.QueryTrim("sha")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := getCommitByRef(ctx.Repo.GitRepo, ref)
+	if err != nil {
+		ctx.Error(http.StatusNotFound, "", fmt.Sprintf("ref does not exist [name: %s]", ref))
+		return
+	}
+
+	opts := models.SearchCommitsOptions{
+		Revision: commit.ID.String(),
+		Path:     cleanTreePath(ctx.Query("path")),
+		Author:   ctx.QueryTrim("author"),
+		Page:     ctx.QueryInt("page"),
+		PageSize: convert.ToCorrectPageSize(ctx.QueryInt("limit")),
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	if len(ctx.Query("since")) > 0 {
+		if opts.Since, err = time.Parse(time.RFC3339, ctx.Query("since")); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", "since must be a RFC 3339 time")
+			return
+		}
+	}
+	if len(ctx.Query("until")) > 0 {
+		if opts.Until, err = time.Parse(time.RFC3339, ctx.Query("until")); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", "until must be a RFC 3339 time")
+			return
+		}
+	}
+
+	commits, count, err := models.SearchRepoCommits(ctx.Repo.GitRepo, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchRepoCommits", err)
+		return
+	}
+
+	userCache := make(map[string]*models.User)
+	apiCommits := make([]*api.Commit, len(commits))
+	for i, c := range commits {
+		apiCommits[i] = convert.ToRepoCommit(ctx.Repo.Repository, c, userCache)
+	}
+
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, apiCommits)
+}
+
+// GetSingleCommit gets a commit of a repository with the files it changed
+func GetSingleCommit(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/commits/{ref} repository repoGetSingleCommit
+	// ---
+	// summary: Get a single commit from a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: name of branch/tag/commit
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Commit"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	commit, err := getCommitByRef(ctx.Repo.GitRepo, ctx.Params(":ref"))
+	if err != nil {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	diff, err := models.GetDiffCommit(ctx.Repo.Repository.RepoPath(), commit.ID.String(),
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDiffCommit", err)
+		return
+	}
+
+	apiCommit := convert.ToRepoCommit(ctx.Repo.Repository, commit, nil)
+	apiCommit.Files, apiCommit.Stats = convert.ToCommitAffectedFiles(diff)
+	ctx.JSON(http.StatusOK, apiCommit)
+}
+
+// CompareCommits compares two refs of a repository
+func CompareCommits(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/compare/{basehead} repository repoCompareCommits
+	// ---
+	// summary: Compare two refs of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: basehead
+	//   in: path
+	//   description: the base and head branch/tag/commit to compare, separated by `...`
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Compare"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	refs := strings.SplitN(ctx.Params("*"), "...", 2)
+	if len(refs) != 2 || len(refs[0]) == 0 || len(refs[1]) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "refs to compare must be given as {base}...{head}")
+		return
+	}
+	baseCommit, err := getCommitByRef(ctx.Repo.GitRepo, refs[0])
+	if err != nil {
+		ctx.Error(http.StatusNotFound, "", fmt.Sprintf("ref does not exist [name: %s]", refs[0]))
+		return
+	}
+	headCommit, err := getCommitByRef(ctx.Repo.GitRepo, refs[1])
+	if err != nil {
+		ctx.Error(http.StatusNotFound, "", fmt.Sprintf("ref does not exist [name: %s]", refs[1]))
+		return
+	}
+
+	mergeBase, err := ctx.Repo.GitRepo.GetMergeBase(baseCommit.ID.String(), headCommit.ID.String())
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("%s and %s have no common ancestor", refs[0], refs[1]))
+		return
+	}
+	mergeBaseCommit, err := ctx.Repo.GitRepo.GetCommit(mergeBase)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		return
+	}
+
+	ahead, behind, err := models.CommitsAheadBehind(ctx.Repo.GitRepo, baseCommit.ID.String(), headCommit.ID.String())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CommitsAheadBehind", err)
+		return
+	}
+	commits, _, err := models.SearchRepoCommits(ctx.Repo.GitRepo, models.SearchCommitsOptions{
+		Revision: mergeBase + ".." + headCommit.ID.String(),
+		PageSize: setting.API.MaxResponseItems,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchRepoCommits", err)
+		return
+	}
+	diff, err := models.GetDiffRange(ctx.Repo.Repository.RepoPath(), mergeBase, headCommit.ID.String(),
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDiffRange", err)
+		return
+	}
+
+	userCache := make(map[string]*models.User)
+	compare := &api.Compare{
+		BaseCommit:      convert.ToRepoCommit(ctx.Repo.Repository, baseCommit, userCache),
+		MergeBaseCommit: convert.ToRepoCommit(ctx.Repo.Repository, mergeBaseCommit, userCache),
+		AheadBy:         ahead,
+		BehindBy:        behind,
+		TotalCommits:    int(ahead),
+		Commits:         make([]*api.Commit, len(commits)),
+		HTMLURL:         ctx.Repo.Repository.HTMLURL() + "/compare/" + refs[0] + "..." + refs[1],
+	}
+	switch {
+	case ahead == 0 && behind == 0:
+		compare.Status = "identical"
+	case behind == 0:
+		compare.Status = "ahead"
+	case ahead == 0:
+		compare.Status = "behind"
+	default:
+		compare.Status = "diverged"
+	}
+	for i, c := range commits {
+		compare.Commits[i] = convert.ToRepoCommit(ctx.Repo.Repository, c, userCache)
+	}
+	compare.Files, compare.Stats = convert.ToCommitAffectedFiles(diff)
+	ctx.JSON(http.StatusOK, compare)
+}
@@ -6,11 +6,23 @@
 package repo
 
 import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/validation"
+	"code.gitea.io/gitea/routers/api/v1/convert"
 	"code.gitea.io/gitea/routers/repo"
 
 	"code.gitea.io/git"
+	api "code.gitea.io/sdk/gitea"
 )
 
 // GetRawFile get a file by path on a repository
@@ -139,3 +151,595 @@ func GetEditorconfig(ctx *context.APIContext) {
 	}
 	ctx.JSON(200, def)
 }
+
+// getCommitByRef returns the commit a branch, a tag or a commit SHA points to
+func getCommitByRef(gitRepo *git.Repository, ref string) (*git.Commit, error) {
+	if gitRepo.IsBranchExist(ref) {
+		return gitRepo.GetBranchCommit(ref)
+	} else if gitRepo.IsTagExist(ref) {
+		return gitRepo.GetTagCommit(ref)
+	}
+	return gitRepo.GetCommit(ref)
+}
+
+// cleanTreePath returns the path of a file relative to the repository root
+func cleanTreePath(treePath string) string {
+	return strings.Trim(path.Clean("/"+treePath), " /")
+}
+
+func escapeTreePath(treePath string) string {
+	parts := strings.Split(treePath, "/")
+	for i := range parts {
+		parts[i] = url.PathEscape(parts[i])
+	}
+	return strings.Join(parts, "/")
+}
+
+// toContentsResponse converts a tree entry of a commit to its API format,
+// the content of files is only included if withContent is set and they are
+// smaller than the maximum display file size
+func toContentsResponse(r *models.Repository, commit *git.Commit, ref, treePath string, entry *git.TreeEntry, withContent bool) (*api.ContentsResponse, error) {
+	escapedPath := escapeTreePath(treePath)
+	contents := &api.ContentsResponse{
+		Name:    entry.Name(),
+		Path:    treePath,
+		SHA:     entry.ID.String(),
+		URL:     r.APIURL() + "/contents/" + escapedPath + "?ref=" + url.QueryEscape(ref),
+		HTMLURL: r.HTMLURL() + "/src/commit/" + commit.ID.String() + "/" + escapedPath,
+	}
+
+	switch {
+	case entry.IsDir():
+		contents.Type = "dir"
+	case entry.IsSubModule():
+		contents.Type = "submodule"
+	case entry.IsLink():
+		contents.Type = "symlink"
+		contents.Size = entry.Size()
+	default:
+		contents.Type = "file"
+		contents.Size = entry.Size()
+		contents.DownloadURL = r.HTMLURL() + "/raw/commit/" + commit.ID.String() + "/" + escapedPath
+	}
+
+	if withContent && contents.Type == "file" && contents.Size < setting.UI.MaxDisplayFileSize {
+		reader, err := entry.Blob().Data()
+		if err != nil {
+			return nil, fmt.Errorf("Data: %v", err)
+		}
+		data, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("ReadAll: %v", err)
+		}
+		encoding := "base64"
+		content := base64.StdEncoding.EncodeToString(data)
+		contents.Encoding = &encoding
+		contents.Content = &content
+	}
+	return contents, nil
+}
+
+// toFileCommitResponse converts a commit created by a file change to its API format
+func toFileCommitResponse(r *models.Repository, commit *git.Commit) *api.FileCommitResponse {
+	apiCommit := convert.ToRepoCommit(r, commit, nil)
+	return &api.FileCommitResponse{
+		SHA:       apiCommit.SHA,
+		URL:       apiCommit.URL,
+		HTMLURL:   apiCommit.HTMLURL,
+		Author:    apiCommit.RepoCommit.Author,
+		Committer: apiCommit.RepoCommit.Committer,
+		Message:   apiCommit.RepoCommit.Message,
+		Parents:   apiCommit.Parents,
+	}
+}
+
+// GetContents gets the metadata and contents of a file or the entries of a directory
+func GetContents(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/contents/{filepath} repository repoGetContents
+	// ---
+	// summary: Gets the metadata and contents (if a file) of an entry in a repository, or a list of entries if a dir
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the dir, file, symlink or submodule in the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentsResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(404)
+		return
+	}
+
+	ref := ctx.QueryTrim("ref")
+	if len(ref) == 0 {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := getCommitByRef(ctx.Repo.GitRepo, ref)
+	if err != nil {
+		ctx.Status(404)
+		return
+	}
+
+	treePath := cleanTreePath(ctx.Params("*"))
+	if len(treePath) == 0 {
+		entries, err := commit.Tree.ListEntries()
+		if err != nil {
+			ctx.Error(500, "ListEntries", err)
+			return
+		}
+		listContents(ctx, commit, ref, "", entries)
+		return
+	}
+
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetTreeEntryByPath", err)
+		}
+		return
+	}
+
+	if entry.IsDir() {
+		tree, err := commit.SubTree(treePath)
+		if err != nil {
+			ctx.Error(500, "SubTree", err)
+			return
+		}
+		entries, err := tree.ListEntries()
+		if err != nil {
+			ctx.Error(500, "ListEntries", err)
+			return
+		}
+		listContents(ctx, commit, ref, treePath, entries)
+		return
+	}
+
+	contents, err := toContentsResponse(ctx.Repo.Repository, commit, ref, treePath, entry, true)
+	if err != nil {
+		ctx.Error(500, "toContentsResponse", err)
+		return
+	}
+	ctx.JSON(200, contents)
+}
+
+// GetContentsList gets the metadata of all the entries of the root dir
+func GetContentsList(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/contents repository repoGetContentsList
+	// ---
+	// summary: Gets the metadata of all the entries of the root dir
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The name of the commit/branch/tag. Default the repository’s default branch (usually master)"
+	//   type: string
+	//   required: false
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentsListResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	GetContents(ctx)
+}
+
+func listContents(ctx *context.APIContext, commit *git.Commit, ref, treePath string, entries git.Entries) {
+	contents := make([]*api.ContentsResponse, 0, len(entries))
+	for _, entry := range entries {
+		c, err := toContentsResponse(ctx.Repo.Repository, commit, ref, path.Join(treePath, entry.Name()), entry, false)
+		if err != nil {
+			ctx.Error(500, "toContentsResponse", err)
+			return
+		}
+		contents = append(contents, c)
+	}
+	ctx.JSON(200, contents)
+}
+
+// toSignature converts an identity given to the API to a git signature, nil
+// is returned if no identity was given
+func toSignature(identity api.Identity) (*git.Signature, error) {
+	if len(identity.Name) == 0 && len(identity.Email) == 0 {
+		return nil, nil
+	} else if len(identity.Name) == 0 || len(identity.Email) == 0 {
+		return nil, fmt.Errorf("both name and email are required for an identity")
+	}
+	return &git.Signature{
+		Name:  identity.Name,
+		Email: identity.Email,
+		When:  time.Now(),
+	}, nil
+}
+
+// fileChange holds the options common to all the changes of a file
+type fileChange struct {
+	Commit    *git.Commit
+	OldBranch string
+	NewBranch string
+	TreePath  string
+	Author    *git.Signature
+	Committer *git.Signature
+}
+
+// prepareFileChange validates the options common to all the changes of a
+// file and checks that the user is allowed to push to the branch, the error
+// has been written to the response if false is returned
+func prepareFileChange(ctx *context.APIContext, opts api.FileOptions) (*fileChange, bool) {
+	if ctx.Repo.Repository.IsBare {
+		ctx.Status(404)
+		return nil, false
+	}
+
+	change := &fileChange{
+		OldBranch: opts.BranchName,
+		NewBranch: opts.NewBranchName,
+		TreePath:  cleanTreePath(ctx.Params("*")),
+	}
+	if len(change.TreePath) == 0 {
+		ctx.Error(422, "", "file path is required")
+		return nil, false
+	}
+
+	if len(change.OldBranch) == 0 {
+		change.OldBranch = ctx.Repo.Repository.DefaultBranch
+	}
+	if !ctx.Repo.GitRepo.IsBranchExist(change.OldBranch) {
+		ctx.Error(404, "", fmt.Sprintf("branch does not exist [name: %s]", change.OldBranch))
+		return nil, false
+	}
+	if len(change.NewBranch) == 0 {
+		change.NewBranch = change.OldBranch
+	} else if !validation.IsValidGitRefName(change.NewBranch) {
+		ctx.Error(422, "", fmt.Sprintf("invalid branch name [name: %s]", change.NewBranch))
+		return nil, false
+	} else if change.NewBranch != change.OldBranch && ctx.Repo.GitRepo.IsBranchExist(change.NewBranch) {
+		ctx.Error(422, "", fmt.Sprintf("branch already exists [name: %s]", change.NewBranch))
+		return nil, false
+	}
+
+	protected, err := ctx.Repo.Repository.IsProtectedBranchForPush(change.NewBranch, ctx.User)
+	if err != nil {
+		ctx.Error(500, "IsProtectedBranchForPush", err)
+		return nil, false
+	} else if protected {
+		ctx.Error(403, "", fmt.Sprintf("branch is protected [name: %s]", change.NewBranch))
+		return nil, false
+	}
+
+	if change.Author, err = toSignature(opts.Author); err != nil {
+		ctx.Error(422, "", fmt.Sprintf("author: %v", err))
+		return nil, false
+	}
+	if change.Committer, err = toSignature(opts.Committer); err != nil {
+		ctx.Error(422, "", fmt.Sprintf("committer: %v", err))
+		return nil, false
+	}
+	if change.Author == nil {
+		change.Author = change.Committer
+	} else if change.Committer == nil {
+		change.Committer = change.Author
+	}
+
+	if change.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(change.OldBranch); err != nil {
+		ctx.Error(500, "GetBranchCommit", err)
+		return nil, false
+	}
+	return change, true
+}
+
+// checkNewTreePath checks that a file can be written at the path of the
+// change, the error has been written to the response if false is returned
+func checkNewTreePath(ctx *context.APIContext, change *fileChange, treePath string) bool {
+	parts := strings.Split(treePath, "/")
+	var subPath string
+	for i, part := range parts {
+		subPath = path.Join(subPath, part)
+		entry, err := change.Commit.GetTreeEntryByPath(subPath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				return true
+			}
+			ctx.Error(500, "GetTreeEntryByPath", err)
+			return false
+		}
+		if i < len(parts)-1 && !entry.IsDir() {
+			ctx.Error(422, "", fmt.Sprintf("a file exists where a directory should be [path: %s]", subPath))
+			return false
+		}
+	}
+	ctx.Error(422, "", models.ErrRepoFileAlreadyExist{FileName: treePath})
+	return false
+}
+
+// commitMessage returns the message given to the API, or the message the
+// web editor would use
+func commitMessage(ctx *context.APIContext, message, key, treePath string) string {
+	message = strings.TrimSpace(message)
+	if len(message) == 0 {
+		message = ctx.Tr(key, treePath)
+	}
+	return message
+}
+
+// writeFileResponse writes the new state of the file of a change to the response
+func writeFileResponse(ctx *context.APIContext, status int, change *fileChange, deleted bool) {
+	commit, err := ctx.Repo.GitRepo.GetBranchCommit(change.NewBranch)
+	if err != nil {
+		ctx.Error(500, "GetBranchCommit", err)
+		return
+	}
+
+	resp := &api.FileResponse{
+		Commit: toFileCommitResponse(ctx.Repo.Repository, commit),
+	}
+	if !deleted {
+		entry, err := commit.GetTreeEntryByPath(change.TreePath)
+		if err != nil {
+			ctx.Error(500, "GetTreeEntryByPath", err)
+			return
+		}
+		if resp.Content, err = toContentsResponse(ctx.Repo.Repository, commit, change.NewBranch, change.TreePath, entry, false); err != nil {
+			ctx.Error(500, "toContentsResponse", err)
+			return
+		}
+	}
+	ctx.JSON(status, resp)
+}
+
+// handleFileChangeError writes the error of a file change to the response
+func handleFileChangeError(ctx *context.APIContext, title string, err error) {
+	switch {
+	case models.IsErrRepoFileDoesNotExist(err):
+		ctx.Error(404, "", err)
+	case models.IsErrRepoFileSHAMismatch(err):
+		ctx.Error(409, "", err)
+	case models.IsErrRepoFileAlreadyExist(err):
+		ctx.Error(422, "", err)
+	default:
+		ctx.Error(500, title, err)
+	}
+}
+
+// CreateFile creates a file in a repository
+func CreateFile(ctx *context.APIContext, opts api.CreateFileOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/contents/{filepath} repository repoCreateFile
+	// ---
+	// summary: Create a file in a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the file to create
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateFileOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/FileResponse"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	change, ok := prepareFileChange(ctx, opts.FileOptions)
+	if !ok {
+		return
+	}
+
+	content, err := base64.StdEncoding.DecodeString(opts.Content)
+	if err != nil {
+		ctx.Error(422, "", fmt.Sprintf("content is not base64 encoded: %v", err))
+		return
+	}
+	if !checkNewTreePath(ctx, change, change.TreePath) {
+		return
+	}
+
+	if err := ctx.Repo.Repository.UpdateRepoFile(ctx.User, models.UpdateRepoFileOptions{
+		LastCommitID: change.Commit.ID.String(),
+		OldBranch:    change.OldBranch,
+		NewBranch:    change.NewBranch,
+		OldTreeName:  change.TreePath,
+		NewTreeName:  change.TreePath,
+		Message:      commitMessage(ctx, opts.Message, "repo.editor.add", change.TreePath),
+		Content:      string(content),
+		IsNewFile:    true,
+		Author:       change.Author,
+		Committer:    change.Committer,
+	}); err != nil {
+		handleFileChangeError(ctx, "UpdateRepoFile", err)
+		return
+	}
+	writeFileResponse(ctx, 201, change, false)
+}
+
+// UpdateFile updates a file in a repository
+func UpdateFile(ctx *context.APIContext, opts api.UpdateFileOptions) {
+	// swagger:operation PUT /repos/{owner}/{repo}/contents/{filepath} repository repoUpdateFile
+	// ---
+	// summary: Update a file in a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the file to update
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/UpdateFileOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/FileResponse"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	change, ok := prepareFileChange(ctx, opts.FileOptions)
+	if !ok {
+		return
+	}
+
+	content, err := base64.StdEncoding.DecodeString(opts.Content)
+	if err != nil {
+		ctx.Error(422, "", fmt.Sprintf("content is not base64 encoded: %v", err))
+		return
+	}
+
+	fromPath := change.TreePath
+	if len(opts.FromPath) > 0 {
+		fromPath = cleanTreePath(opts.FromPath)
+	}
+	if fromPath != change.TreePath && !checkNewTreePath(ctx, change, change.TreePath) {
+		return
+	}
+
+	if err := ctx.Repo.Repository.UpdateRepoFile(ctx.User, models.UpdateRepoFileOptions{
+		LastCommitID: change.Commit.ID.String(),
+		OldBranch:    change.OldBranch,
+		NewBranch:    change.NewBranch,
+		OldTreeName:  fromPath,
+		NewTreeName:  change.TreePath,
+		Message:      commitMessage(ctx, opts.Message, "repo.editor.update", change.TreePath),
+		Content:      string(content),
+		SHA:          opts.SHA,
+		Author:       change.Author,
+		Committer:    change.Committer,
+	}); err != nil {
+		handleFileChangeError(ctx, "UpdateRepoFile", err)
+		return
+	}
+	writeFileResponse(ctx, 200, change, false)
+}
+
+// DeleteFile deletes a file in a repository
+func DeleteFile(ctx *context.APIContext, opts api.DeleteFileOptions) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/contents/{filepath} repository repoDeleteFile
+	// ---
+	// summary: Delete a file in a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: filepath
+	//   in: path
+	//   description: path of the file to delete
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/DeleteFileOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/FileResponse"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	change, ok := prepareFileChange(ctx, opts.FileOptions)
+	if !ok {
+		return
+	}
+
+	if err := ctx.Repo.Repository.DeleteRepoFile(ctx.User, models.DeleteRepoFileOptions{
+		LastCommitID: change.Commit.ID.String(),
+		OldBranch:    change.OldBranch,
+		NewBranch:    change.NewBranch,
+		TreePath:     change.TreePath,
+		Message:      commitMessage(ctx, opts.Message, "repo.editor.delete", change.TreePath),
+		SHA:          opts.SHA,
+		Author:       change.Author,
+		Committer:    change.Committer,
+	}); err != nil {
+		handleFileChangeError(ctx, "DeleteRepoFile", err)
+		return
+	}
+	writeFileResponse(ctx, 200, change, true)
+}
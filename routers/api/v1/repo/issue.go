@@ -12,7 +12,6 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
-	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
@@ -72,7 +71,7 @@ func ListIssues(ctx *context.APIContext) {
 	var issueIDs []int64
 	var err error
 	if len(keyword) > 0 {
-		issueIDs, err = indexer.SearchIssuesByKeyword(ctx.Repo.Repository.ID, keyword)
+		issueIDs, err = models.SearchIssuesByKeyword(ctx.Repo.Repository.ID, keyword)
 	}
 
 	// Only fetch the issues if we either don't have a keyword or the search returned issues
@@ -311,7 +310,7 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 			oneAssignee = *form.Assignee
 		}
 
-		err = models.UpdateAPIAssignee(issue, oneAssignee, form.Assignees, ctx.User)
+		err = updateIssueAssignees(ctx.User, issue, oneAssignee, form.Assignees)
 		if err != nil {
 			ctx.Error(500, "UpdateAPIAssignee", err)
 			return
@@ -326,6 +325,7 @@ func EditIssue(ctx *context.APIContext, form api.EditIssueOption) {
 			ctx.Error(500, "ChangeMilestoneAssign", err)
 			return
 		}
+		notification.NotifyIssueChangeMilestone(ctx.User, issue, oldMilestoneID)
 	}
 
 	if err = models.UpdateIssue(issue); err != nil {
@@ -419,3 +419,36 @@ func UpdateIssueDeadline(ctx *context.APIContext, form api.EditDeadlineOption) {
 
 	ctx.JSON(201, api.IssueDeadline{Deadline: form.Deadline})
 }
+
+// updateIssueAssignees replaces the assignees of the issue and notifies the
+// users who were assigned and unassigned
+func updateIssueAssignees(doer *models.User, issue *models.Issue, oneAssignee string, multipleAssignees []string) error {
+	oldAssignees, err := models.GetAssigneesByIssue(issue)
+	if err != nil {
+		return err
+	}
+	if err = models.UpdateAPIAssignee(issue, oneAssignee, multipleAssignees, doer); err != nil {
+		return err
+	}
+	newAssignees, err := models.GetAssigneesByIssue(issue)
+	if err != nil {
+		return err
+	}
+
+	oldIDs := make(map[int64]bool, len(oldAssignees))
+	for _, assignee := range oldAssignees {
+		oldIDs[assignee.ID] = true
+	}
+	for _, assignee := range newAssignees {
+		if !oldIDs[assignee.ID] {
+			notification.NotifyIssueChangeAssignee(doer, issue, assignee, false)
+		}
+		delete(oldIDs, assignee.ID)
+	}
+	for _, assignee := range oldAssignees {
+		if oldIDs[assignee.ID] {
+			notification.NotifyIssueChangeAssignee(doer, issue, assignee, true)
+		}
+	}
+	return nil
+}
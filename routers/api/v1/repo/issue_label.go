@@ -8,6 +8,7 @@ package repo
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
 
 	api "code.gitea.io/sdk/gitea"
 )
@@ -116,6 +117,7 @@ func AddIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 		ctx.Error(500, "AddLabels", err)
 		return
 	}
+	notification.NotifyIssueChangeLabels(ctx.User, issue, labels, nil)
 
 	labels, err = models.GetLabelsByIssueID(issue.ID)
 	if err != nil {
@@ -192,6 +194,7 @@ func DeleteIssueLabel(ctx *context.APIContext) {
 		ctx.Error(500, "DeleteIssueLabel", err)
 		return
 	}
+	notification.NotifyIssueChangeLabels(ctx.User, issue, nil, []*models.Label{label})
 
 	ctx.Status(204)
 }
@@ -250,7 +253,7 @@ func ReplaceIssueLabels(ctx *context.APIContext, form api.IssueLabelsOption) {
 		return
 	}
 
-	if err := issue.ReplaceLabels(labels, ctx.User); err != nil {
+	if err := replaceIssueLabels(ctx.User, issue, labels); err != nil {
 		ctx.Error(500, "ReplaceLabels", err)
 		return
 	}
@@ -314,6 +317,40 @@ func ClearIssueLabels(ctx *context.APIContext) {
 		ctx.Error(500, "ClearLabels", err)
 		return
 	}
+	notification.NotifyIssueClearLabels(ctx.User, issue)
 
 	ctx.Status(204)
 }
+
+// replaceIssueLabels replaces the labels of the issue and notifies the labels
+// which were added and removed
+func replaceIssueLabels(doer *models.User, issue *models.Issue, labels []*models.Label) error {
+	oldLabels, err := models.GetLabelsByIssueID(issue.ID)
+	if err != nil {
+		return err
+	}
+	if err = issue.ReplaceLabels(labels, doer); err != nil {
+		return err
+	}
+
+	oldIDs := make(map[int64]bool, len(oldLabels))
+	for _, label := range oldLabels {
+		oldIDs[label.ID] = true
+	}
+	var added, removed []*models.Label
+	for _, label := range labels {
+		if !oldIDs[label.ID] {
+			added = append(added, label)
+		}
+		delete(oldIDs, label.ID)
+	}
+	for _, label := range oldLabels {
+		if oldIDs[label.ID] {
+			removed = append(removed, label)
+		}
+	}
+	if len(added) > 0 || len(removed) > 0 {
+		notification.NotifyIssueChangeLabels(doer, issue, added, removed)
+	}
+	return nil
+}
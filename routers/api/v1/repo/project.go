@@ -0,0 +1,540 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// ListProjects list a repository's projects
+func ListProjects(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/projects issue issueListProjects
+	// ---
+	// summary: List a repository's projects
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: state
+	//   in: query
+	//   description: whether the projects are open, closed or all
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of requested projects
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectList"
+	utils.ListProjects(ctx, ctx.Repo.Repository.ID, 0)
+}
+
+// CreateProject create a project for a repository
+func CreateProject(ctx *context.APIContext, form api.CreateProjectOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/projects issue issueCreateProject
+	// ---
+	// summary: Create a project for a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Project"
+	utils.AddProject(ctx, &form, ctx.Repo.Repository.ID, 0)
+}
+
+// GetProject get a project by its id
+func GetProject(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id} issue issueGetProject
+	// ---
+	// summary: Get a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	p := ctx.Data["Project"].(*models.Project)
+	if err := (models.ProjectList{p}).LoadNumIssues(); err != nil {
+		ctx.Error(500, "LoadNumIssues", err)
+		return
+	}
+	ctx.JSON(200, p.APIFormat())
+}
+
+// EditProject modify a project
+func EditProject(ctx *context.APIContext, form api.EditProjectOption) {
+	// swagger:operation PATCH /projects/{id} issue issueEditProject
+	// ---
+	// summary: Update a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Project"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	p := ctx.Data["Project"].(*models.Project)
+	if form.Title != nil {
+		p.Title = *form.Title
+	}
+	if form.Description != nil {
+		p.Description = *form.Description
+	}
+	if form.CloseBoardID != nil {
+		p.CloseBoardID = *form.CloseBoardID
+	}
+	if err := models.UpdateProject(p); err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.Error(422, "", "Invalid close board")
+		} else {
+			ctx.Error(500, "UpdateProject", err)
+		}
+		return
+	}
+
+	if form.State != nil {
+		isClosed := api.StateClosed == api.StateType(*form.State)
+		if isClosed != p.IsClosed {
+			if err := models.ChangeProjectStatus(p, isClosed); err != nil {
+				ctx.Error(500, "ChangeProjectStatus", err)
+				return
+			}
+		}
+	}
+
+	GetProject(ctx)
+}
+
+// DeleteProject delete a project
+func DeleteProject(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id} issue issueDeleteProject
+	// ---
+	// summary: Delete a project, the issues on the project are kept
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	p := ctx.Data["Project"].(*models.Project)
+	if err := models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Error(500, "DeleteProjectByID", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// ListProjectBoards list the boards of a project
+func ListProjectBoards(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id}/boards issue issueListProjectBoards
+	// ---
+	// summary: List the boards of a project
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoardList"
+	p := ctx.Data["Project"].(*models.Project)
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.Error(500, "GetProjectBoards", err)
+		return
+	}
+
+	apiBoards := make([]*api.ProjectBoard, len(boards))
+	for i := range boards {
+		apiBoards[i] = boards[i].APIFormat()
+	}
+	ctx.JSON(200, &apiBoards)
+}
+
+// CreateProjectBoard create a board in a project
+func CreateProjectBoard(ctx *context.APIContext, form api.CreateProjectBoardOption) {
+	// swagger:operation POST /projects/{id}/boards issue issueCreateProjectBoard
+	// ---
+	// summary: Create a board in a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateProjectBoardOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ProjectBoard"
+	p := ctx.Data["Project"].(*models.Project)
+	board := &models.ProjectBoard{
+		Title:     form.Title,
+		Sorting:   form.Sorting,
+		ProjectID: p.ID,
+		CreatorID: ctx.User.ID,
+	}
+	if err := models.NewProjectBoard(board); err != nil {
+		ctx.Error(500, "NewProjectBoard", err)
+		return
+	}
+	ctx.JSON(201, board.APIFormat())
+}
+
+// getProjectBoard returns the board of the request, or the uncategorized
+// board if allowed. If there is an error, write to `ctx` accordingly
+func getProjectBoard(ctx *context.APIContext, p *models.Project, allowUncategorized bool) *models.ProjectBoard {
+	boardID := ctx.ParamsInt64(":boardid")
+	if boardID == 0 && allowUncategorized {
+		return &models.ProjectBoard{ProjectID: p.ID}
+	}
+	board, err := models.GetProjectBoard(p.ID, boardID)
+	if err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetProjectBoard", err)
+		}
+		return nil
+	}
+	return board
+}
+
+// EditProjectBoard modify a board of a project
+func EditProjectBoard(ctx *context.APIContext, form api.EditProjectBoardOption) {
+	// swagger:operation PATCH /projects/{id}/boards/{board_id} issue issueEditProjectBoard
+	// ---
+	// summary: Update a board of a project
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditProjectBoardOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ProjectBoard"
+	board := getProjectBoard(ctx, ctx.Data["Project"].(*models.Project), false)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Title != nil && len(*form.Title) > 0 {
+		board.Title = *form.Title
+	}
+	if form.Sorting != nil {
+		board.Sorting = *form.Sorting
+	}
+	if err := models.UpdateProjectBoard(board); err != nil {
+		ctx.Error(500, "UpdateProjectBoard", err)
+		return
+	}
+	ctx.JSON(200, board.APIFormat())
+}
+
+// DeleteProjectBoard delete a board of a project
+func DeleteProjectBoard(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id}/boards/{board_id} issue issueDeleteProjectBoard
+	// ---
+	// summary: Delete a board of a project, its issues are moved to the uncategorized board
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	board := getProjectBoard(ctx, ctx.Data["Project"].(*models.Project), false)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DeleteProjectBoardByID(board.ProjectID, board.ID); err != nil {
+		ctx.Error(500, "DeleteProjectBoardByID", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// issuePermission returns the permission of the user on the repository of an
+// issue, limited to what the access token of the request allows
+func issuePermission(ctx *context.APIContext, issue *models.Issue, perms map[int64]models.Permission) (models.Permission, error) {
+	if perm, ok := perms[issue.RepoID]; ok {
+		return perm, nil
+	}
+	if err := issue.LoadRepo(); err != nil {
+		return models.Permission{}, err
+	}
+	perm, err := models.GetUserRepoPermission(issue.Repo, ctx.User)
+	if err != nil {
+		return perm, err
+	}
+	if t := ctx.AccessToken(); t != nil {
+		perm = t.LimitRepoPermission(issue.Repo, perm)
+	}
+	perms[issue.RepoID] = perm
+	return perm, nil
+}
+
+// ListProjectBoardIssues list the issues on a board of a project
+func ListProjectBoardIssues(ctx *context.APIContext) {
+	// swagger:operation GET /projects/{id}/boards/{board_id}/issues issue issueListProjectBoardIssues
+	// ---
+	// summary: List the issues on a board of a project in the order of their cards
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board, 0 for the issues not sorted into any board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueList"
+	board := getProjectBoard(ctx, ctx.Data["Project"].(*models.Project), true)
+	if ctx.Written() {
+		return
+	}
+	if err := board.LoadIssues(); err != nil {
+		ctx.Error(500, "LoadIssues", err)
+		return
+	}
+
+	perms := make(map[int64]models.Permission)
+	apiIssues := make([]*api.Issue, 0, len(board.Issues))
+	for _, issue := range board.Issues {
+		perm, err := issuePermission(ctx, issue, perms)
+		if err != nil {
+			ctx.Error(500, "issuePermission", err)
+			return
+		}
+		if perm.CanReadIssuesOrPulls(issue.IsPull) {
+			apiIssues = append(apiIssues, issue.APIFormat())
+		}
+	}
+	ctx.JSON(200, &apiIssues)
+}
+
+// getIssueForProject returns the issue with the given id if the user can
+// change it. If there is an error, write to `ctx` accordingly
+func getIssueForProject(ctx *context.APIContext, issueID int64) *models.Issue {
+	issue, err := models.GetIssueByID(issueID)
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.Error(422, "", "Issue does not exist")
+		} else {
+			ctx.Error(500, "GetIssueByID", err)
+		}
+		return nil
+	}
+
+	perm, err := issuePermission(ctx, issue, make(map[int64]models.Permission))
+	if err != nil {
+		ctx.Error(500, "issuePermission", err)
+		return nil
+	} else if !perm.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Error(422, "", "Issue does not exist")
+		return nil
+	}
+	return issue
+}
+
+// MoveProjectIssue add an issue to a board of a project or move it to another board
+func MoveProjectIssue(ctx *context.APIContext, form api.MoveProjectIssueOption) {
+	// swagger:operation POST /projects/{id}/boards/{board_id}/issues issue issueMoveProjectIssue
+	// ---
+	// summary: Add an issue to a board of a project or move it to another board
+	// consumes:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: board_id
+	//   in: path
+	//   description: id of the board, 0 for the issues not sorted into any board
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MoveProjectIssueOption"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	p := ctx.Data["Project"].(*models.Project)
+	board := getProjectBoard(ctx, p, true)
+	if ctx.Written() {
+		return
+	}
+	issue := getIssueForProject(ctx, form.IssueID)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.ChangeProjectAssign(issue, ctx.User, p.ID); err != nil {
+		if models.IsErrProjectNotExist(err) {
+			ctx.Error(422, "", "Issue cannot be added to the project")
+		} else {
+			ctx.Error(500, "ChangeProjectAssign", err)
+		}
+		return
+	}
+
+	position := -1
+	if form.Position != nil {
+		position = *form.Position
+	}
+	if err := models.MoveIssueOnProjectBoard(board, issue.ID, position); err != nil {
+		ctx.Error(500, "MoveIssueOnProjectBoard", err)
+		return
+	}
+	ctx.Status(204)
+}
+
+// RemoveProjectIssue remove an issue from a project
+func RemoveProjectIssue(ctx *context.APIContext) {
+	// swagger:operation DELETE /projects/{id}/issues/{issue_id} issue issueRemoveProjectIssue
+	// ---
+	// summary: Remove an issue from a project
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the project
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: issue_id
+	//   in: path
+	//   description: id of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	p := ctx.Data["Project"].(*models.Project)
+	issue := getIssueForProject(ctx, ctx.ParamsInt64(":issueid"))
+	if ctx.Written() {
+		return
+	}
+
+	if err := issue.LoadProject(); err != nil {
+		ctx.Error(500, "LoadProject", err)
+		return
+	}
+	if issue.Project == nil || issue.Project.ID != p.ID {
+		ctx.Status(204)
+		return
+	}
+	if err := models.ChangeProjectAssign(issue, ctx.User, 0); err != nil {
+		ctx.Error(500, "ChangeProjectAssign", err)
+		return
+	}
+	ctx.Status(204)
+}
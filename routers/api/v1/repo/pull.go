@@ -384,7 +384,7 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 
 	if ctx.Repo.CanWrite(models.UnitTypePullRequests) && (form.Assignees != nil || len(form.Assignee) > 0) {
 
-		err = models.UpdateAPIAssignee(issue, form.Assignee, form.Assignees, ctx.User)
+		err = updateIssueAssignees(ctx.User, issue, form.Assignee, form.Assignees)
 		if err != nil {
 			if models.IsErrUserNotExist(err) {
 				ctx.Error(422, "", fmt.Sprintf("Assignee does not exist: [name: %s]", err))
@@ -403,6 +403,7 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 			ctx.Error(500, "ChangeMilestoneAssign", err)
 			return
 		}
+		notification.NotifyIssueChangeMilestone(ctx.User, issue, oldMilestoneID)
 	}
 
 	if ctx.Repo.CanWrite(models.UnitTypePullRequests) && form.Labels != nil {
@@ -411,7 +412,7 @@ func EditPullRequest(ctx *context.APIContext, form api.EditPullRequestOption) {
 			ctx.Error(500, "GetLabelsInRepoByIDsError", err)
 			return
 		}
-		if err = issue.ReplaceLabels(labels, ctx.User); err != nil {
+		if err = replaceIssueLabels(ctx.User, issue, labels); err != nil {
 			ctx.Error(500, "ReplaceLabelsError", err)
 			return
 		}
@@ -559,6 +560,15 @@ func MergePullRequest(ctx *context.APIContext, form auth.MergePullRequestForm) {
 		return
 	}
 
+	if err = pr.CheckUserAllowedToMerge(ctx.User); err != nil {
+		if !models.IsErrNotAllowedToMerge(err) {
+			ctx.Error(500, "CheckUserAllowedToMerge", err)
+			return
+		}
+		ctx.Error(405, "CheckUserAllowedToMerge", err)
+		return
+	}
+
 	if len(form.Do) == 0 {
 		form.Do = string(models.MergeStyleMerge)
 	}
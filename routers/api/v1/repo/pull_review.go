@@ -0,0 +1,761 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// ListPullReviews lists all reviews of a pull request
+func ListPullReviews(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/reviews repository repoListPullReviews
+	// ---
+	// summary: List all reviews for a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	reviews, err := models.FindReviews(models.FindReviewOptions{
+		Type:    models.ReviewTypeUnknown,
+		IssueID: pr.IssueID,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindReviews", err)
+		return
+	}
+
+	apiReviews := make([]*api.PullReview, 0, len(reviews))
+	for _, review := range reviews {
+		if !canSeeReview(ctx, review) {
+			continue
+		}
+		review.Issue = pr.Issue
+		if err = review.LoadAttributes(); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiReviews = append(apiReviews, convert.ToPullReview(review))
+	}
+	ctx.JSON(http.StatusOK, apiReviews)
+}
+
+// GetPullReview gets a specific review of a pull request
+func GetPullReview(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/reviews/{id} repository repoGetPullReview
+	// ---
+	// summary: Get a specific review for a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReview"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	review, _ := getReviewForPullRequest(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPullReview(review))
+}
+
+// GetPullReviewComments lists the code comments of a pull request review
+func GetPullReviewComments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/comments repository repoGetPullReviewComments
+	// ---
+	// summary: Get the comments of a pull request review
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReviewCommentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	review, _ := getReviewForPullRequest(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	comments, err := models.FindComments(models.FindCommentsOptions{
+		IssueID:  review.IssueID,
+		ReviewID: review.ID,
+		Type:     models.CommentTypeCode,
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindComments", err)
+		return
+	}
+
+	apiComments := make([]*api.PullReviewComment, len(comments))
+	for i, comment := range comments {
+		comment.Issue = review.Issue
+		comment.Review = review
+		apiComments[i] = convert.ToPullReviewComment(comment)
+	}
+	ctx.JSON(http.StatusOK, apiComments)
+}
+
+// CreatePullReview creates a review with its code comments on a pull request
+func CreatePullReview(ctx *context.APIContext, opts api.CreatePullReviewOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews repository repoCreatePullReview
+	// ---
+	// summary: Create a review for a pull request
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreatePullReviewOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PullReview"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+	if pr.Issue.IsLocked && !ctx.Repo.CanWriteIssuesOrPulls(true) {
+		ctx.Error(http.StatusForbidden, "", "the pull request is locked")
+		return
+	}
+
+	reviewType := models.ReviewTypePending
+	if len(opts.Event) > 0 {
+		reviewType = toReviewType(opts.Event)
+	}
+	if !checkReviewType(ctx, pr, reviewType, true) {
+		return
+	}
+	if !checkReviewComments(ctx, pr, opts.Comments) {
+		return
+	}
+
+	review, err := models.GetCurrentReview(ctx.User, pr.Issue)
+	if err != nil && !models.IsErrReviewNotExist(err) {
+		ctx.Error(http.StatusInternalServerError, "GetCurrentReview", err)
+		return
+	}
+	if reviewType != models.ReviewTypePending && len(opts.Body) == 0 && len(opts.Comments) == 0 &&
+		(review == nil || review.GetCodeCommentsCount() == 0) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "a review needs a body or code comments")
+		return
+	}
+
+	if review == nil && (reviewType == models.ReviewTypePending || len(opts.Comments) > 0) {
+		if review, err = models.CreateReview(models.CreateReviewOptions{
+			Type:     models.ReviewTypePending,
+			Issue:    pr.Issue,
+			Reviewer: ctx.User,
+			Content:  opts.Body,
+		}); err != nil {
+			ctx.Error(http.StatusInternalServerError, "CreateReview", err)
+			return
+		}
+	}
+	if len(opts.Comments) > 0 {
+		comments := make([]models.CodeCommentOptions, len(opts.Comments))
+		for i, c := range opts.Comments {
+			comments[i] = models.CodeCommentOptions{
+				TreePath: c.Path,
+				Line:     c.NewLineNum,
+				Content:  c.Body,
+			}
+			if c.OldLineNum > 0 {
+				comments[i].Line = -c.OldLineNum
+			}
+		}
+		if _, err = models.CreateCodeComments(ctx.User, ctx.Repo.Repository, pr.Issue, review.ID, comments); err != nil {
+			ctx.Error(http.StatusInternalServerError, "CreateCodeComments", err)
+			return
+		}
+	}
+
+	if reviewType == models.ReviewTypePending {
+		if len(opts.Body) > 0 && review.Content != opts.Body {
+			review.Content = opts.Body
+			if err = models.UpdateReview(review); err != nil {
+				ctx.Error(http.StatusInternalServerError, "UpdateReview", err)
+				return
+			}
+		}
+		ctx.JSON(http.StatusCreated, convert.ToPullReview(review))
+		return
+	}
+
+	review = submitReview(ctx, pr, reviewType, opts.Body)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToPullReview(review))
+}
+
+// SubmitPullReview submits the pending review of the user on a pull request
+func SubmitPullReview(ctx *context.APIContext, opts api.SubmitPullReviewOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews/{id} repository repoSubmitPullReview
+	// ---
+	// summary: Submit a pending review for a pull request
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/SubmitPullReviewOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReview"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	review, pr := getReviewForPullRequest(ctx)
+	if ctx.Written() {
+		return
+	}
+	if review.Type != models.ReviewTypePending {
+		ctx.Error(http.StatusUnprocessableEntity, "", "only a pending review can be submitted")
+		return
+	}
+	if pr.Issue.IsLocked && !ctx.Repo.CanWriteIssuesOrPulls(true) {
+		ctx.Error(http.StatusForbidden, "", "the pull request is locked")
+		return
+	}
+
+	reviewType := toReviewType(opts.Event)
+	if !checkReviewType(ctx, pr, reviewType, false) {
+		return
+	}
+	body := opts.Body
+	if len(body) == 0 {
+		body = review.Content
+	}
+	if len(body) == 0 && review.GetCodeCommentsCount() == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "a review needs a body or code comments")
+		return
+	}
+
+	review = submitReview(ctx, pr, reviewType, body)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPullReview(review))
+}
+
+// DismissPullReview dismisses an approval or a rejection of a pull request
+func DismissPullReview(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/reviews/{id}/dismissals repository repoDismissPullReview
+	// ---
+	// summary: Dismiss a review for a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the review
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullReview"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	review, _ := getReviewForPullRequest(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := models.DismissReview(review); err != nil {
+		if models.IsErrReviewNotDismissable(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DismissReview", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPullReview(review))
+}
+
+// CreateReviewRequests requests reviews of a pull request from users
+func CreateReviewRequests(ctx *context.APIContext, opts api.PullReviewRequestOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/requested_reviewers repository repoCreatePullReviewRequests
+	// ---
+	// summary: Create review requests for a pull request
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PullReviewRequestOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PullReviewList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+	reviewers := getReviewers(ctx, pr, opts.Reviewers)
+	if ctx.Written() {
+		return
+	}
+
+	apiReviews := make([]*api.PullReview, len(reviewers))
+	for i, reviewer := range reviewers {
+		review, err := models.AddReviewRequest(pr.Issue, reviewer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "AddReviewRequest", err)
+			return
+		}
+		notification.NotifyPullRequestReviewRequest(ctx.User, pr.Issue, reviewer, true)
+		apiReviews[i] = convert.ToPullReview(review)
+	}
+	ctx.JSON(http.StatusCreated, apiReviews)
+}
+
+// DeleteReviewRequests withdraws the reviews of a pull request requested from users
+func DeleteReviewRequests(ctx *context.APIContext, opts api.PullReviewRequestOptions) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/requested_reviewers repository repoDeletePullReviewRequests
+	// ---
+	// summary: Cancel review requests for a pull request
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/PullReviewRequestOptions"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return
+	}
+	reviewers := getReviewers(ctx, pr, opts.Reviewers)
+	if ctx.Written() {
+		return
+	}
+
+	for _, reviewer := range reviewers {
+		if err := models.RemoveReviewRequest(pr.Issue, reviewer); err != nil {
+			ctx.Error(http.StatusInternalServerError, "RemoveReviewRequest", err)
+			return
+		}
+		notification.NotifyPullRequestReviewRequest(ctx.User, pr.Issue, reviewer, false)
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// getPullRequestForReview returns the pull request of the index in the path
+// with its issue loaded
+func getPullRequestForReview(ctx *context.APIContext) *models.PullRequest {
+	pr, err := models.GetPullRequestByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.Status(http.StatusNotFound)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return nil
+	}
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return nil
+	}
+	pr.Issue.Repo = ctx.Repo.Repository
+	return pr
+}
+
+// getReviewForPullRequest returns the review of the id in the path, if it
+// belongs to the pull request and can be seen by the user
+func getReviewForPullRequest(ctx *context.APIContext) (*models.Review, *models.PullRequest) {
+	pr := getPullRequestForReview(ctx)
+	if ctx.Written() {
+		return nil, nil
+	}
+
+	review, err := models.GetReviewByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrReviewNotExist(err) {
+			ctx.Status(http.StatusNotFound)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetReviewByID", err)
+		}
+		return nil, nil
+	}
+	if review.IssueID != pr.IssueID || !canSeeReview(ctx, review) {
+		ctx.Status(http.StatusNotFound)
+		return nil, nil
+	}
+
+	review.Issue = pr.Issue
+	if err = review.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil, nil
+	}
+	return review, pr
+}
+
+// canSeeReview returns whether the review can be seen by the user, pending
+// reviews can only be seen by their reviewer
+func canSeeReview(ctx *context.APIContext, review *models.Review) bool {
+	return review.Type != models.ReviewTypePending || (ctx.IsSigned && ctx.User.ID == review.ReviewerID)
+}
+
+func toReviewType(state api.ReviewStateType) models.ReviewType {
+	switch state {
+	case api.ReviewStatePending:
+		return models.ReviewTypePending
+	case api.ReviewStateApproved:
+		return models.ReviewTypeApprove
+	case api.ReviewStateComment:
+		return models.ReviewTypeComment
+	case api.ReviewStateRequestChanges:
+		return models.ReviewTypeReject
+	default:
+		return models.ReviewTypeUnknown
+	}
+}
+
+// checkReviewType checks that the user can give a review of the type, which
+// may only be pending if allowPending is set
+func checkReviewType(ctx *context.APIContext, pr *models.PullRequest, reviewType models.ReviewType, allowPending bool) bool {
+	switch reviewType {
+	case models.ReviewTypeUnknown:
+		ctx.Error(http.StatusUnprocessableEntity, "", "unknown review event")
+		return false
+	case models.ReviewTypePending:
+		if !allowPending {
+			ctx.Error(http.StatusUnprocessableEntity, "", "a review cannot be submitted as pending")
+			return false
+		}
+	case models.ReviewTypeApprove, models.ReviewTypeReject:
+		if pr.Issue.PosterID == ctx.User.ID {
+			ctx.Error(http.StatusUnprocessableEntity, "", "the poster cannot approve or request changes on their own pull request")
+			return false
+		}
+	}
+	return true
+}
+
+// checkReviewComments checks that the lines commented are part of the diff of
+// the pull request, between its merge base and its head
+func checkReviewComments(ctx *context.APIContext, pr *models.PullRequest, comments []api.CreatePullReviewComment) bool {
+	if len(comments) == 0 {
+		return true
+	}
+
+	headCommitID, err := ctx.Repo.GitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRefCommitID", err)
+		return false
+	}
+	// the merge base is only stored once the patch of the pull request is
+	// tested, it is computed from the branches until then
+	mergeBase := pr.MergeBase
+	if len(mergeBase) == 0 {
+		if mergeBase, err = ctx.Repo.GitRepo.GetMergeBase(pr.BaseBranch, pr.GetGitRefName()); err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("the merge base of the pull request is unknown: %v", err))
+			return false
+		}
+	}
+	diff, err := models.GetDiffRange(ctx.Repo.Repository.RepoPath(), mergeBase, headCommitID,
+		setting.Git.MaxGitDiffLines, setting.Git.MaxGitDiffLineCharacters, setting.Git.MaxGitDiffFiles)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDiffRange", err)
+		return false
+	}
+
+	for _, c := range comments {
+		if len(c.Path) == 0 || len(c.Body) == 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "a review comment needs a path and a body")
+			return false
+		}
+		if c.OldLineNum < 0 || c.NewLineNum < 0 || (c.OldLineNum == 0) == (c.NewLineNum == 0) {
+			ctx.Error(http.StatusUnprocessableEntity, "", "exactly one of old_position and new_position must be given")
+			return false
+		}
+
+		side, line := "new", c.NewLineNum
+		if c.OldLineNum > 0 {
+			side, line = "old", -c.OldLineNum
+		}
+		if !diffHasLine(diff, c.Path, line) {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("%s line %d of %s is not part of the diff", side, c.NewLineNum+c.OldLineNum, c.Path))
+			return false
+		}
+	}
+	return true
+}
+
+// diffHasLine returns whether the line of the file at treePath is shown in
+// the diff, negative lines being those of the previous version of the file
+func diffHasLine(diff *models.Diff, treePath string, line int64) bool {
+	for _, file := range diff.Files {
+		if file.Name != treePath {
+			continue
+		}
+		for _, section := range file.Sections {
+			for _, l := range section.Lines {
+				switch l.Type {
+				case models.DiffLineAdd:
+					if int64(l.RightIdx) == line {
+						return true
+					}
+				case models.DiffLineDel:
+					if int64(-l.LeftIdx) == line {
+						return true
+					}
+				case models.DiffLinePlain:
+					if int64(l.RightIdx) == line || int64(-l.LeftIdx) == line {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// getReviewers returns the users of the names who can be requested to review
+// the pull request
+func getReviewers(ctx *context.APIContext, pr *models.PullRequest, names []string) []*models.User {
+	reviewers := make([]*models.User, 0, len(names))
+	for _, name := range names {
+		reviewer, err := models.GetUserByName(name)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("reviewer does not exist [name: %s]", name))
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return nil
+		}
+		if reviewer.ID == pr.Issue.PosterID {
+			ctx.Error(http.StatusUnprocessableEntity, "", "a review cannot be requested from the poster of the pull request")
+			return nil
+		}
+		perm, err := models.GetUserRepoPermission(ctx.Repo.Repository, reviewer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+			return nil
+		}
+		if !perm.CanRead(models.UnitTypePullRequests) {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("reviewer cannot read the pull requests of the repository [name: %s]", name))
+			return nil
+		}
+		reviewers = append(reviewers, reviewer)
+	}
+	return reviewers
+}
+
+// submitReview submits the pending review of the user, or a new review, and
+// notifies it
+func submitReview(ctx *context.APIContext, pr *models.PullRequest, reviewType models.ReviewType, body string) *models.Review {
+	review, comm, err := models.SubmitReview(ctx.User, pr.Issue, reviewType, body)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SubmitReview", err)
+		return nil
+	}
+	notification.NotifyPullRequestReview(pr, review, comm)
+	return review
+}
@@ -0,0 +1,243 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	"code.gitea.io/gitea/routers/utils"
+
+	api "code.gitea.io/sdk/gitea"
+)
+
+// ListPushMirrors list all the push mirrors of a repository
+func ListPushMirrors(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors repository repoListPushMirrors
+	// ---
+	// summary: List a repository's push mirrors
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirrorList"
+	mirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(500, "GetPushMirrorsByRepoID", err)
+		return
+	}
+
+	apiMirrors := make([]*api.PushMirror, len(mirrors))
+	for i := range mirrors {
+		apiMirrors[i] = convert.ToPushMirror(mirrors[i])
+	}
+	ctx.JSON(200, &apiMirrors)
+}
+
+// GetPushMirror get a push mirror by id
+func GetPushMirror(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/push_mirrors/{id} repository repoGetPushMirror
+	// ---
+	// summary: Get a repository's push mirror by id
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror to get
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PushMirror"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	m, err := models.GetPushMirrorByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPushMirrorNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetPushMirrorByID", err)
+		}
+		return
+	}
+
+	ctx.JSON(200, convert.ToPushMirror(m))
+}
+
+// CreatePushMirror create a push mirror for a repository
+func CreatePushMirror(ctx *context.APIContext, form api.CreatePushMirrorOption) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors repository repoCreatePushMirror
+	// ---
+	// summary: Add a push mirror to a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreatePushMirrorOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PushMirror"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	var interval time.Duration
+	if len(form.Interval) > 0 {
+		var err error
+		interval, err = time.ParseDuration(form.Interval)
+		if err != nil || (interval != 0 && interval < setting.Mirror.MinInterval) {
+			ctx.Error(422, "", "Invalid interval")
+			return
+		}
+	} else {
+		interval = setting.Mirror.DefaultInterval
+	}
+
+	address, err := utils.ComposeRemoteAddress(form.RemoteAddress, form.RemoteUsername, form.RemotePassword)
+	if err != nil {
+		ctx.Error(422, "", err)
+		return
+	}
+
+	m, err := models.CreatePushMirror(ctx.Repo.Repository, address, interval)
+	if err != nil {
+		ctx.Error(500, "CreatePushMirror", err)
+		return
+	}
+	log.Trace("Push mirror added: %s -> %s", ctx.Repo.Repository.FullName(), m.Address())
+
+	go models.PushMirrorQueue.Add(m.ID)
+	ctx.JSON(201, convert.ToPushMirror(m))
+}
+
+// DeletePushMirror delete a push mirror
+func DeletePushMirror(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/push_mirrors/{id} repository repoDeletePushMirror
+	// ---
+	// summary: Delete a push mirror from a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror to delete
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	m, err := models.GetPushMirrorByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPushMirrorNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetPushMirrorByID", err)
+		}
+		return
+	}
+
+	if err = models.DeletePushMirror(m); err != nil {
+		ctx.Error(500, "DeletePushMirror", err)
+		return
+	}
+	log.Trace("Push mirror removed: %s -> %s", ctx.Repo.Repository.FullName(), m.Address())
+
+	ctx.Status(204)
+}
+
+// SyncPushMirror queue a push mirror for synchronization
+func SyncPushMirror(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/push_mirrors/{id}/sync repository repoSyncPushMirror
+	// ---
+	// summary: Push the repository to a push mirror
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the push mirror to synchronize
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	m, err := models.GetPushMirrorByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPushMirrorNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetPushMirrorByID", err)
+		}
+		return
+	}
+
+	go models.PushMirrorQueue.Add(m.ID)
+	ctx.Status(204)
+}
@@ -7,6 +7,7 @@ package repo
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
 
 	api "code.gitea.io/sdk/gitea"
 )
@@ -149,6 +150,7 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 			}
 			return
 		}
+		notification.NotifyNewRelease(rel)
 	} else {
 		if !rel.IsTag {
 			ctx.Status(409)
@@ -168,6 +170,7 @@ func CreateRelease(ctx *context.APIContext, form api.CreateReleaseOption) {
 			ctx.ServerError("UpdateRelease", err)
 			return
 		}
+		notification.NotifyNewRelease(rel)
 	}
 	ctx.JSON(201, rel.APIFormat())
 }
@@ -229,6 +232,7 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 	if len(form.Note) > 0 {
 		rel.Note = form.Note
 	}
+	wasDraft := rel.IsDraft
 	if form.IsDraft != nil {
 		rel.IsDraft = *form.IsDraft
 	}
@@ -239,6 +243,9 @@ func EditRelease(ctx *context.APIContext, form api.EditReleaseOption) {
 		ctx.Error(500, "UpdateRelease", err)
 		return
 	}
+	if wasDraft {
+		notification.NotifyNewRelease(rel)
+	}
 
 	rel, err = models.GetReleaseByID(id)
 	if err != nil {
@@ -72,6 +72,10 @@ func Search(ctx *context.APIContext) {
 	//   in: query
 	//   description: if `uid` is given, search only for repos that the user owns
 	//   type: boolean
+	// - name: archived
+	//   in: query
+	//   description: if given, search only for archived (true) or non-archived (false) repos
+	//   type: boolean
 	// - name: sort
 	//   in: query
 	//   description: sort repos by attribute. Supported values are
@@ -101,6 +105,10 @@ func Search(ctx *context.APIContext) {
 		opts.Collaborate = util.OptionalBoolFalse
 	}
 
+	if len(ctx.Query("archived")) > 0 {
+		opts.Archived = util.OptionalBoolOf(ctx.QueryBool("archived"))
+	}
+
 	var mode = ctx.Query("mode")
 	switch mode {
 	case "source":
@@ -315,6 +323,105 @@ func CreateOrgRepo(ctx *context.APIContext, opt api.CreateRepoOption) {
 	CreateUserRepo(ctx, org, opt)
 }
 
+// Generate create a repository using a template
+func Generate(ctx *context.APIContext, opt api.GenerateRepoOption) {
+	// swagger:operation POST /repos/{template_owner}/{template_repo}/generate repository generateRepo
+	// ---
+	// summary: Create a repository using a template
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: template_owner
+	//   in: path
+	//   description: name of the template repository owner
+	//   type: string
+	//   required: true
+	// - name: template_repo
+	//   in: path
+	//   description: name of the template repository
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/GenerateRepoOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	if !ctx.Repo.Repository.IsTemplate {
+		ctx.Error(422, "", "this is not a template repo")
+		return
+	}
+
+	opts := models.GenerateRepoOptions{
+		Name:        opt.Name,
+		Description: opt.Description,
+		Private:     opt.Private || setting.Repository.ForcePrivate,
+		GitContent:  opt.GitContent,
+		Topics:      opt.Topics,
+		Webhooks:    opt.Webhooks,
+		IssueLabels: opt.Labels,
+	}
+	if !opts.IsValid() {
+		ctx.Error(422, "", "must select at least one template item")
+		return
+	}
+
+	ctxUser := ctx.User
+	if opt.Owner != ctxUser.Name {
+		var err error
+		ctxUser, err = models.GetUserByName(opt.Owner)
+		if err != nil {
+			if models.IsErrUserNotExist(err) {
+				ctx.Error(422, "", err)
+			} else {
+				ctx.Error(500, "GetUserByName", err)
+			}
+			return
+		}
+
+		if !ctx.User.IsAdmin {
+			if !ctxUser.IsOrganization() {
+				ctx.Error(403, "", "Given user is not an organization.")
+				return
+			}
+			isOwner, err := ctxUser.IsOwnedBy(ctx.User.ID)
+			if err != nil {
+				ctx.Error(500, "IsOwnedBy", err)
+				return
+			} else if !isOwner {
+				ctx.Error(403, "", "Given user is not owner of organization.")
+				return
+			}
+		}
+	}
+
+	repo, err := models.GenerateRepository(ctx.User, ctxUser, ctx.Repo.Repository, opts)
+	if err != nil {
+		if models.IsErrRepoAlreadyExist(err) ||
+			models.IsErrNameReserved(err) ||
+			models.IsErrNamePatternNotAllowed(err) {
+			ctx.Error(422, "", err)
+		} else if models.IsErrReachLimitOfRepo(err) {
+			ctx.Error(403, "", err)
+		} else {
+			ctx.Error(500, "GenerateRepository", err)
+		}
+		return
+	}
+	log.Trace("Repository generated [%d]: %s/%s", repo.ID, ctxUser.Name, repo.Name)
+
+	ctx.JSON(201, repo.APIFormat(models.AccessModeOwner))
+}
+
 // Migrate migrate remote git repository to gitea
 func Migrate(ctx *context.APIContext, form auth.MigrateRepoForm) {
 	// swagger:operation POST /repos/migrate repository repoMigrate
@@ -469,13 +576,72 @@ func GetByID(ctx *context.APIContext) {
 	if err != nil {
 		ctx.Error(500, "AccessLevel", err)
 		return
-	} else if !perm.HasAccess() {
+	}
+	if t := ctx.AccessToken(); t != nil {
+		perm = t.LimitRepoPermission(repo, perm)
+	}
+	if !perm.HasAccess() {
 		ctx.Status(404)
 		return
 	}
 	ctx.JSON(200, repo.APIFormat(perm.AccessMode))
 }
 
+// Edit edit the properties of a repository
+func Edit(ctx *context.APIContext, opts api.EditRepoOption) {
+	// swagger:operation PATCH /repos/{owner}/{repo} repository repoEdit
+	// ---
+	// summary: Edit the properties of a repository. Only fields that are set will be changed.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to edit
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to edit
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditRepoOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	repo := ctx.Repo.Repository
+
+	if opts.Archived != nil && *opts.Archived != repo.IsArchived {
+		if repo.IsMirror {
+			ctx.Error(422, "", "Mirror repositories cannot be archived")
+			return
+		}
+		if err := repo.SetArchiveRepoState(*opts.Archived); err != nil {
+			ctx.Error(500, "SetArchiveRepoState", err)
+			return
+		}
+		log.Trace("Repository archived state changed: %s/%s -> %v", ctx.Repo.Owner.Name, repo.Name, repo.IsArchived)
+	}
+
+	if opts.Template != nil && *opts.Template != repo.IsTemplate {
+		repo.IsTemplate = *opts.Template
+		if err := models.UpdateRepository(repo, false); err != nil {
+			ctx.Error(500, "UpdateRepository", err)
+			return
+		}
+		log.Trace("Repository template state changed: %s/%s -> %v", ctx.Repo.Owner.Name, repo.Name, repo.IsTemplate)
+	}
+
+	ctx.JSON(200, repo.APIFormat(ctx.Repo.AccessMode))
+}
+
 // Delete one repository
 func Delete(ctx *context.APIContext) {
 	// swagger:operation DELETE /repos/{owner}/{repo} repository repoDelete
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
 
 	api "code.gitea.io/sdk/gitea"
 )
@@ -61,6 +62,7 @@ func NewCommitStatus(ctx *context.APIContext, form api.CreateStatusOption) {
 		ctx.Error(500, "NewCommitStatus", err)
 		return
 	}
+	notification.NotifyCreateCommitStatus(ctx.User, ctx.Repo.Repository, status)
 
 	newStatus, err := models.GetCommitStatus(ctx.Repo.Repository, sha, status)
 	if err != nil {
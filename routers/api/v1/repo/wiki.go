@@ -0,0 +1,501 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/git"
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/routers/api/v1/convert"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// ListWikiPages lists the pages of the wiki of a repository
+func ListWikiPages(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/wiki/pages repository repoListWikiPages
+	// ---
+	// summary: List the pages of the wiki of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The branch, tag or commit of the wiki to list the pages at. Default: the latest revision"
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiPageList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.JSON(http.StatusOK, []*api.WikiPageMetaData{})
+		return
+	}
+
+	_, commit := findWikiRepoCommit(ctx, ctx.QueryTrim("ref"))
+	if ctx.Written() {
+		return
+	}
+
+	entries, err := commit.ListEntries()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListEntries", err)
+		return
+	}
+	pages := make([]*api.WikiPageMetaData, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type != git.ObjectBlob {
+			continue
+		}
+		wikiName, err := models.WikiFilenameToName(entry.Name())
+		if err != nil {
+			if models.IsErrWikiInvalidFileName(err) {
+				continue
+			}
+			ctx.Error(http.StatusInternalServerError, "WikiFilenameToName", err)
+			return
+		}
+		lastCommit, err := commit.GetCommitByPath(entry.Name())
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetCommitByPath", err)
+			return
+		}
+		pages = append(pages, toWikiPageMetaData(ctx.Repo.Repository, wikiName, lastCommit))
+	}
+	ctx.JSON(http.StatusOK, pages)
+}
+
+// GetWikiPage gets a wiki page with its raw and rendered content
+func GetWikiPage(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/wiki/pages/{pageName} repository repoGetWikiPage
+	// ---
+	// summary: Get a wiki page with its raw and rendered content
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page, as in its sub_url
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: query
+	//   description: "The branch, tag or commit of the wiki to get the page at. Default: the latest revision"
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiPage"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	wikiRepo, commit := findWikiRepoCommit(ctx, ctx.QueryTrim("ref"))
+	if ctx.Written() {
+		return
+	}
+
+	page := getWikiPage(ctx, wikiRepo, commit, wikiPageName(ctx))
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// ListWikiPageRevisions lists the commits which changed a wiki page
+func ListWikiPageRevisions(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/wiki/revisions/{pageName} repository repoListWikiPageRevisions
+	// ---
+	// summary: List the commits which changed a wiki page, the most recent first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page, as in its sub_url
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of requested commits
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results, maximum page size is 50
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiCommitList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	wikiRepo, commit := findWikiRepoCommit(ctx, "")
+	if ctx.Written() {
+		return
+	}
+
+	wikiName := wikiPageName(ctx)
+	if findWikiPageEntry(ctx, commit, wikiName); ctx.Written() {
+		return
+	}
+
+	opts := models.SearchCommitsOptions{
+		Revision: commit.ID.String(),
+		Path:     models.WikiNameToFilename(wikiName),
+		Page:     ctx.QueryInt("page"),
+		PageSize: convert.ToCorrectPageSize(ctx.QueryInt("limit")),
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	commits, count, err := models.SearchRepoCommits(wikiRepo, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SearchRepoCommits", err)
+		return
+	}
+
+	apiCommits := make([]*api.WikiCommit, len(commits))
+	for i, c := range commits {
+		apiCommits[i] = convert.ToWikiCommit(c)
+	}
+
+	ctx.SetLinkHeader(int(count), opts.PageSize)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, apiCommits)
+}
+
+// CreateWikiPage creates a wiki page
+func CreateWikiPage(ctx *context.APIContext, form api.CreateWikiPageOptions) {
+	// swagger:operation POST /repos/{owner}/{repo}/wiki/pages repository repoCreateWikiPage
+	// ---
+	// summary: Create a wiki page
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateWikiPageOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/WikiPage"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	wikiName := models.NormalizeWikiName(strings.TrimSpace(form.Title))
+	if len(wikiName) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "title cannot be empty")
+		return
+	}
+
+	if err := ctx.Repo.Repository.AddWikiPage(ctx.User, wikiName, form.Content, form.Message); err != nil {
+		if models.IsErrWikiReservedName(err) || models.IsErrWikiAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "AddWikiPage", err)
+		}
+		return
+	}
+	notification.NotifyNewWikiPage(ctx.User, ctx.Repo.Repository, wikiName, form.Message)
+
+	wikiRepo, commit := findWikiRepoCommit(ctx, "")
+	if ctx.Written() {
+		return
+	}
+	page := getWikiPage(ctx, wikiRepo, commit, wikiName)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusCreated, page)
+}
+
+// EditWikiPage edits or renames a wiki page
+func EditWikiPage(ctx *context.APIContext, form api.EditWikiPageOptions) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/wiki/pages/{pageName} repository repoEditWikiPage
+	// ---
+	// summary: Edit or rename a wiki page. Only fields that are set will be changed
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page, as in its sub_url
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditWikiPageOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/WikiPage"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	_, commit := findWikiRepoCommit(ctx, "")
+	if ctx.Written() {
+		return
+	}
+
+	oldWikiName := wikiPageName(ctx)
+	entry := findWikiPageEntry(ctx, commit, oldWikiName)
+	if ctx.Written() {
+		return
+	}
+
+	newWikiName := oldWikiName
+	if form.Title != nil {
+		if newWikiName = models.NormalizeWikiName(strings.TrimSpace(*form.Title)); len(newWikiName) == 0 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "title cannot be empty")
+			return
+		}
+	}
+
+	var content string
+	if form.Content != nil {
+		content = *form.Content
+	} else {
+		data, err := readWikiPageEntry(entry)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "readWikiPageEntry", err)
+			return
+		}
+		content = string(data)
+	}
+
+	if err := ctx.Repo.Repository.EditWikiPage(ctx.User, oldWikiName, newWikiName, content, form.Message); err != nil {
+		if models.IsErrWikiReservedName(err) || models.IsErrWikiAlreadyExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "EditWikiPage", err)
+		}
+		return
+	}
+	notification.NotifyEditWikiPage(ctx.User, ctx.Repo.Repository, newWikiName, form.Message)
+
+	wikiRepo, commit := findWikiRepoCommit(ctx, "")
+	if ctx.Written() {
+		return
+	}
+	page := getWikiPage(ctx, wikiRepo, commit, newWikiName)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, page)
+}
+
+// DeleteWikiPage deletes a wiki page
+func DeleteWikiPage(ctx *context.APIContext, form api.DeleteWikiPageOptions) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/wiki/pages/{pageName} repository repoDeleteWikiPage
+	// ---
+	// summary: Delete a wiki page
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: pageName
+	//   in: path
+	//   description: name of the page, as in its sub_url
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/DeleteWikiPageOptions"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	_, commit := findWikiRepoCommit(ctx, "")
+	if ctx.Written() {
+		return
+	}
+
+	wikiName := wikiPageName(ctx)
+	if findWikiPageEntry(ctx, commit, wikiName); ctx.Written() {
+		return
+	}
+
+	if err := ctx.Repo.Repository.DeleteWikiPage(ctx.User, wikiName, form.Message); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteWikiPage", err)
+		return
+	}
+	notification.NotifyDeleteWikiPage(ctx.User, ctx.Repo.Repository, wikiName)
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// wikiPageName returns the name of the wiki page given in the path
+func wikiPageName(ctx *context.APIContext) string {
+	return models.NormalizeWikiName(ctx.Params("*"))
+}
+
+// findWikiRepoCommit opens the wiki repository and returns the commit of the
+// ref, or of master if ref is empty. Writes a not found error if the wiki or
+// the ref does not exist.
+func findWikiRepoCommit(ctx *context.APIContext, ref string) (*git.Repository, *git.Commit) {
+	if !ctx.Repo.Repository.HasWiki() {
+		ctx.Status(http.StatusNotFound)
+		return nil, nil
+	}
+
+	wikiRepo, err := git.OpenRepository(ctx.Repo.Repository.WikiPath())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "OpenRepository", err)
+		return nil, nil
+	}
+
+	if len(ref) == 0 {
+		ref = "master"
+	}
+	commit, err := getCommitByRef(wikiRepo, ref)
+	if err != nil {
+		ctx.Error(http.StatusNotFound, "", fmt.Sprintf("ref does not exist [name: %s]", ref))
+		return nil, nil
+	}
+	return wikiRepo, commit
+}
+
+// findWikiPageEntry returns the tree entry of a wiki page at the commit.
+// Writes a not found error if the page does not exist.
+func findWikiPageEntry(ctx *context.APIContext, commit *git.Commit, wikiName string) *git.TreeEntry {
+	entry, err := commit.GetTreeEntryByPath(models.WikiNameToFilename(wikiName))
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Status(http.StatusNotFound)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetTreeEntryByPath", err)
+		}
+		return nil
+	} else if entry.Type != git.ObjectBlob {
+		ctx.Status(http.StatusNotFound)
+		return nil
+	}
+	return entry
+}
+
+// readWikiPageEntry returns the content of a wiki page
+func readWikiPageEntry(entry *git.TreeEntry) ([]byte, error) {
+	reader, err := entry.Blob().Data()
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(reader)
+}
+
+// getWikiPage returns a wiki page at the commit with its content. Writes a
+// not found error if the page does not exist.
+func getWikiPage(ctx *context.APIContext, wikiRepo *git.Repository, commit *git.Commit, wikiName string) *api.WikiPage {
+	entry := findWikiPageEntry(ctx, commit, wikiName)
+	if ctx.Written() {
+		return nil
+	}
+
+	data, err := readWikiPageEntry(entry)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "readWikiPageEntry", err)
+		return nil
+	}
+	lastCommit, err := commit.GetCommitByPath(entry.Name())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommitByPath", err)
+		return nil
+	}
+	commitCount, err := wikiRepo.FileCommitsCount(commit.ID.String(), entry.Name())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FileCommitsCount", err)
+		return nil
+	}
+
+	meta := toWikiPageMetaData(ctx.Repo.Repository, wikiName, lastCommit)
+	return &api.WikiPage{
+		Title:       meta.Title,
+		SubURL:      meta.SubURL,
+		HTMLURL:     meta.HTMLURL,
+		LastCommit:  meta.LastCommit,
+		CommitCount: commitCount,
+		Content:     string(data),
+		ContentHTML: markdown.RenderWiki(data, ctx.Repo.Repository.Link(), ctx.Repo.Repository.ComposeMetas()),
+	}
+}
+
+func toWikiPageMetaData(repo *models.Repository, wikiName string, lastCommit *git.Commit) *api.WikiPageMetaData {
+	subURL := models.WikiNameToSubURL(wikiName)
+	return &api.WikiPageMetaData{
+		Title:      wikiName,
+		SubURL:     subURL,
+		HTMLURL:    repo.HTMLURL() + "/wiki/" + subURL,
+		LastCommit: convert.ToWikiCommit(lastCommit),
+	}
+}
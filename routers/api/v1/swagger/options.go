@@ -78,6 +78,8 @@ type swaggerParameterBodies struct {
 	CreateRepoOption api.CreateRepoOption
 	// in:body
 	CreateForkOption api.CreateForkOption
+	// in:body
+	CreatePushMirrorOption api.CreatePushMirrorOption
 
 	// in:body
 	CreateStatusOption api.CreateStatusOption
@@ -98,6 +100,60 @@ type swaggerParameterBodies struct {
 	// in:body
 	MigrateRepoForm auth.MigrateRepoForm
 
+	// in:body
+	GenerateRepoOption api.GenerateRepoOption
+
+	// in:body
+	LockIssueOption api.LockIssueOption
+
+	// in:body
+	CreateProjectOption api.CreateProjectOption
+
+	// in:body
+	EditProjectOption api.EditProjectOption
+
+	// in:body
+	CreateProjectBoardOption api.CreateProjectBoardOption
+
+	// in:body
+	EditProjectBoardOption api.EditProjectBoardOption
+
+	// in:body
+	MoveProjectIssueOption api.MoveProjectIssueOption
+
 	// in:body
 	EditAttachmentOptions api.EditAttachmentOptions
+
+	// in:body
+	CreateFileOptions api.CreateFileOptions
+
+	// in:body
+	UpdateFileOptions api.UpdateFileOptions
+
+	// in:body
+	DeleteFileOptions api.DeleteFileOptions
+
+	// in:body
+	CreatePullReviewOptions api.CreatePullReviewOptions
+
+	// in:body
+	SubmitPullReviewOptions api.SubmitPullReviewOptions
+
+	// in:body
+	PullReviewRequestOptions api.PullReviewRequestOptions
+
+	// in:body
+	CreateBranchProtectionOption api.CreateBranchProtectionOption
+
+	// in:body
+	EditBranchProtectionOption api.EditBranchProtectionOption
+
+	// in:body
+	CreateWikiPageOptions api.CreateWikiPageOptions
+
+	// in:body
+	EditWikiPageOptions api.EditWikiPageOptions
+
+	// in:body
+	DeleteWikiPageOptions api.DeleteWikiPageOptions
 }
@@ -6,10 +6,14 @@
 package user
 
 import (
+	"time"
+
 	api "code.gitea.io/sdk/gitea"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/convert"
 )
 
 // ListAccessTokens list all the access tokens
@@ -36,11 +40,11 @@ func ListAccessTokens(ctx *context.APIContext) {
 
 	apiTokens := make([]*api.AccessToken, len(tokens))
 	for i := range tokens {
-		apiTokens[i] = &api.AccessToken{
-			ID:   tokens[i].ID,
-			Name: tokens[i].Name,
-			Sha1: tokens[i].Sha1,
+		if err = tokens[i].LoadRepos(); err != nil {
+			ctx.Error(500, "LoadRepos", err)
+			return
 		}
+		apiTokens[i] = convert.ToAccessToken(tokens[i])
 	}
 	ctx.JSON(200, &apiTokens)
 }
@@ -69,22 +73,62 @@ func CreateAccessToken(ctx *context.APIContext, form api.CreateAccessTokenOption
 	//     properties:
 	//       name:
 	//         type: string
+	//       scopes:
+	//         type: array
+	//         items:
+	//           type: string
+	//           enum: [all, repo:read, repo:write, admin:org, issue, package, user:email, notification, sudo]
+	//       repositories:
+	//         type: array
+	//         items:
+	//           type: string
+	//       expires_at:
+	//         type: string
+	//         format: date-time
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/AccessToken"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	scope, err := models.ParseAccessTokenScope(form.Scopes)
+	if err != nil {
+		ctx.Error(422, "", err)
+		return
+	}
+
+	repoIDs, err := models.GetAccessTokenRepoIDs(ctx.User, form.Repositories)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.Error(422, "", err)
+		} else {
+			ctx.Error(500, "GetAccessTokenRepoIDs", err)
+		}
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:     ctx.User.ID,
+		Name:    form.Name,
+		Scope:   scope,
+		RepoIDs: repoIDs,
+	}
+	if form.Expires != nil {
+		if !form.Expires.After(time.Now()) {
+			ctx.Error(422, "", "expiry date must be in the future")
+			return
+		}
+		t.ExpiresUnix = util.TimeStamp(form.Expires.Unix())
 	}
-	if err := models.NewAccessToken(t); err != nil {
+
+	if err = models.NewAccessToken(t); err != nil {
 		ctx.Error(500, "NewAccessToken", err)
 		return
 	}
-	ctx.JSON(201, &api.AccessToken{
-		Name: t.Name,
-		Sha1: t.Sha1,
-		ID:   t.ID,
-	})
+	if err = t.LoadRepos(); err != nil {
+		ctx.Error(500, "LoadRepos", err)
+		return
+	}
+	ctx.JSON(201, convert.ToAccessToken(t))
 }
 
 // DeleteAccessToken delete access tokens
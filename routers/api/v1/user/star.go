@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
 )
 
 // getStarredRepos returns the repos that the user with the specified userID has
@@ -119,10 +120,12 @@ func Star(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
-	err := models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
-	if err != nil {
-		ctx.Error(500, "StarRepo", err)
-		return
+	if models.IsStaring(ctx.User.ID, ctx.Repo.Repository.ID) != true {
+		if err := models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, true); err != nil {
+			ctx.Error(500, "StarRepo", err)
+			return
+		}
+		notification.NotifyStarRepository(ctx.User, ctx.Repo.Repository, true)
 	}
 	ctx.Status(204)
 }
@@ -146,10 +149,12 @@ func Unstar(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
-	err := models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
-	if err != nil {
-		ctx.Error(500, "StarRepo", err)
-		return
+	if models.IsStaring(ctx.User.ID, ctx.Repo.Repository.ID) != false {
+		if err := models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, false); err != nil {
+			ctx.Error(500, "StarRepo", err)
+			return
+		}
+		notification.NotifyStarRepository(ctx.User, ctx.Repo.Repository, false)
 	}
 	ctx.Status(204)
 }
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -126,10 +127,12 @@ func Watch(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/WatchInfo"
-	err := models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
-	if err != nil {
-		ctx.Error(500, "WatchRepo", err)
-		return
+	if !models.IsWatching(ctx.User.ID, ctx.Repo.Repository.ID) {
+		if err := models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true); err != nil {
+			ctx.Error(500, "WatchRepo", err)
+			return
+		}
+		notification.NotifyWatchRepository(ctx.User, ctx.Repo.Repository, true)
 	}
 	ctx.JSON(200, api.WatchInfo{
 		Subscribed:    true,
@@ -161,10 +164,12 @@ func Unwatch(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
-	err := models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
-	if err != nil {
-		ctx.Error(500, "UnwatchRepo", err)
-		return
+	if models.IsWatching(ctx.User.ID, ctx.Repo.Repository.ID) {
+		if err := models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, false); err != nil {
+			ctx.Error(500, "UnwatchRepo", err)
+			return
+		}
+		notification.NotifyWatchRepository(ctx.User, ctx.Repo.Repository, false)
 	}
 	ctx.Status(204)
 }
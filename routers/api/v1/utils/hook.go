@@ -6,11 +6,13 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/routers/api/v1/convert"
 	"code.gitea.io/gitea/routers/utils"
 	api "code.gitea.io/sdk/gitea"
@@ -33,6 +35,33 @@ func GetOrgHook(ctx *context.APIContext, orgID, hookID int64) (*models.Webhook,
 	return w, nil
 }
 
+// GetAdminHook get a system or default webhook. If there is an error, write to
+// `ctx` accordingly and return the error
+func GetAdminHook(ctx *context.APIContext, hookID int64) (*models.Webhook, error) {
+	w, err := models.GetSystemOrDefaultWebhook(hookID)
+	if err != nil {
+		if models.IsErrWebhookNotExist(err) {
+			ctx.Status(404)
+		} else {
+			ctx.Error(500, "GetSystemOrDefaultWebhook", err)
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+// ToAdminHook convert a system or default webhook to api.Hook, linking it to
+// its page in the site administration
+func ToAdminHook(w *models.Webhook) *api.Hook {
+	hook := convert.ToHook("", w)
+	kind := "default"
+	if w.IsSystemWebhook {
+		kind = "system"
+	}
+	hook.URL = fmt.Sprintf("%s/admin/hooks/%s/%d", setting.AppSubURL, kind, w.ID)
+	return hook
+}
+
 // GetRepoHook get a repo's webhook. If there is an error, write to `ctx`
 // accordingly and return the error
 func GetRepoHook(ctx *context.APIContext, repoID, hookID int64) (*models.Webhook, error) {
@@ -48,6 +77,74 @@ func GetRepoHook(ctx *context.APIContext, repoID, hookID int64) (*models.Webhook
 	return w, nil
 }
 
+// ListHookDeliveries writes a page of the deliveries of a webhook to `ctx`,
+// the latest first
+func ListHookDeliveries(ctx *context.APIContext, w *models.Webhook) {
+	page := ctx.QueryInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	limit := convert.ToCorrectPageSize(ctx.QueryInt("limit"))
+
+	tasks, err := models.GetHookTasks(w.ID, page, limit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetHookTasks", err)
+		return
+	}
+	count, err := models.CountHookTasks(w.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountHookTasks", err)
+		return
+	}
+
+	deliveries := make([]*api.HookDelivery, len(tasks))
+	for i, t := range tasks {
+		deliveries[i] = convert.ToHookDelivery(t)
+	}
+	ctx.SetLinkHeader(int(count), limit)
+	ctx.Header().Set("X-Total-Count", fmt.Sprintf("%d", count))
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// getHookDelivery get the delivery of a webhook given in the URL. If there is
+// an error, write to `ctx` accordingly and return the error
+func getHookDelivery(ctx *context.APIContext, w *models.Webhook) (*models.HookTask, error) {
+	t, err := models.GetHookTaskByHookID(w.ID, ctx.ParamsInt64(":delivery"))
+	if err != nil {
+		if models.IsErrHookTaskNotExist(err) {
+			ctx.Status(http.StatusNotFound)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetHookTaskByHookID", err)
+		}
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetHookDelivery writes the delivery of a webhook given in the URL to `ctx`
+func GetHookDelivery(ctx *context.APIContext, w *models.Webhook) {
+	t, err := getHookDelivery(ctx, w)
+	if err != nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToHookDelivery(t))
+}
+
+// RedeliverHookDelivery delivers again the payload of the delivery of a
+// webhook given in the URL, and writes the new delivery to `ctx`
+func RedeliverHookDelivery(ctx *context.APIContext, w *models.Webhook) {
+	t, err := getHookDelivery(ctx, w)
+	if err != nil {
+		return
+	}
+	redelivery, err := models.RedeliverHookTask(w, t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "RedeliverHookTask", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToHookDelivery(redelivery))
+}
+
 // CheckCreateHookOption check if a CreateHookOption form is valid. If invalid,
 // write the appropriate error to `ctx`. Return whether the form is valid
 func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption) bool {
@@ -55,19 +152,33 @@ func CheckCreateHookOption(ctx *context.APIContext, form *api.CreateHookOption)
 		ctx.Error(422, "", "Invalid hook type")
 		return false
 	}
-	for _, name := range []string{"url", "content_type"} {
+	for _, name := range requiredHookConfig(form.Type) {
 		if _, ok := form.Config[name]; !ok {
 			ctx.Error(422, "", "Missing config option: "+name)
 			return false
 		}
 	}
-	if !models.IsValidHookContentType(form.Config["content_type"]) {
+	if ct, ok := form.Config["content_type"]; ok && !models.IsValidHookContentType(ct) {
 		ctx.Error(422, "", "Invalid content type")
 		return false
 	}
 	return true
 }
 
+// requiredHookConfig returns the config options needed to create a hook of
+// the given type
+func requiredHookConfig(hookType string) []string {
+	switch models.ToHookTaskType(hookType) {
+	case models.TELEGRAM:
+		return []string{"bot_token", "chat_id"}
+	case models.MATRIX:
+		return []string{"homeserver_url", "room_id", "access_token"}
+	case models.CUSTOM:
+		return []string{"url", "content_type", "template"}
+	}
+	return []string{"url", "content_type"}
+}
+
 // AddOrgHook add a hook to an organization. Writes to `ctx` accordingly
 func AddOrgHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	org := ctx.Org.Organization
@@ -86,6 +197,14 @@ func AddRepoHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	}
 }
 
+// AddAdminHook add a system or default hook. Writes to `ctx` accordingly
+func AddAdminHook(ctx *context.APIContext, form *api.CreateHookOption) {
+	hook, ok := addHook(ctx, form, 0, 0)
+	if ok {
+		ctx.JSON(http.StatusCreated, ToAdminHook(hook))
+	}
+}
+
 // addHook add the hook specified by `form`, `orgID` and `repoID`. If there is
 // an error, write to `ctx` accordingly. Return (webhook, ok)
 func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID int64) (*models.Webhook, bool) {
@@ -101,19 +220,34 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		HookEvent: &models.HookEvent{
 			ChooseEvents: true,
 			HookEvents: models.HookEvents{
-				Create:       com.IsSliceContainsStr(form.Events, string(models.HookEventCreate)),
-				Delete:       com.IsSliceContainsStr(form.Events, string(models.HookEventDelete)),
-				Fork:         com.IsSliceContainsStr(form.Events, string(models.HookEventFork)),
-				Issues:       com.IsSliceContainsStr(form.Events, string(models.HookEventIssues)),
-				IssueComment: com.IsSliceContainsStr(form.Events, string(models.HookEventIssueComment)),
-				Push:         com.IsSliceContainsStr(form.Events, string(models.HookEventPush)),
-				PullRequest:  com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest)),
-				Repository:   com.IsSliceContainsStr(form.Events, string(models.HookEventRepository)),
-				Release:      com.IsSliceContainsStr(form.Events, string(models.HookEventRelease)),
+				Create:               com.IsSliceContainsStr(form.Events, string(models.HookEventCreate)),
+				Delete:               com.IsSliceContainsStr(form.Events, string(models.HookEventDelete)),
+				Fork:                 com.IsSliceContainsStr(form.Events, string(models.HookEventFork)),
+				Issues:               com.IsSliceContainsStr(form.Events, string(models.HookEventIssues)),
+				IssueAssign:          com.IsSliceContainsStr(form.Events, string(models.HookEventIssueAssign)),
+				IssueLabel:           com.IsSliceContainsStr(form.Events, string(models.HookEventIssueLabel)),
+				IssueMilestone:       com.IsSliceContainsStr(form.Events, string(models.HookEventIssueMilestone)),
+				IssueComment:         com.IsSliceContainsStr(form.Events, string(models.HookEventIssueComment)),
+				Push:                 com.IsSliceContainsStr(form.Events, string(models.HookEventPush)),
+				PullRequest:          com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest)),
+				PullRequestAssign:    com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestAssign)),
+				PullRequestLabel:     com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestLabel)),
+				PullRequestMilestone: com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestMilestone)),
+				PullRequestReview:    com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestReview)),
+				PullRequestSync:      com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestSync)),
+				Repository:           com.IsSliceContainsStr(form.Events, string(models.HookEventRepository)),
+				Release:              com.IsSliceContainsStr(form.Events, string(models.HookEventRelease)),
+				Wiki:                 com.IsSliceContainsStr(form.Events, string(models.HookEventWiki)),
+				Status:               com.IsSliceContainsStr(form.Events, string(models.HookEventStatus)),
+				Star:                 com.IsSliceContainsStr(form.Events, string(models.HookEventStar)),
+				Watch:                com.IsSliceContainsStr(form.Events, string(models.HookEventWatch)),
+				BranchProtection:     com.IsSliceContainsStr(form.Events, string(models.HookEventBranchProtection)),
 			},
 		},
 		IsActive:     form.Active,
 		HookTaskType: models.ToHookTaskType(form.Type),
+		// only the hooks of the site administration can be system hooks
+		IsSystemWebhook: orgID == 0 && repoID == 0 && form.IsSystemWebhook,
 	}
 	if w.HookTaskType == models.SLACK {
 		channel, ok := form.Config["channel"]
@@ -138,6 +272,8 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 			return nil, false
 		}
 		w.Meta = string(meta)
+	} else if !updateHookMeta(ctx, w, form.Config) {
+		return nil, false
 	}
 
 	if err := w.UpdateEvent(); err != nil {
@@ -167,6 +303,23 @@ func EditOrgHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64
 	ctx.JSON(200, convert.ToHook(org.HomeLink(), updated))
 }
 
+// EditAdminHook edit the system or default webhook `w` according to `form`.
+// Writes to `ctx` accordingly
+func EditAdminHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64) {
+	hook, err := GetAdminHook(ctx, hookID)
+	if err != nil {
+		return
+	}
+	if !editHook(ctx, form, hook) {
+		return
+	}
+	updated, err := GetAdminHook(ctx, hookID)
+	if err != nil {
+		return
+	}
+	ctx.JSON(200, ToAdminHook(updated))
+}
+
 // EditRepoHook edit webhook `w` according to `form`. Writes to `ctx` accordingly
 func EditRepoHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64) {
 	repo := ctx.Repo
@@ -213,6 +366,8 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 				}
 				w.Meta = string(meta)
 			}
+		} else if !updateHookMeta(ctx, w, form.Config) {
+			return false
 		}
 	}
 
@@ -230,11 +385,24 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	w.Delete = com.IsSliceContainsStr(form.Events, string(models.HookEventDelete))
 	w.Fork = com.IsSliceContainsStr(form.Events, string(models.HookEventFork))
 	w.Issues = com.IsSliceContainsStr(form.Events, string(models.HookEventIssues))
+	w.IssueAssign = com.IsSliceContainsStr(form.Events, string(models.HookEventIssueAssign))
+	w.IssueLabel = com.IsSliceContainsStr(form.Events, string(models.HookEventIssueLabel))
+	w.IssueMilestone = com.IsSliceContainsStr(form.Events, string(models.HookEventIssueMilestone))
 	w.IssueComment = com.IsSliceContainsStr(form.Events, string(models.HookEventIssueComment))
 	w.Push = com.IsSliceContainsStr(form.Events, string(models.HookEventPush))
 	w.PullRequest = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequest))
+	w.PullRequestAssign = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestAssign))
+	w.PullRequestLabel = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestLabel))
+	w.PullRequestMilestone = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestMilestone))
+	w.PullRequestReview = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestReview))
+	w.PullRequestSync = com.IsSliceContainsStr(form.Events, string(models.HookEventPullRequestSync))
 	w.Repository = com.IsSliceContainsStr(form.Events, string(models.HookEventRepository))
 	w.Release = com.IsSliceContainsStr(form.Events, string(models.HookEventRelease))
+	w.Wiki = com.IsSliceContainsStr(form.Events, string(models.HookEventWiki))
+	w.Status = com.IsSliceContainsStr(form.Events, string(models.HookEventStatus))
+	w.Star = com.IsSliceContainsStr(form.Events, string(models.HookEventStar))
+	w.Watch = com.IsSliceContainsStr(form.Events, string(models.HookEventWatch))
+	w.BranchProtection = com.IsSliceContainsStr(form.Events, string(models.HookEventBranchProtection))
 
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(500, "UpdateEvent", err)
@@ -251,3 +419,85 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *models.Webho
 	}
 	return true
 }
+
+// updateHookMeta updates the attributes specific to the type of the webhook
+// `w` with the options of `config`, the missing options are left unchanged. If
+// an error occurs, write to `ctx` accordingly. Return whether successful
+func updateHookMeta(ctx *context.APIContext, w *models.Webhook, config map[string]string) bool {
+	var meta interface{}
+	switch w.HookTaskType {
+	case models.TELEGRAM:
+		telegram := &models.TelegramMeta{}
+		if len(w.Meta) > 0 {
+			telegram = w.GetTelegramHook()
+		}
+		setHookConfigOption(config, "bot_token", &telegram.BotToken)
+		setHookConfigOption(config, "chat_id", &telegram.ChatID)
+		w.URL = telegram.URL()
+		w.ContentType = models.ContentTypeJSON
+		meta = telegram
+	case models.MATRIX:
+		matrix := &models.MatrixMeta{MessageType: models.MatrixMessageTypeNotice}
+		if len(w.Meta) > 0 {
+			matrix = w.GetMatrixHook()
+		}
+		setHookConfigOption(config, "homeserver_url", &matrix.HomeserverURL)
+		setHookConfigOption(config, "room_id", &matrix.Room)
+		setHookConfigOption(config, "access_token", &matrix.AccessToken)
+		setHookConfigOption(config, "message_type", &matrix.MessageType)
+		if !models.IsValidMatrixMessageType(matrix.MessageType) {
+			ctx.Error(422, "", "Invalid matrix message type")
+			return false
+		}
+		w.URL = matrix.URL()
+		w.ContentType = models.ContentTypeJSON
+		meta = matrix
+	case models.CUSTOM:
+		custom := &models.CustomMeta{}
+		if len(w.Meta) > 0 {
+			custom = w.GetCustomHook()
+		}
+		if custom.EventTemplates == nil {
+			custom.EventTemplates = make(map[models.HookEventType]string)
+		}
+		for name, value := range config {
+			if name == "template" {
+				custom.Template = value
+			} else if strings.HasPrefix(name, "template_") {
+				event := models.HookEventType(strings.TrimPrefix(name, "template_"))
+				if strings.TrimSpace(value) == "" {
+					delete(custom.EventTemplates, event)
+				} else {
+					custom.EventTemplates[event] = value
+				}
+			}
+		}
+		if err := custom.Validate(); err != nil {
+			if models.IsErrInvalidWebhookTemplate(err) {
+				ctx.Error(422, "", err.Error())
+			} else {
+				ctx.Error(500, "Validate", err)
+			}
+			return false
+		}
+		meta = custom
+	default:
+		return true
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		ctx.Error(500, w.HookTaskType.Name()+": JSON marshal failed", err)
+		return false
+	}
+	w.Meta = string(data)
+	return true
+}
+
+// setHookConfigOption sets `value` to the option `name` of `config` if it is
+// present
+func setHookConfigOption(config map[string]string, name string, value *string) {
+	if v, ok := config[name]; ok {
+		*value = strings.TrimSpace(v)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/util"
+	api "code.gitea.io/sdk/gitea"
+)
+
+// ListProjects list the projects of a repository, or of an organization if
+// repoID is 0. Writes to `ctx` accordingly
+func ListProjects(ctx *context.APIContext, repoID, ownerID int64) {
+	opts := models.ProjectSearchOptions{
+		RepoID: repoID,
+		Page:   ctx.QueryInt("page"),
+	}
+	if repoID == 0 {
+		opts.OwnerID = ownerID
+	}
+	switch ctx.Query("state") {
+	case "closed":
+		opts.IsClosed = util.OptionalBoolTrue
+	case "all":
+		opts.IsClosed = util.OptionalBoolNone
+	default:
+		opts.IsClosed = util.OptionalBoolFalse
+	}
+
+	projects, _, err := models.GetProjects(opts)
+	if err != nil {
+		ctx.Error(500, "GetProjects", err)
+		return
+	}
+	if err = projects.LoadAttributes(); err != nil {
+		ctx.Error(500, "LoadAttributes", err)
+		return
+	}
+
+	apiProjects := make([]*api.Project, len(projects))
+	for i := range projects {
+		apiProjects[i] = projects[i].APIFormat()
+	}
+	ctx.JSON(200, &apiProjects)
+}
+
+// AddProject add a project to a repository, or to an organization if repoID
+// is 0. Writes to `ctx` accordingly
+func AddProject(ctx *context.APIContext, form *api.CreateProjectOption, repoID, ownerID int64) {
+	boardType, ok := models.ParseProjectBoardType(form.BoardType)
+	if !ok {
+		ctx.Error(422, "", "Invalid board type")
+		return
+	}
+
+	p := &models.Project{
+		Title:       form.Title,
+		Description: form.Description,
+		CreatorID:   ctx.User.ID,
+		BoardType:   boardType,
+	}
+	if repoID > 0 {
+		p.RepoID = repoID
+		p.Type = models.ProjectTypeRepository
+	} else {
+		p.OwnerID = ownerID
+		p.Type = models.ProjectTypeOrganization
+	}
+	if err := models.NewProject(p); err != nil {
+		ctx.Error(500, "NewProject", err)
+		return
+	}
+	if err := p.LoadAttributes(); err != nil {
+		ctx.Error(500, "LoadAttributes", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, p.APIFormat())
+}
@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/search"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
@@ -258,96 +259,85 @@ func ExploreCode(ctx *context.Context) {
 	ctx.Data["PageIsExplore"] = true
 	ctx.Data["PageIsExploreCode"] = true
 
-	keyword := strings.TrimSpace(ctx.Query("q"))
-	page := ctx.QueryInt("page")
-	if page <= 0 {
-		page = 1
+	opts := &indexer.RepoSearchOptions{
+		Keyword:    strings.TrimSpace(ctx.Query("q")),
+		Language:   indexer.LanguageName(strings.TrimSpace(ctx.Query("l"))),
+		PathPrefix: strings.TrimSpace(ctx.Query("path")),
+		Page:       ctx.QueryInt("page"),
+		PageSize:   setting.UI.RepoSearchPagingNum,
 	}
-
-	var (
-		repoIDs []int64
-		err     error
-		isAdmin bool
-		userID  int64
-	)
-	if ctx.User != nil {
-		userID = ctx.User.ID
-		isAdmin = ctx.User.IsAdmin
-	}
-
-	// guest user or non-admin user
-	if ctx.User == nil || !isAdmin {
-		repoIDs, err = models.FindUserAccessibleRepoIDs(userID)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
-		}
+	if opts.Page <= 0 {
+		opts.Page = 1
 	}
+	ctx.Data["Keyword"] = opts.Keyword
+	ctx.Data["Language"] = opts.Language
+	ctx.Data["PathPrefix"] = opts.PathPrefix
 
 	var (
 		total         int
 		searchResults []*search.Result
+		languages     []*indexer.RepoSearchLanguage
+		ownerID       int64
+		err           error
 	)
-
-	// if non-admin login user, we need check UnitTypeCode at first
-	if ctx.User != nil && len(repoIDs) > 0 {
-		repoMaps, err := models.GetRepositoriesMapByIDs(repoIDs)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
+	// nothing matches an unknown owner or a user who can read no code
+	searchable := true
+	ownerName := strings.TrimSpace(ctx.Query("owner"))
+	if len(ownerName) > 0 {
+		owner, err := models.GetUserByName(ownerName)
+		if models.IsErrUserNotExist(err) {
+			searchable = false
+		} else if err != nil {
+			ctx.ServerError("GetUserByName", err)
 			return
+		} else {
+			ownerID = owner.ID
 		}
+	}
+	ctx.Data["Owner"] = ownerName
 
-		var rightRepoMap = make(map[int64]*models.Repository, len(repoMaps))
-		repoIDs = make([]int64, 0, len(repoMaps))
-		for id, repo := range repoMaps {
-			if repo.CheckUnitUser(userID, isAdmin, models.UnitTypeCode) {
-				rightRepoMap[id] = repo
-				repoIDs = append(repoIDs, id)
-			}
-		}
-
-		ctx.Data["RepoMaps"] = rightRepoMap
-
-		total, searchResults, err = search.PerformSearch(repoIDs, keyword, page, setting.UI.RepoSearchPagingNum)
+	if searchable {
+		opts.RepoIDs, err = models.FindUserCodeAccessibleRepoIDs(ctx.User, ownerID)
 		if err != nil {
-			ctx.ServerError("SearchResults", err)
+			ctx.ServerError("FindUserCodeAccessibleRepoIDs", err)
 			return
 		}
-		// if non-login user or isAdmin, no need to check UnitTypeCode
-	} else if (ctx.User == nil && len(repoIDs) > 0) || isAdmin {
-		total, searchResults, err = search.PerformSearch(repoIDs, keyword, page, setting.UI.RepoSearchPagingNum)
+		// a nil list means all the repositories
+		searchable = opts.RepoIDs == nil || len(opts.RepoIDs) > 0
+	}
+	if searchable {
+		total, searchResults, languages, err = search.PerformSearch(opts)
 		if err != nil {
 			ctx.ServerError("SearchResults", err)
 			return
 		}
+	}
 
-		var loadRepoIDs = make([]int64, 0, len(searchResults))
-		for _, result := range searchResults {
-			var find bool
-			for _, id := range loadRepoIDs {
-				if id == result.RepoID {
-					find = true
-					break
-				}
-			}
-			if !find {
-				loadRepoIDs = append(loadRepoIDs, result.RepoID)
+	var loadRepoIDs = make([]int64, 0, len(searchResults))
+	for _, result := range searchResults {
+		var find bool
+		for _, id := range loadRepoIDs {
+			if id == result.RepoID {
+				find = true
+				break
 			}
 		}
-
-		repoMaps, err := models.GetRepositoriesMapByIDs(loadRepoIDs)
-		if err != nil {
-			ctx.ServerError("SearchResults", err)
-			return
+		if !find {
+			loadRepoIDs = append(loadRepoIDs, result.RepoID)
 		}
+	}
 
-		ctx.Data["RepoMaps"] = repoMaps
+	repoMaps, err := models.GetRepositoriesMapByIDs(loadRepoIDs)
+	if err != nil {
+		ctx.ServerError("SearchResults", err)
+		return
 	}
+	ctx.Data["RepoMaps"] = repoMaps
 
-	ctx.Data["Keyword"] = keyword
-	pager := paginater.New(total, setting.UI.RepoSearchPagingNum, page, 5)
+	pager := paginater.New(total, setting.UI.RepoSearchPagingNum, opts.Page, 5)
 	ctx.Data["Page"] = pager
 	ctx.Data["SearchResults"] = searchResults
+	ctx.Data["SearchLanguages"] = languages
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["PageIsViewCode"] = true
 	ctx.HTML(200, tplExploreCode)
@@ -0,0 +1,91 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	userSetting "code.gitea.io/gitea/routers/user/setting"
+)
+
+const (
+	// tplSettingsApplications template path for render oauth2 applications settings
+	tplSettingsApplications base.TplName = "org/settings/applications"
+	// tplSettingsOAuthApplicationEdit template path for render oauth2 application edit settings
+	tplSettingsOAuthApplicationEdit base.TplName = "org/settings/applications_oauth2_edit"
+)
+
+func orgOAuth2Handlers(ctx *context.Context) *userSetting.OAuth2CommonHandlers {
+	return &userSetting.OAuth2CommonHandlers{
+		OwnerID:            ctx.Org.Organization.ID,
+		BasePathList:       ctx.Org.OrgLink + "/settings/applications",
+		BasePathEditPrefix: ctx.Org.OrgLink + "/settings/applications/oauth2",
+		TplAppList:         tplSettingsApplications,
+		TplAppEdit:         tplSettingsOAuthApplicationEdit,
+		LoadListData:       loadApplicationsData,
+	}
+}
+
+func loadApplicationsData(ctx *context.Context) {
+	apps, err := models.GetOAuth2ApplicationsByUserID(ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOAuth2ApplicationsByUserID", err)
+		return
+	}
+	ctx.Data["Applications"] = apps
+}
+
+// Applications render org applications page (for org, at the moment, there are only OAuth2 applications)
+func Applications(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.applications")
+	ctx.Data["PageIsSettingsApplications"] = true
+	ctx.Data["OAuth2ListLink"] = ctx.Org.OrgLink + "/settings/applications"
+	ctx.Data["OAuth2LinkPrefix"] = ctx.Org.OrgLink + "/settings/applications/oauth2"
+
+	loadApplicationsData(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(200, tplSettingsApplications)
+}
+
+// OAuthApplicationsPost response for adding an oauth2 application
+func OAuthApplicationsPost(ctx *context.Context, form auth.EditOAuth2ApplicationForm) {
+	ctx.Data["Title"] = ctx.Tr("settings.applications")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	orgOAuth2Handlers(ctx).AddApp(ctx, form)
+}
+
+// OAuth2ApplicationShow displays the given application
+func OAuth2ApplicationShow(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.applications")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	orgOAuth2Handlers(ctx).EditShow(ctx)
+}
+
+// OAuth2ApplicationEdit response for editing an oauth2 application
+func OAuth2ApplicationEdit(ctx *context.Context, form auth.EditOAuth2ApplicationForm) {
+	ctx.Data["Title"] = ctx.Tr("settings.applications")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	orgOAuth2Handlers(ctx).EditSave(ctx, form)
+}
+
+// OAuthApplicationsRegenerateSecret handles the post request for regenerating the secret
+func OAuthApplicationsRegenerateSecret(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.applications")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	orgOAuth2Handlers(ctx).RegenerateSecret(ctx)
+}
+
+// DeleteOAuth2Application deletes the given oauth2 application
+func DeleteOAuth2Application(ctx *context.Context) {
+	orgOAuth2Handlers(ctx).DeleteApp(ctx)
+}
@@ -34,7 +34,7 @@ func Branches(ctx *context.Context) {
 	ctx.Data["Title"] = "Branches"
 	ctx.Data["IsRepoToolbarBranches"] = true
 	ctx.Data["DefaultBranch"] = ctx.Repo.Repository.DefaultBranch
-	ctx.Data["IsWriter"] = ctx.Repo.CanWrite(models.UnitTypeCode)
+	ctx.Data["IsWriter"] = ctx.Repo.CanWrite(models.UnitTypeCode) && !ctx.Repo.Repository.IsArchived
 	ctx.Data["IsMirror"] = ctx.Repo.Repository.IsMirror
 	ctx.Data["PageIsViewCode"] = true
 	ctx.Data["PageIsBranches"] = true
@@ -125,6 +125,9 @@ func deleteBranch(ctx *context.Context, branchName string) error {
 		log.Error(4, "DeleteBranch: %v", err)
 		return err
 	}
+	if ctx.Repo.Repository.IsIndexedRef(git.BranchPrefix + branchName) {
+		models.UpdateRepoIndexer(ctx.Repo.Repository)
+	}
 
 	// Don't return error here
 	if err := ctx.Repo.Repository.AddDeletedBranch(branchName, commit.ID.String(), ctx.User.ID); err != nil {
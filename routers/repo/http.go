@@ -76,6 +76,7 @@ func HTTP(ctx *context.Context) {
 	var (
 		askAuth      = !isPublicPull || setting.Service.RequireSignInView
 		authUser     *models.User
+		accessToken  *models.AccessToken
 		authUsername string
 		authPasswd   string
 		environ      []string
@@ -144,6 +145,9 @@ func HTTP(ctx *context.Context) {
 
 				// Assume password is a token.
 				token, err := models.GetAccessTokenBySHA(authToken)
+				if err == nil && token.IsExpired() {
+					err = models.ErrAccessTokenNotExist{SHA: authToken}
+				}
 				if err != nil {
 					if models.IsErrAccessTokenNotExist(err) || models.IsErrAccessTokenEmpty(err) {
 						ctx.HandleText(http.StatusUnauthorized, "invalid credentials")
@@ -168,6 +172,7 @@ func HTTP(ctx *context.Context) {
 				if err = models.UpdateAccessToken(token); err != nil {
 					ctx.ServerError("UpdateAccessToken", err)
 				}
+				accessToken = token
 			} else {
 				_, err = models.GetTwoFactorByUID(authUser.ID)
 
@@ -187,6 +192,9 @@ func HTTP(ctx *context.Context) {
 			ctx.ServerError("GetUserRepoPermission", err)
 			return
 		}
+		if accessToken != nil {
+			perm = accessToken.LimitRepoPermission(repo, perm)
+		}
 
 		if !perm.CanAccess(accessMode, unitType) {
 			ctx.HandleText(http.StatusForbidden, "User permission denied")
@@ -198,6 +206,11 @@ func HTTP(ctx *context.Context) {
 			return
 		}
 
+		if !isPull && repo.IsArchived {
+			ctx.HandleText(http.StatusForbidden, "archived repository is read-only")
+			return
+		}
+
 		environ = []string{
 			models.EnvRepoUsername + "=" + username,
 			models.EnvRepoName + "=" + reponame,
@@ -23,7 +23,6 @@ import (
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
-	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/notification"
@@ -123,7 +122,7 @@ func issues(ctx *context.Context, milestoneID int64, isPullOption util.OptionalB
 
 	var issueIDs []int64
 	if len(keyword) > 0 {
-		issueIDs, err = indexer.SearchIssuesByKeyword(repo.ID, keyword)
+		issueIDs, err = models.SearchIssuesByKeyword(repo.ID, keyword)
 		if len(issueIDs) == 0 {
 			forceEmpty = true
 		}
@@ -284,6 +283,31 @@ func RetrieveRepoMilestonesAndAssignees(ctx *context.Context, repo *models.Repos
 	}
 }
 
+// retrieveRepoProjects finds the open projects of the repository and its
+// organization that issues can be put on
+func retrieveRepoProjects(ctx *context.Context, repo *models.Repository) {
+	var err error
+	ctx.Data["OpenRepoProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
+		RepoID:   repo.ID,
+		IsClosed: util.OptionalBoolFalse,
+	})
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+
+	if repo.Owner.IsOrganization() {
+		ctx.Data["OpenOrgProjects"], _, err = models.GetProjects(models.ProjectSearchOptions{
+			OwnerID:  repo.OwnerID,
+			IsClosed: util.OptionalBoolFalse,
+		})
+		if err != nil {
+			ctx.ServerError("GetProjects", err)
+			return
+		}
+	}
+}
+
 // RetrieveRepoMetas find all the meta information of a repository
 func RetrieveRepoMetas(ctx *context.Context, repo *models.Repository) []*models.Label {
 	if !ctx.Repo.CanWrite(models.UnitTypeIssues) {
@@ -643,6 +667,20 @@ func ViewIssue(ctx *context.Context) {
 		}
 	}
 
+	// Check project.
+	if ctx.Repo.CanRead(models.UnitTypeProjects) {
+		if err = issue.LoadProject(); err != nil {
+			ctx.ServerError("LoadProject", err)
+			return
+		}
+		if ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+			retrieveRepoProjects(ctx, repo)
+			if ctx.Written() {
+				return
+			}
+		}
+	}
+
 	if ctx.IsSigned {
 		// Update issue-user.
 		if err = issue.ReadBy(ctx.User.ID); err != nil {
@@ -745,6 +783,21 @@ func ViewIssue(ctx *context.Context) {
 			if comment.MilestoneID > 0 && comment.Milestone == nil {
 				comment.Milestone = ghostMilestone
 			}
+		} else if comment.Type == models.CommentTypeProject {
+			if err = comment.LoadProject(); err != nil {
+				ctx.ServerError("LoadProject", err)
+				return
+			}
+			ghostProject := &models.Project{
+				ID:    -1,
+				Title: ctx.Tr("repo.issues.deleted_project"),
+			}
+			if comment.OldProjectID > 0 && comment.OldProject == nil {
+				comment.OldProject = ghostProject
+			}
+			if comment.ProjectID > 0 && comment.Project == nil {
+				comment.Project = ghostProject
+			}
 		} else if comment.Type == models.CommentTypeAssignees {
 			if err = comment.LoadAssigneeUser(); err != nil {
 				ctx.ServerError("LoadAssigneeUser", err)
@@ -806,7 +859,7 @@ func ViewIssue(ctx *context.Context) {
 		}
 		prConfig := prUnit.PullRequestsConfig()
 
-		ctx.Data["AllowMerge"] = ctx.Repo.CanWrite(models.UnitTypeCode)
+		ctx.Data["AllowMerge"] = ctx.Repo.CanWrite(models.UnitTypeCode) && !repo.IsArchived
 		if err := pull.CheckUserAllowedToMerge(ctx.User); err != nil {
 			if !models.IsErrNotAllowedToMerge(err) {
 				ctx.ServerError("CheckUserAllowedToMerge", err)
@@ -815,6 +868,23 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["AllowMerge"] = false
 		}
 
+		if err = pull.LoadProtectedBranch(); err != nil {
+			ctx.ServerError("LoadProtectedBranch", err)
+			return
+		}
+		if pull.ProtectedBranch != nil {
+			ctx.Data["IsBlockedByApprovals"] = !pull.ProtectedBranch.HasEnoughApprovals(pull)
+			ctx.Data["GrantedApprovals"] = pull.ProtectedBranch.GetGrantedApprovalsCount(pull)
+
+			unsuccessful, err := pull.ProtectedBranch.GetUnsuccessfulStatusChecks(pull)
+			if err != nil {
+				ctx.ServerError("GetUnsuccessfulStatusChecks", err)
+				return
+			}
+			ctx.Data["IsBlockedByStatusChecks"] = len(unsuccessful) > 0
+			ctx.Data["UnsuccessfulStatusChecks"] = strings.Join(unsuccessful, ", ")
+		}
+
 		// Check correct values and select default
 		if ms, ok := ctx.Data["MergeStyle"].(models.MergeStyle); !ok ||
 			!prConfig.IsMergeStyleAllowed(ms) {
@@ -846,8 +916,9 @@ func ViewIssue(ctx *context.Context) {
 	ctx.Data["Issue"] = issue
 	ctx.Data["ReadOnly"] = true
 	ctx.Data["SignInLink"] = setting.AppSubURL + "/user/login?redirect_to=" + ctx.Data["Link"].(string)
-	ctx.Data["IsIssuePoster"] = ctx.IsSigned && issue.IsPoster(ctx.User.ID)
-	ctx.Data["IsIssueWriter"] = ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull)
+	ctx.Data["IsIssuePoster"] = ctx.IsSigned && issue.IsPoster(ctx.User.ID) && !repo.IsArchived
+	ctx.Data["IsIssueWriter"] = ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) && !repo.IsArchived
+	ctx.Data["LockReasons"] = setting.Repository.Issue.LockReasons
 	ctx.HTML(200, tplIssueView)
 }
 
@@ -980,6 +1051,27 @@ func UpdateIssueMilestone(ctx *context.Context) {
 			ctx.ServerError("ChangeMilestoneAssign", err)
 			return
 		}
+		notification.NotifyIssueChangeMilestone(ctx.User, issue, oldMilestoneID)
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
+
+// UpdateIssueProject change issue's project
+func UpdateIssueProject(ctx *context.Context) {
+	issues := getActionIssues(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	projectID := ctx.QueryInt64("id")
+	for _, issue := range issues {
+		if err := models.ChangeProjectAssign(issue, ctx.User, projectID); err != nil {
+			ctx.NotFoundOrServerError("ChangeProjectAssign", models.IsErrProjectNotExist, err)
+			return
+		}
 	}
 
 	ctx.JSON(200, map[string]interface{}{
@@ -1000,15 +1092,26 @@ func UpdateIssueAssignee(ctx *context.Context) {
 	for _, issue := range issues {
 		switch action {
 		case "clear":
+			assignees := issue.Assignees
 			if err := models.DeleteNotPassedAssignee(issue, ctx.User, []*models.User{}); err != nil {
 				ctx.ServerError("ClearAssignees", err)
 				return
 			}
+			for _, assignee := range assignees {
+				notification.NotifyIssueChangeAssignee(ctx.User, issue, assignee, true)
+			}
 		default:
-			if err := issue.ChangeAssignee(ctx.User, assigneeID); err != nil {
+			assignee, err := models.GetUserByID(assigneeID)
+			if err != nil {
+				ctx.NotFoundOrServerError("GetUserByID", models.IsErrUserNotExist, err)
+				return
+			}
+			removed, err := issue.ChangeAssignee(ctx.User, assigneeID)
+			if err != nil {
 				ctx.ServerError("ChangeAssignee", err)
 				return
 			}
+			notification.NotifyIssueChangeAssignee(ctx.User, issue, assignee, removed)
 		}
 	}
 	ctx.JSON(200, map[string]interface{}{
@@ -1070,6 +1173,12 @@ func NewComment(ctx *context.Context, form auth.CreateCommentForm) {
 		return
 	}
 
+	if issue.IsLocked && !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Flash.Error(ctx.Tr("repo.issues.comment_on_locked"))
+		ctx.Redirect(issue.HTMLURL())
+		return
+	}
+
 	var attachments []string
 	if setting.AttachmentEnabled {
 		attachments = form.Files
@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 )
 
 const (
@@ -145,6 +146,7 @@ func UpdateIssueLabel(ctx *context.Context) {
 				ctx.ServerError("ClearLabels", err)
 				return
 			}
+			notification.NotifyIssueClearLabels(ctx.User, issue)
 		}
 	case "attach", "detach", "toggle":
 		label, err := models.GetLabelByID(ctx.QueryInt64("id"))
@@ -174,6 +176,7 @@ func UpdateIssueLabel(ctx *context.Context) {
 					ctx.ServerError("AddLabel", err)
 					return
 				}
+				notification.NotifyIssueChangeLabels(ctx.User, issue, []*models.Label{label}, nil)
 			}
 		} else {
 			for _, issue := range issues {
@@ -181,6 +184,7 @@ func UpdateIssueLabel(ctx *context.Context) {
 					ctx.ServerError("RemoveLabel", err)
 					return
 				}
+				notification.NotifyIssueChangeLabels(ctx.User, issue, nil, []*models.Label{label})
 			}
 		}
 	default:
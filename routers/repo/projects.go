@@ -0,0 +1,541 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/Unknwon/paginater"
+)
+
+const (
+	tplProjects        base.TplName = "repo/projects/list"
+	tplProjectsNew     base.TplName = "repo/projects/new"
+	tplProjectsView    base.TplName = "repo/projects/view"
+	tplOrgProjects     base.TplName = "org/projects/list"
+	tplOrgProjectsNew  base.TplName = "org/projects/new"
+	tplOrgProjectsView base.TplName = "org/projects/view"
+)
+
+type projectsCtx struct {
+	RepoID       int64
+	OrgID        int64
+	Link         string
+	CanWrite     bool
+	ListTemplate base.TplName
+	NewTemplate  base.TplName
+	ViewTemplate base.TplName
+}
+
+// getProjectsCtx determines whether this is a repo context or organization context.
+func getProjectsCtx(ctx *context.Context) (*projectsCtx, error) {
+	if len(ctx.Repo.RepoLink) > 0 {
+		return &projectsCtx{
+			RepoID:       ctx.Repo.Repository.ID,
+			Link:         ctx.Repo.RepoLink + "/projects",
+			CanWrite:     ctx.Repo.CanWrite(models.UnitTypeProjects) && !ctx.Repo.Repository.IsArchived,
+			ListTemplate: tplProjects,
+			NewTemplate:  tplProjectsNew,
+			ViewTemplate: tplProjectsView,
+		}, nil
+	}
+
+	if len(ctx.Org.OrgLink) > 0 {
+		return &projectsCtx{
+			OrgID:        ctx.Org.Organization.ID,
+			Link:         ctx.Org.OrgLink + "/projects",
+			CanWrite:     ctx.Org.IsOwner,
+			ListTemplate: tplOrgProjects,
+			NewTemplate:  tplOrgProjectsNew,
+			ViewTemplate: tplOrgProjectsView,
+		}, nil
+	}
+
+	return nil, errors.New("Unable to set Projects context")
+}
+
+func prepareProjectsCtx(ctx *context.Context, title string) *projectsCtx {
+	pCtx, err := getProjectsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getProjectsCtx", err)
+		return nil
+	}
+	ctx.Data["Title"] = ctx.Tr(title)
+	ctx.Data["PageIsProjects"] = true
+	ctx.Data["ProjectsLink"] = pCtx.Link
+	ctx.Data["CanWriteProjects"] = pCtx.CanWrite
+	return pCtx
+}
+
+// getProject returns the project of the :id parameter if it belongs to the
+// repository or organization of the context.
+func getProject(ctx *context.Context, pCtx *projectsCtx) *models.Project {
+	p, err := models.GetProjectByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetProjectByID", models.IsErrProjectNotExist, err)
+		return nil
+	}
+	if (pCtx.RepoID > 0 && (!p.IsRepositoryProject() || p.RepoID != pCtx.RepoID)) ||
+		(pCtx.OrgID > 0 && (p.IsRepositoryProject() || p.OwnerID != pCtx.OrgID)) {
+		ctx.NotFound("getProject", nil)
+		return nil
+	}
+	if err = p.LoadAttributes(); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	return p
+}
+
+func renderProjectContent(ctx *context.Context, p *models.Project) {
+	if p.IsRepositoryProject() {
+		p.RenderedContent = string(markdown.Render([]byte(p.Description), p.Repo.Link(), p.Repo.ComposeMetas()))
+	} else {
+		p.RenderedContent = string(markdown.Render([]byte(p.Description), p.Owner.HomeLink(), nil))
+	}
+}
+
+// Projects renders the projects page of a repository or an organization
+func Projects(ctx *context.Context) {
+	pCtx := prepareProjectsCtx(ctx, "repo.projects")
+	if ctx.Written() {
+		return
+	}
+
+	isShowClosed := ctx.Query("state") == "closed"
+	opts := models.ProjectSearchOptions{
+		RepoID:  pCtx.RepoID,
+		OwnerID: pCtx.OrgID,
+	}
+	openCount, closedCount, err := models.ProjectStats(opts)
+	if err != nil {
+		ctx.ServerError("ProjectStats", err)
+		return
+	}
+	ctx.Data["OpenCount"] = openCount
+	ctx.Data["ClosedCount"] = closedCount
+
+	page := ctx.QueryInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	var total int
+	if !isShowClosed {
+		total = int(openCount)
+		opts.IsClosed = util.OptionalBoolFalse
+	} else {
+		total = int(closedCount)
+		opts.IsClosed = util.OptionalBoolTrue
+	}
+	ctx.Data["Page"] = paginater.New(total, setting.UI.IssuePagingNum, page, 5)
+
+	opts.Page = page
+	opts.SortType = ctx.Query("sort")
+	projects, _, err := models.GetProjects(opts)
+	if err != nil {
+		ctx.ServerError("GetProjects", err)
+		return
+	}
+	if err = projects.LoadAttributes(); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return
+	}
+	for _, p := range projects {
+		renderProjectContent(ctx, p)
+	}
+	ctx.Data["Projects"] = projects
+
+	if isShowClosed {
+		ctx.Data["State"] = "closed"
+	} else {
+		ctx.Data["State"] = "open"
+	}
+
+	ctx.Data["SortType"] = opts.SortType
+	ctx.Data["IsShowClosed"] = isShowClosed
+	ctx.HTML(200, pCtx.ListTemplate)
+}
+
+// NewProject renders the page to create a project
+func NewProject(ctx *context.Context) {
+	pCtx := prepareProjectsCtx(ctx, "repo.projects.new")
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["BoardTypes"] = models.ProjectBoardTypes
+	ctx.HTML(200, pCtx.NewTemplate)
+}
+
+// NewProjectPost creates a project
+func NewProjectPost(ctx *context.Context, form auth.CreateProjectForm) {
+	pCtx := prepareProjectsCtx(ctx, "repo.projects.new")
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["BoardTypes"] = models.ProjectBoardTypes
+
+	if ctx.HasError() {
+		ctx.HTML(200, pCtx.NewTemplate)
+		return
+	}
+
+	if !form.BoardType.IsValid() {
+		ctx.Data["Err_BoardType"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.projects.type.invalid"), pCtx.NewTemplate, &form)
+		return
+	}
+
+	p := &models.Project{
+		Title:       form.Title,
+		Description: form.Content,
+		CreatorID:   ctx.User.ID,
+		BoardType:   form.BoardType,
+	}
+	if pCtx.RepoID > 0 {
+		p.RepoID = pCtx.RepoID
+		p.Type = models.ProjectTypeRepository
+	} else {
+		p.OwnerID = pCtx.OrgID
+		p.Type = models.ProjectTypeOrganization
+	}
+	if err := models.NewProject(p); err != nil {
+		ctx.ServerError("NewProject", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.create_success", form.Title))
+	ctx.Redirect(pCtx.Link)
+}
+
+// EditProject renders the page to edit a project
+func EditProject(ctx *context.Context) {
+	pCtx := prepareProjectsCtx(ctx, "repo.projects.edit")
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageIsEditProject"] = true
+
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	ctx.Data["Boards"] = boards
+	ctx.Data["title"] = p.Title
+	ctx.Data["content"] = p.Description
+	ctx.Data["close_board_id"] = p.CloseBoardID
+	ctx.HTML(200, pCtx.NewTemplate)
+}
+
+// EditProjectPost updates a project
+func EditProjectPost(ctx *context.Context, form auth.CreateProjectForm) {
+	pCtx := prepareProjectsCtx(ctx, "repo.projects.edit")
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["PageIsEditProject"] = true
+
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	ctx.Data["Boards"] = boards
+
+	if ctx.HasError() {
+		ctx.HTML(200, pCtx.NewTemplate)
+		return
+	}
+
+	p.Title = form.Title
+	p.Description = form.Content
+	p.CloseBoardID = form.CloseBoardID
+	if err = models.UpdateProject(p); err != nil {
+		if models.IsErrProjectBoardNotExist(err) {
+			ctx.Data["Err_CloseBoardID"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.projects.close_board.invalid"), pCtx.NewTemplate, &form)
+		} else {
+			ctx.ServerError("UpdateProject", err)
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.projects.edit_success", p.Title))
+	ctx.Redirect(p.Link())
+}
+
+// ChangeProjectStatus opens or closes a project
+func ChangeProjectStatus(ctx *context.Context) {
+	pCtx, err := getProjectsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getProjectsCtx", err)
+		return
+	}
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+
+	switch ctx.Params(":action") {
+	case "open":
+		if p.IsClosed {
+			if err = models.ChangeProjectStatus(p, false); err != nil {
+				ctx.ServerError("ChangeProjectStatus", err)
+				return
+			}
+		}
+		ctx.Redirect(pCtx.Link + "?state=open")
+	case "close":
+		if !p.IsClosed {
+			if err = models.ChangeProjectStatus(p, true); err != nil {
+				ctx.ServerError("ChangeProjectStatus", err)
+				return
+			}
+		}
+		ctx.Redirect(pCtx.Link + "?state=closed")
+	default:
+		ctx.Redirect(pCtx.Link)
+	}
+}
+
+// DeleteProject deletes a project
+func DeleteProject(ctx *context.Context) {
+	pCtx, err := getProjectsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getProjectsCtx", err)
+		return
+	}
+
+	p, err := models.GetProjectByID(ctx.QueryInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetProjectByID: " + err.Error())
+	} else if (pCtx.RepoID > 0 && p.RepoID != pCtx.RepoID) || (pCtx.OrgID > 0 && p.OwnerID != pCtx.OrgID) {
+		ctx.Flash.Error(ctx.Tr("repo.projects.deletion_failed"))
+	} else if err = models.DeleteProjectByID(p.ID); err != nil {
+		ctx.Flash.Error("DeleteProjectByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": pCtx.Link,
+	})
+}
+
+// canReadProjectIssue returns true if the user of the context can see the
+// issue on a project of an organization.
+func canReadProjectIssue(ctx *context.Context, issue *models.Issue, perms map[int64]models.Permission) (bool, error) {
+	perm, ok := perms[issue.RepoID]
+	if !ok {
+		var err error
+		if perm, err = models.GetUserRepoPermission(issue.Repo, ctx.User); err != nil {
+			return false, err
+		}
+		perms[issue.RepoID] = perm
+	}
+	return perm.CanReadIssuesOrPulls(issue.IsPull), nil
+}
+
+// ViewProject renders the boards of a project
+func ViewProject(ctx *context.Context) {
+	pCtx := prepareProjectsCtx(ctx, "repo.projects")
+	if ctx.Written() {
+		return
+	}
+
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+	renderProjectContent(ctx, p)
+
+	boards, err := models.GetProjectBoards(p.ID)
+	if err != nil {
+		ctx.ServerError("GetProjectBoards", err)
+		return
+	}
+	boards = append([]*models.ProjectBoard{{
+		ProjectID: p.ID,
+		Title:     ctx.Tr("repo.projects.board.uncategorized"),
+	}}, boards...)
+
+	perms := make(map[int64]models.Permission)
+	for _, b := range boards {
+		if err = b.LoadIssues(); err != nil {
+			ctx.ServerError("LoadIssues", err)
+			return
+		}
+		if p.IsRepositoryProject() {
+			continue
+		}
+
+		// Organization projects can hold issues of repositories the user
+		// cannot see.
+		issues := b.Issues[:0]
+		for _, issue := range b.Issues {
+			canRead, err := canReadProjectIssue(ctx, issue, perms)
+			if err != nil {
+				ctx.ServerError("canReadProjectIssue", err)
+				return
+			}
+			if canRead {
+				issues = append(issues, issue)
+			}
+		}
+		b.Issues = issues
+	}
+
+	ctx.Data["Title"] = p.Title
+	ctx.Data["Project"] = p
+	ctx.Data["Boards"] = boards
+	ctx.HTML(200, pCtx.ViewTemplate)
+}
+
+// NewProjectBoardPost adds a board to a project
+func NewProjectBoardPost(ctx *context.Context, form auth.EditProjectBoardForm) {
+	pCtx, err := getProjectsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getProjectsCtx", err)
+		return
+	}
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(p.Link())
+		return
+	}
+
+	if err = models.NewProjectBoard(&models.ProjectBoard{
+		Title:     form.Title,
+		Sorting:   form.Sorting,
+		ProjectID: p.ID,
+		CreatorID: ctx.User.ID,
+	}); err != nil {
+		ctx.ServerError("NewProjectBoard", err)
+		return
+	}
+	ctx.Redirect(p.Link())
+}
+
+// EditProjectBoardPost renames or moves a board of a project
+func EditProjectBoardPost(ctx *context.Context, form auth.EditProjectBoardForm) {
+	pCtx, err := getProjectsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getProjectsCtx", err)
+		return
+	}
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(p.Link())
+		return
+	}
+
+	b, err := models.GetProjectBoard(p.ID, ctx.ParamsInt64(":boardID"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetProjectBoard", models.IsErrProjectBoardNotExist, err)
+		return
+	}
+	b.Title = form.Title
+	b.Sorting = form.Sorting
+	if err = models.UpdateProjectBoard(b); err != nil {
+		ctx.ServerError("UpdateProjectBoard", err)
+		return
+	}
+	ctx.Redirect(p.Link())
+}
+
+// DeleteProjectBoard deletes a board of a project
+func DeleteProjectBoard(ctx *context.Context) {
+	pCtx, err := getProjectsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getProjectsCtx", err)
+		return
+	}
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+
+	if err = models.DeleteProjectBoardByID(p.ID, ctx.QueryInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteProjectBoardByID: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.projects.board.deletion_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": p.Link(),
+	})
+}
+
+// MoveProjectIssues moves cards to a board of a project, the issues are given
+// in the order of the cards on the board.
+func MoveProjectIssues(ctx *context.Context) {
+	pCtx, err := getProjectsCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getProjectsCtx", err)
+		return
+	}
+	p := getProject(ctx, pCtx)
+	if ctx.Written() {
+		return
+	}
+
+	board := &models.ProjectBoard{ProjectID: p.ID}
+	if boardID := ctx.ParamsInt64(":boardID"); boardID > 0 {
+		if board, err = models.GetProjectBoard(p.ID, boardID); err != nil {
+			ctx.NotFoundOrServerError("GetProjectBoard", models.IsErrProjectBoardNotExist, err)
+			return
+		}
+	}
+
+	var issueIDs []int64
+	if commaSeparatedIssueIDs := ctx.Query("issue_ids"); len(commaSeparatedIssueIDs) > 0 {
+		for _, stringIssueID := range strings.Split(commaSeparatedIssueIDs, ",") {
+			issueID, err := strconv.ParseInt(stringIssueID, 10, 64)
+			if err != nil {
+				ctx.Error(400, "ParseInt")
+				return
+			}
+			issueIDs = append(issueIDs, issueID)
+		}
+	}
+
+	if err = models.MoveIssuesOnProjectBoard(board, issueIDs); err != nil {
+		ctx.ServerError("MoveIssuesOnProjectBoard", err)
+		return
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"ok": true,
+	})
+}
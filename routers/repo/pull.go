@@ -541,6 +541,16 @@ func MergePullRequest(ctx *context.Context, form auth.MergePullRequestForm) {
 		return
 	}
 
+	if err = pr.CheckUserAllowedToMerge(ctx.User); err != nil {
+		if !models.IsErrNotAllowedToMerge(err) {
+			ctx.ServerError("CheckUserAllowedToMerge", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("repo.pulls.no_merge_not_ready"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
+		return
+	}
+
 	if ctx.HasError() {
 		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
 		ctx.Redirect(ctx.Repo.RepoLink + "/pulls/" + com.ToStr(pr.Index))
@@ -29,6 +29,11 @@ func CreateCodeComment(ctx *context.Context, form auth.CodeCommentForm) {
 		ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
 		return
 	}
+	if issue.IsLocked && !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Flash.Error(ctx.Tr("repo.issues.comment_on_locked"))
+		ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
+		return
+	}
 	var comment *models.Comment
 	defer func() {
 		if comment != nil {
@@ -102,9 +107,11 @@ func SubmitReview(ctx *context.Context, form auth.SubmitReviewForm) {
 		ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
 		return
 	}
-	var review *models.Review
-	var err error
-
+	if issue.IsLocked && !ctx.Repo.CanWriteIssuesOrPulls(issue.IsPull) {
+		ctx.Flash.Error(ctx.Tr("repo.issues.comment_on_locked"))
+		ctx.Redirect(fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index))
+		return
+	}
 	reviewType := form.ReviewType()
 
 	switch reviewType {
@@ -131,7 +138,7 @@ func SubmitReview(ctx *context.Context, form auth.SubmitReviewForm) {
 		}
 	}
 
-	review, err = models.GetCurrentReview(ctx.User, issue)
+	review, err := models.GetCurrentReview(ctx.User, issue)
 	if err == nil {
 		review.Issue = issue
 		if errl := review.LoadCodeComments(); errl != nil {
@@ -148,43 +155,13 @@ func SubmitReview(ctx *context.Context, form auth.SubmitReviewForm) {
 		return
 	}
 
-	if err != nil {
-		if !models.IsErrReviewNotExist(err) {
-			ctx.ServerError("GetCurrentReview", err)
-			return
-		}
-		// No current review. Create a new one!
-		if review, err = models.CreateReview(models.CreateReviewOptions{
-			Type:     reviewType,
-			Issue:    issue,
-			Reviewer: ctx.User,
-			Content:  form.Content,
-		}); err != nil {
-			ctx.ServerError("CreateReview", err)
-			return
-		}
-	} else {
-		review.Content = form.Content
-		review.Type = reviewType
-		if err = models.UpdateReview(review); err != nil {
-			ctx.ServerError("UpdateReview", err)
-			return
-		}
-	}
-	comm, err := models.CreateComment(&models.CreateCommentOptions{
-		Type:     models.CommentTypeReview,
-		Doer:     ctx.User,
-		Content:  review.Content,
-		Issue:    issue,
-		Repo:     issue.Repo,
-		ReviewID: review.ID,
-	})
-	if err != nil || comm == nil {
-		ctx.ServerError("CreateComment", err)
+	if err != nil && !models.IsErrReviewNotExist(err) {
+		ctx.ServerError("GetCurrentReview", err)
 		return
 	}
-	if err = review.Publish(); err != nil {
-		ctx.ServerError("Publish", err)
+	review, comm, err := models.SubmitReview(ctx.User, issue, reviewType, form.Content)
+	if err != nil {
+		ctx.ServerError("SubmitReview", err)
 		return
 	}
 
@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/Unknwon/paginater"
@@ -67,7 +68,7 @@ func Releases(ctx *context.Context) {
 	}
 
 	writeAccess := ctx.Repo.CanWrite(models.UnitTypeReleases)
-	ctx.Data["CanCreateRelease"] = writeAccess
+	ctx.Data["CanCreateRelease"] = writeAccess && !ctx.Repo.Repository.IsArchived
 
 	opts := models.FindReleasesOptions{
 		IncludeDrafts: writeAccess,
@@ -186,6 +187,7 @@ func NewReleasePost(ctx *context.Context, form auth.NewReleaseForm) {
 			}
 			return
 		}
+		notification.NotifyNewRelease(rel)
 	} else {
 		if !rel.IsTag {
 			ctx.Data["Err_TagName"] = true
@@ -206,6 +208,7 @@ func NewReleasePost(ctx *context.Context, form auth.NewReleaseForm) {
 			ctx.ServerError("UpdateRelease", err)
 			return
 		}
+		notification.NotifyNewRelease(rel)
 	}
 	log.Trace("Release created: %s/%s:%s", ctx.User.LowerName, ctx.Repo.Repository.Name, form.TagName)
 
@@ -276,6 +279,7 @@ func EditReleasePost(ctx *context.Context, form auth.EditReleaseForm) {
 		attachmentUUIDs = form.Files
 	}
 
+	wasDraft := rel.IsDraft
 	rel.Title = form.Title
 	rel.Note = form.Content
 	rel.IsDraft = len(form.Draft) > 0
@@ -284,6 +288,9 @@ func EditReleasePost(ctx *context.Context, form auth.EditReleaseForm) {
 		ctx.ServerError("UpdateRelease", err)
 		return
 	}
+	if wasDraft {
+		notification.NotifyNewRelease(rel)
+	}
 	ctx.Redirect(ctx.Repo.RepoLink + "/releases")
 }
 
@@ -19,6 +19,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 )
@@ -35,6 +36,13 @@ func MustBeNotBare(ctx *context.Context) {
 	}
 }
 
+// MustBeNotArchived render when a repo is archived and thus read-only
+func MustBeNotArchived(ctx *context.Context) {
+	if ctx.Repo.Repository.IsArchived {
+		ctx.NotFound("MustBeNotArchived", nil)
+	}
+}
+
 // MustBeEditable check that repo can be edited
 func MustBeEditable(ctx *context.Context) {
 	if !ctx.Repo.Repository.CanEnableEditor() || ctx.Repo.IsViewCommit {
@@ -104,6 +112,39 @@ func getRepoPrivate(ctx *context.Context) bool {
 	}
 }
 
+// getTemplateRepository returns the template repository with the given ID if
+// the signed in user is allowed to generate repositories from it.
+func getTemplateRepository(ctx *context.Context, templateID int64) *models.Repository {
+	templateRepo, err := models.GetRepositoryByID(templateID)
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryByID", nil)
+		} else {
+			ctx.ServerError("GetRepositoryByID", err)
+		}
+		return nil
+	}
+
+	perm, err := models.GetUserRepoPermission(templateRepo, ctx.User)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return nil
+	}
+
+	if !templateRepo.IsTemplate || !perm.CanRead(models.UnitTypeCode) {
+		ctx.NotFound("getTemplateRepository", nil)
+		return nil
+	}
+
+	if err = templateRepo.GetOwner(); err != nil {
+		ctx.ServerError("GetOwner", err)
+		return nil
+	}
+
+	ctx.Data["TemplateRepo"] = templateRepo
+	return templateRepo
+}
+
 // Create render creating repository page
 func Create(ctx *context.Context) {
 	if !ctx.User.CanCreateRepo() {
@@ -126,6 +167,16 @@ func Create(ctx *context.Context) {
 	}
 	ctx.Data["ContextUser"] = ctxUser
 
+	if templateID := ctx.QueryInt64("template_id"); templateID > 0 {
+		templateRepo := getTemplateRepository(ctx, templateID)
+		if ctx.Written() {
+			return
+		}
+		ctx.Data["repo_template"] = templateRepo.ID
+		ctx.Data["description"] = templateRepo.Description
+		ctx.Data["git_content"] = true
+	}
+
 	ctx.HTML(200, tplCreate)
 }
 
@@ -161,20 +212,48 @@ func CreatePost(ctx *context.Context, form auth.CreateRepoForm) {
 	}
 	ctx.Data["ContextUser"] = ctxUser
 
+	var templateRepo *models.Repository
+	if form.RepoTemplate > 0 {
+		templateRepo = getTemplateRepository(ctx, form.RepoTemplate)
+		if ctx.Written() {
+			return
+		}
+	}
+
 	if ctx.HasError() {
 		ctx.HTML(200, tplCreate)
 		return
 	}
 
-	repo, err := models.CreateRepository(ctx.User, ctxUser, models.CreateRepoOptions{
-		Name:        form.RepoName,
-		Description: form.Description,
-		Gitignores:  form.Gitignores,
-		License:     form.License,
-		Readme:      form.Readme,
-		IsPrivate:   form.Private || setting.Repository.ForcePrivate,
-		AutoInit:    form.AutoInit,
-	})
+	var repo *models.Repository
+	var err error
+	if templateRepo != nil {
+		opts := models.GenerateRepoOptions{
+			Name:        form.RepoName,
+			Description: form.Description,
+			Private:     form.Private || setting.Repository.ForcePrivate,
+			GitContent:  form.GitContent,
+			Topics:      form.Topics,
+			Webhooks:    form.Webhooks,
+			IssueLabels: form.Labels,
+		}
+		if !opts.IsValid() {
+			ctx.RenderWithErr(ctx.Tr("repo.template.one_item"), tplCreate, &form)
+			return
+		}
+
+		repo, err = models.GenerateRepository(ctx.User, ctxUser, templateRepo, opts)
+	} else {
+		repo, err = models.CreateRepository(ctx.User, ctxUser, models.CreateRepoOptions{
+			Name:        form.RepoName,
+			Description: form.Description,
+			Gitignores:  form.Gitignores,
+			License:     form.License,
+			Readme:      form.Readme,
+			IsPrivate:   form.Private || setting.Repository.ForcePrivate,
+			AutoInit:    form.AutoInit,
+		})
+	}
 	if err == nil {
 		log.Trace("Repository created [%d]: %s/%s", repo.ID, ctxUser.Name, repo.Name)
 		ctx.Redirect(setting.AppSubURL + "/" + ctxUser.Name + "/" + repo.Name)
@@ -282,15 +361,21 @@ func MigratePost(ctx *context.Context, form auth.MigrateRepoForm) {
 // Action response for actions to a repository
 func Action(ctx *context.Context) {
 	var err error
-	switch ctx.Params(":action") {
-	case "watch":
-		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
-	case "unwatch":
-		err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
-	case "star":
-		err = models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, true)
-	case "unstar":
-		err = models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, false)
+	switch action := ctx.Params(":action"); action {
+	case "watch", "unwatch":
+		watch := action == "watch"
+		if models.IsWatching(ctx.User.ID, ctx.Repo.Repository.ID) != watch {
+			if err = models.WatchRepo(ctx.User.ID, ctx.Repo.Repository.ID, watch); err == nil {
+				notification.NotifyWatchRepository(ctx.User, ctx.Repo.Repository, watch)
+			}
+		}
+	case "star", "unstar":
+		star := action == "star"
+		if models.IsStaring(ctx.User.ID, ctx.Repo.Repository.ID) != star {
+			if err = models.StarRepo(ctx.User.ID, ctx.Repo.Repository.ID, star); err == nil {
+				notification.NotifyStarRepository(ctx.User, ctx.Repo.Repository, star)
+			}
+		}
 	case "desc": // FIXME: this is not used
 		if !ctx.Repo.IsOwner() {
 			ctx.Error(404)
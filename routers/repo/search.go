@@ -5,11 +5,12 @@
 package repo
 
 import (
-	"path"
 	"strings"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/indexer"
 	"code.gitea.io/gitea/modules/search"
 	"code.gitea.io/gitea/modules/setting"
 
@@ -29,17 +30,29 @@ func Search(ctx *context.Context) {
 	if page <= 0 {
 		page = 1
 	}
-	total, searchResults, err := search.PerformSearch([]int64{ctx.Repo.Repository.ID},
-		keyword, page, setting.UI.RepoSearchPagingNum)
+	ref := ctx.Query("ref")
+	refs, err := models.GetIndexedRefs(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetIndexedRefs", err)
+		return
+	}
+
+	total, searchResults, _, err := search.PerformSearch(&indexer.RepoSearchOptions{
+		RepoIDs:  []int64{ctx.Repo.Repository.ID},
+		Ref:      ref,
+		Keyword:  keyword,
+		Page:     page,
+		PageSize: setting.UI.RepoSearchPagingNum,
+	})
 	if err != nil {
 		ctx.ServerError("SearchResults", err)
 		return
 	}
 	ctx.Data["Keyword"] = keyword
+	ctx.Data["Ref"] = ref
+	ctx.Data["IndexedRefs"] = refs
 	pager := paginater.New(total, setting.UI.RepoSearchPagingNum, page, 5)
 	ctx.Data["Page"] = pager
-	ctx.Data["SourcePath"] = setting.AppSubURL + "/" +
-		path.Join(ctx.Repo.Repository.Owner.Name, ctx.Repo.Repository.Name, "src", "branch", ctx.Repo.Repository.DefaultBranch)
 	ctx.Data["SearchResults"] = searchResults
 	ctx.Data["RequireHighlightJS"] = true
 	ctx.Data["PageIsViewCode"] = true
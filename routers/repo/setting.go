@@ -36,6 +36,16 @@ const (
 func Settings(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
+
+	pushMirrors, err := models.GetPushMirrorsByRepoID(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetPushMirrorsByRepoID", err)
+		return
+	}
+	ctx.Data["PushMirrors"] = pushMirrors
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+	ctx.Data["IndexerRefs"] = strings.Join(ctx.Repo.Repository.IndexerRefs, ", ")
+
 	ctx.HTML(200, tplSettingsOptions)
 }
 
@@ -46,6 +56,15 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 
 	repo := ctx.Repo.Repository
 
+	pushMirrors, err := models.GetPushMirrorsByRepoID(repo.ID)
+	if err != nil {
+		ctx.ServerError("GetPushMirrorsByRepoID", err)
+		return
+	}
+	ctx.Data["PushMirrors"] = pushMirrors
+	ctx.Data["IsRepoIndexerEnabled"] = setting.Indexer.RepoIndexerEnabled
+	ctx.Data["IndexerRefs"] = strings.Join(repo.IndexerRefs, ", ")
+
 	switch ctx.Query("action") {
 	case "update":
 		if ctx.HasError() {
@@ -95,6 +114,7 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 
 		visibilityChanged := repo.IsPrivate != form.Private
 		repo.IsPrivate = form.Private
+		repo.IsTemplate = form.Template
 		if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
 			ctx.ServerError("UpdateRepository", err)
 			return
@@ -150,6 +170,59 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		ctx.Flash.Info(ctx.Tr("repo.settings.mirror_sync_in_progress"))
 		ctx.Redirect(repo.Link() + "/settings")
 
+	case "push-mirror-add":
+		interval, err := time.ParseDuration(form.PushMirrorInterval)
+		if err != nil || (interval != 0 && interval < setting.Mirror.MinInterval) {
+			ctx.Data["Err_PushMirrorInterval"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.mirror_interval_invalid"), tplSettingsOptions, &form)
+			return
+		}
+
+		address, err := utils.ComposeRemoteAddress(form.PushMirrorAddress, form.PushMirrorUsername, form.PushMirrorPassword)
+		if err != nil {
+			ctx.Data["Err_PushMirrorAddress"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.push_mirror_address_invalid"), tplSettingsOptions, &form)
+			return
+		}
+
+		m, err := models.CreatePushMirror(repo, address, interval)
+		if err != nil {
+			ctx.ServerError("CreatePushMirror", err)
+			return
+		}
+		log.Trace("Push mirror added: %s -> %s", repo.FullName(), m.Address())
+
+		go models.PushMirrorQueue.Add(m.ID)
+		ctx.Flash.Success(ctx.Tr("repo.settings.push_mirror_add_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "push-mirror-remove":
+		m, err := models.GetPushMirrorByID(repo.ID, form.PushMirrorID)
+		if err != nil {
+			ctx.NotFoundOrServerError("GetPushMirrorByID", models.IsErrPushMirrorNotExist, err)
+			return
+		}
+
+		if err = models.DeletePushMirror(m); err != nil {
+			ctx.ServerError("DeletePushMirror", err)
+			return
+		}
+		log.Trace("Push mirror removed: %s -> %s", repo.FullName(), m.Address())
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.push_mirror_remove_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "push-mirror-sync":
+		m, err := models.GetPushMirrorByID(repo.ID, form.PushMirrorID)
+		if err != nil {
+			ctx.NotFoundOrServerError("GetPushMirrorByID", models.IsErrPushMirrorNotExist, err)
+			return
+		}
+
+		go models.PushMirrorQueue.Add(m.ID)
+		ctx.Flash.Info(ctx.Tr("repo.settings.mirror_sync_in_progress"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "advanced":
 		var units []models.RepoUnit
 
@@ -232,6 +305,14 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 			})
 		}
 
+		if form.EnableProjects {
+			units = append(units, models.RepoUnit{
+				RepoID: repo.ID,
+				Type:   models.UnitTypeProjects,
+				Config: new(models.UnitConfig),
+			})
+		}
+
 		if err := models.UpdateRepositoryUnits(repo, units); err != nil {
 			ctx.ServerError("UpdateRepositoryUnits", err)
 			return
@@ -241,6 +322,26 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "indexer":
+		if !setting.Indexer.RepoIndexerEnabled {
+			ctx.NotFound("", nil)
+			return
+		}
+
+		if err := repo.UpdateIndexerRefs(strings.Split(form.IndexerRefs, ",")); err != nil {
+			if models.IsErrInvalidIndexerRefPattern(err) {
+				ctx.Data["Err_IndexerRefs"] = true
+				ctx.RenderWithErr(ctx.Tr("repo.settings.indexer_refs_invalid", err.(models.ErrInvalidIndexerRefPattern).Pattern), tplSettingsOptions, &form)
+			} else {
+				ctx.ServerError("UpdateIndexerRefs", err)
+			}
+			return
+		}
+		log.Trace("Repository indexer settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "admin":
 		if !ctx.User.IsAdmin {
 			ctx.Error(403)
@@ -353,6 +454,36 @@ func SettingsPost(ctx *context.Context, form auth.RepoSettingForm) {
 		ctx.Flash.Success(ctx.Tr("repo.settings.wiki_deletion_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
+	case "archive":
+		if !ctx.Repo.IsOwner() || repo.IsMirror {
+			ctx.Error(404)
+			return
+		}
+
+		if err := repo.SetArchiveRepoState(true); err != nil {
+			ctx.ServerError("SetArchiveRepoState", err)
+			return
+		}
+		log.Trace("Repository archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.archive_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "unarchive":
+		if !ctx.Repo.IsOwner() {
+			ctx.Error(404)
+			return
+		}
+
+		if err := repo.SetArchiveRepoState(false); err != nil {
+			ctx.ServerError("SetArchiveRepoState", err)
+			return
+		}
+		log.Trace("Repository unarchived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.unarchive_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
 	default:
 		ctx.NotFound("", nil)
 	}
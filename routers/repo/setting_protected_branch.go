@@ -14,6 +14,7 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/setting"
 )
 
@@ -136,6 +137,7 @@ func SettingsProtectedBranch(c *context.Context) {
 		c.Data["merge_whitelist_teams"] = strings.Join(base.Int64sToStrings(protectBranch.MergeWhitelistTeamIDs), ",")
 	}
 
+	c.Data["status_check_contexts"] = strings.Join(protectBranch.StatusCheckContexts, "\n")
 	c.Data["Branch"] = protectBranch
 	c.HTML(200, tplProtectedBranch)
 }
@@ -157,6 +159,7 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 	}
 
 	if f.Protected {
+		isNew := protectBranch == nil
 		if protectBranch == nil {
 			// No options found, create defaults.
 			protectBranch = &models.ProtectedBranch{
@@ -180,11 +183,25 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 		if strings.TrimSpace(f.MergeWhitelistTeams) != "" {
 			mergeWhitelistTeams, _ = base.StringsToInt64s(strings.Split(f.MergeWhitelistTeams, ","))
 		}
+		if f.RequiredApprovals < 0 {
+			ctx.Flash.Error(ctx.Tr("repo.settings.protect_invalid_required_approvals"))
+			ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, branch))
+			return
+		}
+		protectBranch.RequiredApprovals = f.RequiredApprovals
+		protectBranch.EnableStatusCheck = f.EnableStatusCheck
+		protectBranch.StatusCheckContexts = make([]string, 0, 2)
+		for _, statusContext := range strings.Split(f.StatusCheckContexts, "\n") {
+			if statusContext = strings.TrimSpace(statusContext); len(statusContext) > 0 {
+				protectBranch.StatusCheckContexts = append(protectBranch.StatusCheckContexts, statusContext)
+			}
+		}
 		err = models.UpdateProtectBranch(ctx.Repo.Repository, protectBranch, whitelistUsers, whitelistTeams, mergeWhitelistUsers, mergeWhitelistTeams)
 		if err != nil {
 			ctx.ServerError("UpdateProtectBranch", err)
 			return
 		}
+		notification.NotifyUpdateProtectedBranch(ctx.User, ctx.Repo.Repository, protectBranch, isNew)
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_protect_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, branch))
 	} else {
@@ -193,6 +210,7 @@ func SettingsProtectedBranchPost(ctx *context.Context, f auth.ProtectBranchForm)
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			notification.NotifyDeleteProtectedBranch(ctx.User, ctx.Repo.Repository, protectBranch)
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
@@ -24,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/templates"
 
 	"github.com/Unknwon/paginater"
@@ -137,7 +138,7 @@ func renderDirectory(ctx *context.Context, treeLink string) {
 	ctx.Data["LatestCommitStatus"] = models.CalcCommitStatus(statuses)
 
 	// Check permission to add or upload new file.
-	if ctx.Repo.CanWrite(models.UnitTypeCode) && ctx.Repo.IsViewBranch {
+	if ctx.Repo.CanWrite(models.UnitTypeCode) && ctx.Repo.IsViewBranch && !ctx.Repo.Repository.IsArchived {
 		ctx.Data["CanAddFile"] = true
 		ctx.Data["CanUploadFile"] = setting.Repository.Upload.Enabled
 	}
@@ -177,7 +178,7 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 				oid := strings.TrimPrefix(splitLines[1], models.LFSMetaFileOidPrefix)
 				size, err := strconv.ParseInt(strings.TrimPrefix(splitLines[2], "size "), 10, 64)
 				if len(oid) == 64 && err == nil {
-					contentStore := &lfs.ContentStore{BasePath: setting.LFS.ContentPath}
+					contentStore := &lfs.ContentStore{ObjectStorage: storage.LFS}
 					meta := &models.LFSMetaObject{Oid: oid}
 					if contentStore.Exists(meta) {
 						ctx.Data["IsTextFile"] = false
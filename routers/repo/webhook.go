@@ -23,16 +23,17 @@ import (
 )
 
 const (
-	tplHooks      base.TplName = "repo/settings/webhook/base"
-	tplHookNew    base.TplName = "repo/settings/webhook/new"
-	tplOrgHookNew base.TplName = "org/settings/hook_new"
+	tplHooks        base.TplName = "repo/settings/webhook/base"
+	tplHookNew      base.TplName = "repo/settings/webhook/new"
+	tplOrgHookNew   base.TplName = "org/settings/hook_new"
+	tplAdminHookNew base.TplName = "admin/hook_new"
 )
 
 // Webhooks render web hooks list page
 func Webhooks(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings.hooks")
 	ctx.Data["PageIsSettingsHooks"] = true
-	ctx.Data["BaseLink"] = ctx.Repo.RepoLink
+	ctx.Data["BaseLink"] = ctx.Repo.RepoLink + "/settings/hooks"
 	ctx.Data["Description"] = ctx.Tr("repo.settings.hooks_desc", "https://docs.gitea.io/en-us/webhooks/")
 
 	ws, err := models.GetWebhooksByRepoID(ctx.Repo.Repository.ID)
@@ -46,18 +47,22 @@ func Webhooks(ctx *context.Context) {
 }
 
 type orgRepoCtx struct {
-	OrgID       int64
-	RepoID      int64
+	OrgID           int64
+	RepoID          int64
+	IsAdmin         bool
+	IsSystemWebhook bool
+	// Link is the link of the webhooks settings page
 	Link        string
 	NewTemplate base.TplName
 }
 
-// getOrgRepoCtx determines whether this is a repo context or organization context.
+// getOrgRepoCtx determines whether this is a repo, organization or site
+// administration context.
 func getOrgRepoCtx(ctx *context.Context) (*orgRepoCtx, error) {
 	if len(ctx.Repo.RepoLink) > 0 {
 		return &orgRepoCtx{
 			RepoID:      ctx.Repo.Repository.ID,
-			Link:        ctx.Repo.RepoLink,
+			Link:        ctx.Repo.RepoLink + "/settings/hooks",
 			NewTemplate: tplHookNew,
 		}, nil
 	}
@@ -65,11 +70,21 @@ func getOrgRepoCtx(ctx *context.Context) (*orgRepoCtx, error) {
 	if len(ctx.Org.OrgLink) > 0 {
 		return &orgRepoCtx{
 			OrgID:       ctx.Org.Organization.ID,
-			Link:        ctx.Org.OrgLink,
+			Link:        ctx.Org.OrgLink + "/settings/hooks",
 			NewTemplate: tplOrgHookNew,
 		}, nil
 	}
 
+	if kind := ctx.Params(":kind"); len(kind) > 0 && ctx.IsSigned && ctx.User.IsAdmin {
+		ctx.Data["PageIsAdminHooks"] = true
+		return &orgRepoCtx{
+			IsAdmin:         true,
+			IsSystemWebhook: kind == "system",
+			Link:            setting.AppSubURL + "/admin/hooks/" + kind,
+			NewTemplate:     tplAdminHookNew,
+		}, nil
+	}
+
 	return nil, errors.New("Unable to set OrgRepo context")
 }
 
@@ -100,11 +115,16 @@ func WebhooksNew(ctx *context.Context) {
 	if ctx.Written() {
 		return
 	}
-	if hookType == "discord" {
+	switch hookType {
+	case "discord":
 		ctx.Data["DiscordHook"] = map[string]interface{}{
 			"Username": "Gitea",
 			"IconURL":  setting.AppURL + "img/favicon.png",
 		}
+	case "matrix":
+		ctx.Data["MatrixHook"] = &models.MatrixMeta{MessageType: models.MatrixMessageTypeNotice}
+	case "custom":
+		ctx.Data["CustomHook"] = &models.CustomMeta{}
 	}
 	ctx.Data["BaseLink"] = orCtx.Link
 
@@ -118,15 +138,28 @@ func ParseHookEvent(form auth.WebhookForm) *models.HookEvent {
 		SendEverything: form.SendEverything(),
 		ChooseEvents:   form.ChooseEvents(),
 		HookEvents: models.HookEvents{
-			Create:       form.Create,
-			Delete:       form.Delete,
-			Fork:         form.Fork,
-			Issues:       form.Issues,
-			IssueComment: form.IssueComment,
-			Release:      form.Release,
-			Push:         form.Push,
-			PullRequest:  form.PullRequest,
-			Repository:   form.Repository,
+			Create:               form.Create,
+			Delete:               form.Delete,
+			Fork:                 form.Fork,
+			Issues:               form.Issues,
+			IssueAssign:          form.IssueAssign,
+			IssueLabel:           form.IssueLabel,
+			IssueMilestone:       form.IssueMilestone,
+			IssueComment:         form.IssueComment,
+			Release:              form.Release,
+			Push:                 form.Push,
+			PullRequest:          form.PullRequest,
+			PullRequestAssign:    form.PullRequestAssign,
+			PullRequestLabel:     form.PullRequestLabel,
+			PullRequestMilestone: form.PullRequestMilestone,
+			PullRequestReview:    form.PullRequestReview,
+			PullRequestSync:      form.PullRequestSync,
+			Repository:           form.Repository,
+			Wiki:                 form.Wiki,
+			Status:               form.Status,
+			Star:                 form.Star,
+			Watch:                form.Watch,
+			BranchProtection:     form.BranchProtection,
 		},
 	}
 }
@@ -157,14 +190,15 @@ func WebHooksNewPost(ctx *context.Context, form auth.NewWebhookForm) {
 	}
 
 	w := &models.Webhook{
-		RepoID:       orCtx.RepoID,
-		URL:          form.PayloadURL,
-		ContentType:  contentType,
-		Secret:       form.Secret,
-		HookEvent:    ParseHookEvent(form.WebhookForm),
-		IsActive:     form.Active,
-		HookTaskType: models.GITEA,
-		OrgID:        orCtx.OrgID,
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     contentType,
+		Secret:          form.Secret,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.GITEA,
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -175,7 +209,7 @@ func WebHooksNewPost(ctx *context.Context, form auth.NewWebhookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
-	ctx.Redirect(orCtx.Link + "/settings/hooks")
+	ctx.Redirect(orCtx.Link)
 }
 
 // GogsHooksNewPost response for creating webhook
@@ -204,14 +238,15 @@ func GogsHooksNewPost(ctx *context.Context, form auth.NewGogshookForm) {
 	}
 
 	w := &models.Webhook{
-		RepoID:       orCtx.RepoID,
-		URL:          form.PayloadURL,
-		ContentType:  contentType,
-		Secret:       form.Secret,
-		HookEvent:    ParseHookEvent(form.WebhookForm),
-		IsActive:     form.Active,
-		HookTaskType: models.GOGS,
-		OrgID:        orCtx.OrgID,
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     contentType,
+		Secret:          form.Secret,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.GOGS,
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -222,7 +257,7 @@ func GogsHooksNewPost(ctx *context.Context, form auth.NewGogshookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
-	ctx.Redirect(orCtx.Link + "/settings/hooks")
+	ctx.Redirect(orCtx.Link)
 }
 
 // DiscordHooksNewPost response for creating discord hook
@@ -253,14 +288,15 @@ func DiscordHooksNewPost(ctx *context.Context, form auth.NewDiscordHookForm) {
 	}
 
 	w := &models.Webhook{
-		RepoID:       orCtx.RepoID,
-		URL:          form.PayloadURL,
-		ContentType:  models.ContentTypeJSON,
-		HookEvent:    ParseHookEvent(form.WebhookForm),
-		IsActive:     form.Active,
-		HookTaskType: models.DISCORD,
-		Meta:         string(meta),
-		OrgID:        orCtx.OrgID,
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.DISCORD,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -271,7 +307,7 @@ func DiscordHooksNewPost(ctx *context.Context, form auth.NewDiscordHookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
-	ctx.Redirect(orCtx.Link + "/settings/hooks")
+	ctx.Redirect(orCtx.Link)
 }
 
 // DingtalkHooksNewPost response for creating dingtalk hook
@@ -293,14 +329,162 @@ func DingtalkHooksNewPost(ctx *context.Context, form auth.NewDingtalkHookForm) {
 	}
 
 	w := &models.Webhook{
-		RepoID:       orCtx.RepoID,
-		URL:          form.PayloadURL,
-		ContentType:  models.ContentTypeJSON,
-		HookEvent:    ParseHookEvent(form.WebhookForm),
-		IsActive:     form.Active,
-		HookTaskType: models.DINGTALK,
-		Meta:         "",
-		OrgID:        orCtx.OrgID,
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.DINGTALK,
+		Meta:            "",
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// MSTeamsHooksNewPost response for creating MS Teams hook
+func MSTeamsHooksNewPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = "msteams"
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+	ctx.Data["BaseLink"] = orCtx.Link
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.MSTEAMS,
+		Meta:            "",
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// TelegramHooksNewPost response for creating telegram hook
+func TelegramHooksNewPost(ctx *context.Context, form auth.NewTelegramHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = "telegram"
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+	ctx.Data["BaseLink"] = orCtx.Link
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	telegram := &models.TelegramMeta{
+		BotToken: strings.TrimSpace(form.BotToken),
+		ChatID:   strings.TrimSpace(form.ChatID),
+	}
+	meta, err := json.Marshal(telegram)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             telegram.URL(),
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.TELEGRAM,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// MatrixHooksNewPost response for creating matrix hook
+func MatrixHooksNewPost(ctx *context.Context, form auth.NewMatrixHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = "matrix"
+	ctx.Data["MatrixHook"] = &models.MatrixMeta{MessageType: models.MatrixMessageTypeNotice}
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+	ctx.Data["BaseLink"] = orCtx.Link
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	matrix := newMatrixMeta(form)
+	meta, err := json.Marshal(matrix)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             matrix.URL(),
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.MATRIX,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -311,7 +495,105 @@ func DingtalkHooksNewPost(ctx *context.Context, form auth.NewDingtalkHookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
-	ctx.Redirect(orCtx.Link + "/settings/hooks")
+	ctx.Redirect(orCtx.Link)
+}
+
+func newMatrixMeta(form auth.NewMatrixHookForm) *models.MatrixMeta {
+	msgType := form.MessageType
+	if !models.IsValidMatrixMessageType(msgType) {
+		msgType = models.MatrixMessageTypeNotice
+	}
+	return &models.MatrixMeta{
+		HomeserverURL: strings.TrimSpace(form.HomeserverURL),
+		Room:          strings.TrimSpace(form.RoomID),
+		AccessToken:   strings.TrimSpace(form.AccessToken),
+		MessageType:   msgType,
+	}
+}
+
+// CustomHooksNewPost response for creating custom hook
+func CustomHooksNewPost(ctx *context.Context, form auth.NewCustomHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksNew"] = true
+	ctx.Data["Webhook"] = models.Webhook{HookEvent: &models.HookEvent{}}
+	ctx.Data["HookType"] = "custom"
+	ctx.Data["CustomHook"] = &models.CustomMeta{}
+
+	orCtx, err := getOrgRepoCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getOrgRepoCtx", err)
+		return
+	}
+	ctx.Data["BaseLink"] = orCtx.Link
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	contentType := models.ContentTypeJSON
+	if models.HookContentType(form.ContentType) == models.ContentTypeForm {
+		contentType = models.ContentTypeForm
+	}
+
+	custom := &models.CustomMeta{
+		Template:       form.Template,
+		EventTemplates: form.EventTemplates(),
+	}
+	w := &models.Webhook{
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     contentType,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.CUSTOM,
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
+	}
+	if !checkCustomHookTemplates(ctx, orCtx, w, custom) {
+		return
+	}
+
+	meta, err := json.Marshal(custom)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+	w.Meta = string(meta)
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.CreateWebhook(w); err != nil {
+		ctx.ServerError("CreateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
+	ctx.Redirect(orCtx.Link)
+}
+
+// checkCustomHookTemplates checks that the templates of a custom hook can be
+// parsed, otherwise renders the form again with the error
+func checkCustomHookTemplates(ctx *context.Context, orCtx *orgRepoCtx, w *models.Webhook, custom *models.CustomMeta) bool {
+	err := custom.Validate()
+	if err == nil {
+		return true
+	} else if !models.IsErrInvalidWebhookTemplate(err) {
+		ctx.ServerError("Validate", err)
+		return false
+	}
+
+	invalid := err.(models.ErrInvalidWebhookTemplate)
+	name := ctx.Tr("repo.settings.custom_template")
+	if invalid.Event != "" {
+		name = ctx.Tr("repo.settings.event_" + string(invalid.Event))
+	}
+	ctx.Data["Err_Template"] = true
+	ctx.Data["Webhook"] = w
+	ctx.Data["CustomHook"] = custom
+	ctx.RenderWithErr(ctx.Tr("repo.settings.custom_template_invalid", name, invalid.Reason), orCtx.NewTemplate, nil)
+	return false
 }
 
 // SlackHooksNewPost response for creating slack hook
@@ -334,7 +616,7 @@ func SlackHooksNewPost(ctx *context.Context, form auth.NewSlackHookForm) {
 
 	if form.HasInvalidChannel() {
 		ctx.Flash.Error(ctx.Tr("repo.settings.add_webhook.invalid_channel_name"))
-		ctx.Redirect(orCtx.Link + "/settings/hooks/slack/new")
+		ctx.Redirect(orCtx.Link + "/slack/new")
 		return
 	}
 
@@ -350,14 +632,15 @@ func SlackHooksNewPost(ctx *context.Context, form auth.NewSlackHookForm) {
 	}
 
 	w := &models.Webhook{
-		RepoID:       orCtx.RepoID,
-		URL:          form.PayloadURL,
-		ContentType:  models.ContentTypeJSON,
-		HookEvent:    ParseHookEvent(form.WebhookForm),
-		IsActive:     form.Active,
-		HookTaskType: models.SLACK,
-		Meta:         string(meta),
-		OrgID:        orCtx.OrgID,
+		RepoID:          orCtx.RepoID,
+		URL:             form.PayloadURL,
+		ContentType:     models.ContentTypeJSON,
+		HookEvent:       ParseHookEvent(form.WebhookForm),
+		IsActive:        form.Active,
+		HookTaskType:    models.SLACK,
+		Meta:            string(meta),
+		OrgID:           orCtx.OrgID,
+		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
@@ -368,7 +651,7 @@ func SlackHooksNewPost(ctx *context.Context, form auth.NewSlackHookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
-	ctx.Redirect(orCtx.Link + "/settings/hooks")
+	ctx.Redirect(orCtx.Link)
 }
 
 func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
@@ -384,8 +667,13 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 	var w *models.Webhook
 	if orCtx.RepoID > 0 {
 		w, err = models.GetWebhookByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
-	} else {
+	} else if orCtx.OrgID > 0 {
 		w, err = models.GetWebhookByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	} else {
+		w, err = models.GetSystemOrDefaultWebhook(ctx.ParamsInt64(":id"))
+		if err == nil && w.IsSystemWebhook != orCtx.IsSystemWebhook {
+			err = models.ErrWebhookNotExist{ID: w.ID}
+		}
 	}
 	if err != nil {
 		if models.IsErrWebhookNotExist(err) {
@@ -402,6 +690,12 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *models.Webhook) {
 		ctx.Data["SlackHook"] = w.GetSlackHook()
 	case models.DISCORD:
 		ctx.Data["DiscordHook"] = w.GetDiscordHook()
+	case models.TELEGRAM:
+		ctx.Data["TelegramHook"] = w.GetTelegramHook()
+	case models.MATRIX:
+		ctx.Data["MatrixHook"] = w.GetMatrixHook()
+	case models.CUSTOM:
+		ctx.Data["CustomHook"] = w.GetCustomHook()
 	}
 
 	ctx.Data["History"], err = w.History(1)
@@ -462,7 +756,7 @@ func WebHooksEditPost(ctx *context.Context, form auth.NewWebhookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
-	ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d", orCtx.Link, w.ID))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
 // GogsHooksEditPost response for editing gogs hook
@@ -501,7 +795,7 @@ func GogsHooksEditPost(ctx *context.Context, form auth.NewGogshookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
-	ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d", orCtx.Link, w.ID))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
 // SlackHooksEditPost response for editing slack hook
@@ -523,7 +817,7 @@ func SlackHooksEditPost(ctx *context.Context, form auth.NewSlackHookForm) {
 
 	if form.HasInvalidChannel() {
 		ctx.Flash.Error(ctx.Tr("repo.settings.add_webhook.invalid_channel_name"))
-		ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d", orCtx.Link, w.ID))
+		ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 		return
 	}
 
@@ -551,7 +845,7 @@ func SlackHooksEditPost(ctx *context.Context, form auth.NewSlackHookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
-	ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d", orCtx.Link, w.ID))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
 // DiscordHooksEditPost response for editing discord hook
@@ -593,7 +887,7 @@ func DiscordHooksEditPost(ctx *context.Context, form auth.NewDiscordHookForm) {
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
-	ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d", orCtx.Link, w.ID))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
 // DingtalkHooksEditPost response for editing discord hook
@@ -625,7 +919,174 @@ func DingtalkHooksEditPost(ctx *context.Context, form auth.NewDingtalkHookForm)
 	}
 
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
-	ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d", orCtx.Link, w.ID))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// MSTeamsHooksEditPost response for editing MS Teams hook
+func MSTeamsHooksEditPost(ctx *context.Context, form auth.NewMSTeamsHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	w.URL = form.PayloadURL
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// TelegramHooksEditPost response for editing telegram hook
+func TelegramHooksEditPost(ctx *context.Context, form auth.NewTelegramHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	telegram := &models.TelegramMeta{
+		BotToken: strings.TrimSpace(form.BotToken),
+		ChatID:   strings.TrimSpace(form.ChatID),
+	}
+	meta, err := json.Marshal(telegram)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w.URL = telegram.URL()
+	w.Meta = string(meta)
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// MatrixHooksEditPost response for editing matrix hook
+func MatrixHooksEditPost(ctx *context.Context, form auth.NewMatrixHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	matrix := newMatrixMeta(form)
+	meta, err := json.Marshal(matrix)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+
+	w.URL = matrix.URL()
+	w.Meta = string(meta)
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
+// CustomHooksEditPost response for editing custom hook
+func CustomHooksEditPost(ctx *context.Context, form auth.NewCustomHookForm) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings")
+	ctx.Data["PageIsSettingsHooks"] = true
+	ctx.Data["PageIsSettingsHooksEdit"] = true
+
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Webhook"] = w
+
+	if ctx.HasError() {
+		ctx.HTML(200, orCtx.NewTemplate)
+		return
+	}
+
+	contentType := models.ContentTypeJSON
+	if models.HookContentType(form.ContentType) == models.ContentTypeForm {
+		contentType = models.ContentTypeForm
+	}
+
+	custom := &models.CustomMeta{
+		Template:       form.Template,
+		EventTemplates: form.EventTemplates(),
+	}
+	w.URL = form.PayloadURL
+	w.ContentType = contentType
+	w.HookEvent = ParseHookEvent(form.WebhookForm)
+	w.IsActive = form.Active
+	if !checkCustomHookTemplates(ctx, orCtx, w, custom) {
+		return
+	}
+
+	meta, err := json.Marshal(custom)
+	if err != nil {
+		ctx.ServerError("Marshal", err)
+		return
+	}
+	w.Meta = string(meta)
+	if err := w.UpdateEvent(); err != nil {
+		ctx.ServerError("UpdateEvent", err)
+		return
+	} else if err := models.UpdateWebhook(w); err != nil {
+		ctx.ServerError("UpdateWebhook", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
 // TestWebhook test if web hook is work fine
@@ -684,6 +1145,27 @@ func TestWebhook(ctx *context.Context) {
 	}
 }
 
+// RedeliverWebhook delivers again the payload of a past delivery of a webhook
+func RedeliverWebhook(ctx *context.Context) {
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	t, err := models.GetHookTaskByHookID(w.ID, ctx.ParamsInt64(":delivery"))
+	if err != nil {
+		ctx.NotFoundOrServerError("GetHookTaskByHookID", models.IsErrHookTaskNotExist, err)
+		return
+	}
+	if _, err = models.RedeliverHookTask(w, t); err != nil {
+		ctx.ServerError("RedeliverHookTask", err)
+		return
+	}
+
+	ctx.Flash.Info(ctx.Tr("repo.settings.webhook.redelivery_success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
 // DeleteWebhook delete a webhook
 func DeleteWebhook(ctx *context.Context) {
 	if err := models.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.QueryInt64("id")); err != nil {
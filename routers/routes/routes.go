@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -20,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/options"
 	"code.gitea.io/gitea/modules/public"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers"
@@ -45,6 +48,60 @@ import (
 	"gopkg.in/macaron.v1"
 )
 
+// storageHandler serves the objects of a storage below prefix, redirecting
+// to the storage itself if it is configured to serve them directly.
+// The storage is looked up on each request as it is initialized after the
+// routes are set up.
+func storageHandler(prefix string, expiresAfter time.Duration, getStorage func() (storage.ObjectStorage, bool)) macaron.Handler {
+	return func(ctx *macaron.Context) {
+		if ctx.Req.Method != "GET" && ctx.Req.Method != "HEAD" {
+			return
+		}
+		if !strings.HasPrefix(ctx.Req.URL.Path, prefix) {
+			return
+		}
+		rPath := strings.TrimPrefix(ctx.Req.URL.Path, prefix)
+		if rPath == "" {
+			return
+		}
+		objStore, serveDirect := getStorage()
+
+		if serveDirect {
+			if u, err := objStore.URL(rPath, path.Base(rPath)); err == nil {
+				http.Redirect(ctx.Resp, ctx.Req.Request, u.String(), http.StatusFound)
+				return
+			}
+		}
+
+		fi, err := objStore.Stat(rPath)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				log.Error(4, "Unable to stat %s%s: %v", prefix, rPath, err)
+				ctx.Resp.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		} else if fi.IsDir() {
+			return
+		}
+		obj, err := objStore.Open(rPath)
+		if err != nil {
+			log.Error(4, "Unable to open %s%s: %v", prefix, rPath, err)
+			ctx.Resp.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		defer obj.Close()
+
+		ctx.Resp.Header().Set("Expires", time.Now().Add(expiresAfter).UTC().Format(http.TimeFormat))
+		tag := public.GenerateETag(strconv.FormatInt(fi.Size(), 10), fi.Name(), fi.ModTime().UTC().Format(http.TimeFormat))
+		ctx.Resp.Header().Set("ETag", tag)
+		if ctx.Req.Header.Get("If-None-Match") == tag {
+			ctx.Resp.WriteHeader(http.StatusNotModified)
+			return
+		}
+		http.ServeContent(ctx.Resp, ctx.Req.Request, path.Base(rPath), fi.ModTime(), obj)
+	}
+}
+
 // NewMacaron initializes Macaron instance.
 func NewMacaron() *macaron.Macaron {
 	gob.Register(&u2f.Challenge{})
@@ -54,7 +111,16 @@ func NewMacaron() *macaron.Macaron {
 	}
 	m.Use(macaron.Recovery())
 	if setting.EnableGzip {
-		m.Use(gzip.Gziper())
+		gziper := gzip.Gziper()
+		m.Use(func(ctx *macaron.Context) {
+			// an event stream must reach the clients as soon as it is flushed
+			if ctx.Req.Header.Get("Accept") == "text/event-stream" {
+				return
+			}
+			if _, err := ctx.Invoke(gziper); err != nil {
+				panic(err)
+			}
+		})
 	}
 	if setting.Protocol == setting.FCGI {
 		m.SetURLPrefix(setting.AppSubURL)
@@ -72,14 +138,9 @@ func NewMacaron() *macaron.Macaron {
 			ExpiresAfter: time.Hour * 6,
 		},
 	))
-	m.Use(public.StaticHandler(
-		setting.AvatarUploadPath,
-		&public.Options{
-			Prefix:       "avatars",
-			SkipLogging:  setting.DisableRouterLog,
-			ExpiresAfter: time.Hour * 6,
-		},
-	))
+	m.Use(storageHandler("/avatars/", time.Hour*6, func() (storage.ObjectStorage, bool) {
+		return storage.Avatars, setting.AvatarStorage.ServeDirect
+	}))
 
 	m.Use(templates.HTMLRenderer())
 	models.InitMailRender(templates.Mailer())
@@ -164,6 +225,13 @@ func RegisterRoutes(m *macaron.Macaron) {
 		}
 	}
 
+	oauth2Enabled := func(ctx *context.Context) {
+		if !setting.OAuth2.Enable {
+			ctx.Error(404)
+			return
+		}
+	}
+
 	m.Use(user.GetNotificationCount)
 
 	// FIXME: not all routes need go through same middlewares.
@@ -187,6 +255,19 @@ func RegisterRoutes(m *macaron.Macaron) {
 		Post(bindIgnErr(auth.InstallForm{}), routers.InstallPost)
 	m.Get("/^:type(issues|pulls)$", reqSignIn, user.Issues)
 
+	// ***** START: OAuth2 provider *****
+	m.Group("/login/oauth", func() {
+		m.Get("/authorize", bindIgnErr(auth.AuthorizationForm{}), user.AuthorizeOAuth)
+		m.Post("/grant", bindIgnErr(auth.GrantApplicationForm{}), user.GrantApplicationOAuth)
+	}, reqSignIn, oauth2Enabled)
+	m.Group("/login/oauth", func() {
+		m.Post("/access_token", bindIgnErr(auth.AccessTokenForm{}), user.AccessTokenOAuth)
+		m.Get("/userinfo", user.InfoOAuth)
+		m.Get("/keys", user.OIDCKeys)
+	}, oauth2Enabled)
+	m.Get("/.well-known/openid-configuration", oauth2Enabled, user.OIDCWellKnown)
+	// ***** END: OAuth2 provider *****
+
 	// ***** START: User *****
 	m.Group("/user", func() {
 		m.Get("/login", user.SignIn)
@@ -264,6 +345,15 @@ func RegisterRoutes(m *macaron.Macaron) {
 			}, openIDSignInEnabled)
 			m.Post("/account_link", userSetting.DeleteAccountLink)
 		})
+		m.Combo("/notifications").Get(userSetting.Notifications).
+			Post(bindIgnErr(auth.UpdateNotificationSettingsForm{}), userSetting.NotificationsPost)
+		m.Group("/applications/oauth2", func() {
+			m.Post("", bindIgnErr(auth.EditOAuth2ApplicationForm{}), userSetting.OAuthApplicationsPost)
+			m.Post("/delete", userSetting.DeleteOAuth2Application)
+			m.Get("/:id", userSetting.OAuth2ApplicationShow)
+			m.Post("/:id", bindIgnErr(auth.EditOAuth2ApplicationForm{}), userSetting.OAuth2ApplicationEdit)
+			m.Post("/:id/regenerate_secret", userSetting.OAuthApplicationsRegenerateSecret)
+		}, oauth2Enabled)
 		m.Combo("/applications").Get(userSetting.Applications).
 			Post(bindIgnErr(auth.NewAccessTokenForm{}), userSetting.ApplicationsPost)
 		m.Post("/applications/delete", userSetting.DeleteApplication)
@@ -343,6 +433,35 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/delete", admin.DeleteNotices)
 			m.Get("/empty", admin.EmptyNotices)
 		})
+
+		m.Get("/hooks", func(ctx *context.Context) {
+			ctx.Redirect(setting.AppSubURL + "/admin/hooks/system")
+		})
+		m.Group("/hooks/^:kind(system|default)$", func() {
+			m.Get("", admin.Webhooks)
+			m.Post("/delete", admin.DeleteWebhook)
+			m.Get("/:type/new", repo.WebhooksNew)
+			m.Post("/gitea/new", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksNewPost)
+			m.Post("/gogs/new", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksNewPost)
+			m.Post("/slack/new", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksNewPost)
+			m.Post("/discord/new", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksNewPost)
+			m.Post("/dingtalk/new", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksNewPost)
+			m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
+			m.Post("/telegram/new", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksNewPost)
+			m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
+			m.Post("/custom/new", bindIgnErr(auth.NewCustomHookForm{}), repo.CustomHooksNewPost)
+			m.Get("/:id", repo.WebHooksEdit)
+			m.Post("/:id/deliveries/:delivery/redeliver", repo.RedeliverWebhook)
+			m.Post("/gitea/:id", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
+			m.Post("/gogs/:id", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksEditPost)
+			m.Post("/slack/:id", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksEditPost)
+			m.Post("/discord/:id", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksEditPost)
+			m.Post("/dingtalk/:id", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksEditPost)
+			m.Post("/msteams/:id", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
+			m.Post("/telegram/:id", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksEditPost)
+			m.Post("/matrix/:id", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
+			m.Post("/custom/:id", bindIgnErr(auth.NewCustomHookForm{}), repo.CustomHooksEditPost)
+		})
 	}, adminReq)
 	// ***** END: Admin *****
 
@@ -364,17 +483,25 @@ func RegisterRoutes(m *macaron.Macaron) {
 				return
 			}
 
-			fr, err := os.Open(attach.LocalPath())
-			if err != nil {
-				ctx.ServerError("Open", err)
+			if err := attach.IncreaseDownloadCount(); err != nil {
+				ctx.ServerError("Update", err)
 				return
 			}
-			defer fr.Close()
 
-			if err := attach.IncreaseDownloadCount(); err != nil {
-				ctx.ServerError("Update", err)
+			if setting.AttachmentStorage.ServeDirect {
+				// If the storage can serve the attachment itself, redirect to it
+				if u, err := storage.Attachments.URL(attach.RelativePath(), attach.Name); err == nil {
+					ctx.Redirect(u.String())
+					return
+				}
+			}
+
+			fr, err := storage.Attachments.Open(attach.RelativePath())
+			if err != nil {
+				ctx.ServerError("Open", err)
 				return
 			}
+			defer fr.Close()
 
 			if err = repo.ServeData(ctx, attach.Name, fr); err != nil {
 				ctx.ServerError("ServeData", err)
@@ -403,6 +530,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 	reqRepoPullsReader := context.RequireRepoReader(models.UnitTypePullRequests)
 	reqRepoIssuesOrPullsWriter := context.RequireRepoWriterOr(models.UnitTypeIssues, models.UnitTypePullRequests)
 	reqRepoIssuesOrPullsReader := context.RequireRepoReaderOr(models.UnitTypeIssues, models.UnitTypePullRequests)
+	reqRepoProjectsReader := context.RequireRepoReader(models.UnitTypeProjects)
+	reqRepoProjectsWriter := context.RequireRepoWriter(models.UnitTypeProjects)
 
 	// ***** START: Organization *****
 	m.Group("/org", func() {
@@ -418,6 +547,9 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/members/action/:action", org.MembersAction)
 
 			m.Get("/teams", org.Teams)
+
+			m.Get("/projects", repo.Projects)
+			m.Get("/projects/:id", repo.ViewProject)
 		}, context.OrgAssignment(true))
 
 		m.Group("/:org", func() {
@@ -434,6 +566,19 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/teams/:team/edit", bindIgnErr(auth.CreateTeamForm{}), org.EditTeamPost)
 			m.Post("/teams/:team/delete", org.DeleteTeam)
 
+			m.Group("/projects", func() {
+				m.Combo("/new").Get(repo.NewProject).
+					Post(bindIgnErr(auth.CreateProjectForm{}), repo.NewProjectPost)
+				m.Get("/:id/edit", repo.EditProject)
+				m.Post("/:id/edit", bindIgnErr(auth.CreateProjectForm{}), repo.EditProjectPost)
+				m.Post("/:id/boards", bindIgnErr(auth.EditProjectBoardForm{}), repo.NewProjectBoardPost)
+				m.Post("/:id/boards/delete", repo.DeleteProjectBoard)
+				m.Post("/:id/boards/:boardID", bindIgnErr(auth.EditProjectBoardForm{}), repo.EditProjectBoardPost)
+				m.Post("/:id/boards/:boardID/move", repo.MoveProjectIssues)
+				m.Get("/:id/:action", repo.ChangeProjectStatus)
+				m.Post("/delete", repo.DeleteProject)
+			})
+
 			m.Group("/settings", func() {
 				m.Combo("").Get(org.Settings).
 					Post(bindIgnErr(auth.UpdateOrgSettingForm{}), org.SettingsPost)
@@ -449,14 +594,32 @@ func RegisterRoutes(m *macaron.Macaron) {
 					m.Post("/slack/new", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksNewPost)
 					m.Post("/discord/new", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksNewPost)
 					m.Post("/dingtalk/new", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksNewPost)
+					m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
+					m.Post("/telegram/new", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksNewPost)
+					m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
+					m.Post("/custom/new", bindIgnErr(auth.NewCustomHookForm{}), repo.CustomHooksNewPost)
 					m.Get("/:id", repo.WebHooksEdit)
+					m.Post("/:id/deliveries/:delivery/redeliver", repo.RedeliverWebhook)
 					m.Post("/gitea/:id", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
 					m.Post("/gogs/:id", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksEditPost)
 					m.Post("/slack/:id", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksEditPost)
 					m.Post("/discord/:id", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksEditPost)
 					m.Post("/dingtalk/:id", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksEditPost)
+					m.Post("/msteams/:id", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
+					m.Post("/telegram/:id", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksEditPost)
+					m.Post("/matrix/:id", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
+					m.Post("/custom/:id", bindIgnErr(auth.NewCustomHookForm{}), repo.CustomHooksEditPost)
 				})
 
+				m.Group("/applications", func() {
+					m.Get("", org.Applications)
+					m.Post("/oauth2", bindIgnErr(auth.EditOAuth2ApplicationForm{}), org.OAuthApplicationsPost)
+					m.Post("/oauth2/delete", org.DeleteOAuth2Application)
+					m.Get("/oauth2/:id", org.OAuth2ApplicationShow)
+					m.Post("/oauth2/:id", bindIgnErr(auth.EditOAuth2ApplicationForm{}), org.OAuth2ApplicationEdit)
+					m.Post("/oauth2/:id/regenerate_secret", org.OAuthApplicationsRegenerateSecret)
+				}, oauth2Enabled)
+
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
 		}, context.OrgAssignment(true, true))
@@ -499,13 +662,22 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/slack/new", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksNewPost)
 				m.Post("/discord/new", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksNewPost)
 				m.Post("/dingtalk/new", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksNewPost)
+				m.Post("/msteams/new", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksNewPost)
+				m.Post("/telegram/new", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksNewPost)
+				m.Post("/matrix/new", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksNewPost)
+				m.Post("/custom/new", bindIgnErr(auth.NewCustomHookForm{}), repo.CustomHooksNewPost)
 				m.Get("/:id", repo.WebHooksEdit)
 				m.Post("/:id/test", repo.TestWebhook)
+				m.Post("/:id/deliveries/:delivery/redeliver", repo.RedeliverWebhook)
 				m.Post("/gitea/:id", bindIgnErr(auth.NewWebhookForm{}), repo.WebHooksEditPost)
 				m.Post("/gogs/:id", bindIgnErr(auth.NewGogshookForm{}), repo.GogsHooksEditPost)
 				m.Post("/slack/:id", bindIgnErr(auth.NewSlackHookForm{}), repo.SlackHooksEditPost)
 				m.Post("/discord/:id", bindIgnErr(auth.NewDiscordHookForm{}), repo.DiscordHooksEditPost)
 				m.Post("/dingtalk/:id", bindIgnErr(auth.NewDingtalkHookForm{}), repo.DingtalkHooksEditPost)
+				m.Post("/msteams/:id", bindIgnErr(auth.NewMSTeamsHookForm{}), repo.MSTeamsHooksEditPost)
+				m.Post("/telegram/:id", bindIgnErr(auth.NewTelegramHookForm{}), repo.TelegramHooksEditPost)
+				m.Post("/matrix/:id", bindIgnErr(auth.NewMatrixHookForm{}), repo.MatrixHooksEditPost)
+				m.Post("/custom/:id", bindIgnErr(auth.NewCustomHookForm{}), repo.CustomHooksEditPost)
 
 				m.Group("/git", func() {
 					m.Get("", repo.GitHooks)
@@ -531,7 +703,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 		m.Group("/issues", func() {
 			m.Combo("/new").Get(context.RepoRef(), repo.NewIssue).
 				Post(bindIgnErr(auth.CreateIssueForm{}), repo.NewIssuePost)
-		}, reqRepoIssueReader)
+		}, reqRepoIssueReader, repo.MustBeNotArchived)
 		// FIXME: should use different URLs but mostly same logic for comments of issue and pull reuqest.
 		// So they can apply their own enable/disable logic on routers.
 		m.Group("/issues", func() {
@@ -539,6 +711,8 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Post("/title", repo.UpdateIssueTitle)
 				m.Post("/content", repo.UpdateIssueContent)
 				m.Post("/watch", repo.IssueWatch)
+				m.Post("/lock", reqRepoIssuesOrPullsWriter, bindIgnErr(auth.IssueLockForm{}), repo.LockIssue)
+				m.Post("/unlock", reqRepoIssuesOrPullsWriter, repo.UnlockIssue)
 				m.Group("/dependency", func() {
 					m.Post("/add", repo.AddDependency)
 					m.Post("/delete", repo.RemoveDependency)
@@ -556,20 +730,21 @@ func RegisterRoutes(m *macaron.Macaron) {
 
 			m.Post("/labels", reqRepoIssuesOrPullsWriter, repo.UpdateIssueLabel)
 			m.Post("/milestone", reqRepoIssuesOrPullsWriter, repo.UpdateIssueMilestone)
+			m.Post("/projects", reqRepoIssuesOrPullsWriter, reqRepoProjectsReader, repo.UpdateIssueProject)
 			m.Post("/assignee", reqRepoIssuesOrPullsWriter, repo.UpdateIssueAssignee)
 			m.Post("/status", reqRepoIssuesOrPullsWriter, repo.UpdateIssueStatus)
-		})
+		}, repo.MustBeNotArchived)
 		m.Group("/comments/:id", func() {
 			m.Post("", repo.UpdateCommentContent)
 			m.Post("/delete", repo.DeleteComment)
 			m.Post("/reactions/:action", bindIgnErr(auth.ReactionForm{}), repo.ChangeCommentReaction)
-		})
+		}, repo.MustBeNotArchived)
 		m.Group("/labels", func() {
 			m.Post("/new", bindIgnErr(auth.CreateLabelForm{}), repo.NewLabel)
 			m.Post("/edit", bindIgnErr(auth.CreateLabelForm{}), repo.UpdateLabel)
 			m.Post("/delete", repo.DeleteLabel)
 			m.Post("/initialize", bindIgnErr(auth.InitializeLabelsForm{}), repo.InitializeLabels)
-		}, reqRepoIssuesOrPullsWriter, context.RepoRef())
+		}, reqRepoIssuesOrPullsWriter, repo.MustBeNotArchived, context.RepoRef())
 		m.Group("/milestones", func() {
 			m.Combo("/new").Get(repo.NewMilestone).
 				Post(bindIgnErr(auth.CreateMilestoneForm{}), repo.NewMilestonePost)
@@ -577,13 +752,25 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Post("/:id/edit", bindIgnErr(auth.CreateMilestoneForm{}), repo.EditMilestonePost)
 			m.Get("/:id/:action", repo.ChangeMilestonStatus)
 			m.Post("/delete", repo.DeleteMilestone)
-		}, reqRepoIssuesOrPullsWriter, context.RepoRef())
+		}, reqRepoIssuesOrPullsWriter, repo.MustBeNotArchived, context.RepoRef())
 		m.Group("/milestone", func() {
 			m.Get("/:id", repo.MilestoneIssuesAndPulls)
 		}, reqRepoIssuesOrPullsWriter, context.RepoRef())
+		m.Group("/projects", func() {
+			m.Combo("/new").Get(repo.NewProject).
+				Post(bindIgnErr(auth.CreateProjectForm{}), repo.NewProjectPost)
+			m.Get("/:id/edit", repo.EditProject)
+			m.Post("/:id/edit", bindIgnErr(auth.CreateProjectForm{}), repo.EditProjectPost)
+			m.Post("/:id/boards", bindIgnErr(auth.EditProjectBoardForm{}), repo.NewProjectBoardPost)
+			m.Post("/:id/boards/delete", repo.DeleteProjectBoard)
+			m.Post("/:id/boards/:boardID", bindIgnErr(auth.EditProjectBoardForm{}), repo.EditProjectBoardPost)
+			m.Post("/:id/boards/:boardID/move", repo.MoveProjectIssues)
+			m.Get("/:id/:action", repo.ChangeProjectStatus)
+			m.Post("/delete", repo.DeleteProject)
+		}, reqRepoProjectsWriter, repo.MustBeNotArchived, context.RepoRef())
 		m.Combo("/compare/*", reqRepoCodeReader, reqRepoPullsReader, repo.MustAllowPulls, repo.SetEditorconfigIfExists).
 			Get(repo.SetDiffViewStyle, repo.CompareAndPullRequest).
-			Post(bindIgnErr(auth.CreateIssueForm{}), repo.CompareAndPullRequestPost)
+			Post(repo.MustBeNotArchived, bindIgnErr(auth.CreateIssueForm{}), repo.CompareAndPullRequestPost)
 
 		m.Group("", func() {
 			m.Group("", func() {
@@ -612,7 +799,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 			}, bindIgnErr(auth.NewBranchForm{}))
 			m.Post("/delete", repo.DeleteBranchPost)
 			m.Post("/restore", repo.RestoreBranchPost)
-		}, reqRepoCodeWriter, repo.MustBeNotBare)
+		}, reqRepoCodeWriter, repo.MustBeNotBare, repo.MustBeNotArchived)
 
 	}, reqSignIn, context.RepoAssignment(), context.UnitTypes())
 
@@ -625,11 +812,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/new", repo.NewRelease)
 			m.Post("/new", bindIgnErr(auth.NewReleaseForm{}), repo.NewReleasePost)
 			m.Post("/delete", repo.DeleteRelease)
-		}, reqSignIn, repo.MustBeNotBare, reqRepoReleaseWriter, context.RepoRef())
+		}, reqSignIn, repo.MustBeNotBare, repo.MustBeNotArchived, reqRepoReleaseWriter, context.RepoRef())
 		m.Group("/releases", func() {
 			m.Get("/edit/*", repo.EditRelease)
 			m.Post("/edit/*", bindIgnErr(auth.EditReleaseForm{}), repo.EditReleasePost)
-		}, reqSignIn, repo.MustBeNotBare, reqRepoReleaseWriter, func(ctx *context.Context) {
+		}, reqSignIn, repo.MustBeNotBare, repo.MustBeNotArchived, reqRepoReleaseWriter, func(ctx *context.Context) {
 			var err error
 			ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
 			if err != nil {
@@ -657,6 +844,11 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get("/milestones", reqRepoIssuesOrPullsReader, repo.Milestones)
 		}, context.RepoRef())
 
+		m.Group("/projects", func() {
+			m.Get("", repo.Projects)
+			m.Get("/:id", repo.ViewProject)
+		}, reqRepoProjectsReader, context.RepoRef())
+
 		m.Group("/wiki", func() {
 			m.Get("/?:page", repo.Wiki)
 			m.Get("/_pages", repo.WikiPages)
@@ -667,7 +859,7 @@ func RegisterRoutes(m *macaron.Macaron) {
 				m.Combo("/:page/_edit").Get(repo.EditWiki).
 					Post(bindIgnErr(auth.NewWikiForm{}), repo.EditWikiPost)
 				m.Post("/:page/delete", repo.DeleteWikiPagePost)
-			}, reqSignIn, reqRepoWikiWriter)
+			}, reqSignIn, reqRepoWikiWriter, repo.MustBeNotArchived)
 		}, repo.MustEnableWiki, context.RepoRef())
 
 		m.Group("/wiki", func() {
@@ -689,14 +881,14 @@ func RegisterRoutes(m *macaron.Macaron) {
 			m.Get(".diff", repo.DownloadPullDiff)
 			m.Get(".patch", repo.DownloadPullPatch)
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
-			m.Post("/merge", reqRepoPullsWriter, bindIgnErr(auth.MergePullRequestForm{}), repo.MergePullRequest)
-			m.Post("/cleanup", context.RepoRef(), repo.CleanUpPullRequest)
+			m.Post("/merge", reqRepoPullsWriter, repo.MustBeNotArchived, bindIgnErr(auth.MergePullRequestForm{}), repo.MergePullRequest)
+			m.Post("/cleanup", repo.MustBeNotArchived, context.RepoRef(), repo.CleanUpPullRequest)
 			m.Group("/files", func() {
 				m.Get("", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFiles)
 				m.Group("/reviews", func() {
 					m.Post("/comments", bindIgnErr(auth.CodeCommentForm{}), repo.CreateCodeComment)
 					m.Post("/submit", bindIgnErr(auth.SubmitReviewForm{}), repo.SubmitReview)
-				})
+				}, repo.MustBeNotArchived)
 			})
 		}, repo.MustAllowPulls)
 
@@ -35,6 +35,7 @@ func GetNotificationCount(c *context.Context) {
 	}
 
 	c.Data["NotificationUnreadCount"] = count
+	c.Data["EnableNotificationEvents"] = setting.UI.Notification.EventSourceUpdateTime > 0
 }
 
 // Notifications is the notifications page
@@ -0,0 +1,673 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/auth/oauth2"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	tplGrantAccess base.TplName = "user/auth/grant"
+	tplGrantError  base.TplName = "user/auth/grant_error"
+)
+
+// AuthorizeErrorCode represents an error code specified in RFC 6749
+type AuthorizeErrorCode string
+
+const (
+	// ErrorCodeInvalidRequest represents the according error in RFC 6749
+	ErrorCodeInvalidRequest AuthorizeErrorCode = "invalid_request"
+	// ErrorCodeUnauthorizedClient represents the according error in RFC 6749
+	ErrorCodeUnauthorizedClient AuthorizeErrorCode = "unauthorized_client"
+	// ErrorCodeAccessDenied represents the according error in RFC 6749
+	ErrorCodeAccessDenied AuthorizeErrorCode = "access_denied"
+	// ErrorCodeUnsupportedResponseType represents the according error in RFC 6749
+	ErrorCodeUnsupportedResponseType AuthorizeErrorCode = "unsupported_response_type"
+	// ErrorCodeInvalidScope represents the according error in RFC 6749
+	ErrorCodeInvalidScope AuthorizeErrorCode = "invalid_scope"
+	// ErrorCodeServerError represents the according error in RFC 6749
+	ErrorCodeServerError AuthorizeErrorCode = "server_error"
+	// ErrorCodeTemporaryUnavailable represents the according error in RFC 6749
+	ErrorCodeTemporaryUnavailable AuthorizeErrorCode = "temporarily_unavailable"
+)
+
+// AuthorizeError represents an error type specified in RFC 6749
+type AuthorizeError struct {
+	ErrorCode        AuthorizeErrorCode
+	ErrorDescription string
+	State            string
+}
+
+// Error returns the error message
+func (err AuthorizeError) Error() string {
+	return fmt.Sprintf("%s: %s", err.ErrorCode, err.ErrorDescription)
+}
+
+// AccessTokenErrorCode represents an error code specified in RFC 6749
+type AccessTokenErrorCode string
+
+const (
+	// AccessTokenErrorCodeInvalidRequest represents an error code specified in RFC 6749
+	AccessTokenErrorCodeInvalidRequest AccessTokenErrorCode = "invalid_request"
+	// AccessTokenErrorCodeInvalidClient represents an error code specified in RFC 6749
+	AccessTokenErrorCodeInvalidClient AccessTokenErrorCode = "invalid_client"
+	// AccessTokenErrorCodeInvalidGrant represents an error code specified in RFC 6749
+	AccessTokenErrorCodeInvalidGrant AccessTokenErrorCode = "invalid_grant"
+	// AccessTokenErrorCodeUnauthorizedClient represents an error code specified in RFC 6749
+	AccessTokenErrorCodeUnauthorizedClient AccessTokenErrorCode = "unauthorized_client"
+	// AccessTokenErrorCodeUnsupportedGrantType represents an error code specified in RFC 6749
+	AccessTokenErrorCodeUnsupportedGrantType AccessTokenErrorCode = "unsupported_grant_type"
+	// AccessTokenErrorCodeInvalidScope represents an error code specified in RFC 6749
+	AccessTokenErrorCodeInvalidScope AccessTokenErrorCode = "invalid_scope"
+)
+
+// AccessTokenError represents an error response specified in RFC 6749
+type AccessTokenError struct {
+	ErrorCode        AccessTokenErrorCode `json:"error"`
+	ErrorDescription string               `json:"error_description"`
+}
+
+// Error returns the error message
+func (err AccessTokenError) Error() string {
+	return fmt.Sprintf("%s: %s", err.ErrorCode, err.ErrorDescription)
+}
+
+// TokenType specifies the kind of token
+type TokenType string
+
+const (
+	// TokenTypeBearer represents a token type specified in RFC 6749
+	TokenTypeBearer TokenType = "bearer"
+)
+
+// AccessTokenResponse represents a successful access token response
+type AccessTokenResponse struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    TokenType `json:"token_type"`
+	ExpiresIn    int64     `json:"expires_in"`
+	RefreshToken string    `json:"refresh_token"`
+	IDToken      string    `json:"id_token,omitempty"`
+}
+
+func newAccessTokenResponse(grant *models.OAuth2Grant, app *models.OAuth2Application) (*AccessTokenResponse, *AccessTokenError) {
+	if setting.OAuth2.InvalidateRefreshTokens {
+		if err := grant.IncreaseCounter(); err != nil {
+			return nil, &AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+				ErrorDescription: "cannot increase the grant counter",
+			}
+		}
+	}
+
+	// generate access token to access the API
+	expirationDate := time.Now().Add(time.Duration(setting.OAuth2.AccessTokenExpirationTime) * time.Second).Unix()
+	accessToken := &models.OAuth2Token{
+		GrantID: grant.ID,
+		Type:    models.TypeAccessToken,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: expirationDate,
+		},
+	}
+	signedAccessToken, err := accessToken.SignToken()
+	if err != nil {
+		return nil, &AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot sign token",
+		}
+	}
+
+	// generate refresh token to request an access token after it expired later
+	refreshExpirationDate := time.Now().Add(time.Duration(setting.OAuth2.RefreshTokenExpirationTime) * time.Hour).Unix()
+	refreshToken := &models.OAuth2Token{
+		GrantID: grant.ID,
+		Counter: grant.Counter,
+		Type:    models.TypeRefreshToken,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: refreshExpirationDate,
+		},
+	}
+	signedRefreshToken, err := refreshToken.SignToken()
+	if err != nil {
+		return nil, &AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot sign token",
+		}
+	}
+
+	// generate OpenID Connect id_token
+	var signedIDToken string
+	if grant.ScopeContains("openid") {
+		user, err := models.GetUserByID(grant.UserID)
+		if err != nil {
+			return nil, &AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot find user",
+			}
+		}
+
+		idToken := &models.OIDCToken{
+			StandardClaims: jwt.StandardClaims{
+				ExpiresAt: expirationDate,
+				Issuer:    setting.AppURL,
+				Audience:  app.ClientID,
+				Subject:   fmt.Sprint(grant.UserID),
+				IssuedAt:  time.Now().Unix(),
+			},
+			Nonce: grant.Nonce,
+		}
+		if grant.ScopeContains("profile") {
+			idToken.Name = user.FullName
+			idToken.PreferredUsername = user.Name
+			idToken.Profile = user.HTMLURL()
+			idToken.Picture = user.AvatarLink()
+			idToken.Website = user.Website
+			idToken.UpdatedAt = user.UpdatedUnix
+		}
+		if grant.ScopeContains("email") {
+			idToken.Email = user.Email
+			idToken.EmailVerified = user.IsActive
+		}
+
+		signedIDToken, err = idToken.SignToken()
+		if err != nil {
+			return nil, &AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot sign token",
+			}
+		}
+	}
+
+	return &AccessTokenResponse{
+		AccessToken:  signedAccessToken,
+		TokenType:    TokenTypeBearer,
+		ExpiresIn:    setting.OAuth2.AccessTokenExpirationTime,
+		RefreshToken: signedRefreshToken,
+		IDToken:      signedIDToken,
+	}, nil
+}
+
+// AuthorizeOAuth manages authorize requests
+func AuthorizeOAuth(ctx *context.Context, form auth.AuthorizationForm) {
+	app, err := models.GetOAuth2ApplicationByClientID(form.ClientID)
+	if err != nil {
+		if models.IsErrOAuthClientIDInvalid(err) {
+			handleAuthorizeError(ctx, AuthorizeError{
+				ErrorCode:        ErrorCodeUnauthorizedClient,
+				ErrorDescription: "Client ID not registered",
+				State:            form.State,
+			}, "")
+			return
+		}
+		ctx.ServerError("GetOAuth2ApplicationByClientID", err)
+		return
+	}
+	if err = app.LoadOwner(); err != nil {
+		ctx.ServerError("LoadOwner", err)
+		return
+	}
+
+	if len(form.RedirectURI) == 0 && len(app.RedirectURIs) == 1 {
+		form.RedirectURI = app.PrimaryRedirectURI()
+	}
+	if !app.ContainsRedirectURI(form.RedirectURI) {
+		handleAuthorizeError(ctx, AuthorizeError{
+			ErrorCode:        ErrorCodeInvalidRequest,
+			ErrorDescription: "Unregistered Redirect URI",
+			State:            form.State,
+		}, "")
+		return
+	}
+
+	if ctx.HasError() {
+		handleAuthorizeError(ctx, AuthorizeError{
+			ErrorCode:        ErrorCodeInvalidRequest,
+			ErrorDescription: ctx.GetErrMsg(),
+			State:            form.State,
+		}, form.RedirectURI)
+		return
+	}
+
+	// pkce support
+	switch form.CodeChallengeMethod {
+	case "S256", "plain":
+		if len(form.CodeChallenge) == 0 {
+			handleAuthorizeError(ctx, AuthorizeError{
+				ErrorCode:        ErrorCodeInvalidRequest,
+				ErrorDescription: "code_challenge is required with code_challenge_method",
+				State:            form.State,
+			}, form.RedirectURI)
+			return
+		}
+	case "":
+		if len(form.CodeChallenge) > 0 {
+			// the default method is plain, see RFC 7636 section 4.3
+			form.CodeChallengeMethod = "plain"
+		}
+	default:
+		handleAuthorizeError(ctx, AuthorizeError{
+			ErrorCode:        ErrorCodeInvalidRequest,
+			ErrorDescription: "unsupported code challenge method",
+			State:            form.State,
+		}, form.RedirectURI)
+		return
+	}
+
+	grant, err := app.GetGrantByUserID(ctx.User.ID)
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		return
+	}
+
+	// Redirect if user already granted access with the same scopes
+	if grant != nil && grantCoversScope(grant, form.Scope) {
+		code, err := grant.GenerateNewAuthorizationCode(form.RedirectURI, form.CodeChallenge, form.CodeChallengeMethod)
+		if err != nil {
+			handleServerError(ctx, form.State, form.RedirectURI)
+			return
+		}
+		redirect, err := code.GenerateRedirectURI(form.State)
+		if err != nil {
+			handleServerError(ctx, form.State, form.RedirectURI)
+			return
+		}
+		// Update nonce to reflect the new session
+		if len(form.Nonce) > 0 {
+			if err = grant.SetNonce(form.Nonce); err != nil {
+				log.Error(4, "Unable to update nonce: %v", err)
+			}
+		}
+		ctx.Redirect(redirect.String(), 302)
+		return
+	}
+
+	// show authorize page to grant access
+	ctx.Data["Title"] = ctx.Tr("auth.authorize_title", app.Name)
+	ctx.Data["Application"] = app
+	ctx.Data["RedirectURI"] = form.RedirectURI
+	ctx.Data["State"] = form.State
+	ctx.Data["Scope"] = form.Scope
+	ctx.Data["Scopes"] = strings.Fields(form.Scope)
+	ctx.Data["Nonce"] = form.Nonce
+	ctx.Data["ApplicationRedirectDomain"] = redirectDomain(form.RedirectURI)
+	ctx.Data["CancelLink"] = authorizeErrorURI(AuthorizeError{
+		ErrorCode:        ErrorCodeAccessDenied,
+		ErrorDescription: "the user denied the access",
+		State:            form.State,
+	}, form.RedirectURI)
+
+	if err := ctx.Session.Set("CodeChallengeMethod", form.CodeChallengeMethod); err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		return
+	}
+	if err := ctx.Session.Set("CodeChallenge", form.CodeChallenge); err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		return
+	}
+	ctx.HTML(200, tplGrantAccess)
+}
+
+// GrantApplicationOAuth manages the post request submitted when a user grants access to an application
+func GrantApplicationOAuth(ctx *context.Context, form auth.GrantApplicationForm) {
+	if ctx.HasError() {
+		ctx.Error(400)
+		return
+	}
+
+	app, err := models.GetOAuth2ApplicationByClientID(form.ClientID)
+	if err != nil {
+		if models.IsErrOAuthClientIDInvalid(err) {
+			ctx.Error(400)
+			return
+		}
+		ctx.ServerError("GetOAuth2ApplicationByClientID", err)
+		return
+	}
+	if !app.ContainsRedirectURI(form.RedirectURI) {
+		ctx.Error(400)
+		return
+	}
+
+	grant, err := app.GetGrantByUserID(ctx.User.ID)
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		return
+	}
+	if grant == nil {
+		grant, err = app.CreateGrant(ctx.User.ID, form.Scope)
+	} else {
+		err = grant.UpdateScope(form.Scope)
+	}
+	if err != nil {
+		handleAuthorizeError(ctx, AuthorizeError{
+			State:            form.State,
+			ErrorDescription: "cannot create grant for user",
+			ErrorCode:        ErrorCodeServerError,
+		}, form.RedirectURI)
+		return
+	}
+	if err = grant.SetNonce(form.Nonce); err != nil {
+		log.Error(4, "Unable to update nonce: %v", err)
+	}
+
+	var codeChallenge, codeChallengeMethod string
+	codeChallenge, _ = ctx.Session.Get("CodeChallenge").(string)
+	codeChallengeMethod, _ = ctx.Session.Get("CodeChallengeMethod").(string)
+	_ = ctx.Session.Delete("CodeChallenge")
+	_ = ctx.Session.Delete("CodeChallengeMethod")
+
+	code, err := grant.GenerateNewAuthorizationCode(form.RedirectURI, codeChallenge, codeChallengeMethod)
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		return
+	}
+	redirect, err := code.GenerateRedirectURI(form.State)
+	if err != nil {
+		handleServerError(ctx, form.State, form.RedirectURI)
+		return
+	}
+	ctx.Redirect(redirect.String(), 302)
+}
+
+// AccessTokenOAuth manages all access token requests by the client
+func AccessTokenOAuth(ctx *context.Context, form auth.AccessTokenForm) {
+	// clients may authenticate with HTTP Basic authentication, see RFC 6749 section 2.3.1
+	if len(form.ClientID) == 0 {
+		authHeader := strings.Fields(ctx.Req.Header.Get("Authorization"))
+		if len(authHeader) == 2 && authHeader[0] == "Basic" {
+			clientID, clientSecret, err := base.BasicAuthDecode(authHeader[1])
+			if err != nil {
+				handleAccessTokenError(ctx, AccessTokenError{
+					ErrorCode:        AccessTokenErrorCodeInvalidClient,
+					ErrorDescription: "cannot parse basic auth header",
+				})
+				return
+			}
+			form.ClientID, _ = url.QueryUnescape(clientID)
+			form.ClientSecret, _ = url.QueryUnescape(clientSecret)
+		}
+	}
+
+	switch form.GrantType {
+	case "refresh_token":
+		handleRefreshToken(ctx, form)
+	case "authorization_code":
+		handleAuthorizationCode(ctx, form)
+	default:
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnsupportedGrantType,
+			ErrorDescription: "Only refresh_token or authorization_code grant type is supported",
+		})
+	}
+}
+
+func handleRefreshToken(ctx *context.Context, form auth.AccessTokenForm) {
+	app, err := models.GetOAuth2ApplicationByClientID(form.ClientID)
+	if err != nil || !app.ValidateClientSecret(form.ClientSecret) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: "invalid client credentials",
+		})
+		return
+	}
+
+	token, err := models.ParseOAuth2Token(form.RefreshToken)
+	if err != nil || token.Type != models.TypeRefreshToken {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "unable to parse refresh token",
+		})
+		return
+	}
+
+	// get grant before increasing counter
+	grant, err := models.GetOAuth2GrantByID(token.GrantID)
+	if err != nil || grant == nil || grant.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "grant does not exist",
+		})
+		return
+	}
+
+	// check if token got already used
+	if grant.Counter != token.Counter || token.Counter == 0 {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "token was already used",
+		})
+		log.Warn("A client tried to use a refresh token for grant_id = %d was used twice!", grant.ID)
+		return
+	}
+
+	accessToken, tokenErr := newAccessTokenResponse(grant, app)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	ctx.JSON(200, accessToken)
+}
+
+func handleAuthorizationCode(ctx *context.Context, form auth.AccessTokenForm) {
+	app, err := models.GetOAuth2ApplicationByClientID(form.ClientID)
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: fmt.Sprintf("cannot load client with client id: '%s'", form.ClientID),
+		})
+		return
+	}
+
+	authorizationCode, err := models.GetOAuth2AuthorizationByCode(form.Code)
+	if err != nil || authorizationCode == nil || authorizationCode.Grant.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "client is not authorized",
+		})
+		return
+	}
+
+	// public clients without secret have to use PKCE, see RFC 7636
+	if !app.ValidateClientSecret(form.ClientSecret) &&
+		(len(form.ClientSecret) > 0 || len(authorizationCode.CodeChallenge) == 0) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: "invalid client secret",
+		})
+		return
+	}
+
+	// check if code verifier authorizes the client, PKCE support
+	if !authorizationCode.ValidateCodeChallenge(form.CodeVerifier) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "client is not authorized",
+		})
+		return
+	}
+
+	// check if granted for this application
+	if authorizationCode.ValidUntil.AsTime().Before(time.Now()) || authorizationCode.RedirectURI != form.RedirectURI {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "authorization code is expired or has been issued for another redirect uri",
+		})
+		return
+	}
+
+	// remove token from database to deny duplicate usage
+	if err := authorizationCode.Invalidate(); err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	}
+
+	resp, tokenErr := newAccessTokenResponse(authorizationCode.Grant, app)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	// send successful response
+	ctx.JSON(200, resp)
+}
+
+type userInfoResponse struct {
+	Sub               string `json:"sub"`
+	Name              string `json:"name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Profile           string `json:"profile,omitempty"`
+	Picture           string `json:"picture,omitempty"`
+	Website           string `json:"website,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     bool   `json:"email_verified,omitempty"`
+}
+
+// InfoOAuth returns the claims about the user the access token has been issued for
+func InfoOAuth(ctx *context.Context) {
+	var token string
+	authHeader := strings.Fields(ctx.Req.Header.Get("Authorization"))
+	if len(authHeader) == 2 && strings.ToLower(authHeader[0]) == "bearer" {
+		token = authHeader[1]
+	}
+
+	grant, err := models.GetOAuth2GrantByAccessToken(token)
+	if err != nil {
+		if !models.IsErrAccessTokenNotExist(err) {
+			log.Error(4, "GetOAuth2GrantByAccessToken: %v", err)
+		}
+		ctx.Resp.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+		ctx.Error(401)
+		return
+	}
+
+	user, err := models.GetUserByID(grant.UserID)
+	if err != nil {
+		ctx.ServerError("GetUserByID", err)
+		return
+	}
+
+	response := &userInfoResponse{
+		Sub: fmt.Sprint(user.ID),
+	}
+	if grant.ScopeContains("profile") {
+		response.Name = user.FullName
+		response.PreferredUsername = user.Name
+		response.Profile = user.HTMLURL()
+		response.Picture = user.AvatarLink()
+		response.Website = user.Website
+	}
+	if grant.ScopeContains("email") {
+		response.Email = user.Email
+		response.EmailVerified = user.IsActive
+	}
+	ctx.JSON(200, response)
+}
+
+// OIDCWellKnown generates JSON so OIDC clients know Gitea's capabilities
+func OIDCWellKnown(ctx *context.Context) {
+	scopes := []string{"openid", "profile", "email"}
+	for _, scope := range models.AccessTokenScopes {
+		scopes = append(scopes, string(scope))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"issuer":                                setting.AppURL,
+		"authorization_endpoint":                setting.AppURL + "login/oauth/authorize",
+		"token_endpoint":                        setting.AppURL + "login/oauth/access_token",
+		"userinfo_endpoint":                     setting.AppURL + "login/oauth/userinfo",
+		"jwks_uri":                              setting.AppURL + "login/oauth/keys",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code", "refresh_token"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"plain", "S256"},
+		"scopes_supported":                      scopes,
+		"claims_supported": []string{
+			"aud", "exp", "iat", "iss", "sub", "nonce",
+			"name", "preferred_username", "profile", "picture", "website", "updated_at",
+			"email", "email_verified",
+		},
+	})
+}
+
+// OIDCKeys generates the JSON Web Key Set clients use to verify the signature of the issued tokens
+func OIDCKeys(ctx *context.Context) {
+	ctx.JSON(200, oauth2.JSONWebKeySet())
+}
+
+// grantCoversScope returns true if all the requested scopes have already been granted
+func grantCoversScope(grant *models.OAuth2Grant, scope string) bool {
+	for _, s := range strings.Fields(scope) {
+		if !grant.ScopeContains(s) {
+			return false
+		}
+	}
+	return true
+}
+
+func redirectDomain(redirectURI string) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	return u.Host
+}
+
+func handleAccessTokenError(ctx *context.Context, acErr AccessTokenError) {
+	status := 400
+	if acErr.ErrorCode == AccessTokenErrorCodeInvalidClient {
+		status = 401
+	}
+	ctx.JSON(status, acErr)
+}
+
+func handleServerError(ctx *context.Context, state string, redirectURI string) {
+	handleAuthorizeError(ctx, AuthorizeError{
+		ErrorCode:        ErrorCodeServerError,
+		ErrorDescription: "A server error occurred",
+		State:            state,
+	}, redirectURI)
+}
+
+func handleAuthorizeError(ctx *context.Context, authErr AuthorizeError, redirectURI string) {
+	if redirectURI == "" {
+		log.Warn("Authorization failed: %v", authErr.ErrorDescription)
+		ctx.Data["Error"] = authErr
+		ctx.HTML(400, tplGrantError)
+		return
+	}
+	ctx.Redirect(authorizeErrorURI(authErr, redirectURI), 302)
+}
+
+// authorizeErrorURI returns the redirect URI with the error added to its query
+func authorizeErrorURI(authErr AuthorizeError, redirectURI string) string {
+	redirect, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+	q := redirect.Query()
+	q.Set("error", string(authErr.ErrorCode))
+	q.Set("error_description", authErr.ErrorDescription)
+	if len(authErr.State) > 0 {
+		q.Set("state", authErr.State)
+	}
+	redirect.RawQuery = q.Encode()
+	return redirect.String()
+}
@@ -6,11 +6,15 @@
 package setting
 
 import (
+	"strings"
+	"time"
+
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/auth"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 )
 
 const (
@@ -39,10 +43,46 @@ func ApplicationsPost(ctx *context.Context, form auth.NewAccessTokenForm) {
 		return
 	}
 
+	scope, err := models.ParseAccessTokenScope(form.Scope)
+	if err != nil {
+		loadApplicationsData(ctx)
+		ctx.RenderWithErr(ctx.Tr("settings.token_scope_invalid"), tplSettingsApplications, &form)
+		return
+	}
+
+	repoIDs, err := models.GetAccessTokenRepoIDs(ctx.User, strings.Split(form.Repositories, ","))
+	if err != nil {
+		if models.IsErrRepoNotExist(err) {
+			loadApplicationsData(ctx)
+			ctx.Data["Err_Repositories"] = true
+			ctx.RenderWithErr(ctx.Tr("settings.token_repositories_invalid"), tplSettingsApplications, &form)
+		} else {
+			ctx.ServerError("GetAccessTokenRepoIDs", err)
+		}
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.User.ID,
-		Name: form.Name,
+		UID:     ctx.User.ID,
+		Name:    form.Name,
+		Scope:   scope,
+		RepoIDs: repoIDs,
 	}
+	if len(form.Expires) > 0 {
+		// The token stays valid until the end of the given day
+		expires, err := time.ParseInLocation("2006-01-02", form.Expires, time.Local)
+		if err == nil {
+			expires = expires.AddDate(0, 0, 1)
+		}
+		if err != nil || !expires.After(time.Now()) {
+			loadApplicationsData(ctx)
+			ctx.Data["Err_Expires"] = true
+			ctx.RenderWithErr(ctx.Tr("settings.token_expires_invalid"), tplSettingsApplications, &form)
+			return
+		}
+		t.ExpiresUnix = util.TimeStamp(expires.Unix())
+	}
+
 	if err := models.NewAccessToken(t); err != nil {
 		ctx.ServerError("NewAccessToken", err)
 		return
@@ -73,5 +113,22 @@ func loadApplicationsData(ctx *context.Context) {
 		ctx.ServerError("ListAccessTokens", err)
 		return
 	}
+	for _, t := range tokens {
+		if err = t.LoadRepos(); err != nil {
+			ctx.ServerError("LoadRepos", err)
+			return
+		}
+	}
 	ctx.Data["Tokens"] = tokens
+	ctx.Data["AccessTokenScopes"] = models.AccessTokenScopes
+
+	if setting.OAuth2.Enable {
+		ctx.Data["Applications"], err = models.GetOAuth2ApplicationsByUserID(ctx.User.ID)
+		if err != nil {
+			ctx.ServerError("GetOAuth2ApplicationsByUserID", err)
+			return
+		}
+		ctx.Data["OAuth2ListLink"] = setting.AppSubURL + "/user/settings/applications"
+		ctx.Data["OAuth2LinkPrefix"] = setting.AppSubURL + "/user/settings/applications/oauth2"
+	}
 }
@@ -0,0 +1,55 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/Unknwon/com"
+)
+
+const (
+	tplSettingsNotifications base.TplName = "user/settings/notifications"
+)
+
+// Notifications render user's notification settings page
+func Notifications(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsNotifications"] = true
+	ctx.Data["EnableNotifyMail"] = setting.Service.EnableNotifyMail
+
+	settings, err := models.GetUserNotificationSettings(ctx.User.ID)
+	if err != nil {
+		ctx.ServerError("GetUserNotificationSettings", err)
+		return
+	}
+	ctx.Data["NotificationSettings"] = settings
+
+	ctx.HTML(200, tplSettingsNotifications)
+}
+
+// NotificationsPost response for updating user's notification settings
+func NotificationsPost(ctx *context.Context, form auth.UpdateNotificationSettingsForm) {
+	settings := make([]*models.UserNotificationSetting, 0, len(models.NotificationReasons))
+	for _, reason := range models.NotificationReasons {
+		settings = append(settings, &models.UserNotificationSetting{
+			Reason: reason,
+			Web:    com.IsSliceContainsStr(form.Web, reason.String()),
+			Email:  com.IsSliceContainsStr(form.Email, reason.String()),
+		})
+	}
+
+	if err := models.UpdateUserNotificationSettings(ctx.User.ID, settings); err != nil {
+		ctx.ServerError("UpdateUserNotificationSettings", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.update_notification_settings_success"))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/notifications")
+}
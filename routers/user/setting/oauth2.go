@@ -0,0 +1,236 @@
+// Copyright 2018 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/auth"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const (
+	tplSettingsOAuthApplicationEdit base.TplName = "user/settings/applications_oauth2_edit"
+)
+
+// OAuth2CommonHandlers implements the settings pages of the OAuth2 applications
+// of a user or an organization
+type OAuth2CommonHandlers struct {
+	OwnerID            int64        // the user or organization the applications belong to
+	BasePathList       string       // the page listing the applications
+	BasePathEditPrefix string       // the prefix of the edit page of an application
+	TplAppList         base.TplName // the template of the list page
+	TplAppEdit         base.TplName // the template of the edit page
+	LoadListData       func(ctx *context.Context)
+}
+
+// parseRedirectURIs splits the newline separated redirect URIs and checks
+// that they are absolute URLs without fragment, see RFC 6749 section 3.1.2
+func parseRedirectURIs(redirectURIs string) ([]string, bool) {
+	uris := make([]string, 0, 2)
+	for _, uri := range strings.Split(redirectURIs, "\n") {
+		uri = strings.TrimSpace(uri)
+		if len(uri) == 0 {
+			continue
+		}
+		u, err := url.Parse(uri)
+		if err != nil || !u.IsAbs() || len(u.Fragment) > 0 {
+			return nil, false
+		}
+		uris = append(uris, uri)
+	}
+	return uris, len(uris) > 0
+}
+
+func (oa *OAuth2CommonHandlers) setLinks(ctx *context.Context) {
+	ctx.Data["OAuth2ListLink"] = oa.BasePathList
+	ctx.Data["OAuth2LinkPrefix"] = oa.BasePathEditPrefix
+}
+
+// AddApp adds an oauth2 application
+func (oa *OAuth2CommonHandlers) AddApp(ctx *context.Context, form auth.EditOAuth2ApplicationForm) {
+	oa.setLinks(ctx)
+	if ctx.HasError() {
+		oa.LoadListData(ctx)
+		ctx.HTML(200, oa.TplAppList)
+		return
+	}
+
+	redirectURIs, ok := parseRedirectURIs(form.RedirectURIs)
+	if !ok {
+		oa.LoadListData(ctx)
+		ctx.Data["Err_RedirectURIs"] = true
+		ctx.RenderWithErr(ctx.Tr("settings.oauth2_redirect_uris_invalid"), oa.TplAppList, &form)
+		return
+	}
+
+	app, err := models.CreateOAuth2Application(models.CreateOAuth2ApplicationOptions{
+		Name:         form.ApplicationName,
+		UID:          oa.OwnerID,
+		RedirectURIs: redirectURIs,
+	})
+	if err != nil {
+		ctx.ServerError("CreateOAuth2Application", err)
+		return
+	}
+	log.Trace("OAuth2 application created: %d", app.ID)
+
+	// the secret is only shown once, right after it has been generated
+	ctx.Data["App"] = app
+	ctx.Data["ClientSecret"], err = app.GenerateClientSecret()
+	if err != nil {
+		ctx.ServerError("GenerateClientSecret", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("settings.create_oauth2_application_success"), true)
+	ctx.HTML(200, oa.TplAppEdit)
+}
+
+// EditShow displays the given application
+func (oa *OAuth2CommonHandlers) EditShow(ctx *context.Context) {
+	oa.setLinks(ctx)
+	app := oa.getApp(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["App"] = app
+	ctx.HTML(200, oa.TplAppEdit)
+}
+
+// EditSave saves the oauth2 application
+func (oa *OAuth2CommonHandlers) EditSave(ctx *context.Context, form auth.EditOAuth2ApplicationForm) {
+	oa.setLinks(ctx)
+	app := oa.getApp(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["App"] = app
+
+	if ctx.HasError() {
+		ctx.HTML(200, oa.TplAppEdit)
+		return
+	}
+
+	redirectURIs, ok := parseRedirectURIs(form.RedirectURIs)
+	if !ok {
+		ctx.Data["Err_RedirectURIs"] = true
+		ctx.RenderWithErr(ctx.Tr("settings.oauth2_redirect_uris_invalid"), oa.TplAppEdit, &form)
+		return
+	}
+
+	if _, err := models.UpdateOAuth2Application(models.UpdateOAuth2ApplicationOptions{
+		ID:           app.ID,
+		Name:         form.ApplicationName,
+		UID:          oa.OwnerID,
+		RedirectURIs: redirectURIs,
+	}); err != nil {
+		ctx.ServerError("UpdateOAuth2Application", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("settings.update_oauth2_application_success"))
+	ctx.Redirect(oa.BasePathList)
+}
+
+// RegenerateSecret regenerates the secret
+func (oa *OAuth2CommonHandlers) RegenerateSecret(ctx *context.Context) {
+	oa.setLinks(ctx)
+	app := oa.getApp(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var err error
+	ctx.Data["App"] = app
+	ctx.Data["ClientSecret"], err = app.GenerateClientSecret()
+	if err != nil {
+		ctx.ServerError("GenerateClientSecret", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("settings.update_oauth2_application_success"), true)
+	ctx.HTML(200, oa.TplAppEdit)
+}
+
+// DeleteApp deletes the given oauth2 application
+func (oa *OAuth2CommonHandlers) DeleteApp(ctx *context.Context) {
+	if err := models.DeleteOAuth2Application(ctx.QueryInt64("id"), oa.OwnerID); err != nil {
+		ctx.Flash.Error("DeleteOAuth2Application: " + err.Error())
+	} else {
+		log.Trace("OAuth2 application deleted: %d", ctx.QueryInt64("id"))
+		ctx.Flash.Success(ctx.Tr("settings.remove_oauth2_application_success"))
+	}
+
+	ctx.JSON(200, map[string]interface{}{
+		"redirect": oa.BasePathList,
+	})
+}
+
+func (oa *OAuth2CommonHandlers) getApp(ctx *context.Context) *models.OAuth2Application {
+	app, err := models.GetOAuth2ApplicationByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrOAuthApplicationNotFound(err) {
+			ctx.NotFound("GetOAuth2ApplicationByID", err)
+		} else {
+			ctx.ServerError("GetOAuth2ApplicationByID", err)
+		}
+		return nil
+	} else if app.UID != oa.OwnerID {
+		ctx.NotFound("GetOAuth2ApplicationByID", nil)
+		return nil
+	}
+	return app
+}
+
+func userOAuth2Handlers(ctx *context.Context) *OAuth2CommonHandlers {
+	return &OAuth2CommonHandlers{
+		OwnerID:            ctx.User.ID,
+		BasePathList:       setting.AppSubURL + "/user/settings/applications",
+		BasePathEditPrefix: setting.AppSubURL + "/user/settings/applications/oauth2",
+		TplAppList:         tplSettingsApplications,
+		TplAppEdit:         tplSettingsOAuthApplicationEdit,
+		LoadListData:       loadApplicationsData,
+	}
+}
+
+// OAuthApplicationsPost response for adding an oauth2 application
+func OAuthApplicationsPost(ctx *context.Context, form auth.EditOAuth2ApplicationForm) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	userOAuth2Handlers(ctx).AddApp(ctx, form)
+}
+
+// OAuth2ApplicationEdit response for editing an oauth2 application
+func OAuth2ApplicationEdit(ctx *context.Context, form auth.EditOAuth2ApplicationForm) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	userOAuth2Handlers(ctx).EditSave(ctx, form)
+}
+
+// OAuthApplicationsRegenerateSecret handles the post request for regenerating the secret
+func OAuthApplicationsRegenerateSecret(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	userOAuth2Handlers(ctx).RegenerateSecret(ctx)
+}
+
+// OAuth2ApplicationShow displays the given application
+func OAuth2ApplicationShow(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	userOAuth2Handlers(ctx).EditShow(ctx)
+}
+
+// DeleteOAuth2Application deletes the given oauth2 application
+func DeleteOAuth2Application(ctx *context.Context) {
+	userOAuth2Handlers(ctx).DeleteApp(ctx)
+}
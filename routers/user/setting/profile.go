@@ -17,8 +17,8 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
 
-	"github.com/Unknwon/com"
 	"github.com/Unknwon/i18n"
 )
 
@@ -139,7 +139,7 @@ func UpdateAvatarSetting(ctx *context.Context, form auth.AvatarForm, ctxUser *mo
 	} else {
 		// No avatar is uploaded but setting has been changed to enable,
 		// generate a random one when needed.
-		if ctxUser.UseCustomAvatar && !com.IsFile(ctxUser.CustomAvatarPath()) {
+		if ctxUser.UseCustomAvatar && !hasCustomAvatar(ctxUser) {
 			if err := ctxUser.GenerateRandomAvatar(); err != nil {
 				log.Error(4, "GenerateRandomAvatar[%d]: %v", ctxUser.ID, err)
 			}
@@ -153,6 +153,14 @@ func UpdateAvatarSetting(ctx *context.Context, form auth.AvatarForm, ctxUser *mo
 	return nil
 }
 
+func hasCustomAvatar(u *models.User) bool {
+	if u.Avatar == "" {
+		return false
+	}
+	_, err := storage.Avatars.Stat(u.CustomAvatarRelativePath())
+	return err == nil
+}
+
 // AvatarPost response for change user's avatar request
 func AvatarPost(ctx *context.Context, form auth.AvatarForm) {
 	if err := UpdateAvatarSetting(ctx, form, ctx.User); err != nil {
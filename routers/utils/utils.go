@@ -5,9 +5,16 @@
 package utils
 
 import (
+	"errors"
+	"net/url"
 	"strings"
+
+	"code.gitea.io/gitea/modules/validation"
 )
 
+// ErrInvalidRemoteAddress is returned if a remote address is not a valid HTTP(S) URL
+var ErrInvalidRemoteAddress = errors.New("invalid remote address")
+
 // RemoveUsernameParameterSuffix returns the username parameter without the (fullname) suffix - leaving just the username
 func RemoveUsernameParameterSuffix(name string) string {
 	if index := strings.Index(name, " ("); index >= 0 {
@@ -34,3 +41,21 @@ func IsValidSlackChannel(channelName string) bool {
 
 	return true
 }
+
+// ComposeRemoteAddress checks that the given remote address is a valid HTTP(S) URL
+// and returns it with the username and password, if any, added to it.
+func ComposeRemoteAddress(address, username, password string) (string, error) {
+	address = strings.TrimSpace(address)
+	if !validation.IsValidURL(address) {
+		return "", ErrInvalidRemoteAddress
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return "", ErrInvalidRemoteAddress
+	}
+	if len(username)+len(password) > 0 {
+		u.User = url.UserPassword(username, password)
+	}
+	return u.String(), nil
+}